@@ -0,0 +1,320 @@
+package account
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"flashSurvey/store"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const magicLinkTimeout = 15 * time.Minute
+
+type pendingLogin struct {
+	email   string
+	expires time.Time
+}
+
+// Accounts binds browser user ids to creator accounts.
+// An account is identified by the e-mail address of the creator.
+// All user ids bound to the same account are treated as the same creator.
+// All methods can be called on a nil value, which means that accounts are disabled.
+type Accounts struct {
+	mutex   sync.Mutex
+	pending map[string]pendingLogin
+	users   map[string]binding
+	// the registered passkeys of an account
+	passkeys   map[string][]*passkey
+	challenges map[string]challenge
+	// the relying party id and origin used by WebAuthn
+	rpId   string
+	origin string
+	store  store.Store
+	roles  RoleConfig
+	admins map[string]bool
+}
+
+type binding struct {
+	email string
+	// session is set if the binding was verified by a passkey
+	session string
+	// handle identifies the binding in the session list without revealing the user id
+	handle   string
+	device   string
+	created  time.Time
+	lastSeen time.Time
+}
+
+func newBinding(email, session string) binding {
+	now := time.Now()
+	return binding{
+		email:    email,
+		session:  session,
+		handle:   randomToken(),
+		created:  now,
+		lastSeen: now,
+	}
+}
+
+// New creates a new account store. The host is the externally
+// visible URL of the server, which is required to use passkeys.
+func New(host string) *Accounts {
+	a := &Accounts{
+		pending:    make(map[string]pendingLogin),
+		users:      make(map[string]binding),
+		passkeys:   make(map[string][]*passkey),
+		challenges: make(map[string]challenge),
+		roles:      RoleConfig{Default: Creator, Users: map[string]Role{}},
+		admins:     map[string]bool{},
+	}
+	if u, err := url.Parse(host); err == nil && u.Hostname() != "" {
+		a.rpId = u.Hostname()
+		a.origin = u.Scheme + "://" + u.Host
+	}
+	return a
+}
+
+// NormalizeEMail returns the normalized form of an e-mail address
+// which is used as the account id
+func NormalizeEMail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// RequestMagicLink creates a token which can be used once to bind a
+// user id to the account of the given email address.
+func (a *Accounts) RequestMagicLink(email string) (string, error) {
+	if a == nil {
+		return "", errors.New("Die Anmeldung ist nicht verfügbar!")
+	}
+	email = NormalizeEMail(email)
+	if email == "" {
+		return "", errors.New("Es fehlt die E-Mail-Adresse!")
+	}
+
+	token := randomToken()
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	now := time.Now()
+	for t, p := range a.pending {
+		if now.After(p.expires) {
+			delete(a.pending, t)
+		}
+	}
+
+	a.pending[token] = pendingLogin{email: email, expires: now.Add(magicLinkTimeout)}
+	return token, nil
+}
+
+// ConfirmMagicLink binds the given user id to the account the token was created for.
+func (a *Accounts) ConfirmMagicLink(token string, userId string) (string, error) {
+	if a == nil {
+		return "", errors.New("Die Anmeldung ist nicht verfügbar!")
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	p, ok := a.pending[token]
+	if !ok || time.Now().After(p.expires) {
+		return "", errors.New("Der Anmelde-Link ist ungültig oder abgelaufen!")
+	}
+	delete(a.pending, token)
+
+	if len(a.passkeys[p.email]) > 0 {
+		return "", errors.New("Für dieses Konto ist die Anmeldung mit einem Passkey erforderlich!")
+	}
+
+	a.users[userId] = newBinding(p.email, "")
+	return p.email, nil
+}
+
+// BindExternal binds the given user id to the account with the given id, which
+// was authenticated by an external identity provider.
+func (a *Accounts) BindExternal(userId string, id string) (string, error) {
+	if a == nil {
+		return "", errors.New("Die Anmeldung ist nicht verfügbar!")
+	}
+	id = NormalizeEMail(id)
+	if id == "" {
+		return "", errors.New("Die Anmeldung ist fehlgeschlagen!")
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if len(a.passkeys[id]) > 0 {
+		return "", errors.New("Für dieses Konto ist die Anmeldung mit einem Passkey erforderlich!")
+	}
+
+	a.users[userId] = newBinding(id, "")
+	return id, nil
+}
+
+// Logout removes the binding of the given user id
+func (a *Accounts) Logout(userId string) {
+	if a == nil {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	delete(a.users, userId)
+}
+
+// AccountOf returns the account the given user id is bound to
+func (a *Accounts) AccountOf(userId string) (string, bool) {
+	if a == nil {
+		return "", false
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	b, ok := a.users[userId]
+	return b.email, ok
+}
+
+// SameAccount returns true if both user ids are bound to the same account
+func (a *Accounts) SameAccount(userId1, userId2 string) bool {
+	if a == nil {
+		return false
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	acc1, ok := a.users[userId1]
+	if !ok {
+		return false
+	}
+	acc2, ok := a.users[userId2]
+	if !ok {
+		return false
+	}
+	return acc1.email == acc2.email
+}
+
+// Verify checks if the given user id is allowed to be used with the given
+// session token. If the user id is bound to an account which is protected
+// by a passkey, the session token issued at the passkey login is required.
+// This way a copied uid cookie alone does not grant access to the account.
+func (a *Accounts) Verify(userId string, session string) bool {
+	if a == nil {
+		return true
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	b, ok := a.users[userId]
+	if !ok || len(a.passkeys[b.email]) == 0 {
+		return true
+	}
+	return b.session != "" && subtle.ConstantTimeCompare([]byte(b.session), []byte(session)) == 1
+}
+
+// Touch records the activity of the given user id
+func (a *Accounts) Touch(userId string, device string) {
+	if a == nil {
+		return
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if b, ok := a.users[userId]; ok {
+		b.lastSeen = time.Now()
+		if len(device) > 100 {
+			device = device[:100]
+		}
+		b.device = device
+		a.users[userId] = b
+	}
+}
+
+// Session describes a device bound to an account
+type Session struct {
+	Handle   string
+	Device   string
+	Created  time.Time
+	LastSeen time.Time
+	Passkey  bool
+	Current  bool
+}
+
+// Sessions returns all devices bound to the account of the given user id
+func (a *Accounts) Sessions(userId string) []Session {
+	if a == nil {
+		return nil
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	current, ok := a.users[userId]
+	if !ok {
+		return nil
+	}
+
+	var list []Session
+	for u, b := range a.users {
+		if b.email == current.email {
+			list = append(list, Session{
+				Handle:   b.handle,
+				Device:   b.device,
+				Created:  b.created,
+				LastSeen: b.lastSeen,
+				Passkey:  b.session != "",
+				Current:  u == userId,
+			})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].LastSeen.After(list[j].LastSeen)
+	})
+	return list
+}
+
+// Revoke removes the binding with the given handle from the account
+// of the given user id. The user id of the revoked device is returned.
+func (a *Accounts) Revoke(userId string, handle string) (string, error) {
+	if a == nil {
+		return "", errors.New("Sie sind nicht angemeldet!")
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	current, ok := a.users[userId]
+	if !ok {
+		return "", errors.New("Sie sind nicht angemeldet!")
+	}
+
+	for u, b := range a.users {
+		if b.email == current.email && b.handle == handle {
+			if u == userId {
+				return "", errors.New("Das aktuelle Gerät kann nicht entfernt werden!")
+			}
+			delete(a.users, u)
+			return u, nil
+		}
+	}
+	return "", errors.New("Dieses Gerät ist nicht bekannt!")
+}
+
+func randomToken() string {
+	b := make([]byte, 24)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
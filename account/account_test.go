@@ -0,0 +1,38 @@
+package account
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessions(t *testing.T) {
+	a := New("")
+	for _, d := range []string{"tablet", "laptop"} {
+		token, err := a.RequestMagicLink("creator@example.com")
+		assert.NoError(t, err)
+		_, err = a.ConfirmMagicLink(token, d)
+		assert.NoError(t, err)
+		a.Touch(d, "Browser on "+d)
+	}
+
+	sessions := a.Sessions("laptop")
+	assert.Len(t, sessions, 2)
+
+	var tablet Session
+	for _, s := range sessions {
+		if s.Device == "Browser on tablet" {
+			tablet = s
+		} else {
+			assert.True(t, s.Current)
+			_, err := a.Revoke("laptop", s.Handle)
+			assert.Error(t, err)
+		}
+	}
+
+	revoked, err := a.Revoke("laptop", tablet.Handle)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "tablet", revoked)
+	assert.Len(t, a.Sessions("laptop"), 1)
+	assert.Nil(t, a.Sessions("tablet"))
+}
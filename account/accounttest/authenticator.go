@@ -0,0 +1,147 @@
+// Package accounttest provides a simulated passkey authenticator which is
+// used to test the passkey login without a browser.
+package accounttest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	flagUserPresent  = 0x01
+	flagAttestedData = 0x40
+)
+
+// Authenticator simulates a passkey authenticator
+type Authenticator struct {
+	t    *testing.T
+	key  *ecdsa.PrivateKey
+	id   []byte
+	rpId string
+	// Origin is the origin the authenticator reports to the server
+	Origin    string
+	signCount uint32
+}
+
+// NewAuthenticator creates an authenticator for the host https://example.com
+func NewAuthenticator(t *testing.T) *Authenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	return &Authenticator{t: t, key: key, id: []byte("credential-id"), rpId: "example.com", Origin: "https://example.com"}
+}
+
+func cborHead(major byte, n int) []byte {
+	if n < 24 {
+		return []byte{major<<5 | byte(n)}
+	}
+	return []byte{major<<5 | 24, byte(n)}
+}
+
+func cborInt(i int) []byte {
+	if i < 0 {
+		return cborHead(1, -1-i)
+	}
+	return cborHead(0, i)
+}
+
+func cborBytes(b []byte) []byte {
+	return append(cborHead(2, len(b)), b...)
+}
+
+func cborText(s string) []byte {
+	return append(cborHead(3, len(s)), s...)
+}
+
+func (a *Authenticator) coseKey() []byte {
+	x := make([]byte, 32)
+	y := make([]byte, 32)
+	a.key.X.FillBytes(x)
+	a.key.Y.FillBytes(y)
+	k := cborHead(5, 5)
+	k = append(k, cborInt(1)...)
+	k = append(k, cborInt(2)...)
+	k = append(k, cborInt(3)...)
+	k = append(k, cborInt(-7)...)
+	k = append(k, cborInt(-1)...)
+	k = append(k, cborInt(1)...)
+	k = append(k, cborInt(-2)...)
+	k = append(k, cborBytes(x)...)
+	k = append(k, cborInt(-3)...)
+	k = append(k, cborBytes(y)...)
+	return k
+}
+
+func (a *Authenticator) authData(attested bool) []byte {
+	rpIdHash := sha256.Sum256([]byte(a.rpId))
+	d := append([]byte(nil), rpIdHash[:]...)
+	flags := byte(flagUserPresent)
+	if attested {
+		flags |= flagAttestedData
+	}
+	d = append(d, flags)
+	d = binary.BigEndian.AppendUint32(d, a.signCount)
+	if attested {
+		d = append(d, make([]byte, 16)...)
+		d = binary.BigEndian.AppendUint16(d, uint16(len(a.id)))
+		d = append(d, a.id...)
+		d = append(d, a.coseKey()...)
+	}
+	return d
+}
+
+func (a *Authenticator) clientData(kind string, options []byte) []byte {
+	var o struct {
+		Challenge string `json:"challenge"`
+	}
+	assert.NoError(a.t, json.Unmarshal(options, &o))
+	cd, err := json.Marshal(map[string]string{"type": kind, "challenge": o.Challenge, "origin": a.Origin})
+	assert.NoError(a.t, err)
+	return cd
+}
+
+// Create returns the response to the given registration options
+func (a *Authenticator) Create(options []byte) []byte {
+	att := cborHead(5, 3)
+	att = append(att, cborText("fmt")...)
+	att = append(att, cborText("none")...)
+	att = append(att, cborText("attStmt")...)
+	att = append(att, cborHead(5, 0)...)
+	att = append(att, cborText("authData")...)
+	att = append(att, cborBytes(a.authData(true))...)
+
+	r, err := json.Marshal(map[string]string{
+		"id":                base64.RawURLEncoding.EncodeToString(a.id),
+		"clientDataJSON":    base64.RawURLEncoding.EncodeToString(a.clientData("webauthn.create", options)),
+		"attestationObject": base64.RawURLEncoding.EncodeToString(att),
+	})
+	assert.NoError(a.t, err)
+	return r
+}
+
+// Get returns the response to the given login options
+func (a *Authenticator) Get(options []byte) []byte {
+	a.signCount++
+	authData := a.authData(false)
+	cd := a.clientData("webauthn.get", options)
+	cdHash := sha256.Sum256(cd)
+	signed := sha256.Sum256(append(append([]byte(nil), authData...), cdHash[:]...))
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, signed[:])
+	assert.NoError(a.t, err)
+
+	r, err := json.Marshal(map[string]string{
+		"id":                base64.RawURLEncoding.EncodeToString(a.id),
+		"clientDataJSON":    base64.RawURLEncoding.EncodeToString(cd),
+		"authenticatorData": base64.RawURLEncoding.EncodeToString(authData),
+		"signature":         base64.RawURLEncoding.EncodeToString(sig),
+	})
+	assert.NoError(a.t, err)
+	return r
+}
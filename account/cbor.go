@@ -0,0 +1,128 @@
+package account
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// decodeCBOR decodes a single CBOR data item as used by WebAuthn.
+// Only the subset required to read attestation objects and COSE keys
+// is supported. The remaining bytes are returned.
+// Integers are returned as int64, byte strings as []byte, text strings
+// as string, arrays as []any and maps as map[any]any.
+func decodeCBOR(data []byte) (any, []byte, error) {
+	return decodeCBORDepth(data, 0)
+}
+
+var errCBOR = errors.New("invalid cbor data")
+
+func decodeCBORDepth(data []byte, depth int) (any, []byte, error) {
+	if depth > 16 || len(data) == 0 {
+		return nil, nil, errCBOR
+	}
+
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+	data = data[1:]
+
+	var arg uint64
+	switch {
+	case info < 24:
+		arg = uint64(info)
+	case info == 24:
+		if len(data) < 1 {
+			return nil, nil, errCBOR
+		}
+		arg = uint64(data[0])
+		data = data[1:]
+	case info == 25:
+		if len(data) < 2 {
+			return nil, nil, errCBOR
+		}
+		arg = uint64(binary.BigEndian.Uint16(data))
+		data = data[2:]
+	case info == 26:
+		if len(data) < 4 {
+			return nil, nil, errCBOR
+		}
+		arg = uint64(binary.BigEndian.Uint32(data))
+		data = data[4:]
+	case info == 27:
+		if len(data) < 8 {
+			return nil, nil, errCBOR
+		}
+		arg = binary.BigEndian.Uint64(data)
+		data = data[8:]
+	default:
+		return nil, nil, errCBOR
+	}
+
+	switch major {
+	case 0:
+		if arg > 1<<62 {
+			return nil, nil, errCBOR
+		}
+		return int64(arg), data, nil
+	case 1:
+		if arg > 1<<62 {
+			return nil, nil, errCBOR
+		}
+		return -1 - int64(arg), data, nil
+	case 2, 3:
+		if arg > uint64(len(data)) {
+			return nil, nil, errCBOR
+		}
+		b := data[:arg]
+		if major == 2 {
+			return append([]byte(nil), b...), data[arg:], nil
+		}
+		return string(b), data[arg:], nil
+	case 4:
+		if arg > uint64(len(data)) {
+			return nil, nil, errCBOR
+		}
+		arr := make([]any, 0, arg)
+		for range arg {
+			var item any
+			var err error
+			item, data, err = decodeCBORDepth(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			arr = append(arr, item)
+		}
+		return arr, data, nil
+	case 5:
+		if arg > uint64(len(data)) {
+			return nil, nil, errCBOR
+		}
+		m := make(map[any]any, arg)
+		for range arg {
+			var key, value any
+			var err error
+			key, data, err = decodeCBORDepth(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			if _, ok := key.([]byte); ok {
+				return nil, nil, errCBOR
+			}
+			value, data, err = decodeCBORDepth(data, depth+1)
+			if err != nil {
+				return nil, nil, err
+			}
+			m[key] = value
+		}
+		return m, data, nil
+	case 7:
+		switch info {
+		case 20:
+			return false, data, nil
+		case 21:
+			return true, data, nil
+		case 22:
+			return nil, data, nil
+		}
+	}
+	return nil, nil, errCBOR
+}
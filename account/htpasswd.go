@@ -0,0 +1,139 @@
+package account
+
+import (
+	"bufio"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Htpasswd contains the password hashes of the users by their names. The
+// formats created by "htpasswd -m" (apr1 MD5) and "htpasswd -s" (SHA1) as
+// well as plain passwords are supported.
+type Htpasswd map[string]string
+
+// LoadHtpasswd reads the htpasswd file with the given name
+func LoadHtpasswd(name string) (Htpasswd, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseHtpasswd(f)
+}
+
+// ParseHtpasswd reads the lines user:hash, empty lines and comments are ignored
+func ParseHtpasswd(r io.Reader) (Htpasswd, error) {
+	h := Htpasswd{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		user, hash, ok := strings.Cut(text, ":")
+		if !ok || user == "" || hash == "" {
+			return nil, fmt.Errorf("htpasswd line %d: user:hash expected", line)
+		}
+		if strings.HasPrefix(hash, "$2") || strings.HasPrefix(hash, "$5$") || strings.HasPrefix(hash, "$6$") {
+			return nil, fmt.Errorf("htpasswd line %d: hash of user %s not supported, use htpasswd -m or -s", line, user)
+		}
+		h[user] = hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(h) == 0 {
+		return nil, fmt.Errorf("htpasswd contains no users")
+	}
+	return h, nil
+}
+
+// Verify returns true if the password of the user is correct
+func (h Htpasswd) Verify(user, password string) bool {
+	hash, ok := h[user]
+	if !ok {
+		return false
+	}
+	var computed string
+	switch {
+	case strings.HasPrefix(hash, "{SHA}"):
+		sum := sha1.Sum([]byte(password))
+		computed = "{SHA}" + base64.StdEncoding.EncodeToString(sum[:])
+	case strings.HasPrefix(hash, apr1Magic):
+		salt, _, _ := strings.Cut(strings.TrimPrefix(hash, apr1Magic), "$")
+		computed = apr1(password, salt)
+	default:
+		computed = password
+	}
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(hash)) == 1
+}
+
+const apr1Magic = "$apr1$"
+
+// apr1 returns the MD5 based hash of the password used by the Apache web server
+func apr1(password, salt string) string {
+	if len(salt) > 8 {
+		salt = salt[:8]
+	}
+	pw := []byte(password)
+
+	alt := md5.Sum([]byte(password + salt + password))
+	ctx := md5.New()
+	ctx.Write([]byte(password + apr1Magic + salt))
+	for i := len(pw); i > 0; i -= 16 {
+		ctx.Write(alt[:min(i, 16)])
+	}
+	for i := len(pw); i > 0; i >>= 1 {
+		if i&1 != 0 {
+			ctx.Write([]byte{0})
+		} else {
+			ctx.Write(pw[:1])
+		}
+	}
+	final := ctx.Sum(nil)
+
+	// the rounds slow down brute force attacks
+	for i := 0; i < 1000; i++ {
+		c := md5.New()
+		if i&1 != 0 {
+			c.Write(pw)
+		} else {
+			c.Write(final)
+		}
+		if i%3 != 0 {
+			c.Write([]byte(salt))
+		}
+		if i%7 != 0 {
+			c.Write(pw)
+		}
+		if i&1 != 0 {
+			c.Write(final)
+		} else {
+			c.Write(pw)
+		}
+		final = c.Sum(nil)
+	}
+
+	const itoa64 = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+	var b strings.Builder
+	b.WriteString(apr1Magic + salt + "$")
+	encode := func(v uint, n int) {
+		for ; n > 0; n-- {
+			b.WriteByte(itoa64[v&0x3f])
+			v >>= 6
+		}
+	}
+	for _, g := range [][3]int{{0, 6, 12}, {1, 7, 13}, {2, 8, 14}, {3, 9, 15}, {4, 10, 5}} {
+		encode(uint(final[g[0]])<<16|uint(final[g[1]])<<8|uint(final[g[2]]), 4)
+	}
+	encode(uint(final[11]), 2)
+	return b.String()
+}
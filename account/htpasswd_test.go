@@ -0,0 +1,45 @@
+package account
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHtpasswd(t *testing.T) {
+	// the hashes are created by openssl passwd -apr1 and htpasswd -s
+	h, err := ParseHtpasswd(strings.NewReader(`
+# operators
+md5:$apr1$r31abcde$SZEN.U5sWGGNcv9YsUrqI.
+sha:{SHA}5en6G6MezRroT3XKqkdPOmY/BfQ=
+plain:secret
+`))
+	require.NoError(t, err)
+
+	for _, user := range []string{"md5", "sha", "plain"} {
+		assert.True(t, h.Verify(user, "secret"), user)
+		assert.False(t, h.Verify(user, "wrong"), user)
+		assert.False(t, h.Verify(user, ""), user)
+	}
+	assert.False(t, h.Verify("unknown", "secret"))
+}
+
+func TestHtpasswdInvalid(t *testing.T) {
+	for _, content := range []string{
+		"",
+		"# only a comment",
+		"user",
+		"bcrypt:$2y$05$abcdefghijklmnopqrstuu",
+	} {
+		_, err := ParseHtpasswd(strings.NewReader(content))
+		assert.Error(t, err, content)
+	}
+}
+
+func TestApr1(t *testing.T) {
+	// created by openssl passwd -apr1 -salt xy12
+	assert.Equal(t, "$apr1$xy12$4etmJfkXXkp1HSeASa/iq.", apr1("a", "xy12"))
+	assert.Equal(t, "$apr1$xy12$mUY40PLALlhHn1vuFyiEg1", apr1("a long password with more than sixteen characters", "xy12"))
+}
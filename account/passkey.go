@@ -0,0 +1,422 @@
+package account
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+)
+
+const challengeTimeout = 5 * time.Minute
+
+const (
+	flagUserPresent  = 0x01
+	flagAttestedData = 0x40
+	coseAlgES256     = -7
+)
+
+type passkey struct {
+	id        []byte
+	key       *ecdsa.PublicKey
+	signCount uint32
+}
+
+type challenge struct {
+	value   []byte
+	kind    string
+	email   string
+	expires time.Time
+}
+
+// b64url is a byte slice which is encoded as base64url in JSON,
+// which is the encoding used by the WebAuthn JavaScript helpers.
+type b64url []byte
+
+func (b b64url) MarshalJSON() ([]byte, error) {
+	return json.Marshal(base64.RawURLEncoding.EncodeToString(b))
+}
+
+func (b *b64url) UnmarshalJSON(data []byte) error {
+	var str string
+	err := json.Unmarshal(data, &str)
+	if err != nil {
+		return err
+	}
+	*b, err = base64.RawURLEncoding.DecodeString(strings.TrimRight(str, "="))
+	return err
+}
+
+type credentialParam struct {
+	Type string `json:"type"`
+	Alg  int    `json:"alg"`
+}
+
+type credentialDescriptor struct {
+	Type string `json:"type"`
+	Id   b64url `json:"id"`
+}
+
+type creationOptions struct {
+	Challenge b64url `json:"challenge"`
+	Rp        struct {
+		Id   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"rp"`
+	User struct {
+		Id          b64url `json:"id"`
+		Name        string `json:"name"`
+		DisplayName string `json:"displayName"`
+	} `json:"user"`
+	PubKeyCredParams       []credentialParam      `json:"pubKeyCredParams"`
+	Timeout                int                    `json:"timeout"`
+	Attestation            string                 `json:"attestation"`
+	ExcludeCredentials     []credentialDescriptor `json:"excludeCredentials"`
+	AuthenticatorSelection struct {
+		ResidentKey        string `json:"residentKey"`
+		RequireResidentKey bool   `json:"requireResidentKey"`
+		UserVerification   string `json:"userVerification"`
+	} `json:"authenticatorSelection"`
+}
+
+type requestOptions struct {
+	Challenge        b64url `json:"challenge"`
+	RpId             string `json:"rpId"`
+	Timeout          int    `json:"timeout"`
+	UserVerification string `json:"userVerification"`
+}
+
+// credentialResponse is the response sent by the browser. Depending on the
+// ceremony either AttestationObject or AuthenticatorData and Signature are set.
+type credentialResponse struct {
+	Id                b64url `json:"id"`
+	ClientDataJSON    b64url `json:"clientDataJSON"`
+	AttestationObject b64url `json:"attestationObject"`
+	AuthenticatorData b64url `json:"authenticatorData"`
+	Signature         b64url `json:"signature"`
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge b64url `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+// PasskeysAvailable returns true if passkeys can be used
+func (a *Accounts) PasskeysAvailable() bool {
+	return a != nil && a.rpId != ""
+}
+
+// PasskeyCount returns the number of passkeys registered for the account
+// the given user id is bound to.
+func (a *Accounts) PasskeyCount(userId string) int {
+	if a == nil {
+		return 0
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	b, ok := a.users[userId]
+	if !ok {
+		return 0
+	}
+	return len(a.passkeys[b.email])
+}
+
+// BeginRegistration creates the options required to register a new passkey
+// for the account the given user id is bound to.
+func (a *Accounts) BeginRegistration(userId string) ([]byte, error) {
+	if !a.PasskeysAvailable() {
+		return nil, errors.New("Passkeys sind nicht verfügbar!")
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	b, ok := a.users[userId]
+	if !ok {
+		return nil, errors.New("Sie sind nicht angemeldet!")
+	}
+
+	c := a.newChallenge(userId, "webauthn.create", b.email)
+
+	var o creationOptions
+	o.Challenge = c
+	o.Rp.Id = a.rpId
+	o.Rp.Name = "flashSurvey"
+	userHandle := sha256.Sum256([]byte(b.email))
+	o.User.Id = userHandle[:]
+	o.User.Name = b.email
+	o.User.DisplayName = b.email
+	o.PubKeyCredParams = []credentialParam{{Type: "public-key", Alg: coseAlgES256}}
+	o.Timeout = int(challengeTimeout / time.Millisecond)
+	o.Attestation = "none"
+	o.ExcludeCredentials = []credentialDescriptor{}
+	for _, pk := range a.passkeys[b.email] {
+		o.ExcludeCredentials = append(o.ExcludeCredentials, credentialDescriptor{Type: "public-key", Id: pk.id})
+	}
+	o.AuthenticatorSelection.ResidentKey = "required"
+	o.AuthenticatorSelection.RequireResidentKey = true
+	o.AuthenticatorSelection.UserVerification = "preferred"
+
+	return json.Marshal(o)
+}
+
+// FinishRegistration verifies the browser's response and stores the new passkey.
+// From now on the account can only be used with a passkey. The returned session
+// token must be presented together with the user id, see Verify.
+func (a *Accounts) FinishRegistration(userId string, response []byte) (string, error) {
+	if !a.PasskeysAvailable() {
+		return "", errors.New("Passkeys sind nicht verfügbar!")
+	}
+
+	var r credentialResponse
+	err := json.Unmarshal(response, &r)
+	if err != nil {
+		return "", errors.New("Ungültige Antwort des Browsers!")
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	c, err := a.takeChallenge(userId, "webauthn.create", r.ClientDataJSON)
+	if err != nil {
+		return "", err
+	}
+
+	attestation, _, err := decodeCBOR(r.AttestationObject)
+	if err != nil {
+		return "", errors.New("Ungültige Antwort des Browsers!")
+	}
+	attMap, ok := attestation.(map[any]any)
+	if !ok {
+		return "", errors.New("Ungültige Antwort des Browsers!")
+	}
+	authData, ok := attMap["authData"].([]byte)
+	if !ok {
+		return "", errors.New("Ungültige Antwort des Browsers!")
+	}
+
+	flags, signCount, rest, err := a.parseAuthData(authData)
+	if err != nil {
+		return "", err
+	}
+	if flags&flagAttestedData == 0 || len(rest) < 18 {
+		return "", errors.New("Der Passkey enthält keinen Schlüssel!")
+	}
+	idLen := int(binary.BigEndian.Uint16(rest[16:18]))
+	rest = rest[18:]
+	if len(rest) < idLen {
+		return "", errors.New("Ungültige Antwort des Browsers!")
+	}
+	id := append([]byte(nil), rest[:idLen]...)
+	key, err := parseCOSEKey(rest[idLen:])
+	if err != nil {
+		return "", err
+	}
+
+	if len(a.findPasskey(id)) > 0 {
+		return "", errors.New("Dieser Passkey ist bereits registriert!")
+	}
+
+	a.passkeys[c.email] = append(a.passkeys[c.email], &passkey{id: id, key: key, signCount: signCount})
+
+	// all bindings of this account not verified by a passkey are removed
+	for u, b := range a.users {
+		if b.email == c.email && b.session == "" && u != userId {
+			delete(a.users, u)
+		}
+	}
+	session := randomToken()
+	a.users[userId] = newBinding(c.email, session)
+
+	log.Println("passkey registered")
+	return session, nil
+}
+
+// BeginLogin creates the options required to log in with a passkey
+func (a *Accounts) BeginLogin(userId string) ([]byte, error) {
+	if !a.PasskeysAvailable() {
+		return nil, errors.New("Passkeys sind nicht verfügbar!")
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	o := requestOptions{
+		Challenge:        a.newChallenge(userId, "webauthn.get", ""),
+		RpId:             a.rpId,
+		Timeout:          int(challengeTimeout / time.Millisecond),
+		UserVerification: "preferred",
+	}
+	return json.Marshal(o)
+}
+
+// FinishLogin verifies the browser's response and binds the user id to the
+// account the passkey belongs to. The returned session token must be presented
+// together with the user id, see Verify.
+func (a *Accounts) FinishLogin(userId string, response []byte) (string, string, error) {
+	if !a.PasskeysAvailable() {
+		return "", "", errors.New("Passkeys sind nicht verfügbar!")
+	}
+
+	var r credentialResponse
+	err := json.Unmarshal(response, &r)
+	if err != nil {
+		return "", "", errors.New("Ungültige Antwort des Browsers!")
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	_, err = a.takeChallenge(userId, "webauthn.get", r.ClientDataJSON)
+	if err != nil {
+		return "", "", err
+	}
+
+	found := a.findPasskey(r.Id)
+	if len(found) == 0 {
+		return "", "", errors.New("Dieser Passkey ist unbekannt!")
+	}
+	var email string
+	var pk *passkey
+	for e, p := range found {
+		email, pk = e, p
+	}
+
+	_, signCount, _, err := a.parseAuthData(r.AuthenticatorData)
+	if err != nil {
+		return "", "", err
+	}
+
+	clientHash := sha256.Sum256(r.ClientDataJSON)
+	signed := sha256.Sum256(append(append([]byte(nil), r.AuthenticatorData...), clientHash[:]...))
+	if !ecdsa.VerifyASN1(pk.key, signed[:], r.Signature) {
+		return "", "", errors.New("Die Signatur des Passkeys ist ungültig!")
+	}
+
+	if signCount != 0 || pk.signCount != 0 {
+		if signCount <= pk.signCount {
+			log.Println("passkey sign count did not increase, passkey may be cloned")
+			return "", "", errors.New("Die Signatur des Passkeys ist ungültig!")
+		}
+		pk.signCount = signCount
+	}
+
+	session := randomToken()
+	a.users[userId] = newBinding(email, session)
+	return email, session, nil
+}
+
+// newChallenge creates and stores a new challenge, the mutex must be held
+func (a *Accounts) newChallenge(userId, kind, email string) []byte {
+	value := make([]byte, 32)
+	_, err := rand.Read(value)
+	if err != nil {
+		panic(err)
+	}
+
+	now := time.Now()
+	for u, c := range a.challenges {
+		if now.After(c.expires) {
+			delete(a.challenges, u)
+		}
+	}
+
+	a.challenges[userId] = challenge{value: value, kind: kind, email: email, expires: now.Add(challengeTimeout)}
+	return value
+}
+
+// takeChallenge removes the challenge of the given user and checks that
+// the client data matches the challenge, the mutex must be held
+func (a *Accounts) takeChallenge(userId, kind string, clientDataJSON []byte) (challenge, error) {
+	c, ok := a.challenges[userId]
+	delete(a.challenges, userId)
+	if !ok || c.kind != kind || time.Now().After(c.expires) {
+		return challenge{}, errors.New("Die Anfrage ist abgelaufen!")
+	}
+
+	var cd clientData
+	err := json.Unmarshal(clientDataJSON, &cd)
+	if err != nil {
+		return challenge{}, errors.New("Ungültige Antwort des Browsers!")
+	}
+	if cd.Type != kind || !bytes.Equal(cd.Challenge, c.value) || cd.Origin != a.origin {
+		return challenge{}, errors.New("Ungültige Antwort des Browsers!")
+	}
+	return c, nil
+}
+
+// findPasskey returns the passkey with the given id mapped by the account it belongs to
+func (a *Accounts) findPasskey(id []byte) map[string]*passkey {
+	found := map[string]*passkey{}
+	for email, list := range a.passkeys {
+		for _, pk := range list {
+			if bytes.Equal(pk.id, id) {
+				found[email] = pk
+			}
+		}
+	}
+	return found
+}
+
+// parseAuthData checks the authenticator data and returns the flags,
+// the signature counter and the remaining attested credential data
+func (a *Accounts) parseAuthData(authData []byte) (byte, uint32, []byte, error) {
+	if len(authData) < 37 {
+		return 0, 0, nil, errors.New("Ungültige Antwort des Browsers!")
+	}
+	rpIdHash := sha256.Sum256([]byte(a.rpId))
+	if !bytes.Equal(authData[:32], rpIdHash[:]) {
+		return 0, 0, nil, errors.New("Der Passkey gehört zu einem anderen Server!")
+	}
+	flags := authData[32]
+	if flags&flagUserPresent == 0 {
+		return 0, 0, nil, errors.New("Der Passkey wurde nicht bestätigt!")
+	}
+	return flags, binary.BigEndian.Uint32(authData[33:37]), authData[37:], nil
+}
+
+// parseCOSEKey parses an ES256 public key in the COSE format
+func parseCOSEKey(data []byte) (*ecdsa.PublicKey, error) {
+	errKey := errors.New("Der Schlüssel des Passkeys wird nicht unterstützt!")
+
+	k, _, err := decodeCBOR(data)
+	if err != nil {
+		return nil, errKey
+	}
+	m, ok := k.(map[any]any)
+	if !ok {
+		return nil, errKey
+	}
+	if m[int64(1)] != int64(2) || m[int64(3)] != int64(coseAlgES256) || m[int64(-1)] != int64(1) {
+		return nil, errKey
+	}
+	x, okX := m[int64(-2)].([]byte)
+	y, okY := m[int64(-3)].([]byte)
+	if !okX || !okY || len(x) != 32 || len(y) != 32 {
+		return nil, errKey
+	}
+
+	// checks that the point is on the curve
+	_, err = ecdh.P256().NewPublicKey(append(append([]byte{4}, x...), y...))
+	if err != nil {
+		return nil, errKey
+	}
+
+	return &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(x),
+		Y:     new(big.Int).SetBytes(y),
+	}, nil
+}
@@ -0,0 +1,87 @@
+package account
+
+import (
+	"flashSurvey/account/accounttest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPasskey(t *testing.T) {
+	a := New("https://example.com")
+	auth := accounttest.NewAuthenticator(t)
+
+	token, err := a.RequestMagicLink("creator@example.com")
+	assert.NoError(t, err)
+	_, err = a.ConfirmMagicLink(token, "device1")
+	assert.NoError(t, err)
+	token, err = a.RequestMagicLink("creator@example.com")
+	assert.NoError(t, err)
+	_, err = a.ConfirmMagicLink(token, "device2")
+	assert.NoError(t, err)
+
+	options, err := a.BeginRegistration("device1")
+	assert.NoError(t, err)
+	session1, err := a.FinishRegistration("device1", auth.Create(options))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, a.PasskeyCount("device1"))
+
+	// device2 was bound by a magic link and is removed
+	_, ok := a.AccountOf("device2")
+	assert.False(t, ok)
+
+	// a copied uid cookie is not sufficient
+	assert.True(t, a.Verify("device1", session1))
+	assert.False(t, a.Verify("device1", ""))
+
+	// magic links are no longer accepted
+	token, err = a.RequestMagicLink("creator@example.com")
+	assert.NoError(t, err)
+	_, err = a.ConfirmMagicLink(token, "device3")
+	assert.Error(t, err)
+
+	options, err = a.BeginLogin("device3")
+	assert.NoError(t, err)
+	email, session3, err := a.FinishLogin("device3", auth.Get(options))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "creator@example.com", email)
+	assert.True(t, a.Verify("device3", session3))
+	assert.True(t, a.SameAccount("device1", "device3"))
+
+	// a replayed response is rejected
+	options, err = a.BeginLogin("device4")
+	assert.NoError(t, err)
+	response := auth.Get(options)
+	_, _, err = a.FinishLogin("device4", response)
+	assert.NoError(t, err)
+	_, _, err = a.FinishLogin("device4", response)
+	assert.Error(t, err)
+}
+
+func TestPasskeyWrongSignature(t *testing.T) {
+	a := New("https://example.com")
+	auth := accounttest.NewAuthenticator(t)
+
+	token, err := a.RequestMagicLink("creator@example.com")
+	assert.NoError(t, err)
+	_, err = a.ConfirmMagicLink(token, "device1")
+	assert.NoError(t, err)
+	options, err := a.BeginRegistration("device1")
+	assert.NoError(t, err)
+	_, err = a.FinishRegistration("device1", auth.Create(options))
+	assert.NoError(t, err)
+
+	other := accounttest.NewAuthenticator(t)
+	options, err = a.BeginLogin("device2")
+	assert.NoError(t, err)
+	_, _, err = a.FinishLogin("device2", other.Get(options))
+	assert.Error(t, err)
+
+	// wrong origin
+	other = auth
+	other.Origin = "https://evil.example.com"
+	options, err = a.BeginLogin("device2")
+	assert.NoError(t, err)
+	_, _, err = a.FinishLogin("device2", other.Get(options))
+	assert.Error(t, err)
+}
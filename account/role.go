@@ -0,0 +1,204 @@
+package account
+
+import (
+	"errors"
+	"flashSurvey/store"
+	"fmt"
+	"log"
+	"sort"
+)
+
+// Role is the role of a user
+type Role string
+
+const (
+	// Viewer is only allowed to vote
+	Viewer Role = "viewer"
+	// Moderator is allowed to control the surveys of other users, but not to create surveys
+	Moderator Role = "moderator"
+	// Creator is allowed to create surveys
+	Creator Role = "creator"
+	// Admin is allowed to do everything, including the assignment of roles
+	Admin Role = "admin"
+)
+
+// Roles contains all roles ordered by their privileges
+var Roles = []Role{Viewer, Moderator, Creator, Admin}
+
+func (r Role) valid() bool {
+	for _, v := range Roles {
+		if r == v {
+			return true
+		}
+	}
+	return false
+}
+
+// CanCreate returns true if the role allows to create surveys
+func (r Role) CanCreate() bool {
+	return r == Creator || r == Admin
+}
+
+// CanModerate returns true if the role allows to control the surveys of other users
+func (r Role) CanModerate() bool {
+	return r == Moderator || r == Admin
+}
+
+// CanControl returns true if the role allows to control any survey
+func (r Role) CanControl() bool {
+	return r.CanCreate() || r.CanModerate()
+}
+
+// CanAdminister returns true if the role allows to assign roles
+func (r Role) CanAdminister() bool {
+	return r == Admin
+}
+
+// RoleConfig is the persisted role configuration
+type RoleConfig struct {
+	// Default is the role of users which are not logged in or have no role assigned
+	Default Role
+	// Users maps the account to its role
+	Users map[string]Role
+}
+
+const rolesKey = "roles"
+
+// LoadRoles loads the role configuration from the given store.
+// The given admins always have the admin role, which allows to bootstrap
+// the configuration.
+func (a *Accounts) LoadRoles(st store.Store, admins []string) error {
+	rc := RoleConfig{Default: Creator, Users: map[string]Role{}}
+	_, err := st.Load(rolesKey, &rc)
+	if err != nil {
+		return fmt.Errorf("could not load roles: %w", err)
+	}
+	if rc.Users == nil {
+		rc.Users = map[string]Role{}
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	a.store = st
+	a.roles = rc
+	a.admins = map[string]bool{}
+	for _, admin := range admins {
+		if admin != "" {
+			a.admins[NormalizeEMail(admin)] = true
+		}
+	}
+	log.Printf("default role is %s, %d users with roles", rc.Default, len(rc.Users))
+	return nil
+}
+
+// RoleOf returns the role of the given user id
+func (a *Accounts) RoleOf(userId string) Role {
+	if a == nil {
+		return Creator
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	return a.roleOf(userId)
+}
+
+func (a *Accounts) roleOf(userId string) Role {
+	if b, ok := a.users[userId]; ok {
+		if a.admins[b.email] {
+			return Admin
+		}
+		if r, ok := a.roles.Users[b.email]; ok {
+			return r
+		}
+	}
+	if a.roles.Default == "" {
+		return Creator
+	}
+	return a.roles.Default
+}
+
+// RoleEntry is a single role assignment
+type RoleEntry struct {
+	EMail string
+	Role  Role
+	Fixed bool
+}
+
+// GetRoles returns the role configuration
+func (a *Accounts) GetRoles(userId string) (Role, []RoleEntry, error) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if !a.roleOf(userId).CanAdminister() {
+		return "", nil, errors.New("Sie dürfen die Rollen nicht verwalten!")
+	}
+
+	var list []RoleEntry
+	for email := range a.admins {
+		list = append(list, RoleEntry{EMail: email, Role: Admin, Fixed: true})
+	}
+	for email, r := range a.roles.Users {
+		if !a.admins[email] {
+			list = append(list, RoleEntry{EMail: email, Role: r})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].EMail < list[j].EMail
+	})
+	return a.roles.Default, list, nil
+}
+
+// SetDefaultRole sets the role of users without an assigned role
+func (a *Accounts) SetDefaultRole(userId string, role Role) error {
+	return a.modifyRoles(userId, role, func(rc *RoleConfig) {
+		rc.Default = role
+	})
+}
+
+// SetRole assigns a role to the given account. If role is empty, the
+// assignment is removed.
+func (a *Accounts) SetRole(userId string, email string, role Role) error {
+	email = NormalizeEMail(email)
+	if email == "" {
+		return errors.New("Es fehlt die E-Mail-Adresse!")
+	}
+	if role == "" {
+		return a.modifyRoles(userId, Viewer, func(rc *RoleConfig) {
+			delete(rc.Users, email)
+		})
+	}
+	return a.modifyRoles(userId, role, func(rc *RoleConfig) {
+		rc.Users[email] = role
+	})
+}
+
+func (a *Accounts) modifyRoles(userId string, role Role, modify func(rc *RoleConfig)) error {
+	if !role.valid() {
+		return errors.New("Ungültige Rolle!")
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+
+	if !a.roleOf(userId).CanAdminister() {
+		return errors.New("Sie dürfen die Rollen nicht verwalten!")
+	}
+
+	rc := RoleConfig{Default: a.roles.Default, Users: map[string]Role{}}
+	for k, v := range a.roles.Users {
+		rc.Users[k] = v
+	}
+	modify(&rc)
+
+	if a.store != nil {
+		err := a.store.Save(rolesKey, rc)
+		if err != nil {
+			log.Println(err)
+			return errors.New("Die Rollen konnten nicht gespeichert werden!")
+		}
+	}
+	a.roles = rc
+	return nil
+}
@@ -0,0 +1,43 @@
+package account
+
+import (
+	"flashSurvey/store"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func login(t *testing.T, a *Accounts, email, userId string) {
+	token, err := a.RequestMagicLink(email)
+	assert.NoError(t, err)
+	_, err = a.ConfirmMagicLink(token, userId)
+	assert.NoError(t, err)
+}
+
+func TestRoles(t *testing.T) {
+	st, err := store.New(t.TempDir())
+	assert.NoError(t, err)
+
+	a := New("")
+	assert.NoError(t, a.LoadRoles(st, []string{"Admin@example.com"}))
+	login(t, a, "admin@example.com", "admin")
+	login(t, a, "mod@example.com", "mod")
+
+	assert.EqualValues(t, Admin, a.RoleOf("admin"))
+	assert.EqualValues(t, Creator, a.RoleOf("anonymous"))
+
+	assert.Error(t, a.SetDefaultRole("mod", Viewer))
+	assert.NoError(t, a.SetDefaultRole("admin", Viewer))
+	assert.NoError(t, a.SetRole("admin", "mod@example.com", Moderator))
+	assert.Error(t, a.SetRole("admin", "mod@example.com", "superuser"))
+
+	assert.EqualValues(t, Viewer, a.RoleOf("anonymous"))
+	assert.EqualValues(t, Moderator, a.RoleOf("mod"))
+
+	// the configuration is persisted
+	b := New("")
+	assert.NoError(t, b.LoadRoles(st, nil))
+	login(t, b, "mod@example.com", "mod")
+	assert.EqualValues(t, Moderator, b.RoleOf("mod"))
+	assert.EqualValues(t, Viewer, b.RoleOf("anonymous"))
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"flashSurvey/account"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+)
+
+// adminTLS returns the TLS configuration of the admin listener. The clients
+// have to present a certificate issued by one of the certificate authorities
+// contained in the PEM file caFile, so the management routes are not
+// reachable without a client certificate even if the port is exposed.
+func adminTLS(caFile string, certs *certReloader) (*tls.Config, error) {
+	if certs == nil {
+		return nil, errors.New("the admin listener requires a server certificate")
+	}
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read client certificate authorities: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+	return &tls.Config{
+		GetCertificate: certs.GetCertificate,
+		ClientAuth:     tls.RequireAndVerifyClientCert,
+		ClientCAs:      pool,
+		MinVersion:     tls.VersionTLS12,
+	}, nil
+}
+
+// logClient logs the subject of the client certificate of every request
+// to the admin listener
+func logClient(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		log.Printf("admin: %s %s by %s", request.Method, request.URL.Path, clientSubject(request))
+		h.ServeHTTP(writer, request)
+	})
+}
+
+// clientSubject returns the subject of the verified client certificate of
+// the request, it is empty if no certificate was presented
+func clientSubject(request *http.Request) string {
+	if request.TLS == nil || len(request.TLS.VerifiedChains) == 0 {
+		return ""
+	}
+	return request.TLS.VerifiedChains[0][0].Subject.String()
+}
+
+// adminUsers returns the users of the operator page, the password is the
+// password of the user admin. If neither is given, nil is returned and the
+// page is disabled.
+func adminUsers(password, htpasswdFile string) (account.Htpasswd, error) {
+	users := account.Htpasswd{}
+	if htpasswdFile != "" {
+		var err error
+		users, err = account.LoadHtpasswd(htpasswdFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read the users of the operator page: %w", err)
+		}
+	}
+	if password != "" {
+		users["admin"] = password
+	}
+	if len(users) == 0 {
+		return nil, nil
+	}
+	return users, nil
+}
@@ -0,0 +1,235 @@
+// Package archive writes the final results of the surveys to an S3
+// compatible object storage when they are deleted, so the results outlive
+// the instance, which keeps the surveys only in memory.
+package archive
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"flashSurvey/chart"
+	"flashSurvey/survey"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Archiver stores the results in a bucket
+type Archiver struct {
+	endpoint  string
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+	prefix    string
+	client    *http.Client
+	now       func() time.Time
+}
+
+// New creates an archiver which uses the given endpoint, e.g.
+// https://s3.eu-central-1.amazonaws.com. The objects are addressed
+// path-style, which is supported by most S3 compatible storages.
+// If endpoint is empty, nil is returned.
+func New(endpoint, bucket, region, accessKey, secretKey, prefix string) (*Archiver, error) {
+	if endpoint == "" {
+		return nil, nil
+	}
+	if bucket == "" || accessKey == "" || secretKey == "" {
+		return nil, errors.New("the archive requires a bucket and credentials")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	return &Archiver{
+		endpoint:  strings.TrimSuffix(endpoint, "/"),
+		bucket:    bucket,
+		region:    region,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		prefix:    prefix,
+		client:    &http.Client{Timeout: 30 * time.Second},
+		now:       time.Now,
+	}, nil
+}
+
+// Hooks returns the hooks which archive the surveys when they are
+// deleted by the creator or because of the timeout
+func (a *Archiver) Hooks() survey.Hooks {
+	store := func(e survey.ResultEvent) {
+		go func() {
+			err := a.Archive(e)
+			if err != nil {
+				log.Println("archive:", err)
+			}
+		}()
+	}
+	return survey.Hooks{
+		OnClose:  store,
+		OnExpire: store,
+	}
+}
+
+// Question is the archived result of a single question
+type Question struct {
+	Number  int
+	Title   string
+	Started time.Time
+	Ended   time.Time
+	// Encrypted is set if the question is end-to-end encrypted, then only
+	// the number of votes is known
+	Encrypted  bool `json:",omitempty"`
+	Votes      int
+	Ranked     bool             `json:",omitempty"`
+	Options    []Option         `json:",omitempty"`
+	WriteIns   []survey.WriteIn `json:",omitempty"`
+	Rating     *survey.Rating   `json:",omitempty"`
+	Annotation string           `json:",omitempty"`
+	// Chart is the name of the object containing the bar chart
+	Chart string `json:",omitempty"`
+}
+
+// Option is the archived result of an option
+type Option struct {
+	Title   string
+	Votes   int
+	Percent float64
+}
+
+// Document is the archived survey
+type Document struct {
+	SurveyId  survey.SurveyId
+	Archived  time.Time
+	Questions []Question
+}
+
+// Archive writes the questions of the survey as a JSON document and the
+// charts of the questions as PNG images to the bucket. All objects of a
+// survey share the prefix <prefix><survey id>/<time>.
+func (a *Archiver) Archive(e survey.ResultEvent) error {
+	now := a.now()
+	base := a.prefix + string(e.SurveyId) + "/" + now.UTC().Format("20060102T150405Z")
+	d := Document{SurveyId: e.SurveyId, Archived: now}
+	for _, r := range e.Rounds {
+		q := Question{
+			Number:     r.Result.Number,
+			Title:      r.Result.Title,
+			Started:    r.Result.Started,
+			Ended:      r.Ended,
+			Encrypted:  r.Result.Encrypted,
+			Votes:      r.Result.Votes,
+			Ranked:     r.Result.Ranked,
+			WriteIns:   r.Result.WriteIns,
+			Rating:     r.Result.Rating,
+			Annotation: r.Result.Annotation,
+		}
+		if !r.Result.Encrypted {
+			for _, o := range r.Result.Result {
+				q.Options = append(q.Options, Option{Title: o.Title, Votes: o.VoteCount(), Percent: o.PercentValue()})
+			}
+			img, err := chart.PNG(r.Result)
+			if err != nil {
+				return err
+			}
+			q.Chart = base + "-" + strconv.Itoa(r.Result.Number) + ".png"
+			err = a.put(q.Chart, "image/png", img)
+			if err != nil {
+				return err
+			}
+		}
+		d.Questions = append(d.Questions, q)
+	}
+
+	data, err := json.MarshalIndent(d, "", "  ")
+	if err != nil {
+		return err
+	}
+	return a.put(base+".json", "application/json", data)
+}
+
+// SetExpiration configures the bucket to delete the archived objects after
+// the given number of days. This replaces the lifecycle configuration of
+// the bucket.
+func (a *Archiver) SetExpiration(days int) error {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?><LifecycleConfiguration><Rule><ID>flashSurvey</ID><Filter><Prefix>`)
+	if err := xmlEscape(&b, a.prefix); err != nil {
+		return err
+	}
+	fmt.Fprintf(&b, `</Prefix></Filter><Status>Enabled</Status><Expiration><Days>%d</Days></Expiration></Rule></LifecycleConfiguration>`, days)
+
+	sum := md5.Sum(b.Bytes())
+	req, err := a.request(http.MethodPut, "", "lifecycle=", b.Bytes())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/xml")
+	req.Header.Set("Content-Md5", base64.StdEncoding.EncodeToString(sum[:]))
+	return a.do(req)
+}
+
+// put stores an object
+func (a *Archiver) put(key, contentType string, body []byte) error {
+	req, err := a.request(http.MethodPut, key, "", body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	return a.do(req)
+}
+
+// request creates a request to the bucket or, if key is not empty, to an
+// object of the bucket
+func (a *Archiver) request(method, key, query string, body []byte) (*http.Request, error) {
+	path := "/" + a.bucket
+	if key != "" {
+		path += "/" + key
+	}
+	u := a.endpoint + escapePath(path)
+	if query != "" {
+		u += "?" + query
+	}
+	req, err := http.NewRequest(method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// do signs and sends the request
+func (a *Archiver) do(req *http.Request) error {
+	var body []byte
+	if req.GetBody != nil {
+		r, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		body, err = io.ReadAll(r)
+		if err != nil {
+			return err
+		}
+	}
+	a.sign(req, body, a.now())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, msg)
+	}
+	return nil
+}
+
+func xmlEscape(w io.Writer, s string) error {
+	r := strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;")
+	_, err := r.WriteString(w, s)
+	return err
+}
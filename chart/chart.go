@@ -0,0 +1,78 @@
+// Package chart renders the results of the surveys as images
+package chart
+
+import (
+	"bytes"
+	"flashSurvey/survey"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+)
+
+const (
+	chartWidth  = 800
+	barHeight   = 40
+	barGap      = 16
+	chartMargin = 24
+)
+
+var (
+	chartBackground = color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}
+	chartTrack      = color.RGBA{R: 0xe8, G: 0xe8, B: 0xe8, A: 0xff}
+	chartBars       = []color.RGBA{
+		{R: 0x1f, G: 0x77, B: 0xb4, A: 0xff},
+		{R: 0xff, G: 0x7f, B: 0x0e, A: 0xff},
+		{R: 0x2c, G: 0xa0, B: 0x2c, A: 0xff},
+		{R: 0xd6, G: 0x27, B: 0x28, A: 0xff},
+		{R: 0x94, G: 0x67, B: 0xbd, A: 0xff},
+		{R: 0x8c, G: 0x56, B: 0x4b, A: 0xff},
+	}
+)
+
+// PNG renders the result as a horizontal bar chart. There is no font
+// available, so the bars are shown in the order of the options and the
+// texts have to be given next to the image.
+func PNG(r survey.Result) ([]byte, error) {
+	n := len(r.Result)
+	height := 2*chartMargin + n*barHeight + (n-1)*barGap
+	if n == 0 {
+		height = 2 * chartMargin
+	}
+	img := image.NewRGBA(image.Rect(0, 0, chartWidth, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: chartBackground}, image.Point{}, draw.Src)
+
+	maxPercent := r.MaxPercent
+	if maxPercent <= 0 {
+		maxPercent = 100
+	}
+	trackWidth := chartWidth - 2*chartMargin
+	for i, o := range r.Result {
+		y := chartMargin + i*(barHeight+barGap)
+		track := image.Rect(chartMargin, y, chartMargin+trackWidth, y+barHeight)
+		draw.Draw(img, track, &image.Uniform{C: chartTrack}, image.Point{}, draw.Src)
+
+		w := int(float64(trackWidth) * o.PercentVal(maxPercent) / 100)
+		bar := image.Rect(chartMargin, y, chartMargin+w, y+barHeight)
+		draw.Draw(img, bar, &image.Uniform{C: barColor(o, i)}, image.Point{}, draw.Src)
+	}
+
+	var b bytes.Buffer
+	err := png.Encode(&b, img)
+	if err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// barColor returns the color of the option, or a default color if the
+// option has no valid color
+func barColor(o survey.OptionResult, i int) color.RGBA {
+	var c color.RGBA
+	if _, err := fmt.Sscanf(o.Color(), "#%02x%02x%02x", &c.R, &c.G, &c.B); err != nil {
+		return chartBars[i%len(chartBars)]
+	}
+	c.A = 0xff
+	return c
+}
@@ -0,0 +1,34 @@
+package chart
+
+import (
+	"bytes"
+	"flashSurvey/survey"
+	"image/color"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPNG(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Q", Options: []string{"a", "b"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "v1", []int{0}, 1))
+	assert.NoError(t, s.Vote(sid, "v2", []int{1}, 1))
+	assert.NoError(t, s.Vote(sid, "v3", []int{1}, 1))
+	assert.NoError(t, s.Uncover("creator", sid))
+
+	data, err := PNG(s.GetResult("creator", sid))
+	assert.NoError(t, err)
+	img, err := png.Decode(bytes.NewReader(data))
+	assert.NoError(t, err)
+	assert.EqualValues(t, chartWidth, img.Bounds().Dx())
+	assert.EqualValues(t, 2*chartMargin+2*barHeight+barGap, img.Bounds().Dy())
+
+	// the second option has the most votes, so its bar is full length,
+	// it is drawn in the default color of the option
+	y := chartMargin + barHeight + barGap + barHeight/2
+	assert.EqualValues(t, color.RGBA{R: 0xf2, G: 0x8e, B: 0x2b, A: 0xff}, img.At(chartWidth-chartMargin-1, y))
+	assert.EqualValues(t, chartTrack, img.At(chartWidth-chartMargin-1, chartMargin+barHeight/2))
+}
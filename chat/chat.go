@@ -0,0 +1,106 @@
+// Package chat feeds votes from live stream chats into the surveys. Viewers
+// vote by sending messages like "!vote A" or "!vote A,C". The votes are
+// counted for the survey most recently started with the announce option.
+package chat
+
+import (
+	"flashSurvey/survey"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Ingest passes the votes received from the chats to the surveys
+type Ingest struct {
+	surveys *survey.Surveys
+
+	mutex    sync.Mutex
+	current  survey.SurveyId
+	number   int
+	options  int
+	multiple bool
+}
+
+// NewIngest creates a new ingest. Its hooks need to be registered.
+func NewIngest(s *survey.Surveys) *Ingest {
+	return &Ingest{surveys: s}
+}
+
+// Hooks returns the hooks which keep track of the current survey
+func (in *Ingest) Hooks() survey.Hooks {
+	return survey.Hooks{
+		OnStart: func(e survey.Event) {
+			if !e.Question.Announce {
+				return
+			}
+			in.mutex.Lock()
+			defer in.mutex.Unlock()
+			in.current = e.SurveyId
+			in.number = e.Number
+			in.options = len(e.Question.Options)
+			in.multiple = e.Question.Multiple
+		},
+		OnExpire: in.stop,
+		OnClose:  in.stop,
+	}
+}
+
+// stop ends the ingest if the survey is deleted
+func (in *Ingest) stop(e survey.ResultEvent) {
+	in.mutex.Lock()
+	defer in.mutex.Unlock()
+	if in.current == e.SurveyId {
+		in.current = ""
+	}
+}
+
+// message handles a chat message. The user has to be unique for the platform.
+func (in *Ingest) message(platform, user, text string) {
+	in.mutex.Lock()
+	current, number, options, multiple := in.current, in.number, in.options, in.multiple
+	in.mutex.Unlock()
+
+	if current == "" {
+		return
+	}
+	o, ok := parseVote(text, options)
+	if !ok || (!multiple && len(o) > 1) {
+		return
+	}
+	err := in.surveys.Vote(current, survey.UserId(platform+":"+user), o, number)
+	if err != nil {
+		log.Printf("%s: vote rejected: %v", platform, err)
+	}
+}
+
+// parseVote parses messages like "!vote B" or "!vote 1,3". Options are given
+// either as letters or as one based numbers. An option given several times
+// is counted only once.
+func parseVote(text string, options int) ([]int, bool) {
+	fields := strings.Fields(text)
+	if len(fields) < 2 || !strings.EqualFold(fields[0], "!vote") {
+		return nil, false
+	}
+	var o []int
+	for _, part := range strings.Split(strings.Join(fields[1:], ""), ",") {
+		var n int
+		if c := strings.ToUpper(part); len(c) == 1 && c[0] >= 'A' && c[0] <= 'Z' {
+			n = int(c[0] - 'A')
+		} else {
+			i, err := strconv.Atoi(part)
+			if err != nil {
+				return nil, false
+			}
+			n = i - 1
+		}
+		if n < 0 || n >= options {
+			return nil, false
+		}
+		if !slices.Contains(o, n) {
+			o = append(o, n)
+		}
+	}
+	return o, len(o) > 0
+}
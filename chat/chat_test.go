@@ -0,0 +1,150 @@
+package chat
+
+import (
+	"bufio"
+	"flashSurvey/survey"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVote(t *testing.T) {
+	tests := []struct {
+		text string
+		want []int
+		ok   bool
+	}{
+		{"!vote A", []int{0}, true},
+		{"!VOTE b", []int{1}, true},
+		{"!vote 3", []int{2}, true},
+		{"!vote 1, c", []int{0, 2}, true},
+		{"!vote A,A,A", []int{0}, true},
+		{"!vote a,1,C,3", []int{0, 2}, true},
+		{"!vote D", nil, false},
+		{"!vote 0", nil, false},
+		{"!vote", nil, false},
+		{"vote A", nil, false},
+		{"!vote Ä", nil, false},
+	}
+	for _, test := range tests {
+		t.Run(test.text, func(t *testing.T) {
+			o, ok := parseVote(test.text, 3)
+			assert.EqualValues(t, test.ok, ok)
+			assert.EqualValues(t, test.want, o)
+		})
+	}
+}
+
+func TestParseIRC(t *testing.T) {
+	prefix, command, params := parseIRC("@badge-info=;color=#FF0000 :alice!alice@alice.tmi.twitch.tv PRIVMSG #chan :!vote A")
+	assert.EqualValues(t, "alice!alice@alice.tmi.twitch.tv", prefix)
+	assert.EqualValues(t, "PRIVMSG", command)
+	assert.EqualValues(t, []string{"#chan", "!vote A"}, params)
+
+	_, command, params = parseIRC("PING :tmi.twitch.tv")
+	assert.EqualValues(t, "PING", command)
+	assert.EqualValues(t, []string{"tmi.twitch.tv"}, params)
+}
+
+func newSurvey(t *testing.T, announce bool) (*survey.Surveys, *Ingest, survey.SurveyId) {
+	s := survey.New("localhost", 30, false, true)
+	in := NewIngest(s)
+	q := survey.SurveyQuestion{Title: "Q", Options: []string{"a", "b"}, Announce: announce}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	in.Hooks().OnStart(survey.Event{SurveyId: sid, Number: 1, Question: q})
+	return s, in, sid
+}
+
+func TestIngest(t *testing.T) {
+	s, in, sid := newSurvey(t, true)
+	in.message("twitch", "alice", "!vote B")
+	in.message("twitch", "alice", "!vote A")
+	in.message("youtube", "alice", "!vote B")
+	in.message("twitch", "bob", "hello")
+	// multiple options are not allowed
+	in.message("twitch", "carol", "!vote A,B")
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.EqualValues(t, 2, r.Votes)
+	assert.EqualValues(t, 2, r.Result[1].VoteCount())
+}
+
+func TestIngestNotAnnounced(t *testing.T) {
+	s, in, sid := newSurvey(t, false)
+	in.message("twitch", "alice", "!vote B")
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	assert.EqualValues(t, 0, s.GetResult("creator", sid).Votes)
+}
+
+func TestIngestClosed(t *testing.T) {
+	s, in, sid := newSurvey(t, true)
+	in.message("twitch", "alice", "!vote A,A,A")
+	in.Hooks().OnClose(survey.ResultEvent{Event: survey.Event{SurveyId: sid}})
+	in.message("twitch", "bob", "!vote B")
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.EqualValues(t, 1, r.Votes)
+	assert.EqualValues(t, 1, r.Result[0].VoteCount())
+}
+
+func TestTwitchSession(t *testing.T) {
+	s, in, sid := newSurvey(t, true)
+	tw := &Twitch{ingest: in, channel: "#chan", user: "bot", token: "oauth:secret"}
+
+	server, client := net.Pipe()
+	done := make(chan error)
+	go func() {
+		done <- tw.session(client)
+	}()
+
+	r := bufio.NewReader(server)
+	for _, want := range []string{"PASS oauth:secret", "NICK bot", "JOIN #chan"} {
+		line, err := r.ReadString('\n')
+		assert.NoError(t, err)
+		assert.EqualValues(t, want+"\r\n", line)
+	}
+	server.Write([]byte(":alice!alice@alice.tmi.twitch.tv PRIVMSG #chan :!vote 2\r\n"))
+	server.Write([]byte(":bob!bob@bob.tmi.twitch.tv PRIVMSG #other :!vote 2\r\n"))
+	server.Write([]byte("PING :tmi.twitch.tv\r\n"))
+	line, err := r.ReadString('\n')
+	assert.NoError(t, err)
+	assert.EqualValues(t, "PONG tmi.twitch.tv\r\n", line)
+	server.Close()
+	assert.Error(t, <-done)
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	r2 := s.GetResult("creator", sid)
+	assert.EqualValues(t, 1, r2.Votes)
+	assert.EqualValues(t, 1, r2.Result[1].VoteCount())
+}
+
+func TestYouTubeFetch(t *testing.T) {
+	s, in, sid := newSurvey(t, true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.EqualValues(t, "chat", r.URL.Query().Get("liveChatId"))
+		assert.EqualValues(t, "key", r.URL.Query().Get("key"))
+		assert.True(t, strings.Contains(r.URL.Query().Get("part"), "authorDetails"))
+		w.Write([]byte(`{"nextPageToken":"next","pollingIntervalMillis":5000,"items":[
+			{"snippet":{"displayMessage":"!vote a"},"authorDetails":{"channelId":"UC1"}},
+			{"snippet":{"displayMessage":"!vote b"},"authorDetails":{"channelId":"UC2"}}]}`))
+	}))
+	defer server.Close()
+	youtubeURL = server.URL
+
+	y := &YouTube{ingest: in, chatId: "chat", key: "key", client: server.Client()}
+	page, err := y.fetch("")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "next", page.NextPageToken)
+	y.handle(page)
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	assert.EqualValues(t, 2, s.GetResult("creator", sid).Votes)
+}
@@ -0,0 +1,136 @@
+package chat
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	twitchAddr  = "irc.chat.twitch.tv:6697"
+	twitchRetry = 30 * time.Second
+	readTimeout = 6 * time.Minute
+)
+
+// Twitch reads the chat of a Twitch channel using the IRC interface
+type Twitch struct {
+	ingest  *Ingest
+	channel string
+	user    string
+	token   string
+	dial    func() (net.Conn, error)
+}
+
+// NewTwitch starts reading the chat of the given channel. The user and its
+// oauth token are used to log in. If channel is empty, nil is returned.
+func NewTwitch(in *Ingest, channel, user, token string) (*Twitch, error) {
+	if channel == "" {
+		return nil, nil
+	}
+	if user == "" || token == "" {
+		return nil, errors.New("reading the twitch chat requires a user and a token")
+	}
+	t := &Twitch{
+		ingest:  in,
+		channel: "#" + strings.ToLower(strings.TrimPrefix(channel, "#")),
+		user:    strings.ToLower(user),
+		token:   "oauth:" + strings.TrimPrefix(token, "oauth:"),
+		dial: func() (net.Conn, error) {
+			return tls.DialWithDialer(&net.Dialer{Timeout: 10 * time.Second}, "tcp", twitchAddr, nil)
+		},
+	}
+	go t.run()
+	return t, nil
+}
+
+func (t *Twitch) run() {
+	for {
+		conn, err := t.dial()
+		if err == nil {
+			log.Println("twitch: connected to", t.channel)
+			err = t.session(conn)
+			conn.Close()
+		}
+		log.Println("twitch:", err)
+		time.Sleep(twitchRetry)
+	}
+}
+
+// session logs in and handles the messages until the connection is lost
+func (t *Twitch) session(conn net.Conn) error {
+	w := bufio.NewWriter(conn)
+	send := func(line string) error {
+		_, err := w.WriteString(line + "\r\n")
+		if err != nil {
+			return err
+		}
+		return w.Flush()
+	}
+	for _, l := range []string{"PASS " + t.token, "NICK " + t.user, "JOIN " + t.channel} {
+		if err := send(l); err != nil {
+			return err
+		}
+	}
+
+	r := bufio.NewReader(conn)
+	for {
+		// the server sends a PING about every five minutes
+		conn.SetReadDeadline(time.Now().Add(readTimeout))
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return errors.New("connection closed")
+			}
+			return err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		prefix, command, params := parseIRC(line)
+		switch command {
+		case "PING":
+			if err := send("PONG " + strings.Join(params, " ")); err != nil {
+				return err
+			}
+		case "NOTICE":
+			if len(params) > 1 && strings.Contains(params[1], "authentication failed") {
+				return fmt.Errorf("login failed: %s", params[1])
+			}
+		case "PRIVMSG":
+			if len(params) == 2 && params[0] == t.channel {
+				nick, _, _ := strings.Cut(prefix, "!")
+				if nick != "" {
+					t.ingest.message("twitch", nick, params[1])
+				}
+			}
+		}
+	}
+}
+
+// parseIRC splits an IRC line into prefix, command and parameters.
+// Message tags are ignored.
+func parseIRC(line string) (string, string, []string) {
+	if strings.HasPrefix(line, "@") {
+		_, line, _ = strings.Cut(line, " ")
+	}
+	var prefix string
+	if strings.HasPrefix(line, ":") {
+		prefix, line, _ = strings.Cut(line[1:], " ")
+	}
+	var params []string
+	command, rest, _ := strings.Cut(line, " ")
+	for rest != "" {
+		if strings.HasPrefix(rest, ":") {
+			params = append(params, rest[1:])
+			break
+		}
+		var p string
+		p, rest, _ = strings.Cut(rest, " ")
+		params = append(params, p)
+	}
+	return prefix, command, params
+}
@@ -0,0 +1,114 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	youtubeMinInterval = 2 * time.Second
+	youtubeRetry       = 30 * time.Second
+)
+
+var youtubeURL = "https://www.googleapis.com/youtube/v3/liveChat/messages"
+
+// YouTube polls the messages of a YouTube live chat
+type YouTube struct {
+	ingest *Ingest
+	chatId string
+	key    string
+	client *http.Client
+}
+
+type youtubePage struct {
+	NextPageToken         string `json:"nextPageToken"`
+	PollingIntervalMillis int    `json:"pollingIntervalMillis"`
+	Items                 []struct {
+		Snippet struct {
+			DisplayMessage string `json:"displayMessage"`
+		} `json:"snippet"`
+		AuthorDetails struct {
+			ChannelId string `json:"channelId"`
+		} `json:"authorDetails"`
+	} `json:"items"`
+}
+
+// NewYouTube starts polling the live chat with the given id using the
+// given API key. If chatId is empty, nil is returned.
+func NewYouTube(in *Ingest, chatId, key string) (*YouTube, error) {
+	if chatId == "" {
+		return nil, nil
+	}
+	if key == "" {
+		return nil, errors.New("reading the youtube chat requires an api key")
+	}
+	y := &YouTube{
+		ingest: in,
+		chatId: chatId,
+		key:    key,
+		client: &http.Client{Timeout: 30 * time.Second},
+	}
+	go y.run()
+	return y, nil
+}
+
+func (y *YouTube) run() {
+	var pageToken string
+	first := true
+	for {
+		page, err := y.fetch(pageToken)
+		if err != nil {
+			log.Println("youtube:", err)
+			time.Sleep(youtubeRetry)
+			continue
+		}
+		// messages sent before the server was started are not counted
+		if !first {
+			y.handle(page)
+		}
+		first = false
+		pageToken = page.NextPageToken
+
+		interval := time.Duration(page.PollingIntervalMillis) * time.Millisecond
+		if interval < youtubeMinInterval {
+			interval = youtubeMinInterval
+		}
+		time.Sleep(interval)
+	}
+}
+
+func (y *YouTube) handle(page youtubePage) {
+	for _, item := range page.Items {
+		if item.AuthorDetails.ChannelId != "" {
+			y.ingest.message("youtube", item.AuthorDetails.ChannelId, item.Snippet.DisplayMessage)
+		}
+	}
+}
+
+func (y *YouTube) fetch(pageToken string) (youtubePage, error) {
+	q := url.Values{}
+	q.Set("liveChatId", y.chatId)
+	q.Set("part", "snippet,authorDetails")
+	q.Set("key", y.key)
+	if pageToken != "" {
+		q.Set("pageToken", pageToken)
+	}
+	var page youtubePage
+	resp, err := y.client.Get(youtubeURL + "?" + q.Encode())
+	if err != nil {
+		return page, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1000))
+		return page, fmt.Errorf("live chat returned %s: %s", resp.Status, msg)
+	}
+	err = json.NewDecoder(resp.Body).Decode(&page)
+	return page, err
+}
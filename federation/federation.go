@@ -0,0 +1,124 @@
+// Package federation allows to vote on the surveys of an origin instance
+// via relay instances. The relays fetch the questions from the origin and
+// forward the votes. All requests are signed with a secret shared by the
+// origin and the relay.
+package federation
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	relayHeader     = "X-FlashSurvey-Relay"
+	timeHeader      = "X-FlashSurvey-Time"
+	signatureHeader = "X-FlashSurvey-Signature"
+	// maxClockSkew is the maximum age of a signed request
+	maxClockSkew = 5 * time.Minute
+	maxBodySize  = 64 * 1024
+)
+
+func signature(secret []byte, relay string, t int64, method, uri string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	fmt.Fprintf(mac, "%s\n%d\n%s\n%s\n", relay, t, method, uri)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Peers are the relays known to the origin
+type Peers map[string][]byte
+
+// ParsePeers parses a comma separated list of name=secret pairs
+func ParsePeers(list string) (Peers, error) {
+	p := Peers{}
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, secret, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || secret == "" {
+			return nil, fmt.Errorf("invalid federation peer %q", entry)
+		}
+		p[name] = []byte(secret)
+	}
+	return p, nil
+}
+
+// Verify checks the signature of a request sent by a relay and returns the
+// name of the relay and the body of the request.
+func (p Peers) Verify(r *http.Request) (string, []byte, error) {
+	relay := r.Header.Get(relayHeader)
+	secret, ok := p[relay]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown relay %q", relay)
+	}
+	t, err := strconv.ParseInt(r.Header.Get(timeHeader), 10, 64)
+	if err != nil {
+		return "", nil, errors.New("invalid time")
+	}
+	if d := time.Since(time.Unix(t, 0)); d > maxClockSkew || d < -maxClockSkew {
+		return "", nil, errors.New("request expired")
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		return "", nil, err
+	}
+	expected := signature(secret, relay, t, r.Method, r.URL.RequestURI(), body)
+	if !hmac.Equal([]byte(expected), []byte(r.Header.Get(signatureHeader))) {
+		return "", nil, errors.New("invalid signature")
+	}
+	return relay, body, nil
+}
+
+// sign creates a signed request
+func sign(secret []byte, relay, method, url string, body []byte) (*http.Request, error) {
+	r, err := http.NewRequest(method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	t := time.Now().Unix()
+	r.Header.Set(relayHeader, relay)
+	r.Header.Set(timeHeader, strconv.FormatInt(t, 10))
+	r.Header.Set(signatureHeader, signature(secret, relay, t, method, r.URL.RequestURI(), body))
+	if body != nil {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	return r, nil
+}
+
+// VoterId returns the id used by the origin for a voter of the given relay
+func VoterId(relay, voterId string) string {
+	return "relay:" + relay + ":" + voterId
+}
+
+// VoteRequest is sent by the relay to forward a vote
+type VoteRequest struct {
+	SurveyId string
+	VoterId  string
+	Options  []int
+	// Ballot is the encrypted vote of an end-to-end encrypted survey
+	Ballot string `json:",omitempty"`
+	// WriteIn is the free text answer of the voter
+	WriteIn string `json:",omitempty"`
+	Number  int
+}
+
+// VoteResponse is the answer to a VoteRequest or a voted query.
+// The error is shown to the voter. If it is an error of the survey, the
+// code is sent as well, so the vote page of the relay can localize it.
+type VoteResponse struct {
+	Voted bool   `json:",omitempty"`
+	Error string `json:",omitempty"`
+	Code  string `json:",omitempty"`
+	Args  []any  `json:",omitempty"`
+}
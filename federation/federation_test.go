@@ -0,0 +1,52 @@
+package federation
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePeers(t *testing.T) {
+	p, err := ParsePeers(" b=secret1, c=secret2 ")
+	assert.NoError(t, err)
+	assert.EqualValues(t, Peers{"b": []byte("secret1"), "c": []byte("secret2")}, p)
+	p, err = ParsePeers("")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, len(p))
+	_, err = ParsePeers("b")
+	assert.Error(t, err)
+}
+
+func TestVerify(t *testing.T) {
+	peers := Peers{"b": []byte("secret")}
+	verify := func(secret, relay, method, url string, body []byte) error {
+		r, err := sign([]byte(secret), relay, method, url, body)
+		assert.NoError(t, err)
+		// the server sees the request uri only
+		s := httptest.NewRequest(r.Method, r.URL.RequestURI(), r.Body)
+		s.Header = r.Header
+		_, _, err = peers.Verify(s)
+		return err
+	}
+
+	assert.NoError(t, verify("secret", "b", "POST", "https://a.example.com/federation/vote", []byte(`{"a":1}`)))
+	assert.NoError(t, verify("secret", "b", "GET", "https://a.example.com/federation/question?id=x", nil))
+	assert.Error(t, verify("other", "b", "GET", "https://a.example.com/federation/question?id=x", nil))
+	assert.Error(t, verify("secret", "c", "GET", "https://a.example.com/federation/question?id=x", nil))
+
+	r, err := sign([]byte("secret"), "b", "GET", "https://a.example.com/federation/question?id=x", nil)
+	assert.NoError(t, err)
+	s := httptest.NewRequest("GET", "/federation/question?id=y", nil)
+	s.Header = r.Header
+	_, _, err = peers.Verify(s)
+	assert.Error(t, err)
+
+	s = httptest.NewRequest("GET", "/federation/question?id=x", nil)
+	s.Header = r.Header.Clone()
+	s.Header.Set(timeHeader, strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10))
+	_, _, err = peers.Verify(s)
+	assert.Error(t, err)
+}
@@ -0,0 +1,162 @@
+package federation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flashSurvey/survey"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Relay serves the surveys of this instance and forwards the votes on
+// all other surveys to the origin.
+type Relay struct {
+	local  *survey.Surveys
+	origin string
+	name   string
+	secret []byte
+	client *http.Client
+}
+
+// NewRelay creates a relay. If origin is empty, nil is returned.
+func NewRelay(local *survey.Surveys, origin, name, secret string) (*Relay, error) {
+	if origin == "" {
+		return nil, nil
+	}
+	if name == "" || secret == "" {
+		return nil, errors.New("a federation relay requires a name and a secret")
+	}
+	return &Relay{
+		local:  local,
+		origin: strings.TrimSuffix(origin, "/"),
+		name:   name,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// isLocal returns true if the survey is a survey of this instance, the
+// copies of a read replica are not
+func (r *Relay) isLocal(surveyId survey.SurveyId) bool {
+	return r.local.GetQuestion(surveyId).SurveyId != "" && !r.local.IsMirror(surveyId)
+}
+
+func (r *Relay) call(method, path string, request, response any) error {
+	return r.callWith(context.Background(), r.client, method, path, request, response)
+}
+
+// callWith sends a signed request to the origin using the given client
+func (r *Relay) callWith(ctx context.Context, client *http.Client, method, path string, request, response any) error {
+	var body []byte
+	if request != nil {
+		var err error
+		body, err = json.Marshal(request)
+		if err != nil {
+			return err
+		}
+	}
+	req, err := sign(r.secret, r.name, method, r.origin+path, body)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1000))
+		return fmt.Errorf("%s returned %s: %s", path, resp.Status, msg)
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+// GetQuestion returns the question of a local survey or fetches it from the origin
+func (r *Relay) GetQuestion(surveyId survey.SurveyId) survey.Question {
+	if q := r.local.GetQuestion(surveyId); q.SurveyId != "" {
+		return q
+	}
+	var q survey.Question
+	err := r.call(http.MethodGet, "/federation/question?id="+url.QueryEscape(string(surveyId)), nil, &q)
+	if err != nil {
+		log.Println("federation:", err)
+		return survey.Question{Question: survey.SurveyQuestion{Title: "Die Umfrage ist nicht erreichbar!"}}
+	}
+	return q
+}
+
+// Vote votes on a local survey or forwards the vote to the origin
+func (r *Relay) Vote(surveyId survey.SurveyId, voterId survey.UserId, option []int, number int) error {
+	if r.isLocal(surveyId) {
+		return r.local.Vote(surveyId, voterId, option, number)
+	}
+	return r.forward(VoteRequest{
+		SurveyId: string(surveyId),
+		VoterId:  string(voterId),
+		Options:  option,
+		Number:   number,
+	})
+}
+
+// VoteEncrypted votes on a local survey or forwards the encrypted ballot to the origin
+func (r *Relay) VoteEncrypted(surveyId survey.SurveyId, voterId survey.UserId, ballot string, number int) error {
+	if r.isLocal(surveyId) {
+		return r.local.VoteEncrypted(surveyId, voterId, ballot, number)
+	}
+	return r.forward(VoteRequest{
+		SurveyId: string(surveyId),
+		VoterId:  string(voterId),
+		Ballot:   ballot,
+		Number:   number,
+	})
+}
+
+// VoteWriteIn votes on a local survey or forwards the vote including the free text answer to the origin
+func (r *Relay) VoteWriteIn(surveyId survey.SurveyId, voterId survey.UserId, option []int, writeIn string, number int) error {
+	if r.isLocal(surveyId) {
+		return r.local.VoteWriteIn(surveyId, voterId, option, writeIn, number)
+	}
+	return r.forward(VoteRequest{
+		SurveyId: string(surveyId),
+		VoterId:  string(voterId),
+		Options:  option,
+		WriteIn:  writeIn,
+		Number:   number,
+	})
+}
+
+func (r *Relay) forward(v VoteRequest) error {
+	var resp VoteResponse
+	err := r.call(http.MethodPost, "/federation/vote", v, &resp)
+	if err != nil {
+		log.Println("federation:", err)
+		return errors.New("Die Umfrage ist nicht erreichbar!")
+	}
+	if resp.Code != "" {
+		return &survey.Error{Code: resp.Code, Args: resp.Args}
+	}
+	if resp.Error != "" {
+		return errors.New(resp.Error)
+	}
+	return nil
+}
+
+// HasVoted checks if the voter has already voted on the local or remote survey
+func (r *Relay) HasVoted(surveyId survey.SurveyId, voterId survey.UserId) bool {
+	if r.isLocal(surveyId) {
+		return r.local.HasVoted(surveyId, voterId)
+	}
+	var resp VoteResponse
+	err := r.call(http.MethodGet, "/federation/voted?id="+url.QueryEscape(string(surveyId))+"&v="+url.QueryEscape(string(voterId)), nil, &resp)
+	if err != nil {
+		log.Println("federation:", err)
+		return false
+	}
+	return resp.Voted
+}
@@ -0,0 +1,150 @@
+package federation
+
+import (
+	"context"
+	"flashSurvey/survey"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// PollTimeout is the time the origin waits for a change of a survey
+	// before it answers the poll of a replica
+	PollTimeout = 25 * time.Second
+	// retryDelay is the time a replica waits after a failed poll
+	retryDelay = 5 * time.Second
+)
+
+// Replica serves the vote pages and the results of the surveys of a
+// primary instance. The surveys are mirrored into the local surveys by
+// polling the changes from the primary, all votes are forwarded to the
+// primary. The pages which create or control surveys have to be served by
+// the primary.
+type Replica struct {
+	// the votes are forwarded by the relay, because the mirrored surveys
+	// are not local
+	*Relay
+	poll  *http.Client
+	mutex sync.Mutex
+	// following contains the surveys which are mirrored, the channel is
+	// closed if the first state of the survey has been received
+	following map[survey.SurveyId]chan struct{}
+}
+
+// NewReplica creates a read replica of the given primary. If primary is
+// empty, nil is returned. The replica authenticates like a relay.
+func NewReplica(local *survey.Surveys, primary, name, secret string) (*Replica, error) {
+	relay, err := NewRelay(local, primary, name, secret)
+	if relay == nil || err != nil {
+		return nil, err
+	}
+	return &Replica{
+		Relay:     relay,
+		poll:      &http.Client{Timeout: PollTimeout + 10*time.Second},
+		following: map[survey.SurveyId]chan struct{}{},
+	}, nil
+}
+
+// Primary returns the url of the primary instance
+func (r *Replica) Primary() string {
+	return r.origin
+}
+
+// Follow returns a middleware which mirrors the survey given by the query
+// parameter "id" before the request is handled
+func (r *Replica) Follow(handler http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		r.follow(survey.SurveyId(request.URL.Query().Get("id")))
+		handler(writer, request)
+	}
+}
+
+// follow starts to mirror the survey and waits until its state is known
+func (r *Replica) follow(surveyId survey.SurveyId) {
+	if surveyId == "" || r.local.IsMirror(surveyId) {
+		return
+	}
+
+	r.mutex.Lock()
+	ready, ok := r.following[surveyId]
+	if !ok {
+		ready = make(chan struct{})
+		r.following[surveyId] = ready
+		go r.mirror(surveyId, ready)
+	}
+	r.mutex.Unlock()
+
+	select {
+	case <-ready:
+	case <-time.After(r.client.Timeout):
+	}
+}
+
+// mirror polls the changes of the survey until it is deleted on the
+// primary or the local surveys are closed
+func (r *Replica) mirror(surveyId survey.SurveyId, ready chan struct{}) {
+	started := sync.OnceFunc(func() { close(ready) })
+	defer func() {
+		r.mutex.Lock()
+		delete(r.following, surveyId)
+		r.mutex.Unlock()
+		started()
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-r.local.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	version := 0
+	for {
+		var state survey.Replica
+		path := "/federation/changes?id=" + url.QueryEscape(string(surveyId)) + "&v=" + strconv.Itoa(version)
+		err := r.callWith(ctx, r.poll, http.MethodGet, path, nil, &state)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			log.Println("replica:", err)
+			if version == 0 {
+				// the next request tries again
+				return
+			}
+			select {
+			case <-time.After(retryDelay):
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		r.local.Mirror(surveyId, state)
+		started()
+		if state.Result.Version < 0 {
+			return
+		}
+		version = state.Result.Version
+	}
+}
+
+// GetQuestion returns the question of the mirrored survey, so the vote
+// pages are served without a request to the primary
+func (r *Replica) GetQuestion(surveyId survey.SurveyId) survey.Question {
+	r.follow(surveyId)
+	return r.local.GetQuestion(surveyId)
+}
+
+// Redirect sends all requests which can not be served by the replica to
+// the primary
+func (r *Replica) Redirect(writer http.ResponseWriter, request *http.Request) {
+	http.Redirect(writer, request, r.origin+request.URL.RequestURI(), http.StatusTemporaryRedirect)
+}
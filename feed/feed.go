@@ -0,0 +1,199 @@
+// Package feed keeps the uncovered results of the surveys created by an
+// account and provides them as an Atom feed, so team members can follow
+// the outcome of recurring surveys.
+package feed
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"flashSurvey/account"
+	"flashSurvey/store"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	secretKey = "feedSecret"
+	// maxEntries is the number of results kept per account
+	maxEntries = 50
+)
+
+// Option is the result of a single option
+type Option struct {
+	Title   string
+	Votes   int
+	Percent float64
+}
+
+// Entry is the result of a survey
+type Entry struct {
+	SurveyId string
+	Number   int
+	Title    string
+	Votes    int
+	Options  []Option
+	Time     time.Time
+}
+
+// Feeds keeps the results of all accounts
+type Feeds struct {
+	mutex    sync.Mutex
+	store    store.Store
+	accounts *account.Accounts
+	host     string
+	secret   []byte
+}
+
+type secretDoc struct {
+	Secret []byte
+}
+
+// New creates the feeds. The secret used to sign the feed urls is kept in
+// the store, so the urls stay valid if the server is restarted.
+func New(st store.Store, a *account.Accounts, host string) (*Feeds, error) {
+	var d secretDoc
+	_, err := st.Load(secretKey, &d)
+	if err != nil {
+		return nil, fmt.Errorf("could not load feed secret: %w", err)
+	}
+	if len(d.Secret) == 0 {
+		d.Secret = make([]byte, 32)
+		_, err = rand.Read(d.Secret)
+		if err != nil {
+			return nil, err
+		}
+		err = st.Save(secretKey, d)
+		if err != nil {
+			return nil, fmt.Errorf("could not save feed secret: %w", err)
+		}
+	}
+	return &Feeds{store: st, accounts: a, host: host, secret: d.Secret}, nil
+}
+
+func entriesKey(acc string) string {
+	h := sha256.Sum256([]byte(acc))
+	return "feed-" + hex.EncodeToString(h[:16])
+}
+
+func (f *Feeds) token(acc string) string {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write([]byte(acc))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:18])
+}
+
+// URL returns the url of the feed of the given account
+func (f *Feeds) URL(acc string) string {
+	return f.host + "/feed/?a=" + url.QueryEscape(acc) + "&k=" + f.token(acc)
+}
+
+// Add adds the result to the feed of the given account. A result of the
+// same question is replaced.
+func (f *Feeds) Add(acc string, e Entry) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var entries []Entry
+	_, err := f.store.Load(entriesKey(acc), &entries)
+	if err != nil {
+		return err
+	}
+	n := entries[:0]
+	for _, o := range entries {
+		if o.SurveyId != e.SurveyId || o.Number != e.Number {
+			n = append(n, o)
+		}
+	}
+	entries = append(n, e)
+	if len(entries) > maxEntries {
+		entries = entries[len(entries)-maxEntries:]
+	}
+	return f.store.Save(entriesKey(acc), entries)
+}
+
+// Entries returns the entries of the account, newest first.
+// The token is checked to make sure the feed was shared by the account.
+func (f *Feeds) Entries(acc, token string) ([]Entry, error) {
+	if acc == "" || !hmac.Equal([]byte(f.token(acc)), []byte(token)) {
+		return nil, errors.New("invalid feed token")
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var entries []Entry
+	_, err := f.store.Load(entriesKey(acc), &entries)
+	if err != nil {
+		return nil, err
+	}
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	return entries, nil
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type atomText struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+type atomEntry struct {
+	Title   string   `xml:"title"`
+	Id      string   `xml:"id"`
+	Updated string   `xml:"updated"`
+	Content atomText `xml:"content"`
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	Id      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  string      `xml:"author>name"`
+	Link    atomLink    `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+// Atom renders the entries of the account as an Atom feed
+func (f *Feeds) Atom(acc string, entries []Entry) ([]byte, error) {
+	feed := atomFeed{
+		Title:   "Umfrageergebnisse von " + acc,
+		Id:      "urn:flashsurvey:feed:" + entriesKey(acc),
+		Updated: time.Now().UTC().Format(time.RFC3339),
+		Author:  acc,
+		Link:    atomLink{Href: f.URL(acc), Rel: "self"},
+	}
+	if len(entries) > 0 {
+		feed.Updated = entries[0].Time.UTC().Format(time.RFC3339)
+	}
+	for _, e := range entries {
+		var b strings.Builder
+		b.WriteString(fmt.Sprintf("%s (%d Stimmen)\n\n", e.Title, e.Votes))
+		for i, o := range e.Options {
+			b.WriteString(fmt.Sprintf("%d. %s: %d (%.1f%%)\n", i+1, o.Title, o.Votes, o.Percent))
+		}
+		feed.Entries = append(feed.Entries, atomEntry{
+			Title:   e.Title,
+			Id:      fmt.Sprintf("urn:flashsurvey:result:%s:%d", e.SurveyId, e.Number),
+			Updated: e.Time.UTC().Format(time.RFC3339),
+			Content: atomText{Type: "text", Text: b.String()},
+		})
+	}
+	data, err := xml.MarshalIndent(feed, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), data...), nil
+}
@@ -0,0 +1,85 @@
+package feed
+
+import (
+	"encoding/xml"
+	"flashSurvey/account"
+	"flashSurvey/store"
+	"flashSurvey/survey"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newFeeds(t *testing.T) (*Feeds, *account.Accounts, store.Store) {
+	st, err := store.New("")
+	assert.NoError(t, err)
+	a := account.New("https://example.com")
+	f, err := New(st, a, "https://example.com")
+	assert.NoError(t, err)
+	return f, a, st
+}
+
+func TestFeed(t *testing.T) {
+	f, a, st := newFeeds(t)
+	_, err := a.BindExternal("creator", "alice@example.com")
+	assert.NoError(t, err)
+	t.Cleanup(survey.RegisterHooks(f.Hooks()))
+
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Q1", Options: []string{"a", "b"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "v1", []int{1}, 1))
+	assert.NoError(t, s.Uncover("creator", sid))
+	_, err = s.New("creator", sid, survey.SurveyQuestion{Title: "Q2", Options: []string{"c", "d"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Uncover("creator", sid))
+
+	// surveys of creators which are not logged in are not added
+	other, err := s.New("anonymous", "", survey.SurveyQuestion{Title: "Q3", Options: []string{"a", "b"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Uncover("anonymous", other))
+
+	u, err := url.Parse(f.URL("alice@example.com"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, "/feed/", u.Path)
+	entries, err := f.Entries(u.Query().Get("a"), u.Query().Get("k"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, len(entries))
+	assert.EqualValues(t, "Q2", entries[0].Title)
+	assert.EqualValues(t, "Q1", entries[1].Title)
+	assert.EqualValues(t, 1, entries[1].Options[1].Votes)
+
+	_, err = f.Entries("alice@example.com", "wrong")
+	assert.Error(t, err)
+	_, err = f.Entries("bob@example.com", u.Query().Get("k"))
+	assert.Error(t, err)
+
+	data, err := f.Atom("alice@example.com", entries)
+	assert.NoError(t, err)
+	var feed atomFeed
+	assert.NoError(t, xml.Unmarshal(data, &feed))
+	assert.EqualValues(t, 2, len(feed.Entries))
+	assert.Contains(t, feed.Entries[1].Content.Text, "2. b: 1 (100.0%)")
+
+	// the secret is persisted, so the url stays valid
+	f2, err := New(st, a, "https://example.com")
+	assert.NoError(t, err)
+	assert.EqualValues(t, f.URL("alice@example.com"), f2.URL("alice@example.com"))
+}
+
+func TestAdd(t *testing.T) {
+	f, _, _ := newFeeds(t)
+	for i := 0; i < maxEntries+5; i++ {
+		assert.NoError(t, f.Add("bob", Entry{SurveyId: "s", Number: i, Title: strconv.Itoa(i)}))
+	}
+	// the same question replaces the former entry
+	assert.NoError(t, f.Add("bob", Entry{SurveyId: "s", Number: maxEntries, Title: "new"}))
+
+	entries, err := f.Entries("bob", f.token("bob"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, maxEntries, len(entries))
+	assert.EqualValues(t, "new", entries[0].Title)
+	assert.EqualValues(t, strconv.Itoa(maxEntries+4), entries[1].Title)
+}
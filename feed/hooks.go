@@ -0,0 +1,34 @@
+package feed
+
+import (
+	"flashSurvey/survey"
+	"log"
+	"time"
+)
+
+// Hooks returns the hooks which add the uncovered results to the feed of
+// the creator's account. Creators which are not logged in have no feed.
+func (f *Feeds) Hooks() survey.Hooks {
+	return survey.Hooks{
+		AfterUncover: func(e survey.ResultEvent) {
+			acc, ok := f.accounts.AccountOf(string(e.Creator))
+			if !ok {
+				return
+			}
+			entry := Entry{
+				SurveyId: string(e.SurveyId),
+				Number:   e.Number,
+				Title:    e.Question.Title,
+				Votes:    e.Result.Votes,
+				Time:     time.Now(),
+			}
+			for _, o := range e.Result.Result {
+				entry.Options = append(entry.Options, Option{Title: o.Title, Votes: o.VoteCount(), Percent: o.PercentValue()})
+			}
+			err := f.Add(acc, entry)
+			if err != nil {
+				log.Println("feed:", err)
+			}
+		},
+	}
+}
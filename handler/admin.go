@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"flashSurvey/account"
+	"flashSurvey/survey"
+	"log"
+	"net/http"
+	"runtime"
+	"strconv"
+)
+
+// AdminAuth returns a middleware which requires the operators to log in by
+// the basic authentication with one of the users of the htpasswd file
+func AdminAuth(users account.Htpasswd) func(http.HandlerFunc) http.HandlerFunc {
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			user, password, ok := request.BasicAuth()
+			if !ok || !users.Verify(user, password) {
+				if ok {
+					log.Println("admin: login failed for user", user)
+				}
+				writer.Header().Set("WWW-Authenticate", `Basic realm="flashSurvey admin", charset="UTF-8"`)
+				http.Error(writer, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			handler(writer, request)
+		}
+	}
+}
+
+type AdminData struct {
+	Surveys             []survey.AdminInfo
+	Stats               survey.Stats
+	Waiters             survey.WaiterStats
+	Goroutines          int
+	HeapKB              uint64
+	Timeout             int
+	VoteIfResultVisible bool
+	Message             string
+	Error               error
+}
+
+// Admin is the page of the operators. It lists all surveys, allows to
+// delete stuck surveys and to change the global settings at runtime.
+func Admin(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		var d AdminData
+		if request.Method == http.MethodPost {
+			// the browser sends the basic authentication with the requests
+			// of other sites as well
+			if !sameOrigin(request) {
+				http.Error(writer, "origin not allowed", http.StatusForbidden)
+				return
+			}
+			err := parseForm(writer, request, maxFormSize)
+			if err != nil {
+				formError(writer, err)
+				return
+			}
+			user, _, _ := request.BasicAuth()
+			switch {
+			case request.Form.Has("delete"):
+				surveyId := survey.SurveyId(request.FormValue("delete"))
+				d.Error = s.AdminDelete(surveyId)
+				if d.Error == nil {
+					log.Printf("admin: survey %s deleted by %s", surveyId, user)
+					d.Message = "Die Umfrage wurde gelöscht."
+				}
+			case request.Form.Has("settings"):
+				timeout, err := strconv.Atoi(request.FormValue("timeout"))
+				if err != nil {
+					d.Error = err
+					break
+				}
+				d.Error = s.SetTimeout(timeout)
+				if d.Error == nil {
+					viv := request.FormValue("voteIfResultVisible") == "true"
+					s.SetVoteIfResultVisible(viv)
+					log.Printf("admin: timeout %d min, vote if result visible %t set by %s", timeout, viv, user)
+					d.Message = "Die Einstellungen wurden übernommen."
+				}
+			}
+		}
+
+		d.Surveys = s.AdminList()
+		d.Stats = s.Stats()
+		d.Waiters = s.WaiterStats()
+		d.Goroutines = runtime.NumGoroutine()
+		var m runtime.MemStats
+		runtime.ReadMemStats(&m)
+		d.HeapKB = m.HeapAlloc / 1024
+		d.Timeout = s.DefaultTimeout()
+		d.VoteIfResultVisible = s.VoteIfResultVisible()
+
+		writer.Header().Set("Cache-Control", "no-store")
+		err := localTemplate(request, adminTemp).Execute(writer, d)
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
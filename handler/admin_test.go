@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"flashSurvey/account"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmin(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+
+	h := AdminAuth(account.Htpasswd{"admin": "secret"})(Admin(s))
+	request := func(method, password string, form url.Values, origin string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/admin/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if password != "" {
+			r.SetBasicAuth("admin", password)
+		}
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		h(w, r)
+		return w
+	}
+
+	w := request(http.MethodGet, "", nil, "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Contains(t, w.Header().Get("WWW-Authenticate"), "Basic")
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "wrong", nil, "").Code)
+
+	w = request(http.MethodGet, "secret", nil, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), string(sid))
+	assert.NotContains(t, w.Body.String(), "creator")
+
+	// the settings are changed at runtime
+	w = request(http.MethodPost, "secret", url.Values{"settings": {"true"}, "timeout": {"45"}, "voteIfResultVisible": {"true"}}, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 45, s.DefaultTimeout())
+	assert.True(t, s.VoteIfResultVisible())
+
+	// a form of another site is rejected
+	w = request(http.MethodPost, "secret", url.Values{"delete": {string(sid)}}, "https://evil.example")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Len(t, s.AdminList(), 1)
+
+	w = request(http.MethodPost, "secret", url.Values{"delete": {string(sid)}}, "http://example.com")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, s.AdminList())
+}
@@ -0,0 +1,245 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"flashSurvey/i18n"
+	"flashSurvey/survey"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// apiPrefix is the path of the current version of the API
+const apiPrefix = "/api/v1/surveys"
+
+// maxAPIBodySize is the maximum size of a request body sent to the API
+const maxAPIBodySize = 64 * 1024
+
+// APITokens maps the tokens of the external tools to their names
+type APITokens map[string]string
+
+// ParseAPITokens parses a comma separated list of name=token pairs
+func ParseAPITokens(list string) (APITokens, error) {
+	t := APITokens{}
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, token, ok := strings.Cut(entry, "=")
+		if !ok || name == "" || token == "" {
+			return nil, fmt.Errorf("invalid api token %q", entry)
+		}
+		t[token] = name
+	}
+	return t, nil
+}
+
+// user returns the user the surveys of the tool are created by. Each
+// tool acts as a creator of its own.
+func (t APITokens) user(request *http.Request) (survey.UserId, bool) {
+	token, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return "", false
+	}
+	for known, name := range t {
+		if subtle.ConstantTimeCompare([]byte(known), []byte(token)) == 1 {
+			return survey.UserId("api:" + name), true
+		}
+	}
+	return "", false
+}
+
+// APIVote is a vote cast by an external tool
+type APIVote struct {
+	// Voter identifies the voter within the tool
+	Voter   string
+	Options []int
+	// Number is the number of the question, if zero the running question is used
+	Number int
+}
+
+// APIVotes is the number of votes of the running question
+type APIVotes struct {
+	Number int
+	Votes  int
+	Hidden bool
+}
+
+// API is a JSON API for external tools like learning management systems.
+// The tools authenticate by a bearer token. The endpoints are
+//
+//	GET  /api/v1/surveys              lists the surveys of the tool
+//	POST /api/v1/surveys              creates a survey, the body is a SurveyQuestion
+//	GET  /api/v1/surveys/{id}/votes   returns the number of votes
+//	POST /api/v1/surveys/{id}/votes   casts a vote, the body is an APIVote
+//	GET  /api/v1/surveys/{id}/result  returns the result if it is visible
+//	POST /api/v1/surveys/{id}/result  makes the result visible and returns it
+func API(s *survey.Surveys, tokens APITokens) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId, ok := tokens.user(request)
+		if !ok {
+			writer.Header().Set("WWW-Authenticate", `Bearer realm="flashSurvey"`)
+			apiError(writer, request, errors.New("invalid token"), http.StatusUnauthorized)
+			return
+		}
+
+		path := strings.Trim(strings.TrimPrefix(request.URL.Path, apiPrefix), "/")
+		if path == "" {
+			switch request.Method {
+			case http.MethodGet:
+				list := s.ListByUser(userId)
+				if list == nil {
+					list = []survey.SurveyInfo{}
+				}
+				apiResponse(writer, http.StatusOK, list)
+			case http.MethodPost:
+				apiCreate(s, userId, writer, request)
+			default:
+				apiError(writer, request, errors.New("method not allowed"), http.StatusMethodNotAllowed)
+			}
+			return
+		}
+
+		id, resource, _ := strings.Cut(path, "/")
+		surveyId := survey.SurveyId(id)
+		switch {
+		case resource == "votes" && request.Method == http.MethodGet:
+			result := s.GetResult(userId, surveyId)
+			if result.Version < 0 {
+				apiError(writer, request, survey.ErrSurveyNotFound, http.StatusNotFound)
+				return
+			}
+			apiResponse(writer, http.StatusOK, APIVotes{
+				Number: result.Number,
+				Votes:  result.Votes,
+				Hidden: result.Covered(),
+			})
+		case resource == "votes" && request.Method == http.MethodPost:
+			apiVote(s, userId, surveyId, writer, request)
+		case resource == "result" && (request.Method == http.MethodGet || request.Method == http.MethodPost):
+			if request.Method == http.MethodPost {
+				if err := s.Uncover(userId, surveyId); err != nil {
+					apiError(writer, request, err, errorStatus(err, http.StatusNotFound))
+					return
+				}
+			}
+			e, err := exportResult(s.GetResult(userId, surveyId), time.Now())
+			if err != nil {
+				apiError(writer, request, err, http.StatusConflict)
+				return
+			}
+			apiResponse(writer, http.StatusOK, e)
+		case resource == "votes" || resource == "result":
+			apiError(writer, request, errors.New("method not allowed"), http.StatusMethodNotAllowed)
+		default:
+			apiError(writer, request, errors.New("not found"), http.StatusNotFound)
+		}
+	}
+}
+
+// apiCreate creates a new survey with the question given in the body
+func apiCreate(s *survey.Surveys, userId survey.UserId, writer http.ResponseWriter, request *http.Request) {
+	var q survey.SurveyQuestion
+	if err := apiDecode(request, &q); err != nil {
+		apiError(writer, request, err, http.StatusBadRequest)
+		return
+	}
+	surveyId, err := s.New(userId, "", q)
+	if err != nil {
+		apiError(writer, request, err, errorStatus(err, http.StatusBadRequest))
+		return
+	}
+	for _, info := range s.ListByUser(userId) {
+		if info.Id == surveyId {
+			apiResponse(writer, http.StatusCreated, info)
+			return
+		}
+	}
+	apiError(writer, request, survey.ErrSurveyNotFound, http.StatusNotFound)
+}
+
+// apiVote casts the vote given in the body
+func apiVote(s *survey.Surveys, userId survey.UserId, surveyId survey.SurveyId, writer http.ResponseWriter, request *http.Request) {
+	var v APIVote
+	if err := apiDecode(request, &v); err != nil {
+		apiError(writer, request, err, http.StatusBadRequest)
+		return
+	}
+	if v.Voter == "" {
+		apiError(writer, request, errors.New("voter missing"), http.StatusBadRequest)
+		return
+	}
+	result := s.GetResult(userId, surveyId)
+	if result.Version < 0 {
+		apiError(writer, request, survey.ErrSurveyNotFound, http.StatusNotFound)
+		return
+	}
+	if v.Number == 0 {
+		v.Number = result.Number
+	}
+	// the voters of a tool can not collide with the browsers
+	voterId := survey.UserId(string(userId) + ":" + v.Voter)
+	if err := s.Vote(surveyId, voterId, v.Options, v.Number); err != nil {
+		apiError(writer, request, err, errorStatus(err, http.StatusConflict))
+		return
+	}
+	writer.WriteHeader(http.StatusNoContent)
+}
+
+func apiDecode(request *http.Request, v any) error {
+	dec := json.NewDecoder(io.LimitReader(request.Body, maxAPIBodySize))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return fmt.Errorf("invalid body: %w", err)
+	}
+	return nil
+}
+
+func apiResponse(writer http.ResponseWriter, status int, v any) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	if err := json.NewEncoder(writer).Encode(v); err != nil {
+		log.Println(err)
+	}
+}
+
+// APIError is the body of a failed request. The message is given in the
+// language of the Accept-Language header, the errors of the surveys also
+// contain the code of the message, so the tools can localize it themselves.
+type APIError struct {
+	Error  string
+	Code   string          `json:",omitempty"`
+	Args   []any           `json:",omitempty"`
+	Fields []APIFieldError `json:",omitempty"`
+}
+
+// APIFieldError is the error of a single field of a survey definition
+type APIFieldError struct {
+	Field string
+	Error string
+	Code  string `json:",omitempty"`
+	Args  []any  `json:",omitempty"`
+}
+
+func apiError(writer http.ResponseWriter, request *http.Request, err error, status int) {
+	lang := i18n.Negotiate(request.Header.Get("Accept-Language"))
+	e := APIError{Error: localizeError(lang, err)}
+	var se *survey.Error
+	if errors.As(err, &se) {
+		e.Code = se.Code
+		e.Args = se.Args
+	}
+	var ve survey.ValidationError
+	if errors.As(err, &ve) {
+		for _, f := range ve {
+			e.Fields = append(e.Fields, APIFieldError{Field: f.Field, Error: f.Localize(lang), Code: f.Code, Args: f.Args})
+		}
+	}
+	apiResponse(writer, status, e)
+}
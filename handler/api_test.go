@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"encoding/json"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAPITokens(t *testing.T) {
+	tokens, err := ParseAPITokens("lms=secret, script=other")
+	assert.NoError(t, err)
+	assert.Equal(t, APITokens{"secret": "lms", "other": "script"}, tokens)
+
+	_, err = ParseAPITokens("lms")
+	assert.Error(t, err)
+}
+
+func TestAPI(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	api := API(s, APITokens{"secret": "lms"})
+
+	request := func(method, url, token, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, url, strings.NewReader(body))
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		api(w, r)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/v1/surveys", "", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, request(http.MethodGet, "/api/v1/surveys", "wrong", "").Code)
+
+	w := request(http.MethodPost, "/api/v1/surveys", "secret", `{"Title":"Test","Options":["A","B"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var info survey.SurveyInfo
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+	assert.Equal(t, "Test", info.Title)
+	base := "/api/v1/surveys/" + string(info.Id)
+
+	assert.Equal(t, http.StatusBadRequest, request(http.MethodPost, "/api/v1/surveys", "secret", `{"Unknown":1}`).Code)
+
+	w = request(http.MethodGet, "/api/v1/surveys", "secret", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list []survey.SurveyInfo
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Len(t, list, 1)
+
+	assert.Equal(t, http.StatusNoContent, request(http.MethodPost, base+"/votes", "secret", `{"Voter":"a","Options":[0]}`).Code)
+	assert.Equal(t, http.StatusNoContent, request(http.MethodPost, base+"/votes", "secret", `{"Voter":"b","Options":[1]}`).Code)
+	assert.Equal(t, http.StatusConflict, request(http.MethodPost, base+"/votes", "secret", `{"Voter":"a","Options":[1]}`).Code)
+
+	w = request(http.MethodGet, base+"/votes", "secret", "")
+	var votes APIVotes
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &votes))
+	assert.Equal(t, APIVotes{Number: 1, Votes: 2, Hidden: true}, votes)
+
+	// the result is hidden until it is uncovered
+	assert.Equal(t, http.StatusConflict, request(http.MethodGet, base+"/result", "secret", "").Code)
+	w = request(http.MethodPost, base+"/result", "secret", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var e ExportResult
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &e))
+	assert.Equal(t, 2, e.Votes)
+	assert.Equal(t, 1, e.Options[0].Votes)
+
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, "/api/v1/surveys/unknown/votes", "secret", "").Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, request(http.MethodDelete, base+"/result", "secret", "").Code)
+	assert.Equal(t, http.StatusNotFound, request(http.MethodGet, base+"/other", "secret", "").Code)
+}
+
+func TestAPIErrorLocalized(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	api := API(s, APITokens{"secret": "lms"})
+
+	request := func(method, url, lang, body string) APIError {
+		r := httptest.NewRequest(method, url, strings.NewReader(body))
+		r.Header.Set("Authorization", "Bearer secret")
+		r.Header.Set("Accept-Language", lang)
+		w := httptest.NewRecorder()
+		api(w, r)
+		var e APIError
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &e))
+		return e
+	}
+
+	e := request(http.MethodGet, "/api/v1/surveys/unknown/votes", "en", "")
+	assert.Equal(t, APIError{Error: "This survey does not exist!", Code: "surveyNotFound"}, e)
+	e = request(http.MethodGet, "/api/v1/surveys/unknown/votes", "de", "")
+	assert.Equal(t, "Diese Umfrage existiert nicht!", e.Error)
+
+	e = request(http.MethodPost, "/api/v1/surveys", "en", `{"Title":"","Options":["A"]}`)
+	assert.Equal(t, "The title is missing! At least two options are required!", e.Error)
+	assert.Equal(t, []APIFieldError{
+		{Field: "title", Error: "The title is missing!", Code: "titleMissing"},
+		{Field: "option1", Error: "At least two options are required!", Code: "twoOptions"},
+	}, e.Fields)
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"bytes"
+	"flashSurvey/store"
+	"flashSurvey/survey"
+	"log"
+	"net/http"
+	"time"
+)
+
+const maxArchiveSize = 32 * 1024 * 1024
+
+type BackupData struct {
+	// Restored is the number of restored documents
+	Restored int
+	Error    error
+}
+
+// Backup allows administrators to download and restore all persisted data.
+// The documents are backed up as they are stored, so the given store must
+// not decrypt them.
+func Backup(st store.Store) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		var d BackupData
+		switch {
+		case request.Method == http.MethodGet && request.URL.Query().Has("download"):
+			var b bytes.Buffer
+			n, err := store.Backup(st, &b)
+			if err != nil {
+				log.Println("backup failed:", err)
+				http.Error(writer, "backup failed", http.StatusInternalServerError)
+				return
+			}
+			log.Printf("backup of %d documents downloaded", n)
+			name := "flashSurvey-" + time.Now().Format("2006-01-02") + ".json.gz"
+			writer.Header().Set("Content-Type", "application/gzip")
+			writer.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+			_, err = writer.Write(b.Bytes())
+			if err != nil {
+				log.Println(err)
+			}
+			return
+		case request.Method == http.MethodPost:
+			request.Body = http.MaxBytesReader(writer, request.Body, maxArchiveSize)
+			f, _, err := request.FormFile("archive")
+			if err != nil {
+				d.Error = &survey.Error{Code: "archiveUnreadable", Args: []any{err.Error()}}
+				break
+			}
+			n, err := store.Restore(st, f)
+			f.Close()
+			if err != nil {
+				d.Error = &survey.Error{Code: "restoreFailed", Args: []any{err.Error()}}
+				break
+			}
+			log.Printf("%d documents restored", n)
+			d.Restored = n
+		}
+
+		err := localTemplate(request, backupTemp).Execute(writer, d)
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
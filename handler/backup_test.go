@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"bytes"
+	"flashSurvey/store"
+	"mime/multipart"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackup(t *testing.T) {
+	src, err := store.New("")
+	assert.NoError(t, err)
+	assert.NoError(t, src.Save("banner", bannerDoc{Text: "hello"}))
+
+	w := httptest.NewRecorder()
+	Backup(src)(w, httptest.NewRequest("GET", "/backup/?download=true", nil))
+	assert.EqualValues(t, "application/gzip", w.Header().Get("Content-Type"))
+	archive := w.Body.Bytes()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	f, err := mw.CreateFormFile("archive", "backup.json.gz")
+	assert.NoError(t, err)
+	f.Write(archive)
+	mw.Close()
+
+	dst, err := store.New("")
+	assert.NoError(t, err)
+	r := httptest.NewRequest("POST", "/backup/", &body)
+	r.Header.Set("Content-Type", mw.FormDataContentType())
+	w = httptest.NewRecorder()
+	Backup(dst)(w, r)
+	assert.Contains(t, w.Body.String(), "1 Dokumente wurden wiederhergestellt")
+
+	var d bannerDoc
+	found, err := dst.Load("banner", &d)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.EqualValues(t, "hello", d.Text)
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"flashSurvey/survey"
+	"fmt"
+	"html/template"
+	"log"
+	"net/http"
+	"unicode/utf8"
+)
+
+const (
+	badgeLabel     = "Umfrage"
+	badgeMaxLeader = 30
+	// badgeCharWidth is the approximate width of a character of the badge font
+	badgeCharWidth = 7
+	badgePadding   = 10
+)
+
+// badgeMessage returns the text and the color of the badge
+func badgeMessage(r survey.Result, uncovered bool) (string, string) {
+	if !uncovered {
+		return "nicht aufgedeckt", "#9f9f9f"
+	}
+	if r.Votes == 0 {
+		return "keine Stimmen", "#9f9f9f"
+	}
+	leader := -1
+	tie := false
+	for i, o := range r.Result {
+		switch {
+		case leader < 0 || o.VoteCount() > r.Result[leader].VoteCount():
+			leader = i
+			tie = false
+		case o.VoteCount() == r.Result[leader].VoteCount():
+			tie = true
+		}
+	}
+	if tie {
+		return fmt.Sprintf("Gleichstand · %d Stimmen", r.Votes), "#dfb317"
+	}
+	title := r.Result[leader].Title
+	if utf8.RuneCountInString(title) > badgeMaxLeader {
+		title = string([]rune(title)[:badgeMaxLeader-1]) + "…"
+	}
+	return fmt.Sprintf("%s · %d Stimmen", title, r.Votes), "#4c1"
+}
+
+// badgeSVG renders a badge in the style of shields.io
+func badgeSVG(label, message, color string) string {
+	lw := utf8.RuneCountInString(label)*badgeCharWidth + badgePadding
+	mw := utf8.RuneCountInString(message)*badgeCharWidth + badgePadding
+	label = template.HTMLEscapeString(label)
+	message = template.HTMLEscapeString(message)
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="20" role="img" aria-label="%[4]s: %[5]s">`+
+		`<title>%[4]s: %[5]s</title>`+
+		`<linearGradient id="s" x2="0" y2="100%%"><stop offset="0" stop-color="#bbb" stop-opacity=".1"/><stop offset="1" stop-opacity=".1"/></linearGradient>`+
+		`<clipPath id="r"><rect width="%[1]d" height="20" rx="3" fill="#fff"/></clipPath>`+
+		`<g clip-path="url(#r)"><rect width="%[2]d" height="20" fill="#555"/><rect x="%[2]d" width="%[3]d" height="20" fill="%[6]s"/><rect width="%[1]d" height="20" fill="url(#s)"/></g>`+
+		`<g fill="#fff" text-anchor="middle" font-family="Verdana,Geneva,DejaVu Sans,sans-serif" font-size="11">`+
+		`<text x="%[7]d" y="15" fill="#010101" fill-opacity=".3">%[4]s</text><text x="%[7]d" y="14">%[4]s</text>`+
+		`<text x="%[8]d" y="15" fill="#010101" fill-opacity=".3">%[5]s</text><text x="%[8]d" y="14">%[5]s</text>`+
+		`</g></svg>`,
+		lw+mw, lw, mw, label, message, color, lw/2, lw+mw/2)
+}
+
+// Badge returns a SVG badge showing the leading option and the number of
+// votes of an uncovered survey. It can be embedded in wikis and READMEs.
+func Badge(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		surveyId := survey.SurveyId(request.URL.Query().Get("id"))
+		r, uncovered := s.UncoveredResult(surveyId)
+		message, color := badgeMessage(r, uncovered)
+
+		writer.Header().Set("Content-Type", "image/svg+xml")
+		// the result may still change, so proxies like GitHub's camo must not cache it
+		writer.Header().Set("Cache-Control", "no-cache, max-age=0")
+		_, err := writer.Write([]byte(badgeSVG(badgeLabel, message, color)))
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
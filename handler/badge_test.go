@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"flashSurvey/survey"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBadge(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Q", Options: []string{"<a>", "b"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "v1", []int{0}, 1))
+
+	badge := func() string {
+		w := httptest.NewRecorder()
+		Badge(s)(w, httptest.NewRequest("GET", "/badge/?id="+string(sid), nil))
+		assert.EqualValues(t, "image/svg+xml", w.Header().Get("Content-Type"))
+		return w.Body.String()
+	}
+
+	assert.Contains(t, badge(), "nicht aufgedeckt")
+	assert.NoError(t, s.Uncover("creator", sid))
+	b := badge()
+	assert.Contains(t, b, "&lt;a&gt; · 1 Stimmen")
+	assert.False(t, strings.Contains(b, "<a>"))
+}
+
+func TestBadgeMessage(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Q", Options: []string{"a", "b"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Uncover("creator", sid))
+
+	r, ok := s.UncoveredResult(sid)
+	assert.True(t, ok)
+	m, _ := badgeMessage(r, ok)
+	assert.EqualValues(t, "keine Stimmen", m)
+
+	sid, err = s.New("creator", sid, survey.SurveyQuestion{Title: "Q2", Options: []string{"a", "b"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "v1", []int{0}, 2))
+	assert.NoError(t, s.Vote(sid, "v2", []int{1}, 2))
+	_, ok = s.UncoveredResult(sid)
+	assert.False(t, ok)
+	assert.NoError(t, s.Uncover("creator", sid))
+	r, ok = s.UncoveredResult(sid)
+	assert.True(t, ok)
+	m, _ = badgeMessage(r, ok)
+	assert.EqualValues(t, "Gleichstand · 2 Stimmen", m)
+
+	_, ok = s.UncoveredResult("unknown")
+	assert.False(t, ok)
+}
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/json"
+	"flashSurvey/store"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const (
+	bannerKey    = "banner"
+	maxBannerLen = 500
+)
+
+// banner is the announcement shown on top of all pages. It is global because
+// it is accessed by the templates via the template function "banner".
+var banner struct {
+	mutex sync.RWMutex
+	text  string
+	store store.Store
+}
+
+type bannerDoc struct {
+	Text string `json:"text"`
+}
+
+// LoadBanner loads the banner from the given store
+func LoadBanner(st store.Store) error {
+	var d bannerDoc
+	_, err := st.Load(bannerKey, &d)
+	if err != nil {
+		return fmt.Errorf("could not load banner: %w", err)
+	}
+
+	banner.mutex.Lock()
+	defer banner.mutex.Unlock()
+	banner.store = st
+	banner.text = d.Text
+	return nil
+}
+
+func getBanner() string {
+	banner.mutex.RLock()
+	defer banner.mutex.RUnlock()
+	return banner.text
+}
+
+func setBanner(text string) error {
+	text = strings.TrimSpace(text)
+	if len(text) > maxBannerLen {
+		return errBannerTooLong
+	}
+
+	banner.mutex.Lock()
+	defer banner.mutex.Unlock()
+	if banner.store != nil {
+		err := banner.store.Save(bannerKey, bannerDoc{Text: text})
+		if err != nil {
+			log.Println(err)
+			return errBannerNotSaved
+		}
+	}
+	banner.text = text
+	log.Println("banner changed")
+	return nil
+}
+
+type BannerData struct {
+	Text  string
+	Error error
+}
+
+// Banner allows administrators to edit the banner
+func Banner(writer http.ResponseWriter, request *http.Request) {
+	var d BannerData
+	if request.Method == http.MethodPost {
+		// the session cookie is sent with the requests of other sites as well
+		if !sameOrigin(request) {
+			http.Error(writer, "origin not allowed", http.StatusForbidden)
+			return
+		}
+		err := parseForm(writer, request, maxFormSize)
+		if err != nil {
+			formError(writer, err)
+			return
+		}
+		d.Error = setBanner(request.FormValue("text"))
+	}
+	d.Text = getBanner()
+
+	err := localTemplate(request, bannerTemp).Execute(writer, d)
+	if err != nil {
+		renderError(err)
+	}
+}
+
+// BannerRest allows to read and update the banner by sending
+// {"text":"..."} as json. An empty text removes the banner.
+func BannerRest(writer http.ResponseWriter, request *http.Request) {
+	if request.Method != http.MethodGet && !sameOrigin(request) {
+		http.Error(writer, "origin not allowed", http.StatusForbidden)
+		return
+	}
+	switch request.Method {
+	case http.MethodGet:
+	case http.MethodPost, http.MethodPut:
+		var d bannerDoc
+		err := json.NewDecoder(http.MaxBytesReader(writer, request.Body, maxFormSize)).Decode(&d)
+		if err != nil {
+			http.Error(writer, "invalid json: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		err = setBanner(d.Text)
+		if err != nil {
+			apiError(writer, request, err, http.StatusBadRequest)
+			return
+		}
+	case http.MethodDelete:
+		err := setBanner("")
+		if err != nil {
+			apiError(writer, request, err, http.StatusInternalServerError)
+			return
+		}
+	default:
+		http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(writer).Encode(bannerDoc{Text: getBanner()})
+	if err != nil {
+		log.Println(err)
+	}
+}
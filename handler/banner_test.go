@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBannerOrigin(t *testing.T) {
+	t.Cleanup(func() { _ = setBanner("") })
+
+	form := func(origin, text string) int {
+		r := httptest.NewRequest(http.MethodPost, "/banner/", strings.NewReader(url.Values{"text": {text}}.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		Banner(w, r)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusForbidden, form("https://evil.example", "evil"))
+	assert.Equal(t, "", getBanner())
+	assert.Equal(t, http.StatusOK, form("http://example.com", "Wartung"))
+	assert.Equal(t, "Wartung", getBanner())
+
+	rest := func(method, origin, body string) int {
+		r := httptest.NewRequest(method, "/bannerRest/", strings.NewReader(body))
+		if origin != "" {
+			r.Header.Set("Origin", origin)
+		}
+		w := httptest.NewRecorder()
+		BannerRest(w, r)
+		return w.Code
+	}
+	assert.Equal(t, http.StatusForbidden, rest(http.MethodPut, "https://evil.example", `{"text":"evil"}`))
+	assert.Equal(t, http.StatusForbidden, rest(http.MethodDelete, "https://evil.example", ""))
+	assert.Equal(t, "Wartung", getBanner())
+	assert.Equal(t, http.StatusOK, rest(http.MethodGet, "https://evil.example", ""))
+	assert.Equal(t, http.StatusOK, rest(http.MethodPut, "", `{"text":"Update"}`))
+	assert.Equal(t, "Update", getBanner())
+	assert.Equal(t, http.StatusOK, rest(http.MethodDelete, "http://example.com", ""))
+	assert.Equal(t, "", getBanner())
+}
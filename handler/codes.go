@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"flashSurvey/survey"
+	"net/http"
+	"strconv"
+)
+
+// CodesData contains the access codes of a survey
+type CodesData struct {
+	// Codes contains the codes just created, they are shown only once
+	Codes  []survey.AccessCode
+	Issued int
+	Used   int
+	Error  error
+}
+
+// Codes lets the creator create single-use access codes, which can be
+// printed and handed out to the participants. Only the holders of an
+// unused code can vote.
+func Codes(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+
+		var d CodesData
+		if request.Method == http.MethodPost {
+			err := parseForm(writer, request, maxFormSize)
+			if err != nil {
+				formError(writer, err)
+				return
+			}
+			if request.FormValue("remove") != "" {
+				s.RemoveCodes(userId, surveyId)
+			} else if n, err := strconv.Atoi(request.FormValue("count")); err != nil {
+				d.Error = errInvalidCount
+			} else {
+				d.Codes, d.Error = s.GenerateCodes(userId, surveyId, n)
+			}
+		}
+
+		d.Issued, d.Used = s.CodeStats(userId, surveyId)
+
+		err := localTemplate(request, codesTemp).Execute(writer, d)
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCodes(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, "/codes/", strings.NewReader("count=2"))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+	r.AddCookie(&http.Cookie{Name: "sid", Value: string(sid)})
+	w := httptest.NewRecorder()
+	Codes(s)(w, r)
+	assert.Contains(t, w.Body.String(), "Es wurden 2 Codes erzeugt, davon wurden 0 bereits verwendet.")
+
+	codes, err := s.GenerateCodes("creator", sid, 1)
+	assert.NoError(t, err)
+	u, err := url.Parse(codes[0].URL)
+	assert.NoError(t, err)
+	code := u.Query().Get("a")
+
+	vote := func(query string) string {
+		r := httptest.NewRequest(http.MethodGet, "/voteRest/?id="+string(sid)+query, nil)
+		r = r.WithContext(context.WithValue(r.Context(), "id", "voter"))
+		w := httptest.NewRecorder()
+		VoteRest(s)(w, r)
+		return w.Body.String()
+	}
+
+	page := httptest.NewRecorder()
+	r = httptest.NewRequest(http.MethodGet, "/vote/?id="+string(sid)+"&a="+code, nil)
+	Vote(s)(page, r.WithContext(context.WithValue(r.Context(), "id", "voter")))
+	assert.Contains(t, page.Body.String(), `id="accessCode"`)
+	assert.Contains(t, page.Body.String(), `value="`+code+`"`)
+
+	assert.Contains(t, vote("&o=0&n=1"), "Für diese Umfrage wird ein Zugangscode benötigt!")
+	assert.Contains(t, vote("&o=0&n=1&a="+code), "Sie haben erfolgreich abgestimmt!")
+	assert.Contains(t, vote("&o=0&n=1&a="+code), "Der Zugangscode ist ungültig oder wurde bereits verwendet!")
+	assert.Equal(t, 1, s.CollectedVotes("creator", sid))
+}
+
+func TestCodeWithoutCodes(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	// without codes, an arbitrary code does not create a new voter
+	assert.Contains(t, voteRest(s, sid, "voter", "&a=1"), "Sie haben erfolgreich abgestimmt!")
+	assert.Contains(t, voteRest(s, sid, "voter", "&a=2"), "Sie haben bereits abgestimmt!")
+	assert.Equal(t, 1, s.CollectedVotes("creator", sid))
+}
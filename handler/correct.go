@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"encoding/json"
+	"flashSurvey/survey"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// QuestionCorrection contains the corrected texts of the running question
+type QuestionCorrection struct {
+	Revision int
+	Title    string
+	Options  []string
+}
+
+// writeCorrection sends the texts of the question if they have been changed
+// since the revision shown to the voter. If nothing has changed or another
+// question is running, no content is sent.
+func writeCorrection(writer http.ResponseWriter, question survey.Question, query url.Values) {
+	n, _ := strconv.Atoi(query.Get("n"))
+	c, _ := strconv.Atoi(query.Get("c"))
+	if question.Number != n || question.Revision == c || question.Question.Encrypted() {
+		writer.WriteHeader(http.StatusNoContent)
+		return
+	}
+	jsonData, err := json.Marshal(QuestionCorrection{
+		Revision: question.Revision,
+		Title:    question.Question.Title,
+		Options:  question.Question.Options,
+	})
+	if err != nil {
+		http.Error(writer, "could not marshal correction: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writer.Header().Set("Content-Type", "application/json")
+	_, err = writer.Write(jsonData)
+	if err != nil {
+		log.Println(err)
+	}
+}
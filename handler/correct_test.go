@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoteRestCorrection(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Tets", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	get := func(number, revision int) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/voteRest/?id="+string(sid)+"&n="+strconv.Itoa(number)+"&c="+strconv.Itoa(revision), nil)
+		r = r.WithContext(context.WithValue(r.Context(), "id", "voter"))
+		w := httptest.NewRecorder()
+		VoteRest(s)(w, r)
+		return w
+	}
+
+	assert.Equal(t, http.StatusNoContent, get(1, 0).Code)
+
+	assert.NoError(t, s.Correct("creator", sid, "Test", []string{"A", "B"}))
+	w := get(1, 0)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var c QuestionCorrection
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &c))
+	assert.Equal(t, QuestionCorrection{Revision: 1, Title: "Test", Options: []string{"A", "B"}}, c)
+
+	assert.Equal(t, http.StatusNoContent, get(1, 1).Code)
+	// another question is running
+	assert.Equal(t, http.StatusNoContent, get(2, 0).Code)
+}
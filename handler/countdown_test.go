@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoteCountdown(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}, Countdown: 90})
+	assert.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/vote/?id="+string(sid), nil)
+	r.Header.Set("Accept-Language", "en")
+	w := httptest.NewRecorder()
+	Vote(s)(w, r)
+	assert.Contains(t, w.Body.String(), `id="countdown" data-remaining="90"`)
+	assert.Contains(t, w.Body.String(), "Remaining time:")
+
+	d := dataFromResult(s.GetResult("creator", sid), "de")
+	assert.Contains(t, string(d.Result), `data-remaining="90"`)
+}
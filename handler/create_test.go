@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateConfirm(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	post := func(form url.Values) string {
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.AddCookie(&http.Cookie{Name: "sid", Value: string(sid)})
+		r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+		w := httptest.NewRecorder()
+		Create(s, nil, false)(w, r)
+		return w.Body.String()
+	}
+	form := url.Values{"title": {"Next"}, "option0": {"C"}, "option1": {"D"}, "create": {"true"}}
+
+	// without votes there is nothing to confirm
+	assert.NotContains(t, post(form), "Trotzdem starten")
+	assert.Equal(t, 2, s.GetQuestion(sid).Number)
+
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 2))
+	assert.NoError(t, s.Vote(sid, "b", []int{1}, 2))
+	body := post(form)
+	assert.Contains(t, body, "Beim Neustart werden die 2 bereits abgegebenen Stimmen gelöscht!")
+	assert.Equal(t, 2, s.GetQuestion(sid).Number)
+
+	form.Set("confirm", "true")
+	assert.NotContains(t, post(form), "Trotzdem starten")
+	assert.Equal(t, 3, s.GetQuestion(sid).Number)
+}
+
+func TestCreateFieldErrors(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	form := url.Values{"title": {"Test"}, "option0": {"A"}, "option1": {"B"}, "option2": {"a"}, "create": {"true"}}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+	w := httptest.NewRecorder()
+	Create(s, nil, false)(w, r)
+
+	body := w.Body.String()
+	assert.Contains(t, body, "Bitte die markierten Eingaben korrigieren!")
+	assert.Contains(t, body, `<span class="error">Option 3 ist identisch mit Option 1!</span>`)
+	assert.Equal(t, 0, s.Count())
+}
+
+func TestCreateDataMaxOptions(t *testing.T) {
+	d := CreateData{Question: survey.SurveyQuestion{Options: []string{"A", "B", "C", "D"}}}
+	assert.Equal(t, 6, d.MaxOptions())
+	assert.True(t, d.MoreOptions())
+
+	d.OptionLimit = 5
+	assert.Equal(t, 5, d.MaxOptions())
+	assert.False(t, d.MoreOptions())
+}
+
+func TestCreateOptionList(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	form := url.Values{"title": {"Test"}, "option0": {"X"}, "optionList": {"A\nB\n\nC"}, "create": {"true"}}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+	w := httptest.NewRecorder()
+	Create(s, nil, false)(w, r)
+
+	sid, ok := s.SurveyOfCreator("creator")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"A", "B", "C"}, s.GetQuestion(sid).Question.Options)
+}
+
+func TestCreatePreview(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	form := url.Values{"title": {"Question?"}, "option0": {"A very long option"}, "option1": {"B"}, "preview": {"true"}}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+	w := httptest.NewRecorder()
+	Create(s, nil, false)(w, r)
+
+	body := w.Body.String()
+	assert.Contains(t, body, `<div class="preview">Vorschau</div>`)
+	assert.Contains(t, body, "Question?")
+	assert.Contains(t, body, `<span class="optionText">A very long option</span>`)
+	assert.Equal(t, 0, s.Count())
+}
+
+func TestVoteSeries(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	d := VoteData{Lang: "en", Series: survey.Series{Position: 3, Total: 8}}
+	assert.Equal(t, "Question 3 of 8", d.SeriesText())
+
+	assert.NoError(t, s.SetSeries("creator", sid, survey.Series{Position: 3, Total: 8}))
+	assert.Equal(t, "Frage 3 von 8", newVoteData(s.GetQuestion(sid), "", "de").SeriesText())
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"encoding/json"
+	"flashSurvey/survey"
+	"html/template"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// DashboardTile is the state of a single survey shown on the dashboard
+type DashboardTile struct {
+	Id        survey.SurveyId `json:"Id"`
+	Title     string          `json:"Title"`
+	Result    template.HTML   `json:"Result"`
+	Version   int             `json:"Version"`
+	Hidden    bool            `json:"Hidden"`
+	Encrypted bool            `json:"Encrypted"`
+	// QRCode is only sent if requested by the query parameter "qr"
+	QRCode string `json:"QRCode,omitempty"`
+}
+
+// Dashboard shows the live results of all surveys of the creator's account
+// side by side, e.g. the surveys of parallel workshop rooms.
+func Dashboard(writer http.ResponseWriter, request *http.Request) {
+	err := localTemplate(request, dashboardTemp).Execute(writer, nil)
+	if err != nil {
+		renderError(err)
+	}
+}
+
+type CarouselData struct {
+	Seconds int
+}
+
+// Carousel shows the results of all surveys of the creator's account one
+// after the other, e.g. on a screen in the foyer of a conference. The time
+// each survey is shown is given by the query parameter "s" in seconds.
+func Carousel(writer http.ResponseWriter, request *http.Request) {
+	d := CarouselData{Seconds: 10}
+	if sec, err := strconv.Atoi(request.URL.Query().Get("s")); err == nil && sec >= 3 {
+		d.Seconds = sec
+	}
+	err := localTemplate(request, carouselTemp).Execute(writer, d)
+	if err != nil {
+		renderError(err)
+	}
+}
+
+// DashboardRest returns the tiles of the dashboard. A POST request
+// uncovers the result of the survey given by the query parameter "id".
+func DashboardRest(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+
+		if request.Method == http.MethodPost {
+			err := s.Uncover(userId, survey.SurveyId(request.URL.Query().Get("id")))
+			if err != nil {
+				httpError(writer, request, err, errorStatus(err, http.StatusBadRequest))
+			}
+			return
+		}
+
+		withQR := request.URL.Query().Get("qr") == "1"
+		tiles := []DashboardTile{}
+		for _, id := range s.SurveysOfCreator(userId) {
+			result := s.GetResult(userId, id)
+			if result.Version < 0 {
+				continue
+			}
+			d := dataFromResult(result, resultLocale(result, request))
+			tile := DashboardTile{
+				Id:        id,
+				Title:     string(d.Title),
+				Result:    d.Result,
+				Version:   result.Version,
+				Hidden:    result.Covered(),
+				Encrypted: result.Encrypted,
+			}
+			if withQR {
+				tile.QRCode = result.QRCode
+			}
+			tiles = append(tiles, tile)
+		}
+
+		data, err := json.Marshal(tiles)
+		if err != nil {
+			http.Error(writer, "could not marshal tiles: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		_, err = writer.Write(data)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDashboardRest(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	q := survey.SurveyQuestion{Title: "Room", Options: []string{"A", "B"}}
+	sid1, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	sid2, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	_, err = s.New("other", "", q)
+	assert.NoError(t, err)
+
+	request := func(method, query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/dashboardRest/"+query, nil)
+		r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+		w := httptest.NewRecorder()
+		DashboardRest(s)(w, r)
+		return w
+	}
+	tiles := func() map[survey.SurveyId]DashboardTile {
+		var list []DashboardTile
+		assert.NoError(t, json.Unmarshal(request(http.MethodGet, "").Body.Bytes(), &list))
+		m := map[survey.SurveyId]DashboardTile{}
+		for _, tile := range list {
+			m[tile.Id] = tile
+		}
+		return m
+	}
+
+	m := tiles()
+	assert.Len(t, m, 2)
+	assert.True(t, m[sid1].Hidden)
+	assert.True(t, m[sid2].Hidden)
+
+	assert.Equal(t, http.StatusOK, request(http.MethodPost, "?id="+string(sid1)).Code)
+	m = tiles()
+	assert.False(t, m[sid1].Hidden)
+	assert.True(t, m[sid2].Hidden)
+
+	assert.Equal(t, http.StatusNotFound, request(http.MethodPost, "?id=unknown").Code)
+}
+
+func TestCarousel(t *testing.T) {
+	w := httptest.NewRecorder()
+	Carousel(w, httptest.NewRequest(http.MethodGet, "/carousel/?s=30", nil))
+	assert.Contains(t, w.Body.String(), "const period =  30  * 1000")
+
+	w = httptest.NewRecorder()
+	Carousel(w, httptest.NewRequest(http.MethodGet, "/carousel/?s=1", nil))
+	assert.Contains(t, w.Body.String(), "const period =  10  * 1000")
+}
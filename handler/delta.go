@@ -0,0 +1,43 @@
+package handler
+
+import "flashSurvey/survey"
+
+// ResultDelta contains the changes of a result since the version known by
+// the client. It is much smaller than the rendered result, which matters if
+// many dashboards are showing the same survey.
+type ResultDelta struct {
+	// Votes is the number of voters
+	Votes   int            `json:"Votes"`
+	Changes []OptionChange `json:"Changes,omitempty"`
+}
+
+// OptionChange is the new number of votes of an option
+type OptionChange struct {
+	Option int `json:"Option"`
+	Votes  int `json:"Votes"`
+}
+
+// resultDelta returns the changes from base to current, or nil if the
+// structure of the result has changed, so the whole result has to be sent.
+func resultDelta(current survey.Result, base *survey.Result) *ResultDelta {
+	if base == nil || current.Encrypted || base.Encrypted ||
+		current.Title != base.Title || len(current.Result) != len(base.Result) {
+		return nil
+	}
+	if current.Display.Pie() || current.Display.HideCounts || current.Display.ByGroup || current.Display.PerSelection() || current.Display.Significance || len(current.WriteIns) > 0 || current.Rating != nil || current.Ranked ||
+		!current.Expires.IsZero() || !base.Expires.IsZero() {
+		// the client can only update the bars from the shown counts
+		return nil
+	}
+	d := ResultDelta{Votes: current.Votes}
+	for i, o := range current.Result {
+		b := base.Result[i]
+		if o.Title != b.Title || (o.VoteCount() < 0) != (b.VoteCount() < 0) {
+			return nil
+		}
+		if o.VoteCount() != b.VoteCount() {
+			d.Changes = append(d.Changes, OptionChange{Option: i, Votes: o.VoteCount()})
+		}
+	}
+	return &d
+}
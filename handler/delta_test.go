@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultDelta(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	get := func(query string) ResultData {
+		r := httptest.NewRequest(http.MethodGet, "/resultRest/"+query, nil)
+		r.AddCookie(&http.Cookie{Name: "sid", Value: string(sid)})
+		r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+		w := httptest.NewRecorder()
+		ResultRest(s)(w, r)
+		var d ResultData
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &d))
+		return d
+	}
+
+	first := get("?v=-1")
+	assert.Nil(t, first.Delta)
+	assert.NotEmpty(t, first.Result)
+
+	// while the result is hidden, only the number of voters changes
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+	d := get("?d=1&v=" + strconv.Itoa(first.Version))
+	assert.NotNil(t, d.Delta)
+	assert.Empty(t, d.Result)
+	assert.EqualValues(t, 1, d.Delta.Votes)
+	assert.Empty(t, d.Delta.Changes)
+
+	// uncovering changes the structure, so the whole result is sent
+	assert.NoError(t, s.Uncover("creator", sid))
+	full := get("?d=1&v=" + strconv.Itoa(d.Version))
+	assert.Nil(t, full.Delta)
+	assert.NotEmpty(t, full.Result)
+
+	// an unknown version requires the whole result
+	assert.Nil(t, resultDelta(s.GetResult("creator", sid), nil))
+}
+
+func TestVoteTicker(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+	assert.NoError(t, s.Vote(sid, "b", []int{1}, 1))
+
+	d := dataFromResult(s.GetResult("creator", sid), "de")
+	assert.True(t, d.Hidden)
+	assert.Equal(t, 2, d.Votes)
+	assert.Contains(t, string(d.Result), "2 Stimmen abgegeben")
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	d = dataFromResult(s.GetResult("creator", sid), "de")
+	assert.False(t, d.Hidden)
+	assert.Zero(t, d.Votes)
+	assert.NotContains(t, string(d.Result), "abgegeben")
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"flashSurvey/survey"
+	"net/http"
+)
+
+// Draft stores the content of the create form, which is sent by create.js
+// whenever the form is changed, so a long question is not lost if the page
+// is reloaded. A DELETE request discards the draft.
+func Draft(s *survey.Surveys, announce bool) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+		switch request.Method {
+		case http.MethodPost:
+			if err := parseForm(writer, request, maxFormSize); err != nil {
+				formError(writer, err)
+				return
+			}
+			q, err := questionFromForm(request, announce)
+			if err == nil {
+				err = s.SaveDraft(userId, q)
+			}
+			if err != nil {
+				httpError(writer, request, err, errorStatus(err, http.StatusBadRequest))
+			}
+		case http.MethodDelete:
+			s.DeleteDraft(userId)
+		default:
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}
+}
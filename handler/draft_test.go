@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDraft(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+
+	send := func(method, target string, h http.HandlerFunc, form url.Values) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, target, strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+		w := httptest.NewRecorder()
+		h(w, r)
+		return w
+	}
+
+	form := url.Values{"title": {"Long question"}, "option0": {"A"}, "option1": {"B"}, "option2": {"C"}}
+	assert.Equal(t, http.StatusOK, send(http.MethodPost, "/draft/", Draft(s, false), form).Code)
+
+	body := send(http.MethodGet, "/", Create(s, nil, false), nil).Body.String()
+	assert.Contains(t, body, "Entwurf wurde wiederhergestellt")
+	assert.Contains(t, body, `value="Long question"`)
+	assert.Contains(t, body, `value="C"`)
+
+	// the draft is deleted if the question is started
+	form.Set("create", "true")
+	send(http.MethodPost, "/", Create(s, nil, false), form)
+	_, ok := s.Draft("creator")
+	assert.False(t, ok)
+
+	// encrypted surveys are not stored
+	form = url.Values{"title": {"secret"}, "publicKey": {"key"}}
+	assert.Equal(t, http.StatusBadRequest, send(http.MethodPost, "/draft/", Draft(s, false), form).Code)
+
+	form = url.Values{"title": {"Other"}}
+	send(http.MethodPost, "/draft/", Draft(s, false), form)
+	send(http.MethodDelete, "/draft/", Draft(s, false), nil)
+	_, ok = s.Draft("creator")
+	assert.False(t, ok)
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"errors"
+	"flashSurvey/survey"
+	"net/http"
+)
+
+// The errors of the handlers, they are identified by their code in the
+// message catalog like the errors of the surveys.
+var (
+	errTooManyOptions  = &survey.Error{Code: "tooManyOptions"}
+	errEncryptedList   = &survey.Error{Code: "encryptedList"}
+	errInvalidSeries   = &survey.Error{Code: "invalidSeries"}
+	errInvalidSchedule = &survey.Error{Code: "invalidSchedule"}
+	errInvalidEMail    = &survey.Error{Code: "invalidEMail"}
+	errInvalidEMails   = &survey.Error{Code: "invalidEMails"}
+	errMailNotSent     = &survey.Error{Code: "mailNotSent"}
+	errMailsNotSent    = &survey.Error{Code: "mailsNotSent"}
+	errLoginFailed     = &survey.Error{Code: "loginFailed"}
+	errInvalidLink     = &survey.Error{Code: "invalidLink"}
+	errReopenSurvey    = &survey.Error{Code: "reopenSurvey"}
+	errNoSurvey        = &survey.Error{Code: "noSurvey"}
+	errNoNewSurvey     = &survey.Error{Code: "noNewSurvey"}
+	errInvalidRating   = &survey.Error{Code: "invalidRating"}
+	errInvalidCount    = &survey.Error{Code: "invalidCount"}
+	errNotRunning      = &survey.Error{Code: "notRunning"}
+	errExportEncrypted = &survey.Error{Code: "exportEncrypted"}
+	errResultHidden    = &survey.Error{Code: "resultHidden"}
+	errBannerTooLong   = &survey.Error{Code: "bannerTooLong", Args: []any{maxBannerLen}}
+	errBannerNotSaved  = &survey.Error{Code: "bannerNotSaved"}
+)
+
+// errorStatus returns the http status of an error returned by the surveys.
+// If the error is not known, the given status is returned.
+func errorStatus(err error, fallback int) int {
+	var verr survey.ValidationError
+	switch {
+	case errors.Is(err, survey.ErrSurveyNotFound),
+		errors.Is(err, survey.ErrAnswerNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, survey.ErrNotOwner),
+		errors.Is(err, survey.ErrCreateNotAllowed),
+		errors.Is(err, survey.ErrSurveyOfOtherUser),
+		errors.Is(err, survey.ErrNotRegistered),
+		errors.Is(err, survey.ErrCodeRequired),
+		errors.Is(err, survey.ErrCodeInvalid):
+		return http.StatusForbidden
+	case errors.Is(err, survey.ErrAlreadyVoted),
+		errors.Is(err, survey.ErrDeviceVoted),
+		errors.Is(err, survey.ErrRoundClosed),
+		errors.Is(err, survey.ErrVotingTimeElapsed),
+		errors.Is(err, survey.ErrResultVisible),
+		errors.Is(err, survey.ErrLocked),
+		errors.Is(err, survey.ErrLockedReset),
+		errors.Is(err, survey.ErrNotEnoughVotes):
+		return http.StatusConflict
+	case errors.Is(err, survey.ErrInvalidOption),
+		errors.As(err, &verr):
+		return http.StatusBadRequest
+	case errors.Is(err, survey.ErrTooManySurveys):
+		return http.StatusTooManyRequests
+	case errors.Is(err, survey.ErrTooManyWaiters),
+		errors.Is(err, survey.ErrShutdown):
+		return http.StatusServiceUnavailable
+	}
+	return fallback
+}
@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"errors"
+	"flashSurvey/survey"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorStatus(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{err: survey.ErrSurveyNotFound, status: http.StatusNotFound},
+		{err: survey.ErrNotOwner, status: http.StatusForbidden},
+		{err: survey.ErrAlreadyVoted, status: http.StatusConflict},
+		{err: survey.ErrRoundClosed, status: http.StatusConflict},
+		{err: survey.ErrInvalidOption, status: http.StatusBadRequest},
+		{err: survey.ValidationError{{Field: "title"}}, status: http.StatusBadRequest},
+		{err: fmt.Errorf("create: %w", survey.ErrTooManySurveys), status: http.StatusTooManyRequests},
+		{err: survey.ErrTooManyWaiters, status: http.StatusServiceUnavailable},
+		{err: fmt.Errorf("vote: %w", survey.ErrAlreadyVoted), status: http.StatusConflict},
+		// an error received from the origin of a federated survey
+		{err: &survey.Error{Code: "alreadyVoted"}, status: http.StatusConflict},
+		{err: errors.New("unknown"), status: http.StatusTeapot},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.status, errorStatus(test.err, http.StatusTeapot), test.err.Error())
+	}
+}
@@ -0,0 +1,88 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"flashSurvey/survey"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// eventPingInterval is the interval of the comments sent to keep the
+// connection open if the question does not change
+const eventPingInterval = 30 * time.Second
+
+// VoteEvents notifies the vote page via Server-Sent Events if a new
+// question is started or the running question is corrected, so the voters
+// do not have to ask for the next question. If the survey is deleted, the
+// event "closed" is sent.
+func VoteEvents(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		surveyId := survey.SurveyId(request.URL.Query().Get("id"))
+		changes, unsubscribe, err := s.Subscribe(surveyId)
+		if err != nil {
+			status := errorStatus(err, http.StatusNotFound)
+			if status == http.StatusServiceUnavailable {
+				writer.Header().Set("Retry-After", "30")
+			}
+			http.Error(writer, err.Error(), status)
+			return
+		}
+		defer unsubscribe()
+
+		// the deadlines of the server do not apply to the long-lived stream
+		rc := http.NewResponseController(writer)
+		for _, set := range []func(time.Time) error{rc.SetReadDeadline, rc.SetWriteDeadline} {
+			if err := set(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				log.Println("could not clear deadline:", err)
+			}
+		}
+
+		writer.Header().Set("Content-Type", "text/event-stream")
+		writer.Header().Set("Cache-Control", "no-cache")
+		// disables the buffering of reverse proxies like nginx
+		writer.Header().Set("X-Accel-Buffering", "no")
+
+		send := func(event string) bool {
+			_, err := fmt.Fprint(writer, event)
+			if err == nil {
+				err = rc.Flush()
+			}
+			return err == nil
+		}
+		if !send("retry: 5000\n\n") {
+			return
+		}
+
+		ping := time.NewTicker(eventPingInterval)
+		defer ping.Stop()
+		for {
+			select {
+			case change, ok := <-changes:
+				if !ok {
+					send("event: closed\ndata: {}\n\n")
+					return
+				}
+				data, err := json.Marshal(change)
+				if err != nil {
+					log.Println("could not marshal question change:", err)
+					return
+				}
+				if !send("event: question\ndata: " + string(data) + "\n\n") {
+					return
+				}
+			case <-ping.C:
+				if !send(": ping\n\n") {
+					return
+				}
+			case <-s.Done():
+				// the browser reconnects after the retry time when the server is back
+				return
+			case <-request.Context().Done():
+				return
+			}
+		}
+	}
+}
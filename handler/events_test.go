@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"bufio"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoteEvents(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	q := survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(VoteEvents(s))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/voteEvents/?id=" + string(sid))
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	r := bufio.NewReader(resp.Body)
+	readEvent := func() string {
+		var lines []string
+		for {
+			line, err := r.ReadString('\n')
+			assert.NoError(t, err)
+			line = strings.TrimSuffix(line, "\n")
+			if line == "" {
+				return strings.Join(lines, "\n")
+			}
+			lines = append(lines, line)
+		}
+	}
+	assert.Equal(t, "retry: 5000", readEvent())
+
+	_, err = s.New("creator", sid, q)
+	assert.NoError(t, err)
+	assert.Equal(t, "event: question\ndata: {\"Number\":2,\"Revision\":0}", readEvent())
+
+	s.Clear(sid, "creator")
+	assert.Equal(t, "event: closed\ndata: {}", readEvent())
+
+	resp, err = http.Get(server.URL + "/voteEvents/?id=unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp.Body.Close()
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flashSurvey/survey"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ExportOption is the result of a single option in an export
+type ExportOption struct {
+	Title   string
+	Votes   int
+	Percent float64
+}
+
+// ExportResult is the result of a question as it is exported
+type ExportResult struct {
+	Title    string
+	Number   int
+	Started  time.Time
+	Exported time.Time
+	// Ended is the time a completed question was replaced by the next one
+	Ended *time.Time `json:",omitempty"`
+	Votes int
+	// Ranked is set if the votes are the Borda counts of a ranked question
+	Ranked   bool `json:",omitempty"`
+	Options  []ExportOption
+	WriteIns []survey.WriteIn `json:",omitempty"`
+	Rating   *survey.Rating   `json:",omitempty"`
+	// Annotation is the note of the creator on the result
+	Annotation string `json:",omitempty"`
+}
+
+func exportResult(r survey.Result, now time.Time) (ExportResult, error) {
+	if r.Version < 0 {
+		return ExportResult{}, errNotRunning
+	}
+	if r.Encrypted {
+		return ExportResult{}, errExportEncrypted
+	}
+	e := ExportResult{
+		Title:      r.Title,
+		Number:     r.Number,
+		Started:    r.Started,
+		Exported:   now,
+		Votes:      r.Votes,
+		Ranked:     r.Ranked,
+		WriteIns:   r.WriteIns,
+		Rating:     r.Rating,
+		Annotation: r.Annotation,
+	}
+	for _, o := range r.Result {
+		if o.VoteCount() < 0 {
+			return ExportResult{}, errResultHidden
+		}
+		e.Options = append(e.Options, ExportOption{Title: o.Title, Votes: o.VoteCount(), Percent: o.PercentValue()})
+	}
+	return e, nil
+}
+
+// Export allows the creator to download the result of the running
+// survey. The query parameter "format" selects "csv", which is the
+// default, or "json". If the parameter "history" is set, the completed
+// questions are included and the JSON contains a list of results.
+func Export(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+
+		format := request.URL.Query().Get("format")
+		if format != "" && format != "csv" && format != "json" {
+			http.Error(writer, "unknown format "+format, http.StatusBadRequest)
+			return
+		}
+
+		result := s.GetResult(userId, surveyId)
+		e, err := exportResult(result, time.Now())
+		name := "umfrage-" + strconv.Itoa(e.Number)
+		var data any = e
+		results := []ExportResult{e}
+		if request.URL.Query().Get("history") == "true" {
+			results, err = exportHistory(s, userId, surveyId)
+			if err == nil {
+				// the running question is only included if it is uncovered
+				if current, cerr := exportResult(s.GetResult(userId, surveyId), time.Now()); cerr == nil {
+					results = append(results, current)
+				}
+			}
+			name = "verlauf"
+			data = results
+		}
+		if err != nil {
+			apiError(writer, request, err, errorStatus(err, http.StatusConflict))
+			return
+		}
+
+		if format == "json" {
+			writer.Header().Set("Content-Type", "application/json")
+			writer.Header().Set("Content-Disposition", `attachment; filename="`+name+`.json"`)
+			enc := json.NewEncoder(writer)
+			enc.SetIndent("", "  ")
+			err = enc.Encode(data)
+		} else {
+			writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+			writer.Header().Set("Content-Disposition", `attachment; filename="`+name+`.csv"`)
+			err = writeCSV(writer, results, resultLocale(result, request))
+		}
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// writeCSV writes a row for each option, the free text answers are written
+// as options without a percentage. If the locale uses a decimal comma, the
+// fields are separated by semicolons as expected by spreadsheets.
+func writeCSV(writer io.Writer, results []ExportResult, locale string) error {
+	w := csv.NewWriter(writer)
+	if survey.DecimalComma(locale) {
+		w.Comma = ';'
+	}
+	err := w.Write([]string{"Frage", "Nummer", "Gestartet", "Exportiert", "Teilnehmer", "Option", "Stimmen", "Prozent", "Anmerkung"})
+	if err != nil {
+		return err
+	}
+	for _, e := range results {
+		question := []string{e.Title, strconv.Itoa(e.Number), e.Started.Format(time.RFC3339), e.Exported.Format(time.RFC3339), strconv.Itoa(e.Votes)}
+		for _, o := range e.Options {
+			err = w.Write(append(question, o.Title, strconv.Itoa(o.Votes), survey.FormatNumber(o.Percent, 1, locale), e.Annotation))
+			if err != nil {
+				return err
+			}
+		}
+		for _, wi := range e.WriteIns {
+			err = w.Write(append(question, wi.Text, strconv.Itoa(wi.Count), "", e.Annotation))
+			if err != nil {
+				return err
+			}
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
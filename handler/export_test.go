@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExport(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+	assert.NoError(t, s.Vote(sid, "b", []int{0}, 1))
+	assert.NoError(t, s.Vote(sid, "c", []int{1}, 1))
+
+	export := func(format string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/export/?format="+format, nil)
+		r.AddCookie(&http.Cookie{Name: "sid", Value: string(sid)})
+		r.Header.Set("Accept-Language", "en-US,en;q=0.9")
+		r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+		w := httptest.NewRecorder()
+		Export(s)(w, r)
+		return w
+	}
+
+	// the result is hidden
+	w := export("csv")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var apiErr APIError
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&apiErr))
+	assert.Equal(t, "resultHidden", apiErr.Code)
+	assert.Equal(t, "The result is still hidden!", apiErr.Error)
+	assert.NoError(t, s.Uncover("creator", sid))
+
+	w = export("csv")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	rows, err := csv.NewReader(w.Body).ReadAll()
+	assert.NoError(t, err)
+	assert.Len(t, rows, 3)
+	assert.Equal(t, []string{"Test", "1"}, rows[1][:2])
+	assert.Equal(t, []string{"3", "A", "2", "66.7", ""}, rows[1][4:])
+	assert.Equal(t, []string{"3", "B", "1", "33.3", ""}, rows[2][4:])
+
+	// a german browser gets a decimal comma and semicolons
+	r := httptest.NewRequest(http.MethodGet, "/export/", nil)
+	r.AddCookie(&http.Cookie{Name: "sid", Value: string(sid)})
+	r.Header.Set("Accept-Language", "de-DE")
+	r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+	w = httptest.NewRecorder()
+	Export(s)(w, r)
+	reader := csv.NewReader(w.Body)
+	reader.Comma = ';'
+	rows, err = reader.ReadAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"3", "A", "2", "66,7", ""}, rows[1][4:])
+
+	w = export("json")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var e ExportResult
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &e))
+	assert.Equal(t, "Test", e.Title)
+	assert.Equal(t, 3, e.Votes)
+	assert.Equal(t, "A", e.Options[0].Title)
+	assert.Equal(t, 2, e.Options[0].Votes)
+	assert.False(t, e.Started.IsZero())
+
+	assert.Equal(t, http.StatusBadRequest, export("xml").Code)
+}
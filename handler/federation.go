@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"flashSurvey/federation"
+	"flashSurvey/survey"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// Federation serves the requests of the relays and the read replicas. The
+// votes forwarded by a relay are counted with voter ids which are unique
+// for the relay. The replicas poll the changes of the surveys they mirror.
+func Federation(s *survey.Surveys, peers federation.Peers) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		relay, body, err := peers.Verify(request)
+		if err != nil {
+			log.Println("federation:", err)
+			http.Error(writer, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		query := request.URL.Query()
+		surveyId := survey.SurveyId(query.Get("id"))
+		var resp any
+		switch request.URL.Path {
+		case "/federation/question":
+			resp = s.GetQuestion(surveyId)
+		case "/federation/changes":
+			v, _ := strconv.Atoi(query.Get("v"))
+			resp, err = s.Replicate(request.Context(), surveyId, v, federation.PollTimeout)
+			if err != nil {
+				http.Error(writer, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+		case "/federation/voted":
+			voterId := survey.UserId(federation.VoterId(relay, query.Get("v")))
+			resp = federation.VoteResponse{Voted: s.HasVoted(surveyId, voterId)}
+		case "/federation/vote":
+			var v federation.VoteRequest
+			err = json.Unmarshal(body, &v)
+			if err != nil {
+				http.Error(writer, "invalid vote", http.StatusBadRequest)
+				return
+			}
+			voterId := survey.UserId(federation.VoterId(relay, v.VoterId))
+			var r federation.VoteResponse
+			if v.Ballot != "" {
+				err = s.VoteEncrypted(survey.SurveyId(v.SurveyId), voterId, v.Ballot, v.Number)
+			} else if v.WriteIn != "" {
+				err = s.VoteWriteIn(survey.SurveyId(v.SurveyId), voterId, v.Options, v.WriteIn, v.Number)
+			} else {
+				err = s.Vote(survey.SurveyId(v.SurveyId), voterId, v.Options, v.Number)
+			}
+			if err != nil {
+				r.Error = err.Error()
+				var se *survey.Error
+				if errors.As(err, &se) {
+					r.Code = se.Code
+					r.Args = se.Args
+				}
+			}
+			resp = r
+		default:
+			http.NotFound(writer, request)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(writer).Encode(resp)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"flashSurvey/federation"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFederation(t *testing.T) {
+	origin := survey.New("localhost", 30, false, true)
+	sid, err := origin.New("creator", "", survey.SurveyQuestion{Title: "Q", Options: []string{"a", "b"}})
+	assert.NoError(t, err)
+
+	peers, err := federation.ParsePeers("b=secret")
+	assert.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/federation/", Federation(origin, peers))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	local := survey.New("localhost", 30, false, true)
+	localId, err := local.New("creator", "", survey.SurveyQuestion{Title: "L", Options: []string{"x", "y"}})
+	assert.NoError(t, err)
+	relay, err := federation.NewRelay(local, server.URL, "b", "secret")
+	assert.NoError(t, err)
+
+	q := relay.GetQuestion(sid)
+	assert.EqualValues(t, "Q", q.Question.Title)
+	assert.EqualValues(t, 1, q.Number)
+	assert.EqualValues(t, "L", relay.GetQuestion(localId).Question.Title)
+
+	assert.False(t, relay.HasVoted(sid, "voter"))
+	assert.NoError(t, relay.Vote(sid, "voter", []int{1}, 1))
+	assert.True(t, relay.HasVoted(sid, "voter"))
+	assert.EqualError(t, relay.Vote(sid, "voter", []int{0}, 1), "Sie haben bereits abgestimmt!")
+	assert.NoError(t, relay.Vote(localId, "voter", []int{0}, 1))
+
+	// a local voter with the same id is a different voter
+	assert.NoError(t, origin.Vote(sid, "voter", []int{0}, 1))
+
+	assert.NoError(t, origin.Uncover("creator", sid))
+	r := origin.GetResult("creator", sid)
+	assert.EqualValues(t, 2, r.Votes)
+	assert.EqualValues(t, 1, r.Result[1].VoteCount())
+
+	wrong, err := federation.NewRelay(local, server.URL, "b", "wrong")
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Die Umfrage ist nicht erreichbar!", wrong.GetQuestion(sid).Question.Title)
+}
+
+func TestReplica(t *testing.T) {
+	primary := survey.New("localhost", 30, false, true)
+	sid, err := primary.New("creator", "", survey.SurveyQuestion{Title: "Q", Options: []string{"a", "b"}})
+	assert.NoError(t, err)
+	token, err := primary.ViewerToken("creator", sid)
+	assert.NoError(t, err)
+
+	peers, err := federation.ParsePeers("r=secret")
+	assert.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/federation/", Federation(primary, peers))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	local := survey.New("localhost", 30, false, true)
+	defer local.Close()
+	replica, err := federation.NewReplica(local, server.URL, "r", "secret")
+	assert.NoError(t, err)
+
+	q := replica.GetQuestion(sid)
+	assert.EqualValues(t, "Q", q.Question.Title)
+	assert.True(t, local.IsMirror(sid))
+	creator, ok := local.Viewer(sid, token)
+	assert.True(t, ok)
+	assert.EqualValues(t, "creator", creator)
+	_, ok = local.Viewer(sid, "wrong")
+	assert.False(t, ok)
+
+	// the votes are forwarded to the primary
+	assert.NoError(t, replica.Vote(sid, "voter", []int{1}, 1))
+	assert.True(t, replica.HasVoted(sid, "voter"))
+	assert.EqualValues(t, 1, primary.CollectedVotes("creator", sid))
+
+	assert.NoError(t, primary.Vote(sid, "a", []int{1}, 1))
+	assert.NoError(t, primary.Vote(sid, "b", []int{0}, 1))
+	assert.NoError(t, primary.Uncover("creator", sid))
+	assert.Eventually(t, func() bool {
+		return local.GetResult("creator", sid).Uncovered()
+	}, 5*time.Second, 10*time.Millisecond)
+	r := local.GetResult("creator", sid)
+	assert.EqualValues(t, 3, r.Votes)
+	assert.EqualValues(t, 2, r.Result[1].VoteCount())
+
+	// the copy is removed if the survey is deleted on the primary
+	primary.Clear(sid, "creator")
+	assert.Eventually(t, func() bool {
+		return !local.IsMirror(sid)
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -1,17 +1,28 @@
 package handler
 
 import (
-	"bytes"
 	"context"
 	"embed"
 	"encoding/json"
 	"errors"
+	"flashSurvey/account"
+	"flashSurvey/feed"
+	"flashSurvey/i18n"
+	"flashSurvey/mailer"
+	"flashSurvey/meeting"
+	"flashSurvey/randid"
+	"flashSurvey/saml"
 	"flashSurvey/survey"
+	"fmt"
 	"html/template"
+	"io"
 	"log"
 	"net/http"
+	"net/mail"
+	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -21,20 +32,30 @@ var templateFS embed.FS
 //go:embed static/*
 var staticFS embed.FS
 
+//go:embed legal/*
+var legalFS embed.FS
+
 func Static() http.Handler {
 	return http.FileServer(http.FS(staticFS))
 }
 
 var (
 	Templates = template.Must(template.New("").Funcs(template.FuncMap{
-		"inc": func(i int) int { return i + 1 },
+		"inc":    func(i int) int { return i + 1 },
+		"banner": getBanner,
+		"dateTime": func(t time.Time) string {
+			return t.Format("02.01.2006 15:04")
+		},
+		"time": func(t time.Time) string {
+			return t.Format("15:04")
+		},
 		"getIfAvail": func(o []string, i int) string {
 			if i < len(o) {
 				return o[i]
 			}
 			return ""
 		},
-	}).ParseFS(templateFS, "templates/*.html"))
+	}).Funcs(languageFuncs(i18n.Default)).ParseFS(templateFS, "templates/*.html"))
 	createTemp       = Templates.Lookup("create.html")
 	moveTemp         = Templates.Lookup("move.html")
 	resultTemp       = Templates.Lookup("result.html")
@@ -43,30 +64,106 @@ var (
 	voteNotifyTemp   = Templates.Lookup("voteNotify.html")
 	voteQuestionTemp = Templates.Lookup("voteQuestion.html")
 	finishedTemp     = Templates.Lookup("finished.html")
+	calendarTemp     = Templates.Lookup("calendar.html")
+	registerTemp     = Templates.Lookup("register.html")
+	loginTemp        = Templates.Lookup("login.html")
+	passkeyTemp      = Templates.Lookup("passkey.html")
+	sessionsTemp     = Templates.Lookup("sessions.html")
+	rolesTemp        = Templates.Lookup("roles.html")
+	forbiddenTemp    = Templates.Lookup("forbidden.html")
+	handoverTemp     = Templates.Lookup("handover.html")
+	resubmitTemp     = Templates.Lookup("resubmit.html")
+	legalTemp        = Templates.Lookup("legal.html")
+	bannerTemp       = Templates.Lookup("bannerEdit.html")
+	meetingTemp      = Templates.Lookup("meeting.html")
+	splitTemp        = Templates.Lookup("split.html")
+	browseTemp       = Templates.Lookup("browse.html")
+	backupTemp       = Templates.Lookup("backup.html")
+	dashboardTemp    = Templates.Lookup("dashboard.html")
+	carouselTemp     = Templates.Lookup("carousel.html")
+	myTemp           = Templates.Lookup("my.html")
+	resetTemp        = Templates.Lookup("reset.html")
+	historyTemp      = Templates.Lookup("history.html")
+	reportTemp       = Templates.Lookup("report.html")
+	codesTemp        = Templates.Lookup("codes.html")
+	adminTemp        = Templates.Lookup("admin.html")
 )
 
-func EnsureUserId(handler http.HandlerFunc) http.HandlerFunc {
-	return func(writer http.ResponseWriter, request *http.Request) {
-		userId := getId("uid", writer, request)
-		if userId == "" {
-			userId = survey.RandomString()
-			http.SetCookie(writer, &http.Cookie{
-				Name:  "uid",
-				Value: userId,
-				Path:  "/",
-			})
+// EnsureUserId returns a middleware which makes sure every request has a
+// user id. If the user id is bound to an account protected by a passkey,
+// the passkey session cookie is required as well, otherwise a new user id
+// is issued. Such a user id can not be passed on by the QR code, the other
+// device has to log in with the passkey.
+func EnsureUserId(a *account.Accounts) func(http.HandlerFunc) http.HandlerFunc {
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			if tuid := request.URL.Query().Get("tuid"); tuid != "" && !a.Verify(tuid, getCookie(request, passkeySessionCookie)) {
+				log.Println("handover of a user id protected by a passkey rejected")
+				writer.WriteHeader(http.StatusForbidden)
+				err := localTemplate(request, handoverTemp).Execute(writer, nil)
+				if err != nil {
+					renderError(err)
+				}
+				return
+			}
+			userId := getId("uid", writer, request)
+			if userId != "" && !a.Verify(userId, getCookie(request, passkeySessionCookie)) {
+				log.Println("user id used without passkey session, issuing new user id")
+				userId = ""
+			}
+			if userId == "" {
+				userId = randid.String()
+				http.SetCookie(writer, &http.Cookie{
+					Name:  "uid",
+					Value: userId,
+					Path:  "/",
+				})
 
+			}
+			a.Touch(userId, request.UserAgent())
+			request = request.WithContext(context.WithValue(request.Context(), "id", userId))
+			handler(writer, request)
 		}
-		request = request.WithContext(context.WithValue(request.Context(), "id", userId))
-		handler(writer, request)
 	}
 }
 
+// RequireRole returns a middleware which only passes requests of users
+// whose role satisfies the given condition. It must be used inside of
+// EnsureUserId.
+func RequireRole(a *account.Accounts, allowed func(account.Role) bool) func(http.HandlerFunc) http.HandlerFunc {
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			role := a.RoleOf(string(GetUserId(request)))
+			if !allowed(role) {
+				writer.WriteHeader(http.StatusForbidden)
+				err := localTemplate(request, forbiddenTemp).Execute(writer, role)
+				if err != nil {
+					renderError(err)
+				}
+				return
+			}
+			handler(writer, request)
+		}
+	}
+}
+
+func getCookie(request *http.Request, name string) string {
+	c, err := request.Cookie(name)
+	if err != nil {
+		return ""
+	}
+	return c.Value
+}
+
 func GetUserId(request *http.Request) survey.UserId {
 	return survey.UserId(request.Context().Value("id").(string))
 }
 
 func GetSurveyId(writer http.ResponseWriter, request *http.Request) survey.SurveyId {
+	if id, ok := request.Context().Value("sid").(survey.SurveyId); ok {
+		// the survey is given by a viewer token or was dropped
+		return id
+	}
 	return survey.SurveyId(getId("sid", writer, request))
 }
 
@@ -75,7 +172,7 @@ func getId(key string, writer http.ResponseWriter, request *http.Request) string
 	query := request.URL.Query()
 	if query.Has("t" + key) {
 		id = query.Get("t" + key)
-		if len(id) == survey.IdLength {
+		if randid.Valid(id) {
 			c := &http.Cookie{
 				Name:  key,
 				Value: id,
@@ -109,10 +206,96 @@ func Clear(s *survey.Surveys) http.HandlerFunc {
 	}
 }
 
-func Finished(writer http.ResponseWriter, _ *http.Request) {
-	err := finishedTemp.Execute(writer, nil)
+// Extend restarts the timeout of the running survey
+func Extend(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+		if err := s.Extend(userId, surveyId); err != nil {
+			httpError(writer, request, err, errorStatus(err, http.StatusNotFound))
+			return
+		}
+		http.Redirect(writer, request, "/", http.StatusSeeOther)
+	}
+}
+
+// Heartbeat is called periodically by the result page to defer the timeout of the survey
+func Heartbeat(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+		if err := s.Heartbeat(userId, surveyId); err != nil {
+			httpError(writer, request, err, errorStatus(err, http.StatusNotFound))
+			return
+		}
+		writer.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func Finished(writer http.ResponseWriter, request *http.Request) {
+	err := localTemplate(request, finishedTemp).Execute(writer, nil)
 	if err != nil {
-		log.Println(err)
+		renderError(err)
+	}
+}
+
+type LegalData struct {
+	Title   string
+	Content template.HTML
+}
+
+// Legal returns a page which shows the given Markdown file. This is used
+// for the imprint and the privacy policy. If file is empty, the embedded
+// default legal/<name>.md is shown.
+func Legal(title, name, file string) (*LegalPage, error) {
+	l := &LegalPage{title: title, name: name, file: file}
+	return l, l.Reload()
+}
+
+// LegalPage shows a legal text like the imprint
+type LegalPage struct {
+	title string
+	name  string
+	file  string
+
+	mutex sync.Mutex
+	data  LegalData
+}
+
+// Reload reads the text again, so it can be changed without a restart.
+// If the file can not be read, the former text is kept.
+func (l *LegalPage) Reload() error {
+	var md []byte
+	var err error
+	if l.file == "" {
+		md, err = legalFS.ReadFile("legal/" + l.name + ".md")
+	} else {
+		md, err = os.ReadFile(l.file)
+	}
+	if err != nil {
+		return fmt.Errorf("could not read %s: %w", l.name, err)
+	}
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.data = LegalData{Title: l.title, Content: markdown(string(md))}
+	return nil
+}
+
+func (l *LegalPage) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	l.mutex.Lock()
+	d := l.data
+	l.mutex.Unlock()
+	err := localTemplate(request, legalTemp).Execute(writer, d)
+	if err != nil {
+		renderError(err)
 	}
 }
 
@@ -121,63 +304,293 @@ type CreateData struct {
 	Question survey.SurveyQuestion
 	Hidden   bool
 	Running  bool
-	Error    error
+	// Locked is set if the running survey must not be replaced
+	Locked bool
+	// Confirm is the number of votes which are deleted if the survey is
+	// started again. If set, the creator has to confirm the restart.
+	Confirm int
+	// IdempotencyKey identifies the form, so it is not executed twice if it is sent again
+	IdempotencyKey string
+	// OptionLimit is the maximum number of options, zero means unlimited
+	OptionLimit int
+	// Draft is set if the form shows the draft saved while it was edited
+	Draft bool
+	// Expires is set if the running survey is deleted soon because of the timeout
+	Expires time.Time
+	// Hosts contains the hosts which can be selected for the vote links
+	Hosts []string
+	// MinTimeout and MaxTimeout are the bounds of the timeout in minutes,
+	// both are zero if the timeout can not be chosen
+	MinTimeout     int
+	MaxTimeout     int
+	DefaultTimeout int
+	Account        string
+	Role           account.Role
+	// Announce is set if there is an integration the survey can be announced by
+	Announce bool
+	// VoteKey is the key of the vote links in the strict mode
+	VoteKey string
+	// ThankYou is shown to the voters after they have voted
+	ThankYou survey.ThankYou
+	// Series is the position of the running question in the announced series
+	Series survey.Series
+	// Advance is the automatic start of the prepared questions of the series
+	Advance survey.Advance
+	Error   error
+}
+
+func (d CreateData) Languages() []i18n.Language {
+	return i18n.Languages
+}
+
+// adminPagesSeparate is set if the admin pages are served by the management
+// listener, then they are not linked from the public pages
+var adminPagesSeparate bool
+
+// SeparateAdminPages removes the links to the admin pages from the public
+// pages, because they are served by the management listener
+func SeparateAdminPages() {
+	adminPagesSeparate = true
+}
+
+// AdminLinks returns true if the links to the admin pages are shown
+func (d CreateData) AdminLinks() bool {
+	return d.Role.CanAdminister() && !adminPagesSeparate
 }
 
 func (d CreateData) MaxOptions() int {
 	n := len(d.Question.Options) + 2
 	if n < 5 {
-		return 5
+		n = 5
+	}
+	if d.OptionLimit > 0 && n > d.OptionLimit {
+		return d.OptionLimit
 	}
 	return n
 }
 
+// RatingScales returns the number of points of the selectable rating scales
+func (d CreateData) RatingScales() []int {
+	return []int{3, 4, 5, 6, 7, 10}
+}
+
+// MoreOptions returns true if the form can be extended by more options
+func (d CreateData) MoreOptions() bool {
+	return d.OptionLimit == 0 || d.MaxOptions() < d.OptionLimit
+}
+
+// Color returns the color of the option with the given index
+func (d CreateData) Color(i int) string {
+	if i < len(d.Question.Colors) && d.Question.Colors[i] != "" {
+		return d.Question.Colors[i]
+	}
+	return survey.DefaultColor(i)
+}
+
+// FieldErrors returns true if the error belongs to single input fields
+func (d CreateData) FieldErrors() bool {
+	var v survey.ValidationError
+	return errors.As(d.Error, &v)
+}
+
+// FieldError returns the error of the input field with the given name or
+// nil, the template shows it in the language of the page
+func (d CreateData) FieldError(name string) error {
+	var v survey.ValidationError
+	if errors.As(d.Error, &v) {
+		for _, e := range v {
+			if e.Field == name {
+				return survey.ValidationError{e}
+			}
+		}
+	}
+	return nil
+}
+
+// OptionError returns the error of the option with the given index
+func (d CreateData) OptionError(i int) error {
+	return d.FieldError("option" + strconv.Itoa(i))
+}
+
 func (d CreateData) URL() string {
 	return "?q=" + template.URLQueryEscaper(d.Question.String())
 }
 
-func Create(s *survey.Surveys) http.HandlerFunc {
+// questionFromForm returns the question entered in the create form
+func questionFromForm(request *http.Request, announce bool) (survey.SurveyQuestion, error) {
+	var o, colors, groups []string
+	tooMany := false
+	i := 0
+	for {
+		name := "option" + strconv.Itoa(i)
+		if !request.Form.Has(name) {
+			break
+		}
+		if i == maxOptionFields {
+			tooMany = true
+			break
+		}
+		op := strings.TrimSpace(request.FormValue(name))
+		if op != "" {
+			o = append(o, op)
+			colors = append(colors, request.FormValue("color"+strconv.Itoa(i)))
+			groups = append(groups, request.FormValue("group"+strconv.Itoa(i)))
+		}
+		i++
+	}
+	q := survey.SurveyQuestion{
+		Title:           request.FormValue("title"),
+		Options:         o,
+		Colors:          colors,
+		Groups:          groups,
+		Multiple:        request.FormValue("multiple") == "true",
+		Announce:        announce && request.FormValue("announce") == "true",
+		Public:          request.FormValue("public") == "true",
+		WriteIn:         request.FormValue("writeIn") == "true",
+		MergeDuplicates: request.FormValue("mergeDuplicates") == "true",
+		Acclamation:     request.FormValue("acclamation") == "true",
+		Ranked:          request.FormValue("ranked") == "true",
+		Host:            request.FormValue("host"),
+		Display: survey.Display{
+			SortByVotes:  request.FormValue("sortByVotes") == "true",
+			HidePercent:  request.FormValue("hidePercent") == "true",
+			HideCounts:   request.FormValue("hideCounts") == "true",
+			Chart:        request.FormValue("chart"),
+			ByGroup:      request.FormValue("byGroup") == "true",
+			PercentBase:  request.FormValue("percentBase"),
+			Significance: request.FormValue("significance") == "true",
+		},
+	}
+	if lang := request.FormValue("language"); i18n.Supported(lang) {
+		q.Language = lang
+	}
+	if rating, err := strconv.Atoi(request.FormValue("rating")); err == nil {
+		q.Rating = rating
+	}
+	if timeout, err := strconv.Atoi(request.FormValue("timeout")); err == nil {
+		q.Timeout = timeout
+	}
+	if countdown, err := strconv.Atoi(request.FormValue("countdown")); err == nil {
+		q.Countdown = countdown
+	}
+	q.PublicKey = request.FormValue("publicKey")
+	if tooMany {
+		return q, errTooManyOptions
+	}
+	if list := request.FormValue("optionList"); strings.TrimSpace(list) != "" {
+		if q.Encrypted() {
+			// the list is not encrypted by the browser
+			return q, errEncryptedList
+		}
+		// each line of the list is an option, it replaces the single option fields
+		q.Options = survey.ParseOptionList(list)
+		if len(q.Options) > maxOptionFields {
+			return q, errTooManyOptions
+		}
+		q.Colors = nil
+		q.Groups = nil
+	}
+	return q, nil
+}
+
+func Create(s *survey.Surveys, a *account.Accounts, announce bool) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		userId := GetUserId(request)
 
 		d := CreateData{
-			SurveyID: GetSurveyId(writer, request),
+			SurveyID:    GetSurveyId(writer, request),
+			Announce:    announce,
+			OptionLimit: s.MaxOptions(),
+			Hosts:       s.Hosts(),
 		}
+		d.MinTimeout, d.MaxTimeout = s.TimeoutBounds()
+		d.DefaultTimeout = s.DefaultTimeout()
 
 		if request.Method == http.MethodPost {
-			err := request.ParseForm()
+			err := parseForm(writer, request, maxFormSize)
 			if err != nil {
-				http.Error(writer, "could not parse form: "+err.Error(), http.StatusBadRequest)
+				formError(writer, err)
 				return
 			}
-			var o []string
-			i := 0
-			for {
-				name := "option" + strconv.Itoa(i)
-				if !request.Form.Has(name) {
-					break
-				}
-				op := strings.TrimSpace(request.FormValue(name))
-				if op != "" {
-					o = append(o, op)
+			d.Question, d.Error = questionFromForm(request, announce)
+			if d.Error == nil && request.Form.Has("preview") {
+				// shows the vote page exactly as the voters will see it
+				vd := newVoteData(survey.Question{Question: d.Question}, "", voteLanguage(d.Question.Language, request))
+				vd.Preview = true
+				vd.Remaining = d.Question.Countdown
+				err := voteTemp.Execute(writer, vd)
+				if err != nil {
+					renderError(err)
 				}
-				i++
-			}
-			d.Question = survey.SurveyQuestion{
-				Title:    request.FormValue("title"),
-				Options:  o,
-				Multiple: request.FormValue("multiple") == "true",
+				return
 			}
-			if !request.Form.Has("more") {
+			if d.Error == nil && !request.Form.Has("more") {
 				if request.Form.Has("create") {
-					d.SurveyID, d.Error = s.New(userId, d.SurveyID, d.Question)
-					if d.Error == nil {
-						http.SetCookie(writer, &http.Cookie{
-							Name:  "sid",
-							Value: string(d.SurveyID),
-							Path:  "/",
+					if !request.Form.Has("confirm") {
+						// starting a new question deletes the votes of the running one,
+						// so the form is shown again to ask for confirmation
+						d.Confirm = s.CollectedVotes(userId, d.SurveyID)
+					}
+					if d.Confirm == 0 {
+						// a repeated request must not create a second survey or delete the votes
+						var id string
+						id, d.Error = idempotency.do("create/"+string(userId), idempotencyKey(request), func() (string, error) {
+							sid, err := s.New(userId, d.SurveyID, d.Question)
+							return string(sid), err
 						})
+						d.SurveyID = survey.SurveyId(id)
+						if d.Error == nil {
+							s.DeleteDraft(userId)
+							http.SetCookie(writer, &http.Cookie{
+								Name:  "sid",
+								Value: string(d.SurveyID),
+								Path:  "/",
+							})
+						}
+					}
+				} else if request.Form.Has("queue") {
+					d.Error = s.QueueQuestion(userId, d.SurveyID, d.Question)
+				} else if request.Form.Has("clearQueue") {
+					d.Error = s.ClearQueue(userId, d.SurveyID)
+				} else if request.Form.Has("lock") {
+					d.Error = s.SetLocked(userId, d.SurveyID, request.FormValue("lock") == "true")
+				} else if request.Form.Has("thankYou") {
+					d.Error = s.SetThankYou(userId, d.SurveyID, survey.ThankYou{
+						Message:  request.FormValue("thankYouMessage"),
+						Redirect: request.FormValue("thankYouRedirect"),
+						Context:  request.FormValue("thankYouContext") == "true",
+					})
+				} else if request.Form.Has("series") {
+					var series survey.Series
+					if total := request.FormValue("seriesTotal"); total != "" {
+						series.Total, d.Error = strconv.Atoi(total)
+						if d.Error == nil {
+							series.Position, d.Error = strconv.Atoi(request.FormValue("seriesPosition"))
+						}
+						if d.Error != nil {
+							d.Error = errInvalidSeries
+						}
 					}
+					if d.Error == nil {
+						d.Error = s.SetSeries(userId, d.SurveyID, series)
+					}
+					if d.Error == nil {
+						advance := 0
+						if a := request.FormValue("advance"); a != "" {
+							advance, d.Error = strconv.Atoi(a)
+						}
+						if d.Error == nil {
+							d.Error = s.SetAdvance(userId, d.SurveyID, advance)
+						} else {
+							d.Error = survey.ErrInvalidAdvance
+						}
+					}
+				} else if request.Form.Has("hide") {
+					d.Error = s.Hide(userId, d.SurveyID)
+				} else if request.Form.Has("reset") {
+					d.Error = s.ResetVotes(userId, d.SurveyID)
+				} else if request.Form.Has("correct") {
+					d.Error = s.Correct(userId, d.SurveyID, d.Question.Title, d.Question.Options)
 				} else {
 					d.Error = s.Uncover(userId, d.SurveyID)
 				}
@@ -191,7 +604,10 @@ func Create(s *survey.Surveys) http.HandlerFunc {
 					log.Println("Error parsing survey definition from URL:", err)
 				}
 			}
-			if !d.Question.Valid() {
+			if q == "" {
+				d.Question, d.Draft = s.Draft(userId)
+			}
+			if !d.Draft && !d.Question.Valid() {
 				if running, ok := s.GetRunningSurvey(userId, d.SurveyID); ok {
 					d.Question = running
 				} else {
@@ -204,10 +620,19 @@ func Create(s *survey.Surveys) http.HandlerFunc {
 		}
 
 		d.Hidden, d.Running = s.IsHiddenRunning(userId, d.SurveyID)
+		d.Locked = s.IsLocked(userId, d.SurveyID)
+		d.VoteKey = s.VoteKey(userId, d.SurveyID)
+		d.ThankYou = s.GetThankYou(userId, d.SurveyID)
+		d.Series = s.GetSeries(userId, d.SurveyID)
+		d.Advance = s.GetAdvance(userId, d.SurveyID)
+		d.Expires = s.Expires(userId, d.SurveyID)
+		d.IdempotencyKey = randid.String()
+		d.Account, _ = a.AccountOf(string(userId))
+		d.Role = a.RoleOf(string(userId))
 
-		err := createTemp.Execute(writer, d)
+		err := localTemplate(request, createTemp).Execute(writer, d)
 		if err != nil {
-			log.Println(err)
+			renderError(err)
 			return
 		}
 	}
@@ -224,45 +649,690 @@ func Move(s *survey.Surveys) http.HandlerFunc {
 			return
 		}
 
-		err = moveTemp.Execute(writer, data)
+		err = localTemplate(request, moveTemp).Execute(writer, data)
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
+
+type CalendarData struct {
+	Start    string
+	Duration int
+	Error    error
+}
+
+func Calendar(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+
+		d := CalendarData{
+			Start:    time.Now().Add(24 * time.Hour).Format(dateTimeLocal),
+			Duration: 90,
+		}
+
+		if request.Method == http.MethodPost {
+			err := parseForm(writer, request, maxFormSize)
+			if err != nil {
+				formError(writer, err)
+				return
+			}
+			d.Start = request.FormValue("start")
+			d.Duration, err = strconv.Atoi(request.FormValue("duration"))
+			if err != nil || d.Duration <= 0 {
+				d.Duration = 90
+			}
+
+			start, err := time.ParseInLocation(dateTimeLocal, d.Start, time.Local)
+			if err != nil {
+				d.Error = errInvalidSchedule
+			} else {
+				d.Error = s.Schedule(userId, surveyId, start)
+			}
+			if d.Error == nil {
+				var ics []byte
+				ics, d.Error = s.CalendarInvite(userId, surveyId, start, time.Duration(d.Duration)*time.Minute)
+				if d.Error == nil {
+					writer.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+					writer.Header().Set("Content-Disposition", "attachment; filename=\"umfrage.ics\"")
+					_, err = writer.Write(ics)
+					if err != nil {
+						log.Println(err)
+					}
+					return
+				}
+			}
+		}
+
+		err := localTemplate(request, calendarTemp).Execute(writer, d)
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
+
+// dateTimeLocal is the format used by the datetime-local input element
+const dateTimeLocal = "2006-01-02T15:04"
+
+type LoginData struct {
+	MailAvailable bool
+	SAMLAvailable bool
+	EMail         string
+	Sent          bool
+	Error         error
+}
+
+// Login implements the magic link login of creators. The creator enters
+// an e-mail address and receives a link which binds the browser to the
+// account. All browsers bound to the same account can control the
+// surveys created by any of them.
+func Login(s *survey.Surveys, a *account.Accounts, m *mailer.Mailer, sp *saml.ServiceProvider, host string) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+
+		d := LoginData{MailAvailable: m.Available(), SAMLAvailable: sp.Available()}
+
+		if token := request.URL.Query().Get("m"); token != "" {
+			d.EMail, d.Error = a.ConfirmMagicLink(token, string(userId))
+			if d.Error == nil {
+				log.Println("creator logged in")
+				restoreSurvey(s, userId, writer, request)
+				http.Redirect(writer, request, "/", http.StatusSeeOther)
+				return
+			}
+		} else if request.Method == http.MethodPost && d.MailAvailable {
+			err := parseForm(writer, request, maxFormSize)
+			if err != nil {
+				formError(writer, err)
+				return
+			}
+			addr, err := mail.ParseAddress(request.FormValue("email"))
+			if err != nil {
+				d.Error = errInvalidEMail
+			} else {
+				d.EMail = addr.Address
+				var token string
+				token, d.Error = a.RequestMagicLink(d.EMail)
+				if d.Error == nil {
+					d.Error = m.Send(d.EMail, "Anmeldung bei flashSurvey",
+						"Bitte nutzen Sie den folgenden Link, um sich anzumelden:\n"+
+							host+"/login/?m="+token+"\n\n"+
+							"Der Link ist 15 Minuten gültig und kann nur einmal verwendet werden.\n")
+					if d.Error != nil {
+						log.Println(d.Error)
+						d.Error = errMailNotSent
+					} else {
+						d.Sent = true
+					}
+				}
+			}
+		}
+
+		err := localTemplate(request, loginTemp).Execute(writer, d)
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
+
+// SAMLLogin redirects the browser to the SAML identity provider
+const (
+	// samlRequestCookie contains the id of the SAML request started by the browser
+	samlRequestCookie = "samlreq"
+	// samlRequestTimeout is the time the identity provider has to respond
+	samlRequestTimeout = 10 * time.Minute
+)
+
+func SAMLLogin(sp *saml.ServiceProvider) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		u, id, err := sp.LoginURL()
+		if err != nil {
+			http.Error(writer, "could not create SAML request: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		// only the browser which started the login accepts the response
+		http.SetCookie(writer, &http.Cookie{
+			Name:     samlRequestCookie,
+			Value:    id,
+			Path:     "/saml/",
+			MaxAge:   int(samlRequestTimeout / time.Second),
+			HttpOnly: true,
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(writer, request, u, http.StatusSeeOther)
+	}
+}
+
+func SAMLMetadata(sp *saml.ServiceProvider) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if !sp.Available() {
+			http.NotFound(writer, request)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/samlmetadata+xml")
+		_, err := writer.Write(sp.Metadata())
 		if err != nil {
 			log.Println(err)
 		}
 	}
 }
 
+// ResubmitWithCookies handles POST requests sent by another site. Since the
+// browser does not send the cookies along with such requests, the form is
+// sent again from a page of this site.
+func ResubmitWithCookies(handler http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method == http.MethodPost && getCookie(request, "uid") == "" {
+			err := parseForm(writer, request, maxSAMLSize)
+			if err != nil {
+				formError(writer, err)
+				return
+			}
+			if !request.PostForm.Has("resubmitted") {
+				err = localTemplate(request, resubmitTemp).Execute(writer, request.PostForm)
+				if err != nil {
+					renderError(err)
+				}
+				return
+			}
+		}
+		handler(writer, request)
+	}
+}
+
+// SAMLACS is the assertion consumer service which receives the response
+// of the identity provider.
+func SAMLACS(s *survey.Surveys, a *account.Accounts, sp *saml.ServiceProvider) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+
+		d := LoginData{}
+		if request.Method == http.MethodPost {
+			err := parseForm(writer, request, maxSAMLSize)
+			if err != nil {
+				formError(writer, err)
+				return
+			}
+			http.SetCookie(writer, &http.Cookie{
+				Name:   samlRequestCookie,
+				Path:   "/saml/",
+				MaxAge: -1,
+			})
+			id, err := sp.ParseResponse(request.FormValue("SAMLResponse"), getCookie(request, samlRequestCookie))
+			if err != nil {
+				log.Println("SAML login failed:", err)
+				d.Error = errLoginFailed
+			} else {
+				d.EMail, d.Error = a.BindExternal(string(userId), id)
+				if d.Error == nil {
+					log.Println("creator logged in by SAML")
+					restoreSurvey(s, userId, writer, request)
+					http.Redirect(writer, request, "/", http.StatusSeeOther)
+					return
+				}
+			}
+		}
+
+		err := localTemplate(request, loginTemp).Execute(writer, d)
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
+
+// Meeting is opened from within a video conference. The hosts of the
+// meeting are logged in to the account of the meeting and can create
+// surveys, the participants are forwarded to the survey of the meeting.
+func Meeting(s *survey.Surveys, a *account.Accounts, l *meeting.Launcher) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		lang := i18n.Negotiate(request.Header.Get("Accept-Language"))
+		p, err := l.Participant(request)
+		if err != nil {
+			log.Println("meeting launch failed:", err)
+			writer.WriteHeader(http.StatusForbidden)
+			err = meetingTemp.Execute(writer, VoteNotifyData{Error: errInvalidLink, Lang: lang})
+			if err != nil {
+				renderError(err)
+			}
+			return
+		}
+
+		userId := GetUserId(request)
+		if p.Host {
+			_, err = a.BindExternal(string(userId), p.Account())
+			if err != nil {
+				writer.WriteHeader(http.StatusForbidden)
+				err = meetingTemp.Execute(writer, VoteNotifyData{Error: err, Lang: lang})
+				if err != nil {
+					renderError(err)
+				}
+				return
+			}
+			l.SetHost(p, userId)
+			restoreSurvey(s, userId, writer, request)
+			http.Redirect(writer, request, "/", http.StatusSeeOther)
+			return
+		}
+
+		host, ok := l.Host(p)
+		var surveyId survey.SurveyId
+		if ok {
+			surveyId, ok = s.SurveyOfCreator(host)
+		}
+		if !ok {
+			err = meetingTemp.Execute(writer, VoteNotifyData{Error: errNoSurvey, Lang: lang})
+			if err != nil {
+				renderError(err)
+			}
+			return
+		}
+		http.Redirect(writer, request, "/vote/?id="+string(surveyId)+"&t="+l.VoterToken(p), http.StatusSeeOther)
+	}
+}
+
+// restoreSurvey sets the survey cookie to the most recent survey of the
+// creator's account if there is no running survey.
+func restoreSurvey(s *survey.Surveys, userId survey.UserId, writer http.ResponseWriter, request *http.Request) {
+	surveyId := GetSurveyId(writer, request)
+	if _, running := s.IsHiddenRunning(userId, surveyId); !running {
+		if found, ok := s.SurveyOfCreator(userId); ok {
+			http.SetCookie(writer, &http.Cookie{
+				Name:  "sid",
+				Value: string(found),
+				Path:  "/",
+			})
+		}
+	}
+}
+
+func Logout(a *account.Accounts) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		a.Logout(string(GetUserId(request)))
+		http.Redirect(writer, request, "/", http.StatusSeeOther)
+	}
+}
+
+const passkeySessionCookie = "pks"
+
+type PasskeyData struct {
+	Available  bool
+	Account    string
+	Registered int
+}
+
+func Passkey(a *account.Accounts) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := string(GetUserId(request))
+		d := PasskeyData{
+			Available:  a.PasskeysAvailable(),
+			Registered: a.PasskeyCount(userId),
+		}
+		d.Account, _ = a.AccountOf(userId)
+
+		err := localTemplate(request, passkeyTemp).Execute(writer, d)
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
+
+// PasskeyRest implements the WebAuthn ceremonies. The action is selected
+// by the query parameter "a".
+func PasskeyRest(a *account.Accounts) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		userId := string(GetUserId(request))
+
+		var data []byte
+		var err error
+		switch request.URL.Query().Get("a") {
+		case "registerBegin":
+			data, err = a.BeginRegistration(userId)
+		case "loginBegin":
+			data, err = a.BeginLogin(userId)
+		case "registerFinish", "loginFinish":
+			var body []byte
+			body, err = io.ReadAll(io.LimitReader(request.Body, 64*1024))
+			if err == nil {
+				var session string
+				if request.URL.Query().Get("a") == "registerFinish" {
+					session, err = a.FinishRegistration(userId, body)
+				} else {
+					_, session, err = a.FinishLogin(userId, body)
+				}
+				if err == nil {
+					http.SetCookie(writer, &http.Cookie{
+						Name:     passkeySessionCookie,
+						Value:    session,
+						Path:     "/",
+						HttpOnly: true,
+						Secure:   request.TLS != nil,
+						SameSite: http.SameSiteStrictMode,
+					})
+					data = []byte("{}")
+				}
+			}
+		default:
+			http.Error(writer, "unknown action", http.StatusBadRequest)
+			return
+		}
+
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		_, err = writer.Write(data)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+type SessionsData struct {
+	Account  string
+	FeedURL  string
+	Sessions []account.Session
+	Message  string
+	Error    error
+}
+
+// Sessions lists the devices bound to the creator's account and allows
+// to revoke them. The surveys created by a revoked device are transferred
+// to the current device.
+func Sessions(s *survey.Surveys, a *account.Accounts, f *feed.Feeds) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+
+		var d SessionsData
+		if request.Method == http.MethodPost {
+			err := parseForm(writer, request, maxFormSize)
+			if err != nil {
+				formError(writer, err)
+				return
+			}
+			var revoked string
+			revoked, d.Error = a.Revoke(string(userId), request.FormValue("handle"))
+			if d.Error == nil {
+				n := s.TransferCreator(survey.UserId(revoked), userId)
+				d.Message = "Das Gerät wurde abgemeldet."
+				if n > 0 {
+					d.Message += " Die von diesem Gerät erstellten Umfragen werden nun von diesem Gerät gesteuert."
+				}
+			}
+		}
+
+		d.Account, _ = a.AccountOf(string(userId))
+		d.Sessions = a.Sessions(string(userId))
+		if d.Account != "" {
+			d.FeedURL = f.URL(d.Account)
+			if strings.HasPrefix(d.FeedURL, "/") {
+				// no host configured, feed readers require an absolute url
+				scheme := "http://"
+				if request.TLS != nil {
+					scheme = "https://"
+				}
+				d.FeedURL = scheme + request.Host + d.FeedURL
+			}
+		}
+
+		err := localTemplate(request, sessionsTemp).Execute(writer, d)
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
+
+// Feed returns the Atom feed of the results of an account
+func Feed(f *feed.Feeds) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		query := request.URL.Query()
+		acc := query.Get("a")
+		entries, err := f.Entries(acc, query.Get("k"))
+		if err != nil {
+			http.Error(writer, "feed not found", http.StatusNotFound)
+			return
+		}
+		data, err := f.Atom(acc, entries)
+		if err != nil {
+			log.Println(err)
+			http.Error(writer, "could not create feed", http.StatusInternalServerError)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/atom+xml; charset=utf-8")
+		_, err = writer.Write(data)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// Browse lists the running surveys whose creators have chosen to list them publicly
+func Browse(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		err := localTemplate(request, browseTemp).Execute(writer, s.PublicSurveys())
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
+
+type RolesData struct {
+	Default account.Role
+	Roles   []account.RoleEntry
+	All     []account.Role
+	Error   error
+}
+
+// Roles allows administrators to assign roles to accounts
+func Roles(a *account.Accounts) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := string(GetUserId(request))
+
+		d := RolesData{All: account.Roles}
+		if request.Method == http.MethodPost {
+			err := parseForm(writer, request, maxFormSize)
+			if err != nil {
+				formError(writer, err)
+				return
+			}
+			if request.Form.Has("default") {
+				d.Error = a.SetDefaultRole(userId, account.Role(request.FormValue("default")))
+			} else {
+				d.Error = a.SetRole(userId, request.FormValue("email"), account.Role(request.FormValue("role")))
+			}
+		}
+
+		var err error
+		d.Default, d.Roles, err = a.GetRoles(userId)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusForbidden)
+			return
+		}
+
+		err = localTemplate(request, rolesTemp).Execute(writer, d)
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
+
+type RegisterData struct {
+	MailAvailable bool
+	Registered    int
+	Sent          int
+	Failed        []string
+	Error         error
+}
+
+func Register(s *survey.Surveys, m *mailer.Mailer) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+
+		d := RegisterData{MailAvailable: m.Available()}
+
+		if request.Method == http.MethodPost && d.MailAvailable {
+			err := parseForm(writer, request, maxFormSize)
+			if err != nil {
+				formError(writer, err)
+				return
+			}
+
+			var emails []string
+			for _, line := range strings.FieldsFunc(request.FormValue("emails"), isAddressSeparator) {
+				addr, err := mail.ParseAddress(line)
+				if err != nil {
+					d.Failed = append(d.Failed, line)
+					continue
+				}
+				emails = append(emails, addr.Address)
+			}
+
+			if len(d.Failed) > 0 {
+				d.Error = errInvalidEMails
+			} else {
+				var voters []survey.RegisteredVoter
+				voters, d.Error = s.RegisterVoters(userId, surveyId, emails)
+				if d.Error == nil {
+					question, _ := s.GetRunningSurvey(userId, surveyId)
+					for _, v := range voters {
+						err := m.Send(v.EMail, "Einladung zur Umfrage: "+question.Title,
+							"Sie wurden zur Teilnahme an einer Umfrage eingeladen.\n\n"+
+								"Bitte nutzen Sie zur Abstimmung Ihren persönlichen Link:\n"+v.URL+"\n\n"+
+								"Der Link darf nicht weitergegeben werden. Mit ihm kann pro Frage nur eine Stimme abgegeben werden.\n")
+						if err != nil {
+							log.Println(err)
+							d.Failed = append(d.Failed, v.EMail)
+						} else {
+							d.Sent++
+						}
+					}
+					if len(d.Failed) > 0 {
+						d.Error = errMailsNotSent
+					}
+				}
+			}
+		}
+
+		d.Registered = s.RegisteredVoters(userId, surveyId)
+
+		err := localTemplate(request, registerTemp).Execute(writer, d)
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
+
+func isAddressSeparator(r rune) bool {
+	return r == '\n' || r == '\r' || r == ',' || r == ';'
+}
+
 type ResultData struct {
-	QRCode  string        `json:"-"`
-	Title   string        `json:"Title"`
+	QRCode string `json:"-"`
+	// Title is the escaped title, it is inserted as html by result.js
+	Title   template.HTML `json:"Title"`
 	Result  template.HTML `json:"Result"`
 	Version int           `json:"Version"`
+	// the fields of end-to-end encrypted surveys are decrypted by result.js,
+	// Hidden and Votes are also set while the result of a survey which is
+	// not encrypted is hidden, so the progress of the voting can be shown
+	Encrypted bool     `json:"Encrypted,omitempty"`
+	Hidden    bool     `json:"Hidden,omitempty"`
+	Votes     int      `json:"Votes,omitempty"`
+	Options   []string `json:"Options,omitempty"`
+	Ballots   []string `json:"Ballots,omitempty"`
+	Multiple  bool     `json:"Multiple,omitempty"`
+	// Delta is sent instead of the whole result if the client requests it
+	Delta *ResultDelta `json:"Delta,omitempty"`
+	// Resume is sent back by the client to continue after a reconnect
+	Resume string `json:"Resume,omitempty"`
+	// Viewer is set if the result is shown using a viewer token
+	Viewer bool `json:"-"`
+	// WiFi is the venue network shown next to the vote QR code
+	WiFi *WiFi `json:"-"`
+	// Locale is used to format the numbers updated by the result page
+	Locale string `json:"-"`
 }
 
-func dataFromResult(result survey.Result) ResultData {
-	var b bytes.Buffer
-	err := resultTableTemp.Execute(&b, result)
+// dataFromResult renders the result table, the numbers are formatted
+// according to the given locale
+func dataFromResult(result survey.Result, locale string) ResultData {
+	table, err := renderFragment(inLanguage(locale, resultTableTemp), result.Localize(locale))
 	if err != nil {
-		log.Println("could not execute result table template:", err)
+		renderError("could not execute result table template:", err)
+	}
+	title := result.Title
+	if strictEscaping {
+		title = removeBidi(title)
 	}
-	return ResultData{
+	d := ResultData{
 		QRCode:  result.QRCode,
-		Title:   template.HTMLEscapeString(result.Title),
-		Result:  template.HTML(b.String()),
+		Title:   template.HTML(template.HTMLEscapeString(title)),
+		Result:  template.HTML(table),
 		Version: result.Version,
 	}
+	if result.Hidden() {
+		d.Hidden = true
+		d.Votes = result.Votes
+	}
+	if result.Encrypted {
+		d.Result = ""
+		d.Encrypted = true
+		d.Votes = result.Votes
+		d.Ballots = result.Ballots
+		d.Multiple = result.Multiple
+		for _, o := range result.Result {
+			d.Options = append(d.Options, o.Title)
+			d.Hidden = o.VoteCount() < 0
+		}
+	}
+	return d
+}
+
+// resultData returns the result for a client which has already seen the
+// given version. If delta is set, only the changes are sent if possible.
+func resultData(s *survey.Surveys, userId survey.UserId, surveyId survey.SurveyId, v int, delta bool, request *http.Request) ResultData {
+	var data ResultData
+	var result survey.Result
+	if v > 0 && delta {
+		var base *survey.Result
+		result, base = s.ResultSince(userId, surveyId, v)
+		if d := resultDelta(result, base); d != nil {
+			data = ResultData{Version: result.Version, Delta: d}
+		} else {
+			data = dataFromResult(result, resultLocale(result, request))
+		}
+	} else {
+		result = s.GetResult(userId, surveyId)
+		data = dataFromResult(result, resultLocale(result, request))
+	}
+	if result.Version > 0 {
+		data.Resume = newResumeToken(surveyId, result)
+	}
+	return data
 }
 
 func Result(s *survey.Surveys) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		userId := GetUserId(request)
 		surveyId := GetSurveyId(writer, request)
+		s.RecordResultAccess(userId, surveyId)
 		result := s.GetResult(userId, surveyId)
 
-		data := dataFromResult(result)
+		data := dataFromResult(result, resultLocale(result, request))
+		data.Locale = resultLocale(result, request)
+		data.Viewer = isViewer(request)
+		data.WiFi = wifi
 
-		err := resultTemp.Execute(writer, data)
+		err := inLanguage(data.Locale, resultTemp).Execute(writer, data)
 		if err != nil {
-			log.Println(err)
+			renderError(err)
 		}
 	}
 }
@@ -272,22 +1342,34 @@ func ResultRest(s *survey.Surveys) http.HandlerFunc {
 		userId := GetUserId(request)
 		surveyId := GetSurveyId(writer, request)
 
-		vStr := request.URL.Query().Get("v")
-		v, err := strconv.Atoi(vStr)
-		if err != nil {
-			v = -1
+		var v int
+		var err error
+		if token := request.URL.Query().Get("r"); token != "" {
+			v = resumeVersion(s, userId, surveyId, token)
+		} else {
+			v, err = strconv.Atoi(request.URL.Query().Get("v"))
+			if err != nil {
+				v = -1
+			}
 		}
 
-		var result survey.Result
+		// only the initial request of a client is recorded, not every update
+		if v <= 0 {
+			s.RecordResultAccess(userId, surveyId)
+		}
 		if v > 0 {
-			select {
-			case <-time.After(30 * time.Second):
-			case <-s.WaitForModification(userId, surveyId, v):
+			metrics.longPolls.Add(1)
+			err = s.Wait(request.Context(), userId, surveyId, v, 30*time.Second)
+			metrics.longPolls.Add(-1)
+			if err != nil {
+				writer.Header().Set("Retry-After", "5")
+				http.Error(writer, err.Error(), http.StatusServiceUnavailable)
+				return
 			}
 		}
-		result = s.GetResult(userId, surveyId)
+		data := resultData(s, userId, surveyId, v, request.URL.Query().Get("d") == "1", request)
 
-		jsonData, err := json.Marshal(dataFromResult(result))
+		jsonData, err := json.Marshal(data)
 		if err != nil {
 			http.Error(writer, "could not marshal result: "+err.Error(), http.StatusInternalServerError)
 			return
@@ -301,24 +1383,224 @@ func ResultRest(s *survey.Surveys) http.HandlerFunc {
 	}
 }
 
-func Vote(s *survey.Surveys) http.HandlerFunc {
+// Promote adds the free text answer given by the form value "text" as a new
+// option to the running survey. The result page is updated by the long poll.
+func Promote(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		err := parseForm(writer, request, maxFormSize)
+		if err != nil {
+			formError(writer, err)
+			return
+		}
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+		err = s.PromoteWriteIn(userId, surveyId, request.FormValue("text"))
+		if err != nil {
+			httpError(writer, request, err, errorStatus(err, http.StatusBadRequest))
+		}
+	}
+}
+
+// Annotate attaches the form value "text" as a note to the uncovered
+// result. The result page is updated by the long poll.
+func Annotate(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		err := parseForm(writer, request, maxFormSize)
+		if err != nil {
+			formError(writer, err)
+			return
+		}
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+		err = s.Annotate(userId, surveyId, request.FormValue("text"))
+		if err != nil {
+			httpError(writer, request, err, errorStatus(err, http.StatusBadRequest))
+		}
+	}
+}
+
+// Reveal changes the visibility of the result, the form value "a" is
+// "next" to uncover one more option, "hide" to cover the result again,
+// and "all" to uncover the whole result.
+func Reveal(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		err := parseForm(writer, request, maxFormSize)
+		if err != nil {
+			formError(writer, err)
+			return
+		}
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+		switch request.FormValue("a") {
+		case "next":
+			err = s.RevealNext(userId, surveyId)
+		case "hide":
+			err = s.Hide(userId, surveyId)
+		case "all":
+			err = s.Uncover(userId, surveyId)
+		default:
+			http.Error(writer, "unknown action", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			httpError(writer, request, err, errorStatus(err, http.StatusBadRequest))
+		}
+	}
+}
+
+type VoteData struct {
+	Number   int
+	SurveyId survey.SurveyId
+	Question survey.SurveyQuestion
+	// Revision is the revision of the texts of the question
+	Revision int
+	// Token is the personal token of a registered voter
+	Token string
+	// Lang is the language of the vote page
+	Lang string
+	// Preview is set if the question is shown to the creator before it is started
+	Preview bool
+	// Remaining is the remaining voting time in seconds if it is limited
+	Remaining int
+	// Key is the key of the vote link in the strict mode
+	Key string
+	// CodeRequired is set if an access code is required to vote
+	CodeRequired bool
+	// Code is the access code contained in the vote link
+	Code string
+	// Series is the position of the question in the announced series
+	Series survey.Series
+}
+
+// T translates the given text to the language of the vote page
+func (d VoteData) T(text string) string {
+	return i18n.Text(d.Lang, text)
+}
+
+// SeriesText returns the position of the question in the series
+func (d VoteData) SeriesText() string {
+	return fmt.Sprintf(d.T("Frage %d von %d"), d.Series.Position, d.Series.Total)
+}
+
+func newVoteData(q survey.Question, token, lang string) VoteData {
+	return VoteData{
+		Number:       q.Number,
+		SurveyId:     q.SurveyId,
+		Question:     q.Question,
+		Revision:     q.Revision,
+		Token:        token,
+		Lang:         lang,
+		Remaining:    q.Remaining(),
+		Key:          q.VoteKey,
+		CodeRequired: q.CodeRequired,
+		Series:       q.Series,
+	}
+}
+
+type VoteNotifyData struct {
+	Error error
+	Lang  string
+	// ThankYou replaces the default confirmation of a successful vote
+	ThankYou survey.ThankYou
+	// Redirect is the follow-up link of the voted question
+	Redirect string
+}
+
+func (d VoteNotifyData) T(text string) string {
+	return i18n.Text(d.Lang, text)
+}
+
+// ErrorText returns the error message in the language of the vote page
+func (d VoteNotifyData) ErrorText() string {
+	return localizeError(d.Lang, d.Error)
+}
+
+// VoteBackend provides the questions and receives the votes. It is
+// implemented by the surveys and by the federation relay.
+type VoteBackend interface {
+	GetQuestion(surveyId survey.SurveyId) survey.Question
+	Vote(surveyId survey.SurveyId, voterId survey.UserId, option []int, number int) error
+	VoteEncrypted(surveyId survey.SurveyId, voterId survey.UserId, ballot string, number int) error
+	VoteWriteIn(surveyId survey.SurveyId, voterId survey.UserId, option []int, writeIn string, number int) error
+	HasVoted(surveyId survey.SurveyId, voterId survey.UserId) bool
+}
+
+func Vote(s VoteBackend) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
 		query := request.URL.Query()
 		surveyId := survey.SurveyId(query.Get("id"))
 
 		question := s.GetQuestion(surveyId)
-		err := voteTemp.Execute(writer, question)
+		lang := voteLanguage(question.Question.Language, request)
+		// the personal links of the registered voters do not contain the key
+		if st, ok := strictBackend(s); ok && !(question.TokenRequired && query.Get("t") != "") {
+			if st.CheckVoteKey(surveyId, query.Get("k")) {
+				setVoterCookie(writer, st, GetUserId(request))
+			} else {
+				question = survey.Question{Question: survey.SurveyQuestion{Title: errInvalidLink.Localize(lang)}}
+			}
+		}
+		d := newVoteData(question, query.Get("t"), lang)
+		d.Code = query.Get("a")
+		err := voteTemp.Execute(writer, d)
 		if err != nil {
-			log.Println(err)
+			renderError(err)
 		}
 	}
 }
 
-func VoteRest(s *survey.Surveys) http.HandlerFunc {
+// ratingOption returns the option of the given scale value of a rating
+func ratingOption(q survey.SurveyQuestion, value string) ([]int, error) {
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, errInvalidRating
+	}
+	i, err := q.RatingOption(v)
+	if err != nil {
+		return nil, err
+	}
+	return []int{i}, nil
+}
+
+// voterTokenVerifier checks the personal voter tokens which are issued
+// outside of the registration mode, e.g. by the meeting launcher
+var voterTokenVerifier func(token string) bool
+
+// SetVoterTokenVerifier sets the function which checks the signature of
+// the personal voter tokens issued outside of the registration mode.
+// Must be called before the handlers are used.
+func SetVoterTokenVerifier(verify func(token string) bool) {
+	voterTokenVerifier = verify
+}
+
+// isVoterToken returns true if the token given by the query parameter "t"
+// identifies the voter. Otherwise a client could vote again and again by
+// sending a new token, so the token is only used in the registration mode,
+// where only the registered tokens are accepted, or if it is signed.
+func isVoterToken(question survey.Question, token string) bool {
+	return question.TokenRequired || (voterTokenVerifier != nil && voterTokenVerifier(token))
+}
+
+func VoteRest(s VoteBackend) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
+		// the vote is sent in the query, the body may only contain the idempotency key
+		request.Body = http.MaxBytesReader(writer, request.Body, maxFormSize)
 		query := request.URL.Query()
 		surveyId := survey.SurveyId(query.Get("id"))
-		isOption := query.Has("o")
+		ballot := query.Get("e")
+		isOption := query.Has("o") || query.Has("s") || ballot != ""
 		var o []int
 		if isOption {
 			option := query.Get("o")
@@ -330,26 +1612,63 @@ func VoteRest(s *survey.Surveys) http.HandlerFunc {
 			}
 		}
 
+		question := s.GetQuestion(surveyId)
 		userId := GetUserId(request)
+		if token := query.Get("t"); token != "" && isVoterToken(question, token) {
+			// registered voters are identified by their personal token
+			userId = survey.UserId(token)
+		} else if code := query.Get("a"); code != "" && question.CodeRequired {
+			// voters holding an access code are identified by the code, without
+			// codes a client could vote again and again by sending a new one
+			userId = survey.CodeVoter(code)
+		}
+		if query.Has("c") {
+			writeCorrection(writer, question, query)
+			return
+		}
+		lang := voteLanguage(question.Question.Language, request)
 		var err error
+		if query.Has("s") {
+			// a rating can also be given by its scale value
+			o, err = ratingOption(question.Question, query.Get("s"))
+		}
 		if isOption {
 			nStr := query.Get("n")
 			var n int
-			n, err = strconv.Atoi(nStr)
 			if err == nil {
-				err = s.Vote(surveyId, userId, o, n)
+				n, err = strconv.Atoi(nStr)
 			}
-			err = voteNotifyTemp.Execute(writer, err)
+			if st, ok := strictBackend(s); ok && err == nil && !st.VerifiedVoter(surveyId, userId) {
+				err = checkStrictVote(st, surveyId, n, userId, request)
+			}
+			if err == nil {
+				// a repeated request must not fail because the vote was already counted
+				scope := "vote/" + string(surveyId) + "/" + string(userId)
+				_, err = idempotency.do(scope, idempotencyKey(request), func() (string, error) {
+					if ballot != "" {
+						return "", s.VoteEncrypted(surveyId, userId, ballot, n)
+					} else if writeIn := query.Get("w"); writeIn != "" {
+						return "", s.VoteWriteIn(surveyId, userId, o, writeIn, n)
+					}
+					return "", s.Vote(surveyId, userId, o, n)
+				})
+			}
+			err = voteNotifyTemp.Execute(writer, VoteNotifyData{Error: err, Lang: lang, ThankYou: question.ThankYou, Redirect: question.FollowUp()})
 		} else {
 			if s.HasVoted(surveyId, userId) {
-				err = voteNotifyTemp.Execute(writer, errors.New("Es gibt noch keine neue Umfrage!"))
+				err = voteNotifyTemp.Execute(writer, VoteNotifyData{Error: errNoNewSurvey, Lang: lang})
 			} else {
-				question := s.GetQuestion(surveyId)
-				err = voteQuestionTemp.Execute(writer, question)
+				d := newVoteData(question, "", lang)
+				d.Code = query.Get("a")
+				var question string
+				question, err = renderFragment(voteQuestionTemp, d)
+				if err == nil {
+					_, err = io.WriteString(writer, question)
+				}
 			}
 		}
 		if err != nil {
-			log.Println(err)
+			renderError(err)
 		}
 	}
 }
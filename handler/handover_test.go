@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"flashSurvey/account"
+	"flashSurvey/account/accounttest"
+	"flashSurvey/randid"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandoverPasskey(t *testing.T) {
+	a := account.New("https://example.com")
+	bind := func(email string) string {
+		userId := randid.String()
+		token, err := a.RequestMagicLink(email)
+		require.NoError(t, err)
+		_, err = a.ConfirmMagicLink(token, userId)
+		require.NoError(t, err)
+		return userId
+	}
+	protected := bind("creator@example.com")
+	options, err := a.BeginRegistration(protected)
+	require.NoError(t, err)
+	session, err := a.FinishRegistration(protected, accounttest.NewAuthenticator(t).Create(options))
+	require.NoError(t, err)
+	unprotected := bind("other@example.com")
+
+	h := EnsureUserId(a)(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(GetUserId(request)))
+	})
+	handover := func(tuid, pks, lang string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/?tuid="+tuid+"&tsid="+randid.String(), nil)
+		r.Header.Set("Accept-Language", lang)
+		if pks != "" {
+			r.AddCookie(&http.Cookie{Name: passkeySessionCookie, Value: pks})
+		}
+		w := httptest.NewRecorder()
+		h(w, r)
+		return w
+	}
+
+	// the QR code does not pass on the passkey session
+	w := handover(protected, "", "de")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "Die Kontrolle kann nicht weitergegeben werden!")
+	assert.Contains(t, w.Body.String(), `href="/passkey/"`)
+	assert.Empty(t, w.Result().Cookies())
+	w = handover(protected, "", "en")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "The control can not be passed on!")
+
+	w = handover(protected, session, "de")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, protected, w.Body.String())
+
+	w = handover(unprotected, "", "de")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, unprotected, w.Body.String())
+}
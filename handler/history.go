@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"encoding/json"
+	"flashSurvey/survey"
+	"log"
+	"net/http"
+	"time"
+)
+
+type HistoryData struct {
+	Rounds []survey.Round
+	Error  error
+}
+
+// History shows the completed questions of the running survey, so the
+// creator can review all questions asked during a session.
+func History(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+
+		var d HistoryData
+		d.Rounds, d.Error = s.History(userId, surveyId)
+		for i, r := range d.Rounds {
+			d.Rounds[i].Result = r.Result.Localize(resultLocale(r.Result, request))
+		}
+		err := localTemplate(request, historyTemp).Execute(writer, d)
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
+
+// HistoryRest returns the completed questions of the running survey as JSON
+func HistoryRest(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+
+		results, err := exportHistory(s, userId, surveyId)
+		if err != nil {
+			httpError(writer, request, err, errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		writer.Header().Set("Content-Type", "application/json")
+		err = json.NewEncoder(writer).Encode(results)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// exportHistory returns the completed questions which are not encrypted
+func exportHistory(s *survey.Surveys, userId survey.UserId, surveyId survey.SurveyId) ([]ExportResult, error) {
+	rounds, err := s.History(userId, surveyId)
+	if err != nil {
+		return nil, err
+	}
+	results := []ExportResult{}
+	for _, r := range rounds {
+		e, err := exportResult(r.Result, time.Now())
+		if err == nil {
+			e.Ended = &r.Ended
+			results = append(results, e)
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "First", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+	_, err = s.New("creator", sid, survey.SurveyQuestion{Title: "Second", Options: []string{"C", "D"}})
+	assert.NoError(t, err)
+
+	request := func(h http.HandlerFunc, url string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		r.AddCookie(&http.Cookie{Name: "sid", Value: string(sid)})
+		r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+		w := httptest.NewRecorder()
+		h(w, r)
+		return w
+	}
+
+	w := request(History(s), "/history/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "First")
+	assert.NotContains(t, w.Body.String(), "Second")
+
+	w = request(HistoryRest(s), "/historyRest/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var results []ExportResult
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &results))
+	assert.Len(t, results, 1)
+	assert.Equal(t, "First", results[0].Title)
+	assert.Equal(t, 1, results[0].Options[0].Votes)
+	assert.NotNil(t, results[0].Ended)
+
+	// the running question is hidden, so only the completed one is exported
+	w = request(Export(s), "/export/?format=json&history=true")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &results))
+	assert.Len(t, results, 1)
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// idempotencyTTL is the time an idempotency key is remembered
+const idempotencyTTL = 10 * time.Minute
+
+// maxIdempotencyKeyLen is the maximum length of an accepted idempotency key
+const maxIdempotencyKeyLen = 64
+
+// idempotentCall is the outcome of a request with an idempotency key
+type idempotentCall struct {
+	done    chan struct{}
+	value   string
+	err     error
+	created time.Time
+}
+
+// idempotencyKeys remembers the outcomes of the requests by their keys. If
+// a flaky network makes a client send a request again, the request is not
+// executed a second time but the outcome of the first one is returned.
+type idempotencyKeys struct {
+	mutex     sync.Mutex
+	calls     map[string]*idempotentCall
+	lastPrune time.Time
+}
+
+var idempotency = &idempotencyKeys{calls: map[string]*idempotentCall{}}
+
+// idempotencyKey returns the key sent by the client, either as the header
+// Idempotency-Key or, for html forms, as the form value idempotencyKey
+func idempotencyKey(request *http.Request) string {
+	key := request.Header.Get("Idempotency-Key")
+	if key == "" {
+		key = request.FormValue("idempotencyKey")
+	}
+	if len(key) > maxIdempotencyKeyLen {
+		return ""
+	}
+	return key
+}
+
+// do calls f once for the given key in the given scope, e.g. a survey. If
+// the key is empty, f is always called. If a request with the same key is
+// still running, do waits for its outcome.
+func (k *idempotencyKeys) do(scope, key string, f func() (string, error)) (string, error) {
+	if key == "" {
+		return f()
+	}
+	id := scope + "/" + key
+
+	k.mutex.Lock()
+	now := time.Now()
+	if now.Sub(k.lastPrune) > idempotencyTTL {
+		for i, c := range k.calls {
+			if now.Sub(c.created) > idempotencyTTL {
+				delete(k.calls, i)
+			}
+		}
+		k.lastPrune = now
+	}
+	if c, ok := k.calls[id]; ok && now.Sub(c.created) <= idempotencyTTL {
+		k.mutex.Unlock()
+		<-c.done
+		return c.value, c.err
+	}
+	c := &idempotentCall{done: make(chan struct{}), created: now}
+	k.calls[id] = c
+	k.mutex.Unlock()
+
+	c.value, c.err = f()
+	close(c.done)
+	return c.value, c.err
+}
@@ -0,0 +1,55 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	k := &idempotencyKeys{calls: map[string]*idempotentCall{}}
+	calls := 0
+	f := func() (string, error) {
+		calls++
+		return "v", errors.New("e")
+	}
+
+	v, err := k.do("s", "key", f)
+	assert.Equal(t, "v", v)
+	assert.EqualError(t, err, "e")
+	v, err = k.do("s", "key", f)
+	assert.Equal(t, "v", v)
+	assert.EqualError(t, err, "e")
+	assert.Equal(t, 1, calls)
+
+	k.do("other", "key", f)
+	k.do("s", "", f)
+	k.do("s", "", f)
+	assert.Equal(t, 4, calls)
+}
+
+func TestVoteRestIdempotent(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	vote := func(key string) string {
+		r := httptest.NewRequest(http.MethodGet, "/voteRest/?id="+string(sid)+"&o=1&n=1", nil)
+		r.Header.Set("Idempotency-Key", key)
+		r = r.WithContext(context.WithValue(r.Context(), "id", "voter"))
+		w := httptest.NewRecorder()
+		VoteRest(s)(w, r)
+		return w.Body.String()
+	}
+
+	assert.Contains(t, vote("k1"), "Sie haben erfolgreich abgestimmt!")
+	// the repeated request is answered like the first one
+	assert.Contains(t, vote("k1"), "Sie haben erfolgreich abgestimmt!")
+	assert.Contains(t, vote("k2"), "Sie haben bereits abgestimmt!")
+	assert.Equal(t, 1, s.CollectedVotes("creator", sid))
+}
@@ -0,0 +1,93 @@
+package handler
+
+import (
+	"errors"
+	"flashSurvey/i18n"
+	"flashSurvey/survey"
+	"fmt"
+	"html/template"
+	"net/http"
+)
+
+// voteLanguage returns the language of the vote page. If the creator has
+// not chosen a language, the Accept-Language header of the browser is used.
+func voteLanguage(surveyLanguage string, request *http.Request) string {
+	if i18n.Supported(surveyLanguage) {
+		return surveyLanguage
+	}
+	return i18n.Negotiate(request.Header.Get("Accept-Language"))
+}
+
+// resultLocale returns the locale used to format the numbers of the result.
+// The language of the vote page is preferred over the browser settings,
+// so the result is shown the same way to the audience.
+func resultLocale(result survey.Result, request *http.Request) string {
+	return voteLanguage(result.Language, request)
+}
+
+// localizeError returns the message of the error in the given language.
+// The errors of the survey package are taken from the message catalog,
+// all other errors are translated by their text.
+func localizeError(lang string, err error) string {
+	var se *survey.Error
+	if errors.As(err, &se) {
+		return se.Localize(lang)
+	}
+	var ve survey.ValidationError
+	if errors.As(err, &ve) {
+		return ve.Localize(lang)
+	}
+	return i18n.Text(lang, err.Error())
+}
+
+// localized contains the templates translated to the languages other than
+// the default language, which is used by the templates in Templates
+var localized = localizeTemplates()
+
+// languageFuncs returns the template functions which translate the texts
+// of the pages to the given language
+func languageFuncs(lang string) template.FuncMap {
+	return template.FuncMap{
+		"lang": func() string { return lang },
+		"tr": func(text string, args ...any) string {
+			text = i18n.Text(lang, text)
+			if len(args) == 0 {
+				return text
+			}
+			return fmt.Sprintf(text, args...)
+		},
+		"errorText": func(err error) string {
+			return localizeError(lang, err)
+		},
+	}
+}
+
+func localizeTemplates() map[string]*template.Template {
+	l := map[string]*template.Template{}
+	for _, lang := range i18n.Languages {
+		if lang.Code != i18n.Default {
+			l[lang.Code] = template.Must(Templates.Clone()).Funcs(languageFuncs(lang.Code))
+		}
+	}
+	return l
+}
+
+// inLanguage returns the given template translated to the given language
+func inLanguage(lang string, t *template.Template) *template.Template {
+	if l, ok := localized[lang]; ok {
+		return l.Lookup(t.Name())
+	}
+	return t
+}
+
+// localTemplate returns the given template translated to the language
+// requested by the browser. It is used for the pages of the creators.
+func localTemplate(request *http.Request, t *template.Template) *template.Template {
+	return inLanguage(i18n.Negotiate(request.Header.Get("Accept-Language")), t)
+}
+
+// httpError replies with the message of the error in the language requested
+// by the browser, it is used by the requests of the pages' scripts
+func httpError(writer http.ResponseWriter, request *http.Request, err error, status int) {
+	http.Error(writer, localizeError(i18n.Negotiate(request.Header.Get("Accept-Language")), err), status)
+}
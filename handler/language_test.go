@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"context"
+	"flashSurvey/i18n"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTemplateTexts checks that all texts translated by the templates are
+// contained in the translations
+func TestTemplateTexts(t *testing.T) {
+	files, err := filepath.Glob("templates/*.html")
+	require.NoError(t, err)
+	trRegex := regexp.MustCompile(`\{\{tr ("(?:[^"\\]|\\.)*")`)
+	found := 0
+	for _, f := range files {
+		src, err := os.ReadFile(f)
+		require.NoError(t, err)
+		for _, m := range trRegex.FindAllStringSubmatch(string(src), -1) {
+			text, err := strconv.Unquote(m[1])
+			require.NoError(t, err)
+			assert.True(t, i18n.HasText(text), "text %q used in %s is not translated", text, f)
+			found++
+		}
+	}
+	assert.Greater(t, found, 0)
+}
+
+// TestHandlerErrorCodes checks that all codes used by the handlers are
+// contained in the message catalog
+func TestHandlerErrorCodes(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+	codeRegex := regexp.MustCompile(`survey\.Error\{Code: "([^"]+)"`)
+	found := 0
+	for _, f := range files {
+		src, err := os.ReadFile(f)
+		require.NoError(t, err)
+		for _, m := range codeRegex.FindAllStringSubmatch(string(src), -1) {
+			assert.True(t, i18n.Has(m[1]), "code %s used in %s is not in the catalog", m[1], f)
+			found++
+		}
+	}
+	assert.Greater(t, found, 0)
+}
+
+func TestLocalTemplate(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	page := func(lang string) string {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept-Language", lang)
+		r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+		w := httptest.NewRecorder()
+		Create(s, nil, false)(w, r)
+		return w.Body.String()
+	}
+
+	body := page("en-US,en;q=0.9")
+	assert.Contains(t, body, `<html lang="en">`)
+	assert.Contains(t, body, "<h2>Create survey</h2>")
+	assert.Contains(t, body, ">Imprint</a>")
+
+	body = page("fr")
+	assert.Contains(t, body, `<html lang="de">`)
+	assert.Contains(t, body, "<h2>Umfrage erzeugen</h2>")
+}
+
+func TestCodesError(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodPost, "/codes/?count=x", nil)
+	r.Header.Set("Accept-Language", "en")
+	r.AddCookie(&http.Cookie{Name: "sid", Value: string(sid)})
+	r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+	w := httptest.NewRecorder()
+	Codes(s)(w, r)
+	assert.Contains(t, w.Body.String(), "Error: Invalid number!")
+}
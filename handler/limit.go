@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// Limit restricts the number of requests handled at the same time. If
+// more requests arrive, they are rejected immediately with the status
+// 503 and a Retry-After header, so the clients can try again a little
+// later instead of running into a timeout. If max is zero, the number
+// of requests is not limited.
+func Limit(max int, retryAfter time.Duration) func(http.HandlerFunc) http.HandlerFunc {
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		if max <= 0 {
+			return handler
+		}
+		var inFlight atomic.Int64
+		retry := strconv.Itoa(int((retryAfter + time.Second - 1) / time.Second))
+		return func(writer http.ResponseWriter, request *http.Request) {
+			if inFlight.Add(1) > int64(max) {
+				inFlight.Add(-1)
+				writer.Header().Set("Retry-After", retry)
+				http.Error(writer, "server overloaded", http.StatusServiceUnavailable)
+				return
+			}
+			defer inFlight.Add(-1)
+			handler(writer, request)
+		}
+	}
+}
+
+const (
+	// maxFormSize is the maximum size of a posted form, the largest one is
+	// the create form of an end-to-end encrypted question
+	maxFormSize = 64 * 1024
+	// maxSAMLSize is the maximum size of the form containing the response
+	// of the identity provider, which contains the signature and certificates
+	maxSAMLSize = 512 * 1024
+	// maxOptionFields is the maximum number of option fields of the create
+	// form which are parsed, independent of the configured option limit
+	maxOptionFields = 500
+)
+
+// parseForm parses the form of the request, the body must not exceed the
+// given size. The parsed values are available by FormValue afterwards.
+func parseForm(writer http.ResponseWriter, request *http.Request, max int64) error {
+	request.Body = http.MaxBytesReader(writer, request.Body, max)
+	return request.ParseForm()
+}
+
+// formError sends the error returned by parseForm
+func formError(writer http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(writer, "form too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(writer, "could not parse form: "+err.Error(), http.StatusBadRequest)
+}
@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimit(t *testing.T) {
+	block := make(chan struct{})
+	running := make(chan struct{})
+	h := Limit(1, 3*time.Second)(func(writer http.ResponseWriter, request *http.Request) {
+		running <- struct{}{}
+		<-block
+	})
+
+	go h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	<-running
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "3", w.Header().Get("Retry-After"))
+
+	close(block)
+	go func() { <-running }()
+	assert.Eventually(t, func() bool {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w.Code == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestLimitDisabled(t *testing.T) {
+	h := Limit(0, time.Second)(func(writer http.ResponseWriter, request *http.Request) {})
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestParseFormTooLarge(t *testing.T) {
+	body := "title=" + strings.Repeat("x", maxFormSize)
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	err := parseForm(w, r, maxFormSize)
+	assert.Error(t, err)
+	formError(w, err)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestTooManyOptionFields(t *testing.T) {
+	form := url.Values{}
+	form.Set("title", "Frage")
+	for i := 0; i <= maxOptionFields; i++ {
+		form.Set("option"+strconv.Itoa(i), "o")
+	}
+	r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	assert.NoError(t, parseForm(httptest.NewRecorder(), r, maxFormSize))
+
+	q, err := questionFromForm(r, false)
+	assert.EqualError(t, err, "Zu viele Optionen!")
+	assert.Equal(t, "Frage", q.Title)
+	assert.Len(t, q.Options, maxOptionFields)
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"html"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// markdown converts the small subset of Markdown needed for the legal pages
+// to html: headings, paragraphs, unordered lists, emphasis and links.
+// Raw html is escaped, so the result is safe even if the file is not trusted.
+func markdown(md string) template.HTML {
+	var b strings.Builder
+	var paragraph []string
+	inList := false
+
+	flush := func() {
+		if len(paragraph) > 0 {
+			b.WriteString("<p>" + inline(strings.Join(paragraph, " ")) + "</p>\n")
+			paragraph = nil
+		}
+		if inList {
+			b.WriteString("</ul>\n")
+			inList = false
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+			flush()
+		case strings.HasPrefix(line, "#"):
+			flush()
+			level := len(line) - len(strings.TrimLeft(line, "#"))
+			if level > 6 {
+				level = 6
+			}
+			tag := "h" + string(rune('0'+level))
+			b.WriteString("<" + tag + ">" + inline(strings.TrimSpace(line[level:])) + "</" + tag + ">\n")
+		case strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* "):
+			if len(paragraph) > 0 {
+				flush()
+			}
+			if !inList {
+				b.WriteString("<ul>\n")
+				inList = true
+			}
+			b.WriteString("<li>" + inline(strings.TrimSpace(line[2:])) + "</li>\n")
+		default:
+			if inList {
+				flush()
+			}
+			paragraph = append(paragraph, line)
+		}
+	}
+	flush()
+	return template.HTML(b.String())
+}
+
+var (
+	codeRegex   = regexp.MustCompile("`([^`]+)`")
+	linkRegex   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	strongRegex = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	emRegex     = regexp.MustCompile(`\*([^*]+)\*`)
+)
+
+func inline(text string) string {
+	text = html.EscapeString(text)
+	text = linkRegex.ReplaceAllStringFunc(text, func(m string) string {
+		parts := linkRegex.FindStringSubmatch(m)
+		url := html.UnescapeString(parts[2])
+		if !safeURL(url) {
+			return parts[1]
+		}
+		return `<a href="` + html.EscapeString(url) + `">` + parts[1] + `</a>`
+	})
+	text = codeRegex.ReplaceAllString(text, "<code>$1</code>")
+	text = strongRegex.ReplaceAllString(text, "<strong>$1</strong>")
+	text = emRegex.ReplaceAllString(text, "<em>$1</em>")
+	return text
+}
+
+func safeURL(url string) bool {
+	for _, prefix := range []string{"http://", "https://", "mailto:", "/"} {
+		if strings.HasPrefix(url, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{"heading", "# Title", "<h1>Title</h1>\n"},
+		{"paragraph", "a\nb\n\nc", "<p>a b</p>\n<p>c</p>\n"},
+		{"list", "text\n- a\n- **b**\nmore", "<p>text</p>\n<ul>\n<li>a</li>\n<li><strong>b</strong></li>\n</ul>\n<p>more</p>\n"},
+		{"inline", "*a* `b` [c](https://example.com/?a=1&b=2)", `<p><em>a</em> <code>b</code> <a href="https://example.com/?a=1&amp;b=2">c</a></p>` + "\n"},
+		{"html", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"unsafe link", "[c](javascript:void)", "<p>c</p>\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.EqualValues(t, test.want, string(markdown(test.md)))
+		})
+	}
+}
+
+func TestLegalDefaults(t *testing.T) {
+	for _, name := range []string{"imprint", "privacy"} {
+		_, err := Legal(name, name, "")
+		assert.NoError(t, err)
+	}
+}
+
+func TestLegalReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "imprint.md")
+	assert.NoError(t, os.WriteFile(file, []byte("first"), 0644))
+	l, err := Legal("Impressum", "imprint", file)
+	assert.NoError(t, err)
+
+	get := func() string {
+		w := httptest.NewRecorder()
+		l.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/imprint", nil))
+		return w.Body.String()
+	}
+	assert.Contains(t, get(), "first")
+
+	assert.NoError(t, os.WriteFile(file, []byte("second"), 0644))
+	assert.NoError(t, l.Reload())
+	assert.Contains(t, get(), "second")
+
+	// an unreadable file keeps the former text
+	assert.NoError(t, os.Remove(file))
+	assert.Error(t, l.Reload())
+	assert.Contains(t, get(), "second")
+}
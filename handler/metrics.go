@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"flashSurvey/survey"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// metrics contains the counters of the handlers
+var metrics struct {
+	longPolls    atomic.Int64
+	webSockets   atomic.Int64
+	renderErrors atomic.Int64
+}
+
+// renderError logs an error of the execution of a template
+func renderError(v ...any) {
+	metrics.renderErrors.Add(1)
+	log.Println(v...)
+}
+
+// Metrics serves the metrics in the text format of Prometheus. The
+// scraper authenticates by a bearer token. If the token is empty, the
+// scraper is not checked, which is used if it is authenticated by its
+// client certificate.
+func Metrics(s *survey.Surveys, token string) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if token != "" {
+			t, _ := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(t), []byte(token)) != 1 {
+				http.Error(writer, "forbidden", http.StatusForbidden)
+				return
+			}
+		}
+
+		stats := s.Stats()
+		waiters := s.WaiterStats()
+		var b strings.Builder
+		metric := func(name, kind, help string, value any) {
+			fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n%s %v\n", name, help, name, kind, name, value)
+		}
+		metric("flashsurvey_surveys", "gauge", "Number of active surveys.", stats.Surveys)
+		metric("flashsurvey_votes_total", "counter", "Number of accepted votes.", stats.Votes)
+		metric("flashsurvey_votes_per_second", "gauge", "Average number of votes per second during the last minute.", stats.VotesPerSecond)
+		metric("flashsurvey_long_polls", "gauge", "Number of result pages waiting in a long-poll request.", metrics.longPolls.Load())
+		metric("flashsurvey_websockets", "gauge", "Number of open WebSocket connections of result pages.", metrics.webSockets.Load())
+		metric("flashsurvey_waiting_clients", "gauge", "Number of clients waiting for a change of a survey.", waiters.Waiting)
+		metric("flashsurvey_rejected_waits_total", "counter", "Number of waits rejected because of too many waiting clients.", waiters.Rejected)
+		metric("flashsurvey_expired_surveys_total", "counter", "Number of surveys deleted because of the timeout.", stats.Expired)
+		metric("flashsurvey_render_errors_total", "counter", "Number of errors while rendering a template.", metrics.renderErrors.Load())
+		metric("flashsurvey_goroutines", "gauge", "Number of goroutines.", runtime.NumGoroutine())
+
+		writer.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, err := writer.Write([]byte(b.String()))
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// Health is the health check of the load balancers and orchestrators. It
+// fails as soon as the server is shutting down, so no new clients are sent
+// to it while the running requests are drained.
+func Health(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		writer.Header().Set("Cache-Control", "no-store")
+		select {
+		case <-s.Done():
+			http.Error(writer, "shutting down", http.StatusServiceUnavailable)
+		default:
+			_, err := writer.Write([]byte("ok\n"))
+			if err != nil {
+				log.Println(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+
+	request := func(token string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		r.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		Metrics(s, "secret")(w, r)
+		return w
+	}
+
+	assert.Equal(t, http.StatusForbidden, request("wrong").Code)
+
+	w := request("secret")
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, "# TYPE flashsurvey_votes_total counter\nflashsurvey_votes_total 1\n")
+	assert.Contains(t, body, "\nflashsurvey_surveys 1\n")
+	assert.Contains(t, body, "\nflashsurvey_websockets 0\n")
+
+	// without a token the scraper is authenticated by the listener
+	w = httptest.NewRecorder()
+	Metrics(s, "")(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHealth(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	w := httptest.NewRecorder()
+	Health(s)(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	s.Close()
+	w = httptest.NewRecorder()
+	Health(s)(w, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"flashSurvey/survey"
+	"net/http"
+)
+
+type MyData struct {
+	Surveys []survey.SurveyInfo
+	// Current is the survey controlled by the create form
+	Current survey.SurveyId
+}
+
+// My lists the running surveys of the creator, so several surveys can be
+// run at the same time from one browser. The form values switch the create
+// form to another survey ("open"), prepare the create form for an
+// additional survey ("new") or delete a survey ("close").
+func My(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+
+		if request.Method == http.MethodPost {
+			if err := parseForm(writer, request, maxFormSize); err != nil {
+				formError(writer, err)
+				return
+			}
+			switch {
+			case request.Form.Has("open"):
+				id := survey.SurveyId(request.FormValue("open"))
+				if _, ok := s.GetRunningSurvey(userId, id); !ok {
+					http.Error(writer, "Diese Umfrage existiert nicht!", http.StatusNotFound)
+					return
+				}
+				setSurveyCookie(writer, id)
+				s.DeleteDraft(userId)
+				http.Redirect(writer, request, "/", http.StatusSeeOther)
+			case request.Form.Has("new"):
+				// without a survey id the next start creates a new survey
+				setSurveyCookie(writer, "")
+				s.DeleteDraft(userId)
+				http.Redirect(writer, request, "/", http.StatusSeeOther)
+			case request.Form.Has("close"):
+				s.Clear(survey.SurveyId(request.FormValue("close")), userId)
+				http.Redirect(writer, request, "/my/", http.StatusSeeOther)
+			default:
+				http.Error(writer, "unknown action", http.StatusBadRequest)
+			}
+			return
+		}
+
+		d := MyData{
+			Surveys: s.ListByUser(userId),
+			Current: GetSurveyId(writer, request),
+		}
+		err := localTemplate(request, myTemp).Execute(writer, d)
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
+
+// setSurveyCookie sets the survey controlled by the create form. An empty
+// id deletes the cookie.
+func setSurveyCookie(writer http.ResponseWriter, id survey.SurveyId) {
+	c := &http.Cookie{
+		Name:  "sid",
+		Value: string(id),
+		Path:  "/",
+	}
+	if id == "" {
+		c.MaxAge = -1
+	}
+	http.SetCookie(writer, c)
+}
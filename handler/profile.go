@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	profilePrefix     = "/debug/pprof/"
+	maxProfileSeconds = 60
+)
+
+// Profile serves the runtime profiles in the format of net/http/pprof, so
+// they can be analyzed by "go tool pprof". The package net/http/pprof is not
+// used because it registers its handlers without any access control.
+// The CPU profile is available as "profile?seconds=N".
+func Profile(writer http.ResponseWriter, request *http.Request) {
+	name := strings.TrimPrefix(request.URL.Path, profilePrefix)
+	switch name {
+	case "":
+		writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		for _, p := range pprof.Profiles() {
+			fmt.Fprintf(writer, "%s %d\n", p.Name(), p.Count())
+		}
+		fmt.Fprintf(writer, "profile\n")
+	case "profile":
+		seconds, err := strconv.Atoi(request.URL.Query().Get("seconds"))
+		if err != nil || seconds <= 0 || seconds > maxProfileSeconds {
+			seconds = 30
+		}
+		// the cpu profile takes longer than the server's write timeout
+		deadline := time.Now().Add(time.Duration(seconds+10) * time.Second)
+		if err := http.NewResponseController(writer).SetWriteDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			log.Println("could not extend write deadline:", err)
+		}
+		writer.Header().Set("Content-Type", "application/octet-stream")
+		writer.Header().Set("Content-Disposition", `attachment; filename="profile"`)
+		if err := pprof.StartCPUProfile(writer); err != nil {
+			http.Error(writer, "could not start cpu profile: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		select {
+		case <-time.After(time.Duration(seconds) * time.Second):
+		case <-request.Context().Done():
+		}
+		pprof.StopCPUProfile()
+	default:
+		p := pprof.Lookup(name)
+		if p == nil {
+			http.NotFound(writer, request)
+			return
+		}
+		debug, _ := strconv.Atoi(request.URL.Query().Get("debug"))
+		if name == "heap" && request.URL.Query().Get("gc") != "" {
+			runtime.GC()
+		}
+		if debug > 0 {
+			writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		} else {
+			writer.Header().Set("Content-Type", "application/octet-stream")
+			writer.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+		}
+		if err := p.WriteTo(writer, debug); err != nil {
+			log.Println(err)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile(t *testing.T) {
+	w := httptest.NewRecorder()
+	Profile(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	assert.Contains(t, w.Body.String(), "goroutine")
+
+	w = httptest.NewRecorder()
+	Profile(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/goroutine?debug=1", nil))
+	assert.Contains(t, w.Body.String(), "goroutine profile")
+
+	w = httptest.NewRecorder()
+	Profile(w, httptest.NewRequest(http.MethodGet, "/debug/pprof/unknown", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
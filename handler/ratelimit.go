@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// sweepInterval is the interval in which the buckets of the clients which
+// have not sent requests for a while are removed
+const sweepInterval = time.Minute
+
+// bucket contains the tokens of a single client
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a token bucket per client address. Every request takes a
+// token, the tokens are refilled at a constant rate up to the burst.
+type rateLimiter struct {
+	mutex     sync.Mutex
+	buckets   map[string]*bucket
+	perSecond float64
+	burst     float64
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func newRateLimiter(perMinute, burst int) *rateLimiter {
+	if burst <= 0 {
+		burst = perMinute
+	}
+	return &rateLimiter{
+		buckets:   make(map[string]*bucket),
+		perSecond: float64(perMinute) / 60,
+		burst:     float64(burst),
+		now:       time.Now,
+	}
+}
+
+// take takes a token of the client. If there is none left, false and
+// the time until the next token is available are returned.
+func (l *rateLimiter) take(client string) (bool, time.Duration) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) > sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	} else {
+		b.tokens = l.refill(b, now)
+		b.last = now
+	}
+	if b.tokens < 1 {
+		wait := time.Duration((1 - b.tokens) / l.perSecond * float64(time.Second))
+		return false, wait
+	}
+	b.tokens--
+	return true, 0
+}
+
+func (l *rateLimiter) refill(b *bucket, now time.Time) float64 {
+	return math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.perSecond)
+}
+
+// sweep removes the buckets which are full again, they behave like the
+// bucket of a new client
+func (l *rateLimiter) sweep(now time.Time) {
+	for client, b := range l.buckets {
+		if l.refill(b, now) >= l.burst {
+			delete(l.buckets, client)
+		}
+	}
+	l.lastSweep = now
+}
+
+// RateLimit restricts the number of requests of a single client address
+// to perMinute requests per minute, short bursts of up to burst requests
+// are allowed. If the limit is exceeded, the request is rejected with the
+// status 429 and a Retry-After header. If burst is zero, it equals
+// perMinute. If perMinute is zero, the requests are not limited.
+// The clients are identified by their address, so all clients behind a
+// NAT or a reverse proxy share the limit.
+func RateLimit(perMinute, burst int) func(http.HandlerFunc) http.HandlerFunc {
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		if perMinute <= 0 {
+			return handler
+		}
+		l := newRateLimiter(perMinute, burst)
+		return func(writer http.ResponseWriter, request *http.Request) {
+			if ok, wait := l.take(remoteNetwork(request)); !ok {
+				writer.Header().Set("Retry-After", strconv.Itoa(int((wait+time.Second-1)/time.Second)))
+				http.Error(writer, "too many requests", http.StatusTooManyRequests)
+				return
+			}
+			handler(writer, request)
+		}
+	}
+}
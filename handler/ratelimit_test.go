@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Now()
+	l := newRateLimiter(60, 3)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		ok, _ := l.take("a")
+		assert.True(t, ok)
+	}
+	ok, wait := l.take("a")
+	assert.False(t, ok)
+	assert.Equal(t, time.Second, wait)
+
+	// other clients are not affected
+	ok, _ = l.take("b")
+	assert.True(t, ok)
+
+	now = now.Add(time.Second)
+	ok, _ = l.take("a")
+	assert.True(t, ok)
+	ok, _ = l.take("a")
+	assert.False(t, ok)
+
+	// the buckets of idle clients are removed
+	now = now.Add(sweepInterval + time.Second)
+	l.take("c")
+	assert.Len(t, l.buckets, 1)
+}
+
+func TestRateLimit(t *testing.T) {
+	h := RateLimit(1, 2)(func(writer http.ResponseWriter, request *http.Request) {})
+	request := func(addr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/vote/", nil)
+		r.RemoteAddr = addr
+		w := httptest.NewRecorder()
+		h(w, r)
+		return w
+	}
+
+	assert.Equal(t, http.StatusOK, request("192.168.1.2:1234").Code)
+	assert.Equal(t, http.StatusOK, request("192.168.1.2:1235").Code)
+	w := request("192.168.1.2:1236")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, request("192.168.1.3:1234").Code)
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	h := RateLimit(0, 0)(func(writer http.ResponseWriter, request *http.Request) {})
+	for i := 0; i < 100; i++ {
+		w := httptest.NewRecorder()
+		h(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+}
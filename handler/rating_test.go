@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoteRestRating(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Rating: 5})
+	assert.NoError(t, err)
+
+	rate := func(voter, value string) {
+		r := httptest.NewRequest(http.MethodGet, "/voteRest/?id="+string(sid)+"&n=1&s="+value, nil)
+		r = r.WithContext(context.WithValue(r.Context(), "id", voter))
+		w := httptest.NewRecorder()
+		VoteRest(s)(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+
+	rate("a", "6")
+	assert.False(t, s.HasVoted(sid, "a"))
+	rate("a", "x")
+	assert.False(t, s.HasVoted(sid, "a"))
+	rate("a", "5")
+	assert.True(t, s.HasVoted(sid, "a"))
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.Equal(t, 1, r.Result[4].VoteCount())
+	assert.Equal(t, 5.0, r.Rating.Mean)
+}
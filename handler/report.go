@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"flashSurvey/survey"
+	"log"
+	"net/http"
+	"time"
+)
+
+// ReportData contains all questions of a presenter session
+type ReportData struct {
+	Rounds []survey.Round
+	// Votes is the number of votes of all questions
+	Votes int
+	// Start is the start of the first question
+	Start time.Time
+	// Created is the time the report was created
+	Created time.Time
+	// Locale is used to format the numbers
+	Locale string
+}
+
+// Questions returns the number of questions of the session
+func (r ReportData) Questions() int {
+	return len(r.Rounds)
+}
+
+// AverageVotes returns the average number of votes per question
+func (r ReportData) AverageVotes() string {
+	if len(r.Rounds) == 0 {
+		return "0"
+	}
+	return survey.FormatNumber(float64(r.Votes)/float64(len(r.Rounds)), 1, r.Locale)
+}
+
+// reportData returns the completed questions and the running question if
+// its result is visible
+func reportData(s *survey.Surveys, userId survey.UserId, surveyId survey.SurveyId, request *http.Request) (ReportData, error) {
+	rounds, err := s.History(userId, surveyId)
+	if err != nil {
+		return ReportData{}, err
+	}
+	now := time.Now()
+	current := s.GetResult(userId, surveyId)
+	if _, err := exportResult(current, now); err == nil {
+		current.QRCode = ""
+		current.Expires = time.Time{}
+		rounds = append(rounds, survey.Round{Result: current, Ended: now})
+	}
+
+	d := ReportData{Rounds: rounds, Created: now, Locale: resultLocale(current, request)}
+	for i, r := range rounds {
+		d.Votes += r.Result.Votes
+		rounds[i].Result = r.Result.Localize(d.Locale)
+	}
+	if len(rounds) > 0 {
+		d.Start = rounds[0].Result.Started
+	}
+	return d, nil
+}
+
+// Report shows all questions of the session in a printable page. It can
+// be saved as a PDF by the print dialog of the browser. If the query
+// parameter "format" is "zip", the page is downloaded together with the
+// results as a CSV file.
+func Report(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+
+		d, err := reportData(s, userId, surveyId, request)
+		if err != nil {
+			httpError(writer, request, err, errorStatus(err, http.StatusNotFound))
+			return
+		}
+
+		if request.URL.Query().Get("format") != "zip" {
+			err = inLanguage(d.Locale, reportTemp).Execute(writer, d)
+			if err != nil {
+				renderError(err)
+			}
+			return
+		}
+
+		var b bytes.Buffer
+		err = writeReportZip(&b, d)
+		if err != nil {
+			http.Error(writer, "could not create report: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/zip")
+		writer.Header().Set("Content-Disposition", `attachment; filename="bericht.zip"`)
+		_, err = writer.Write(b.Bytes())
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// writeReportZip writes the report page and the results as CSV to a zip archive
+func writeReportZip(b *bytes.Buffer, d ReportData) error {
+	z := zip.NewWriter(b)
+	w, err := z.Create("bericht.html")
+	if err != nil {
+		return err
+	}
+	err = inLanguage(d.Locale, reportTemp).Execute(w, d)
+	if err != nil {
+		return err
+	}
+
+	var results []ExportResult
+	for _, r := range d.Rounds {
+		if e, err := exportResult(r.Result, d.Created); err == nil {
+			results = append(results, e)
+		}
+	}
+	w, err = z.Create("bericht.csv")
+	if err != nil {
+		return err
+	}
+	err = writeCSV(w, results, d.Locale)
+	if err != nil {
+		return err
+	}
+	return z.Close()
+}
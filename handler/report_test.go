@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"flashSurvey/survey"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReport(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "First", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+	_, err = s.New("creator", sid, survey.SurveyQuestion{Title: "Second", Options: []string{"C", "D"}})
+	assert.NoError(t, err)
+
+	request := func(url string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		r.AddCookie(&http.Cookie{Name: "sid", Value: string(sid)})
+		r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+		w := httptest.NewRecorder()
+		Report(s)(w, r)
+		return w
+	}
+
+	// the running question is hidden, so only the completed one is shown
+	w := request("/report/")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "First")
+	assert.NotContains(t, w.Body.String(), "Second")
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	w = request("/report/")
+	assert.Contains(t, w.Body.String(), "Second")
+
+	w = request("/report/?format=zip")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+	z, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	assert.NoError(t, err)
+	files := map[string]string{}
+	for _, f := range z.File {
+		r, err := f.Open()
+		assert.NoError(t, err)
+		b, err := io.ReadAll(r)
+		assert.NoError(t, err)
+		files[f.Name] = string(b)
+	}
+	assert.Contains(t, files["bericht.html"], "Second")
+	assert.Contains(t, files["bericht.csv"], "First")
+	assert.Contains(t, files["bericht.csv"], "Second")
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"flashSurvey/account"
+	"flashSurvey/randid"
+	"flashSurvey/survey"
+	"log"
+	"net/http"
+)
+
+type ResetData struct {
+	Running bool
+	Account string
+}
+
+// ResetIdentity replaces the identity of the browser by a new one after a
+// confirmation. This is intended for shared computers, so the next user
+// does not control the surveys of the previous one. If the form value
+// "clear" is set, the running survey is deleted, otherwise it keeps
+// running until it times out.
+func ResetIdentity(s *survey.Surveys, a *account.Accounts) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+
+		if request.Method == http.MethodPost {
+			if err := parseForm(writer, request, maxFormSize); err != nil {
+				formError(writer, err)
+				return
+			}
+			if request.FormValue("clear") != "" {
+				s.Clear(surveyId, userId)
+			}
+			s.DeleteDraft(userId)
+			a.Logout(string(userId))
+
+			for _, name := range []string{"sid", passkeySessionCookie} {
+				http.SetCookie(writer, &http.Cookie{
+					Name:   name,
+					Path:   "/",
+					MaxAge: -1,
+				})
+			}
+			http.SetCookie(writer, &http.Cookie{
+				Name:  "uid",
+				Value: randid.String(),
+				Path:  "/",
+			})
+			log.Println("identity reset")
+			http.Redirect(writer, request, "/", http.StatusSeeOther)
+			return
+		}
+
+		d := ResetData{}
+		_, d.Running = s.GetRunningSurvey(userId, surveyId)
+		d.Account, _ = a.AccountOf(string(userId))
+		err := localTemplate(request, resetTemp).Execute(writer, d)
+		if err != nil {
+			renderError(err)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"context"
+	"flashSurvey/randid"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResetIdentity(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	request := func(method string, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/reset-identity", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		r.AddCookie(&http.Cookie{Name: "sid", Value: string(sid)})
+		r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+		w := httptest.NewRecorder()
+		ResetIdentity(s, nil)(w, r)
+		return w
+	}
+
+	w := request(http.MethodGet, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `name="clear"`)
+
+	w = request(http.MethodPost, "")
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	cookies := map[string]*http.Cookie{}
+	for _, c := range w.Result().Cookies() {
+		cookies[c.Name] = c
+	}
+	assert.Equal(t, -1, cookies["sid"].MaxAge)
+	assert.Len(t, cookies["uid"].Value, randid.Length())
+	assert.NotEqual(t, "creator", cookies["uid"].Value)
+	// the survey is kept
+	_, running := s.GetRunningSurvey("creator", sid)
+	assert.True(t, running)
+
+	request(http.MethodPost, "clear=true")
+	_, running = s.GetRunningSurvey("creator", sid)
+	assert.False(t, running)
+}
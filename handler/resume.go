@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"flashSurvey/survey"
+	"strconv"
+	"strings"
+)
+
+// resumeToken identifies the state a result page has shown last. It is
+// sent by the page when it reconnects after a network failure, so the
+// server can tell whether the page can simply continue or has missed a
+// new question or even a new survey and needs the whole state.
+type resumeToken struct {
+	surveyId survey.SurveyId
+	// number is the round, the number of the question
+	number  int
+	version int
+}
+
+func newResumeToken(surveyId survey.SurveyId, r survey.Result) string {
+	return resumeToken{surveyId: surveyId, number: r.Number, version: r.Version}.String()
+}
+
+func (t resumeToken) String() string {
+	return string(t.surveyId) + "." + strconv.Itoa(t.number) + "." + strconv.Itoa(t.version)
+}
+
+func parseResumeToken(s string) (resumeToken, bool) {
+	parts := strings.Split(s, ".")
+	if len(parts) != 3 || parts[0] == "" {
+		return resumeToken{}, false
+	}
+	number, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return resumeToken{}, false
+	}
+	version, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return resumeToken{}, false
+	}
+	return resumeToken{surveyId: survey.SurveyId(parts[0]), number: number, version: version}, true
+}
+
+// resumeVersion returns the version the client can continue with. If the
+// client has shown another survey or another question, -1 is returned, so
+// the current state is sent immediately.
+func resumeVersion(s *survey.Surveys, userId survey.UserId, surveyId survey.SurveyId, token string) int {
+	t, ok := parseResumeToken(token)
+	if !ok || t.surveyId != surveyId {
+		return -1
+	}
+	if s.GetResult(userId, surveyId).Number != t.number {
+		return -1
+	}
+	return t.version
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResumeToken(t *testing.T) {
+	tok := resumeToken{surveyId: "abc", number: 2, version: 7}
+	parsed, ok := parseResumeToken(tok.String())
+	assert.True(t, ok)
+	assert.Equal(t, tok, parsed)
+
+	for _, invalid := range []string{"", "abc", "abc.1", ".1.2", "abc.x.2", "abc.1.x"} {
+		_, ok := parseResumeToken(invalid)
+		assert.False(t, ok, invalid)
+	}
+}
+
+func TestResultRestResume(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	get := func(token string) ResultData {
+		r := httptest.NewRequest(http.MethodGet, "/resultRest/?d=1&r="+url.QueryEscape(token), nil)
+		r.AddCookie(&http.Cookie{Name: "sid", Value: string(sid)})
+		r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+		w := httptest.NewRecorder()
+		ResultRest(s)(w, r)
+		var d ResultData
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &d))
+		return d
+	}
+
+	first := get("")
+	assert.NotEmpty(t, first.Resume)
+
+	// a vote was missed while the client was offline
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+	d := get(first.Resume)
+	assert.NotNil(t, d.Delta)
+	assert.EqualValues(t, 1, d.Delta.Votes)
+
+	// the client has shown another survey, so the whole state is replayed at once
+	other := resumeToken{surveyId: "other", number: 1, version: 1000}.String()
+	d = get(other)
+	assert.Nil(t, d.Delta)
+	assert.NotEmpty(t, d.Result)
+
+	// a new question has been started in the meantime
+	_, err = s.New("creator", sid, survey.SurveyQuestion{Title: "Next", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	d = get(first.Resume)
+	assert.Nil(t, d.Delta)
+	assert.Contains(t, d.Title, "Next")
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// strictEscaping enables the audit of the html fragments which are
+// inserted into the result and the vote page by JavaScript. It is set
+// once at startup.
+var strictEscaping bool
+
+// SetStrictEscaping enables the strict escaping mode. In this mode the
+// bidi control characters are removed from the fragments, so voters can
+// not reverse the text shown on the projector, and every fragment is
+// checked to contain only the markup of its template. A fragment failing
+// the check is not sent.
+func SetStrictEscaping(strict bool) {
+	strictEscaping = strict
+}
+
+// fragmentTemplates are the templates whose output is inserted as html
+var fragmentTemplates = []string{"resultTable.html", "voteQuestion.html"}
+
+// renderFragment executes a template whose output is inserted as html by
+// JavaScript, in the strict mode the output is cleaned and audited
+func renderFragment(t *template.Template, data any) (string, error) {
+	var b bytes.Buffer
+	err := t.Execute(&b, data)
+	if err != nil {
+		return "", err
+	}
+	if !strictEscaping {
+		return b.String(), nil
+	}
+	html := removeBidi(b.String())
+	if err := auditFragment(html); err != nil {
+		return "", fmt.Errorf("unsafe markup in %s: %w", t.Name(), err)
+	}
+	return html, nil
+}
+
+// removeBidi removes the characters which change the direction of the text
+func removeBidi(s string) string {
+	if !strings.ContainsFunc(s, isBidi) {
+		return s
+	}
+	return strings.Map(func(r rune) rune {
+		if isBidi(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func isBidi(r rune) bool {
+	return unicode.Is(unicode.Bidi_Control, r)
+}
+
+var (
+	tagRegex      = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9]*)([^>]*)>`)
+	attrRegex     = regexp.MustCompile(`([^\s"'>/=]+)(?:\s*=\s*("[^"]*"|'[^']*'|[^\s"'>]+))?`)
+	actionRegex   = regexp.MustCompile(`{{.*?}}`)
+	functionRegex = regexp.MustCompile(`^\s*([A-Za-z_]+)\(`)
+	// handlerRegex matches the call of a function whose arguments can not
+	// contain any text of the survey
+	handlerRegex = regexp.MustCompile(`^\s*([A-Za-z_]+)\((\s*(this|-?\d+|'[a-z]*')\s*,?)*\)\s*;?\s*$`)
+	unsafeURL    = regexp.MustCompile(`(?i)^\s*(javascript|vbscript|data\s*:\s*text)`)
+)
+
+// fragmentMarkup contains the tags, the attributes and the functions called
+// by the event handlers which are used by the fragment templates
+type fragmentMarkup struct {
+	tags     map[string]bool
+	attrs    map[string]bool
+	handlers map[string]bool
+}
+
+var markup = sync.OnceValues(func() (fragmentMarkup, error) {
+	m := fragmentMarkup{tags: map[string]bool{}, attrs: map[string]bool{}, handlers: map[string]bool{}}
+	for _, name := range fragmentTemplates {
+		src, err := templateFS.ReadFile("templates/" + name)
+		if err != nil {
+			return m, err
+		}
+		// the actions are removed, so the static markup remains
+		static := actionRegex.ReplaceAllString(string(src), "")
+		for _, tag := range tagRegex.FindAllStringSubmatch(static, -1) {
+			m.tags[strings.ToLower(tag[2])] = true
+			for _, attr := range attrRegex.FindAllStringSubmatch(tag[3], -1) {
+				name := strings.ToLower(attr[1])
+				m.attrs[name] = true
+				if f := functionRegex.FindStringSubmatch(strings.Trim(attr[2], `"'`)); f != nil && strings.HasPrefix(name, "on") {
+					m.handlers[f[1]] = true
+				}
+			}
+		}
+	}
+	return m, nil
+})
+
+// auditFragment returns an error if the html contains a tag or an
+// attribute which is not used by the fragment templates, an event handler
+// which is more than the call of a known function, or a script url
+func auditFragment(html string) error {
+	m, err := markup()
+	if err != nil {
+		return err
+	}
+	for _, tag := range tagRegex.FindAllStringSubmatch(html, -1) {
+		name := strings.ToLower(tag[2])
+		if !m.tags[name] {
+			return fmt.Errorf("tag <%s> not allowed", name)
+		}
+		if tag[1] == "/" {
+			continue
+		}
+		for _, attr := range attrRegex.FindAllStringSubmatch(tag[3], -1) {
+			name := strings.ToLower(attr[1])
+			if !m.attrs[name] {
+				return fmt.Errorf("attribute %s not allowed", name)
+			}
+			value := strings.Trim(attr[2], `"'`)
+			if strings.HasPrefix(name, "on") {
+				call := handlerRegex.FindStringSubmatch(value)
+				if call == nil || !m.handlers[call[1]] {
+					return fmt.Errorf("event handler %q not allowed", value)
+				}
+			}
+			if (name == "href" || name == "src") && unsafeURL.MatchString(value) {
+				return fmt.Errorf("url %q not allowed", value)
+			}
+		}
+	}
+	return nil
+}
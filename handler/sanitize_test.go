@@ -0,0 +1,257 @@
+package handler
+
+import (
+	"bytes"
+	"flag"
+	"flashSurvey/randid"
+	"flashSurvey/survey"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "updates the golden files of the template tests")
+
+// attacks are the texts a voter or a creator may enter
+var attacks = []string{
+	`<script>alert(1)</script>`,
+	`"><img src=x onerror=alert(2)>`,
+	`' onmouseover='alert(3)`,
+	`javascript:alert(4)`,
+	"‮gnp.exe",
+	`</td></tr></table><script>alert(5)</script>`,
+	`{{.}}`,
+}
+
+// writeInAttack is the free text answer of a voter
+const writeInAttack = `<svg onload=alert(6)>`
+
+// payloads must never appear unescaped in the output
+var payloads = []string{"<script>alert", "<img src=x", "' onmouseover='", "</table><script>", "<svg"}
+
+var fixedTime = time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)
+
+// adversarialSurvey returns a survey whose texts are all attacks, if write-ins
+// are allowed, one of the votes is an attack as well
+func adversarialSurvey(t *testing.T, q survey.SurveyQuestion) (*survey.Surveys, survey.SurveyId) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", survey.SurveyId(strings.Repeat("s", randid.Length())), q)
+	require.NoError(t, err)
+	if q.Ranked {
+		require.NoError(t, s.Vote(sid, "v1", []int{6, 5, 4, 3, 2, 1, 0}, 1))
+	} else {
+		require.NoError(t, s.Vote(sid, "v1", []int{0}, 1))
+		require.NoError(t, s.Vote(sid, "v2", []int{1}, 1))
+	}
+	if q.WriteIn {
+		require.NoError(t, s.VoteWriteIn(sid, "v3", nil, writeInAttack, 1))
+	}
+	return s, sid
+}
+
+func adversarialQuestion() survey.SurveyQuestion {
+	return survey.SurveyQuestion{
+		Title:   attacks[1] + " " + attacks[4],
+		Options: attacks,
+		Groups:  attacks,
+		WriteIn: true,
+	}
+}
+
+func uncoveredResult(t *testing.T) survey.Result {
+	s, sid := adversarialSurvey(t, adversarialQuestion())
+	require.NoError(t, s.Uncover("creator", sid))
+	require.NoError(t, s.Annotate("creator", sid, attacks[2]))
+	return fixTimes(s.GetResult("creator", sid))
+}
+
+func fixTimes(r survey.Result) survey.Result {
+	r.Started = fixedTime
+	r.QRCode = ""
+	return r
+}
+
+// goldenCases renders the templates showing texts of the creator or the
+// voters, the key is the name of the golden file
+func goldenCases() map[string]func(t *testing.T) (*template.Template, any) {
+	voteData := func(q survey.SurveyQuestion) VoteData {
+		d := newVoteData(survey.Question{Number: 1, SurveyId: "sid", Question: q}, attacks[0], "de")
+		d.Key = attacks[1]
+		d.Code = attacks[2]
+		d.CodeRequired = true
+		d.Series = survey.Series{Position: 3, Total: 8}
+		return d
+	}
+	return map[string]func(t *testing.T) (*template.Template, any){
+		"resultTable": func(t *testing.T) (*template.Template, any) {
+			return resultTableTemp, uncoveredResult(t)
+		},
+		"resultTableHidden": func(t *testing.T) (*template.Template, any) {
+			s, sid := adversarialSurvey(t, adversarialQuestion())
+			return resultTableTemp, fixTimes(s.GetResult("creator", sid))
+		},
+		"resultTableRanked": func(t *testing.T) (*template.Template, any) {
+			q := adversarialQuestion()
+			q.WriteIn = false
+			q.Ranked = true
+			q.Display.Chart = "pie"
+			s, sid := adversarialSurvey(t, q)
+			require.NoError(t, s.Uncover("creator", sid))
+			return resultTableTemp, fixTimes(s.GetResult("creator", sid))
+		},
+		"result": func(t *testing.T) (*template.Template, any) {
+			return resultTemp, dataFromResult(uncoveredResult(t), "de")
+		},
+		"voteQuestion": func(t *testing.T) (*template.Template, any) {
+			return voteQuestionTemp, voteData(adversarialQuestion())
+		},
+		"voteQuestionMultiple": func(t *testing.T) (*template.Template, any) {
+			q := adversarialQuestion()
+			q.Multiple = true
+			return voteQuestionTemp, voteData(q)
+		},
+		"voteQuestionRanked": func(t *testing.T) (*template.Template, any) {
+			q := adversarialQuestion()
+			q.Ranked = true
+			return voteQuestionTemp, voteData(q)
+		},
+		"vote": func(t *testing.T) (*template.Template, any) {
+			return voteTemp, voteData(adversarialQuestion())
+		},
+		"create": func(t *testing.T) (*template.Template, any) {
+			return createTemp, CreateData{
+				SurveyID:       "sid",
+				Question:       adversarialQuestion(),
+				IdempotencyKey: attacks[3],
+				Hosts:          attacks,
+				VoteKey:        attacks[1],
+				Account:        attacks[0],
+				Running:        true,
+				ThankYou:       survey.ThankYou{Message: attacks[5], Redirect: attacks[1], Context: true},
+				Series:         survey.Series{Position: 2, Total: 5},
+				Advance:        survey.Advance{Seconds: 10, Queued: 2},
+			}
+		},
+		"voteNotify": func(t *testing.T) (*template.Template, any) {
+			return voteNotifyTemp, VoteNotifyData{Lang: "de", ThankYou: survey.ThankYou{Message: attacks[0], Redirect: attacks[3]}, Redirect: attacks[3]}
+		},
+		"history": func(t *testing.T) (*template.Template, any) {
+			r := uncoveredResult(t)
+			return historyTemp, HistoryData{Rounds: []survey.Round{{Result: r, Ended: fixedTime}}}
+		},
+		"report": func(t *testing.T) (*template.Template, any) {
+			r := uncoveredResult(t)
+			return reportTemp, ReportData{
+				Rounds:  []survey.Round{{Result: r, Ended: fixedTime}},
+				Created: fixedTime,
+				Start:   fixedTime,
+				Votes:   r.Votes,
+				Locale:  "de",
+			}
+		},
+	}
+}
+
+// withoutVoterText are the templates which show no text entered by the
+// creator or the voters, a new template has to be added to the golden
+// cases or to this list
+var withoutVoterText = []string{
+	"", "admin.html", "backup.html", "banner.html", "bannerEdit.html", "browse.html", "calendar.html",
+	"carousel.html", "codes.html", "dashboard.html", "finished.html", "footer.html",
+	"forbidden.html", "handover.html", "legal.html", "login.html", "meeting.html", "move.html", "my.html",
+	"passkey.html", "register.html", "reset.html", "resubmit.html", "roles.html",
+	"sessions.html", "split.html",
+}
+
+func TestTemplatesGolden(t *testing.T) {
+	for name, c := range goldenCases() {
+		t.Run(name, func(t *testing.T) {
+			temp, data := c(t)
+			var b bytes.Buffer
+			require.NoError(t, temp.Execute(&b, data))
+			out := b.String()
+
+			for _, p := range payloads {
+				assert.NotContains(t, out, p)
+			}
+
+			golden := filepath.Join("testdata", "golden", name+".html")
+			if *updateGolden {
+				require.NoError(t, os.MkdirAll(filepath.Dir(golden), 0o755))
+				require.NoError(t, os.WriteFile(golden, b.Bytes(), 0o644))
+			}
+			expected, err := os.ReadFile(golden)
+			require.NoError(t, err, "run go test ./handler -update to create the golden files")
+			assert.Equal(t, string(expected), out)
+		})
+	}
+}
+
+func TestTemplatesCovered(t *testing.T) {
+	covered := map[string]bool{}
+	for _, c := range goldenCases() {
+		temp, _ := c(t)
+		covered[temp.Name()] = true
+	}
+	for _, name := range withoutVoterText {
+		covered[name] = true
+	}
+	for _, temp := range Templates.Templates() {
+		assert.True(t, covered[temp.Name()], "template %s is not tested with adversarial texts", temp.Name())
+	}
+}
+
+func TestStrictEscaping(t *testing.T) {
+	SetStrictEscaping(true)
+	defer SetStrictEscaping(false)
+
+	r := uncoveredResult(t)
+	table, err := renderFragment(resultTableTemp, r.Localize("de"))
+	require.NoError(t, err)
+	assert.NotContains(t, table, "‮")
+
+	d := dataFromResult(r, "de")
+	assert.NotContains(t, string(d.Title), "‮")
+	assert.Equal(t, template.HTML(table), d.Result)
+
+	// the markup of all fragments passes the audit
+	for name, c := range goldenCases() {
+		temp, data := c(t)
+		if temp == resultTableTemp || temp == voteQuestionTemp {
+			html, err := renderFragment(temp, data)
+			assert.NoError(t, err, name)
+			assert.NotContains(t, html, "‮", name)
+		}
+	}
+}
+
+func TestAuditFragment(t *testing.T) {
+	tests := []struct {
+		html string
+		safe bool
+	}{
+		{html: `<table class="main"><tr><td class="title">&lt;script&gt;</td></tr></table>`, safe: true},
+		{html: `<button onclick="reveal(this, 'next')">x</button>`, safe: true},
+		{html: `<button onclick="vote( 1 , 2 );">x</button>`, safe: true},
+		{html: `<script>alert(1)</script>`, safe: false},
+		{html: `<img src="x">`, safe: false},
+		{html: `<td onmouseover="alert(1)">`, safe: false},
+		{html: `<button onclick="alert(1)">`, safe: false},
+		{html: `<button onclick="vote(document.cookie)">`, safe: false},
+		{html: `<td class="x" formaction="y">`, safe: false},
+	}
+	for _, test := range tests {
+		err := auditFragment(test.html)
+		if test.safe {
+			assert.NoError(t, err, test.html)
+		} else {
+			assert.Error(t, err, test.html)
+		}
+	}
+}
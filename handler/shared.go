@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"flashSurvey/randid"
+	"flashSurvey/survey"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// activityCookie contains the signed time of the last activity of the creator
+const activityCookie = "act"
+
+// SharedComputer implements the shared computer mode intended for podium
+// computers used by many creators per day. The identity of a browser
+// expires if it was not used to control a survey for the idle time. The
+// surveys keep running and can still be controlled by the devices the
+// survey was passed on to. As the signing key is created at startup, all
+// identities expire if the server is restarted.
+type SharedComputer struct {
+	idle   time.Duration
+	secret []byte
+}
+
+// NewSharedComputer creates the shared computer mode with the given idle time
+func NewSharedComputer(idle time.Duration) *SharedComputer {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(err)
+	}
+	return &SharedComputer{idle: idle, secret: secret}
+}
+
+// Active returns a middleware which expires the identity if it was idle
+// and records the request as an activity of the creator. It must be used
+// inside of EnsureUserId.
+func (sc *SharedComputer) Active(handler http.HandlerFunc) http.HandlerFunc {
+	return sc.middleware(handler, true)
+}
+
+// Passive returns a middleware which expires the identity if it was idle
+// but does not record an activity. It is used for the result updates, so
+// an open result page does not keep the identity alive.
+func (sc *SharedComputer) Passive(handler http.HandlerFunc) http.HandlerFunc {
+	return sc.middleware(handler, false)
+}
+
+func (sc *SharedComputer) middleware(handler http.HandlerFunc, active bool) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := string(GetUserId(request))
+		now := time.Now()
+		// the identity is passed on explicitly by the transfer QR code
+		if !request.URL.Query().Has("tuid") && !sc.valid(userId, getCookie(request, activityCookie), now) {
+			userId = randid.String()
+			http.SetCookie(writer, &http.Cookie{
+				Name:  "uid",
+				Value: userId,
+				Path:  "/",
+			})
+			http.SetCookie(writer, &http.Cookie{
+				Name:   "sid",
+				Path:   "/",
+				MaxAge: -1,
+			})
+			log.Println("idle creator identity expired, issuing new user id")
+			// the survey of the former identity is not used by this request
+			ctx := context.WithValue(request.Context(), "id", userId)
+			ctx = context.WithValue(ctx, "sid", survey.SurveyId(""))
+			request = request.WithContext(ctx)
+			active = true
+		}
+		if active {
+			http.SetCookie(writer, &http.Cookie{
+				Name:     activityCookie,
+				Value:    sc.sign(userId, now),
+				Path:     "/",
+				HttpOnly: true,
+			})
+		}
+		handler(writer, request)
+	}
+}
+
+// valid returns true if the activity cookie belongs to the user and is not older than the idle time
+func (sc *SharedComputer) valid(userId, cookie string, now time.Time) bool {
+	ts, _, ok := strings.Cut(cookie, ".")
+	if !ok || !hmac.Equal([]byte(cookie), []byte(sc.signUnix(userId, ts))) {
+		return false
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false
+	}
+	return now.Sub(time.Unix(unix, 0)) <= sc.idle
+}
+
+func (sc *SharedComputer) sign(userId string, t time.Time) string {
+	return sc.signUnix(userId, strconv.FormatInt(t.Unix(), 10))
+}
+
+func (sc *SharedComputer) signUnix(userId, ts string) string {
+	mac := hmac.New(sha256.New, sc.secret)
+	mac.Write([]byte(userId))
+	mac.Write([]byte{0})
+	mac.Write([]byte(ts))
+	return ts + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:18])
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSharedComputer(t *testing.T) {
+	sc := NewSharedComputer(10 * time.Minute)
+
+	var seen string
+	h := sc.Active(func(writer http.ResponseWriter, request *http.Request) {
+		seen = string(GetUserId(request)) + "/" + string(GetSurveyId(writer, request))
+	})
+	request := func(userId, activity string, query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/"+query, nil)
+		r.AddCookie(&http.Cookie{Name: "sid", Value: "survey"})
+		if activity != "" {
+			r.AddCookie(&http.Cookie{Name: activityCookie, Value: activity})
+		}
+		r = r.WithContext(context.WithValue(r.Context(), "id", userId))
+		w := httptest.NewRecorder()
+		h(w, r)
+		return w
+	}
+	cookie := func(w *httptest.ResponseRecorder, name string) string {
+		for _, c := range w.Result().Cookies() {
+			if c.Name == name {
+				return c.Value
+			}
+		}
+		return ""
+	}
+
+	now := time.Now()
+	w := request("creator", sc.sign("creator", now.Add(-5*time.Minute)), "")
+	assert.Equal(t, "creator/survey", seen)
+	assert.Empty(t, cookie(w, "uid"))
+	assert.True(t, sc.valid("creator", cookie(w, activityCookie), now))
+
+	// idle for too long
+	w = request("creator", sc.sign("creator", now.Add(-11*time.Minute)), "")
+	newId := cookie(w, "uid")
+	assert.NotEmpty(t, newId)
+	assert.Equal(t, newId+"/", seen)
+	assert.True(t, sc.valid(newId, cookie(w, activityCookie), now))
+
+	// the activity of another identity
+	request("creator", sc.sign("other", now), "")
+	assert.NotEqual(t, "creator/survey", seen)
+	// no activity recorded
+	request("creator", "", "")
+	assert.NotEqual(t, "creator/survey", seen)
+
+	// passed on by the transfer QR code
+	request("creator", "", "?tuid=creator&tsid=survey")
+	assert.Equal(t, "creator/survey", seen)
+
+	// a passive request does not record an activity
+	w = httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.AddCookie(&http.Cookie{Name: activityCookie, Value: sc.sign("creator", now)})
+	r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+	sc.Passive(func(http.ResponseWriter, *http.Request) {})(w, r)
+	assert.Empty(t, w.Result().Cookies())
+}
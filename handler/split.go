@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"encoding/json"
+	"flashSurvey/survey"
+	"html/template"
+	"log"
+	"net/http"
+)
+
+// SplitCurrent is the running question shown on the split view
+type SplitCurrent struct {
+	Title template.HTML `json:"Title"`
+	Votes int           `json:"Votes"`
+	// Result is only sent if the result is uncovered
+	Result    template.HTML `json:"Result,omitempty"`
+	Hidden    bool          `json:"Hidden"`
+	Encrypted bool          `json:"Encrypted"`
+}
+
+// SplitPrevious is the last completed question shown on the split view
+type SplitPrevious struct {
+	Title template.HTML `json:"Title"`
+	// Result is only sent if the result was uncovered
+	Result    template.HTML `json:"Result,omitempty"`
+	Uncovered bool          `json:"Uncovered"`
+	Encrypted bool          `json:"Encrypted"`
+}
+
+// SplitData is the state of the split view
+type SplitData struct {
+	Version  int            `json:"Version"`
+	Current  SplitCurrent   `json:"Current"`
+	Previous *SplitPrevious `json:"Previous,omitempty"`
+}
+
+// Split shows the number of votes of the running question next to the
+// result of the previous question, so the discussion of the last answer
+// can continue while the votes of the next question are collected.
+func Split(writer http.ResponseWriter, request *http.Request) {
+	err := localTemplate(request, splitTemp).Execute(writer, nil)
+	if err != nil {
+		renderError(err)
+	}
+}
+
+// SplitRest returns the state of the split view
+func SplitRest(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+
+		result := s.GetResult(userId, surveyId)
+		if result.Version < 0 {
+			http.Error(writer, survey.ErrSurveyNotFound.Error(), http.StatusNotFound)
+			return
+		}
+		d := dataFromResult(result, resultLocale(result, request))
+		data := SplitData{
+			Version: result.Version,
+			Current: SplitCurrent{
+				Title:     d.Title,
+				Votes:     result.Votes,
+				Hidden:    result.Covered(),
+				Encrypted: result.Encrypted,
+			},
+		}
+		if !data.Current.Hidden {
+			data.Current.Result = d.Result
+		}
+		if round, ok := s.Previous(userId, surveyId); ok {
+			p := dataFromResult(round.Result, resultLocale(round.Result, request))
+			data.Previous = &SplitPrevious{
+				Title:     p.Title,
+				Uncovered: round.Uncovered,
+				Encrypted: round.Result.Encrypted,
+			}
+			if round.Uncovered {
+				data.Previous.Result = p.Result
+			}
+		}
+
+		jsonData, err := json.Marshal(data)
+		if err != nil {
+			http.Error(writer, "could not marshal split view: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		_, err = writer.Write(jsonData)
+		if err != nil {
+			log.Println(err)
+		}
+	}
+}
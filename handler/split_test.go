@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRest(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "First", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+
+	get := func() (int, SplitData) {
+		r := httptest.NewRequest(http.MethodGet, "/splitRest/?tsid="+string(sid), nil)
+		r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+		w := httptest.NewRecorder()
+		SplitRest(s)(w, r)
+		var d SplitData
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &d))
+		}
+		return w.Code, d
+	}
+
+	code, d := get()
+	assert.Equal(t, http.StatusOK, code)
+	assert.Nil(t, d.Previous)
+	assert.True(t, d.Current.Hidden)
+
+	require.NoError(t, s.Vote(sid, "voter", []int{0}, 1))
+	require.NoError(t, s.Uncover("creator", sid))
+	_, err = s.New("creator", sid, survey.SurveyQuestion{Title: "Second", Options: []string{"C", "D"}})
+	require.NoError(t, err)
+	require.NoError(t, s.Vote(sid, "voter", []int{1}, 2))
+
+	_, d = get()
+	assert.Equal(t, "Second", string(d.Current.Title))
+	assert.Equal(t, 1, d.Current.Votes)
+	assert.True(t, d.Current.Hidden)
+	assert.Empty(t, d.Current.Result)
+	require.NotNil(t, d.Previous)
+	assert.Equal(t, "First", string(d.Previous.Title))
+	assert.True(t, d.Previous.Uncovered)
+	assert.Contains(t, string(d.Previous.Result), "A")
+
+	// a result which was never uncovered is not shown
+	_, err = s.New("creator", sid, survey.SurveyQuestion{Title: "Third", Options: []string{"E", "F"}})
+	require.NoError(t, err)
+	_, d = get()
+	assert.Equal(t, "Second", string(d.Previous.Title))
+	assert.False(t, d.Previous.Uncovered)
+	assert.Empty(t, d.Previous.Result)
+
+	sid = "unknown"
+	code, _ = get()
+	assert.Equal(t, http.StatusNotFound, code)
+}
@@ -39,3 +39,113 @@ function hidePopUp() {
         }
     }
 }
+
+function textInputs(form) {
+    return form.querySelectorAll('input[type="text"]');
+}
+
+// The title and the options of an end-to-end encrypted survey are decrypted
+// after the page is loaded and encrypted again before the form is sent.
+document.addEventListener("DOMContentLoaded", () => {
+    const form = document.getElementById("form");
+    const e2e = document.getElementById("e2e");
+    const publicKey = document.getElementById("publicKey");
+    if (!form || !e2e || !publicKey) {
+        return;
+    }
+    if (publicKey.value) {
+        e2eKeys(false).then(async function (keys) {
+            if (!keys) {
+                return;
+            }
+            for (const input of textInputs(form)) {
+                if (input.value) {
+                    try {
+                        input.value = await e2eDecryptText(keys, input.value);
+                    } catch (e) {
+                        console.log("could not decrypt " + input.name);
+                    }
+                }
+            }
+            document.title = document.getElementById("title").value;
+        });
+    }
+    form.addEventListener("submit", async function (evt) {
+        if (!e2e.checked) {
+            publicKey.value = "";
+            return;
+        }
+        evt.preventDefault();
+        const list = document.getElementById("optionList");
+        if (list && list.value.trim()) {
+            alert(texts.encryptedList);
+            return;
+        }
+        for (const input of textInputs(form)) {
+            if (input.value.length > 100) {
+                alert(texts.tooLong);
+                return;
+            }
+        }
+        const keys = await e2eKeys(true);
+        publicKey.value = keys.publicKey;
+        const plain = [];
+        for (const input of textInputs(form)) {
+            plain.push(input.value);
+            if (input.value) {
+                input.value = await e2eEncryptText(keys, input.value);
+            }
+        }
+        // form.submit() does not send the button which was clicked
+        if (evt.submitter && evt.submitter.name) {
+            const button = document.createElement("input");
+            button.type = "hidden";
+            button.name = evt.submitter.name;
+            button.value = evt.submitter.value;
+            form.appendChild(button);
+        }
+        // form.submit() does not use the target of the button either
+        form.target = evt.submitter ? evt.submitter.formTarget : "";
+        form.submit();
+        if (form.target) {
+            // the page stays open, e.g. for the preview, so the texts are shown again
+            textInputs(form).forEach(function (input, i) {
+                input.value = plain[i];
+            });
+            form.querySelectorAll('input[type="hidden"][name="' + evt.submitter.name + '"]').forEach(b => b.remove());
+            form.target = "";
+        }
+    });
+});
+
+// The content of the form is saved on the server while it is edited, so it
+// is not lost if the page is reloaded. The texts of end-to-end encrypted
+// surveys are not saved because they must not leave the browser unencrypted.
+document.addEventListener("DOMContentLoaded", () => {
+    const form = document.getElementById("form");
+    if (!form) {
+        return;
+    }
+    let timer;
+    const save = function () {
+        const e2e = document.getElementById("e2e");
+        if (e2e && e2e.checked) {
+            return;
+        }
+        fetch("/draft/", {method: "POST", body: new URLSearchParams(new FormData(form))})
+            .catch(function (error) {
+                console.log("could not save draft", error);
+            });
+    };
+    form.addEventListener("input", function () {
+        clearTimeout(timer);
+        timer = setTimeout(save, 1000);
+    });
+});
+
+function discardDraft() {
+    fetch("/draft/", {method: "DELETE"})
+        .then(function () {
+            window.location.href = "/";
+        });
+}
@@ -0,0 +1,77 @@
+// End-to-end encryption of surveys. The browser of the creator keeps an
+// AES key, which encrypts the title and the options, and a RSA key pair,
+// whose public key encrypts the ballots, in the local storage. Only the
+// public key is sent to the server, so the server can neither read the
+// question nor the result.
+
+const e2eStorageKey = "flashSurveyE2E";
+const e2eRsa = {name: "RSA-OAEP", hash: "SHA-256"};
+
+function e2eEncode(buf) {
+    let bin = "";
+    for (const b of new Uint8Array(buf)) {
+        bin += String.fromCharCode(b);
+    }
+    return btoa(bin);
+}
+
+function e2eDecode(str) {
+    const bin = atob(str);
+    const buf = new Uint8Array(bin.length);
+    for (let i = 0; i < bin.length; i++) {
+        buf[i] = bin.charCodeAt(i);
+    }
+    return buf;
+}
+
+// e2eKeys returns the keys of the creator. If create is set, missing keys are generated.
+async function e2eKeys(create) {
+    const stored = localStorage.getItem(e2eStorageKey);
+    if (stored) {
+        const k = JSON.parse(stored);
+        return {
+            aes: await crypto.subtle.importKey("jwk", k.aes, "AES-GCM", false, ["encrypt", "decrypt"]),
+            private: await crypto.subtle.importKey("jwk", k.private, e2eRsa, false, ["decrypt"]),
+            publicKey: k.publicKey
+        };
+    }
+    if (!create) {
+        return null;
+    }
+    const aes = await crypto.subtle.generateKey({name: "AES-GCM", length: 256}, true, ["encrypt", "decrypt"]);
+    const rsa = await crypto.subtle.generateKey({...e2eRsa, modulusLength: 2048, publicExponent: new Uint8Array([1, 0, 1])}, true, ["encrypt", "decrypt"]);
+    const k = {
+        aes: await crypto.subtle.exportKey("jwk", aes),
+        private: await crypto.subtle.exportKey("jwk", rsa.privateKey),
+        publicKey: e2eEncode(await crypto.subtle.exportKey("spki", rsa.publicKey))
+    };
+    localStorage.setItem(e2eStorageKey, JSON.stringify(k));
+    return e2eKeys(false);
+}
+
+async function e2eEncryptText(keys, text) {
+    const iv = crypto.getRandomValues(new Uint8Array(12));
+    const c = await crypto.subtle.encrypt({name: "AES-GCM", iv: iv}, keys.aes, new TextEncoder().encode(text));
+    const buf = new Uint8Array(iv.length + c.byteLength);
+    buf.set(iv);
+    buf.set(new Uint8Array(c), iv.length);
+    return e2eEncode(buf);
+}
+
+async function e2eDecryptText(keys, cipher) {
+    const buf = e2eDecode(cipher);
+    const p = await crypto.subtle.decrypt({name: "AES-GCM", iv: buf.slice(0, 12)}, keys.aes, buf.slice(12));
+    return new TextDecoder().decode(p);
+}
+
+// e2eEncryptBallot is used by the voters, it only requires the public key
+async function e2eEncryptBallot(publicKey, options) {
+    const key = await crypto.subtle.importKey("spki", e2eDecode(publicKey), e2eRsa, false, ["encrypt"]);
+    const c = await crypto.subtle.encrypt(e2eRsa, key, new TextEncoder().encode(JSON.stringify(options)));
+    return e2eEncode(c);
+}
+
+async function e2eDecryptBallot(keys, ballot) {
+    const p = await crypto.subtle.decrypt(e2eRsa, keys.private, e2eDecode(ballot));
+    return JSON.parse(new TextDecoder().decode(p));
+}
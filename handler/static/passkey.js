@@ -0,0 +1,74 @@
+function b64ToBuf(str) {
+    str = str.replace(/-/g, "+").replace(/_/g, "/");
+    while (str.length % 4) {
+        str += "=";
+    }
+    return Uint8Array.from(atob(str), c => c.charCodeAt(0)).buffer;
+}
+
+function bufToB64(buf) {
+    let str = "";
+    for (const b of new Uint8Array(buf)) {
+        str += String.fromCharCode(b);
+    }
+    return btoa(str).replace(/\+/g, "-").replace(/\//g, "_").replace(/=+$/, "");
+}
+
+function passkeyRest(action, body) {
+    return fetch("/passkeyRest/?a=" + action, {method: "POST", body: body})
+        .then(function (response) {
+            if (response.status !== 200) {
+                return response.text().then(function (text) {
+                    throw new Error(text);
+                });
+            }
+            return response.json();
+        });
+}
+
+function showPasskeyError(error) {
+    document.getElementById("error").textContent = texts.error + " " + error.message;
+}
+
+function registerPasskey() {
+    passkeyRest("registerBegin")
+        .then(function (options) {
+            options.challenge = b64ToBuf(options.challenge);
+            options.user.id = b64ToBuf(options.user.id);
+            for (const c of options.excludeCredentials) {
+                c.id = b64ToBuf(c.id);
+            }
+            return navigator.credentials.create({publicKey: options});
+        })
+        .then(function (cred) {
+            return passkeyRest("registerFinish", JSON.stringify({
+                id: bufToB64(cred.rawId),
+                clientDataJSON: bufToB64(cred.response.clientDataJSON),
+                attestationObject: bufToB64(cred.response.attestationObject)
+            }));
+        })
+        .then(function () {
+            window.location.reload();
+        })
+        .catch(showPasskeyError);
+}
+
+function loginPasskey() {
+    passkeyRest("loginBegin")
+        .then(function (options) {
+            options.challenge = b64ToBuf(options.challenge);
+            return navigator.credentials.get({publicKey: options});
+        })
+        .then(function (cred) {
+            return passkeyRest("loginFinish", JSON.stringify({
+                id: bufToB64(cred.rawId),
+                clientDataJSON: bufToB64(cred.response.clientDataJSON),
+                authenticatorData: bufToB64(cred.response.authenticatorData),
+                signature: bufToB64(cred.response.signature)
+            }));
+        })
+        .then(function () {
+            window.location.href = "/";
+        })
+        .catch(showPasskeyError);
+}
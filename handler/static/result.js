@@ -1,8 +1,86 @@
 let version = -1;
+// resume is the token of the last shown state, it is sent on reconnect
+let resume = "";
+// retryDelay is the delay of the next reconnect after a network failure
+let retryDelay = 1000;
+
+// endpoint returns the url of the given result endpoint. If the page is
+// shown by a viewer token, the token is passed on.
+function endpoint(name, query) {
+    if (location.pathname.startsWith("/display/")) {
+        return "/display" + name + "/" + location.search + (query ? "&" + query : "");
+    }
+    return "/result" + name + "/" + (query ? "?" + query : "");
+}
+
+// heartbeatInterval is the interval the open result page defers the timeout of the survey
+const heartbeatInterval = 60000;
+
+// heartbeat tells the server that the result is still shown, so the survey
+// is not deleted during a long presentation
+function heartbeat() {
+    fetch(endpoint("Heartbeat", ""), {method: "POST"})
+        .then(function (response) {
+            if (response.status !== 404) {
+                setTimeout(heartbeat, heartbeatInterval);
+            }
+        })
+        .catch(function () {
+            setTimeout(heartbeat, heartbeatInterval);
+        });
+}
+
+// start shows the result changes pushed via a WebSocket. If the WebSocket
+// can not be established, e.g. because of a proxy, the result is polled.
+function start() {
+    if (!window.WebSocket) {
+        reload();
+        return;
+    }
+    let url = (location.protocol === "https:" ? "wss://" : "ws://") + location.host +
+        endpoint("Ws", resume ? "r=" + encodeURIComponent(resume) : "");
+    let opened = false;
+    let done = false;
+    const ws = new WebSocket(url);
+    ws.onopen = function () {
+        opened = true;
+        retryDelay = 1000;
+    };
+    ws.onmessage = function (event) {
+        if (!show(JSON.parse(event.data))) {
+            done = true;
+            ws.close();
+        }
+    };
+    ws.onclose = function () {
+        if (done) {
+            return;
+        }
+        if (!opened) {
+            // WebSocket not available, use polling instead
+            reload();
+            return;
+        }
+        console.log("reconnect in " + retryDelay + "ms");
+        setTimeout(start, retryDelay);
+        retryDelay = Math.min(retryDelay * 2, 30000);
+    };
+}
 
 function reload() {
-    fetch("/resultRest/?v="+version)
+    let query = "v=" + version + "&d=1";
+    if (resume) {
+        query += "&r=" + encodeURIComponent(resume);
+    }
+    const url = endpoint("Rest", query);
+    fetch(url)
         .then(function (response) {
+            if (response.status === 503) {
+                // too many clients are waiting or the server restarts, try again later
+                const retry = parseInt(response.headers.get("Retry-After")) || 5;
+                setTimeout(reload, retry * 1000);
+                return;
+            }
             if (response.status !== 200) {
                 window.location.reload();
                 return;
@@ -10,24 +88,255 @@ function reload() {
             return response.text();
         })
         .catch(function (error) {
-            alert("Netzwerkfehler");
+            // network failure, e.g. a Wi-Fi drop, reconnect with increasing delay
+            console.log("reconnect in " + retryDelay + "ms", error);
+            setTimeout(reload, retryDelay);
+            retryDelay = Math.min(retryDelay * 2, 30000);
         })
         .then(function (json) {
-            let obj=JSON.parse(json)
-            if (obj.Title) {
-                document.getElementById("title").innerHTML = obj.Title;
-            }
-            if (obj.Result) {
-                document.getElementById("result").innerHTML = obj.Result;
-            }
-            if (obj.Version) {
-                if (obj.Version === -1) {
-                    // Survey was deleted, do not reload
-                    document.getElementById("qrCode").src = "";
-                    return;
-                }
-                version = obj.Version;
-            }
-            setTimeout(reload, 200);
+            if (json === undefined) {
+                return;
+            }
+            retryDelay = 1000;
+            if (show(JSON.parse(json))) {
+                setTimeout(reload, 200);
+            }
+        })
+}
+
+// show updates the page with the received result. It returns false if no
+// more updates are expected.
+function show(obj) {
+    if (obj.Resume) {
+        if (resume && resume.split(".")[0] !== obj.Resume.split(".")[0]) {
+            // another survey is running, the QR code has changed
+            window.location.reload();
+            return false;
+        }
+        resume = obj.Resume;
+    }
+    if (obj.Delta) {
+        applyDelta(obj.Delta);
+    } else if (obj.Encrypted) {
+        showEncrypted(obj);
+    } else if (obj.Title) {
+        document.getElementById("title").innerHTML = obj.Title;
+    }
+    if (obj.Result) {
+        document.getElementById("result").innerHTML = obj.Result;
+    }
+    if (obj.Version) {
+        if (obj.Version === -1) {
+            // Survey was deleted, do not reload
+            document.getElementById("qrCode").src = "";
+            version = -1;
+            return false;
+        }
+        version = obj.Version;
+    }
+    return true;
+}
+
+// formatNumber formats the number according to the language of the page
+function formatNumber(n, digits) {
+    return n.toLocaleString(document.documentElement.lang, {
+        minimumFractionDigits: digits,
+        maximumFractionDigits: digits
+    });
+}
+
+// applyDelta updates the result table with the changes since the last version
+function applyDelta(delta) {
+    document.getElementById("participants").textContent = delta.Votes;
+    const ticker = document.getElementById("ticker");
+    if (ticker) {
+        ticker.textContent = (delta.Votes === 1 ? texts.vote : texts.votes).replace("%v", delta.Votes);
+    }
+    const rows = document.querySelectorAll("#result tr.option");
+    const counts = [];
+    rows.forEach(function (row) {
+        // remove the digit grouping, a hidden count is not a number
+        counts.push(parseInt(row.querySelector(".votes").textContent.replace(/\D/g, "")));
+    });
+    if (delta.Changes) {
+        delta.Changes.forEach(function (c) {
+            counts[c.Option] = c.Votes;
+        });
+    }
+    if (counts.some(isNaN)) {
+        // result is hidden
+        return;
+    }
+    const sum = delta.Votes > 0 ? delta.Votes : 1;
+    const percents = counts.map(function (c) {
+        return c / sum * 100;
+    });
+    const max = Math.max(1, ...percents);
+    rows.forEach(function (row, i) {
+        row.querySelector(".votes").textContent = formatNumber(counts[i], 0);
+        const percent = row.querySelector(".percent");
+        if (percent) {
+            percent.textContent = formatNumber(percents[i], 1) + "%";
+        }
+        const width = percents[i] / max * 100;
+        row.querySelector(".bar").style.width = width + "%";
+        row.querySelector(".remain").style.width = (100 - width) + "%";
+    });
+}
+
+// showCountdown updates the remaining voting time, the result is uncovered
+// by the server when the time has elapsed
+function showCountdown() {
+    const c = document.getElementById("countdown");
+    if (!c) {
+        return;
+    }
+    if (!c.dataset.end) {
+        c.dataset.end = Date.now() + parseInt(c.dataset.remaining) * 1000;
+    }
+    const left = Math.max(0, Math.ceil((c.dataset.end - Date.now()) / 1000));
+    c.querySelector("span").textContent = Math.floor(left / 60) + ":" + String(left % 60).padStart(2, "0");
+}
+
+// extendSurvey restarts the timeout of the survey, the warning is removed
+// by the following update of the result
+function extendSurvey(button) {
+    button.disabled = true;
+    fetch("/extend/", {method: "POST"})
+        .then(function (response) {
+            if (!response.ok) {
+                button.disabled = false;
+            }
+        });
+}
+
+// promoteWriteIn adds the free text answer of the button as a new option
+function promoteWriteIn(button) {
+    const body = new URLSearchParams();
+    body.set("text", button.dataset.text);
+    button.disabled = true;
+    fetch("/promote/", {method: "POST", body: body})
+        .then(function (response) {
+            if (response.status !== 200) {
+                return response.text().then(function (text) {
+                    alert(text);
+                    button.disabled = false;
+                });
+            }
+        });
+}
+
+// reveal uncovers the next option or the whole result, or covers it again,
+// the change is shown by the following update of the result
+function reveal(button, action) {
+    const body = new URLSearchParams();
+    body.set("a", action);
+    button.disabled = true;
+    fetch("/reveal/", {method: "POST", body: body})
+        .then(function (response) {
+            if (response.status !== 200) {
+                return response.text().then(function (text) {
+                    alert(text);
+                });
+            }
         })
+        .finally(function () {
+            button.disabled = false;
+        });
+}
+
+// annotate saves the note of the creator on the result, the note is shown
+// by the following update of the result
+function annotate(button) {
+    const body = new URLSearchParams();
+    body.set("text", button.previousElementSibling.value);
+    button.disabled = true;
+    fetch("/annotate/", {method: "POST", body: body})
+        .then(function (response) {
+            if (response.status !== 200) {
+                return response.text().then(function (text) {
+                    alert(text);
+                });
+            }
+        })
+        .finally(function () {
+            button.disabled = false;
+        });
+}
+
+// showEncrypted decrypts the question and counts the ballots of an
+// end-to-end encrypted survey. This requires the keys of the creator,
+// which are only available in the creator's browser.
+async function showEncrypted(obj) {
+    const title = document.getElementById("title");
+    const result = document.getElementById("result");
+    const keys = await e2eKeys(false);
+    if (!keys) {
+        title.textContent = texts.encrypted;
+        result.textContent = "";
+        return;
+    }
+
+    let options = [];
+    let votes = [];
+    try {
+        title.textContent = await e2eDecryptText(keys, obj.Title);
+        for (const o of obj.Options) {
+            options.push(await e2eDecryptText(keys, o));
+            votes.push(0);
+        }
+    } catch (e) {
+        title.textContent = texts.undecryptable;
+        result.textContent = "";
+        return;
+    }
+    for (const b of obj.Ballots || []) {
+        try {
+            // every option counts only once per ballot and a ballot of a
+            // single choice question must not select more than one option
+            const selected = new Set((await e2eDecryptBallot(keys, b))
+                .filter(o => Number.isInteger(o) && o >= 0 && o < votes.length));
+            if (selected.size > 1 && !obj.Multiple) {
+                console.log("invalid ballot");
+                continue;
+            }
+            for (const o of selected) {
+                votes[o]++;
+            }
+        } catch (e) {
+            console.log("invalid ballot");
+        }
+    }
+
+    const sum = obj.Votes > 0 ? obj.Votes : 1;
+    const max = Math.max(1, ...votes.map(v => v / sum * 100));
+    const table = document.createElement("table");
+    table.className = "main";
+    const cell = function (row, text, className) {
+        const td = row.insertCell();
+        td.textContent = text;
+        if (className) {
+            td.className = className;
+        }
+        return td;
+    };
+    options.forEach(function (o, i) {
+        const row = table.insertRow();
+        cell(row, (i + 1) + ". " + o, "title");
+        const percent = votes[i] / sum * 100;
+        cell(row, obj.Hidden ? "-" : formatNumber(votes[i], 0), "num");
+        cell(row, obj.Hidden ? "-" : formatNumber(percent, 1) + "%", "num");
+        const bar = cell(row, "");
+        bar.style.minWidth = "6em";
+        const inner = document.createElement("div");
+        inner.style.height = "0.8em";
+        inner.style.backgroundColor = "gray";
+        inner.style.width = (obj.Hidden ? 0 : percent / max * 100) + "%";
+        bar.appendChild(inner);
+    });
+    const row = table.insertRow();
+    cell(row, "Teilnehmer:", "title").style.color = "gray";
+    cell(row, obj.Votes || 0, "num");
+    cell(row, "");
+    result.replaceChildren(table);
 }
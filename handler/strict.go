@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"flashSurvey/survey"
+	"net"
+	"net/http"
+	"net/netip"
+)
+
+// voterCookie is the name of the cookie containing the signature of the
+// user id of a voter in the strict mode
+const voterCookie = "vsig"
+
+// strictVoting is implemented by the vote backends which support the strict
+// mode, see survey.Surveys.EnableStrictVoting
+type strictVoting interface {
+	StrictVoting() bool
+	CheckVoteKey(surveyId survey.SurveyId, key string) bool
+	SignVoter(voterId survey.UserId) string
+	VerifyVoter(voterId survey.UserId, signature string) bool
+	Fingerprint(surveyId survey.SurveyId, address, userAgent string) string
+	ClaimFingerprint(surveyId survey.SurveyId, number int, fingerprint string, voterId survey.UserId) error
+	VerifiedVoter(surveyId survey.SurveyId, voterId survey.UserId) bool
+}
+
+// strictBackend returns the backend if the strict mode is enabled
+func strictBackend(s VoteBackend) (strictVoting, bool) {
+	st, ok := s.(strictVoting)
+	return st, ok && st.StrictVoting()
+}
+
+// setVoterCookie issues the signed voter cookie, only voters who have
+// opened the vote page can vote
+func setVoterCookie(writer http.ResponseWriter, st strictVoting, userId survey.UserId) {
+	http.SetCookie(writer, &http.Cookie{
+		Name:     voterCookie,
+		Value:    st.SignVoter(userId),
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// checkStrictVote checks the key of the vote link and the signed voter
+// cookie and claims the fingerprint of the device for the voter
+func checkStrictVote(st strictVoting, surveyId survey.SurveyId, number int, userId survey.UserId, request *http.Request) error {
+	if !st.CheckVoteKey(surveyId, request.URL.Query().Get("k")) {
+		return errInvalidLink
+	}
+	if !st.VerifyVoter(userId, getCookie(request, voterCookie)) {
+		return errReopenSurvey
+	}
+	fingerprint := st.Fingerprint(surveyId, remoteNetwork(request), request.UserAgent())
+	return st.ClaimFingerprint(surveyId, number, fingerprint, userId)
+}
+
+// remoteNetwork returns the address of the client. Of an IPv6 address only
+// the /64 prefix is used, because the devices change the rest regularly.
+func remoteNetwork(request *http.Request) string {
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		host = request.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return host
+	}
+	addr = addr.Unmap()
+	if addr.Is6() {
+		prefix, err := addr.Prefix(64)
+		if err == nil {
+			return prefix.String()
+		}
+	}
+	return addr.String()
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"context"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictVoting(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	s.EnableStrictVoting()
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	key := s.VoteKey("creator", sid)
+
+	open := func(voter, k string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/vote/?id="+string(sid)+"&k="+k, nil)
+		r = r.WithContext(context.WithValue(r.Context(), "id", voter))
+		w := httptest.NewRecorder()
+		Vote(s)(w, r)
+		return w
+	}
+	vote := func(voter, k, signature, address string) string {
+		r := httptest.NewRequest(http.MethodGet, "/voteRest/?id="+string(sid)+"&o=1&n=1&k="+k, nil)
+		r.RemoteAddr = address
+		r.Header.Set("User-Agent", "Firefox")
+		r.AddCookie(&http.Cookie{Name: voterCookie, Value: signature})
+		r = r.WithContext(context.WithValue(r.Context(), "id", voter))
+		w := httptest.NewRecorder()
+		VoteRest(s)(w, r)
+		return w.Body.String()
+	}
+
+	assert.Contains(t, open("a", "wrong").Body.String(), "Der Link ist ungültig!")
+	w := open("a", key)
+	assert.Contains(t, w.Body.String(), "&k="+key)
+	signature := w.Result().Cookies()[0].Value
+
+	assert.Contains(t, vote("a", "", signature, "10.0.0.1:1234"), "Der Link ist ungültig!")
+	assert.Contains(t, vote("a", key, "", "10.0.0.1:1234"), "Bitte öffnen Sie die Abstimmung erneut!")
+	assert.Contains(t, vote("a", key, signature, "10.0.0.1:1234"), "Sie haben erfolgreich abgestimmt!")
+
+	// a new cookie does not allow to vote again from the same device
+	signature = open("b", key).Result().Cookies()[0].Value
+	assert.Contains(t, vote("b", key, signature, "10.0.0.1:4321"), "Von diesem Gerät wurde bereits abgestimmt!")
+	assert.Contains(t, vote("b", key, signature, "10.0.0.2:4321"), "Sie haben erfolgreich abgestimmt!")
+	assert.Equal(t, 2, s.CollectedVotes("creator", sid))
+}
+
+func TestRemoteNetwork(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "192.168.1.2:1234"
+	assert.Equal(t, "192.168.1.2", remoteNetwork(r))
+	r.RemoteAddr = "[2001:db8:1:2:3:4:5:6]:1234"
+	assert.Equal(t, "2001:db8:1:2::/64", remoteNetwork(r))
+	r.RemoteAddr = "[::ffff:10.0.0.1]:1234"
+	assert.Equal(t, "10.0.0.1", remoteNetwork(r))
+}
+
+func TestStrictVotingCredentials(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	s.EnableStrictVoting()
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	key := s.VoteKey("creator", sid)
+
+	// a token or a code does not bypass the checks if the survey uses neither
+	assert.Contains(t, voteRest(s, sid, "a", "&t=1"), "Der Link ist ungültig!")
+	assert.Contains(t, voteRest(s, sid, "a", "&k="+key+"&a=1"), "Bitte öffnen Sie die Abstimmung erneut!")
+
+	// a registered voter needs neither the key nor the cookie
+	voters, err := s.RegisterVoters("creator", sid, []string{"a@example.com"})
+	assert.NoError(t, err)
+	token := voters[0].URL[strings.Index(voters[0].URL, "&t=")+3:]
+	assert.Contains(t, voteRest(s, sid, "a", "&t=unknown"), "Der Link ist ungültig!")
+	assert.Contains(t, voteRest(s, sid, "a", "&t="+token), "Sie haben erfolgreich abgestimmt!")
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"context"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVoteRestThankYou(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+	require.NoError(t, s.SetThankYou("creator", sid, survey.ThankYou{Message: "Vielen Dank!", Redirect: "https://example.com/slides"}))
+
+	vote := func(voter string) string {
+		r := httptest.NewRequest(http.MethodGet, "/voteRest/?id="+string(sid)+"&n=1&o=0", nil)
+		r = r.WithContext(context.WithValue(r.Context(), "id", voter))
+		w := httptest.NewRecorder()
+		VoteRest(s)(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	body := vote("a")
+	assert.Contains(t, body, "Vielen Dank!")
+	assert.Contains(t, body, `<a id="redirect" href="https://example.com/slides">`)
+	assert.NotContains(t, body, "Sie haben erfolgreich abgestimmt!")
+
+	// an error is shown without redirect
+	body = vote("a")
+	assert.Contains(t, body, "Sie haben bereits abgestimmt!")
+	assert.NotContains(t, body, "redirect")
+}
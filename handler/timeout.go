@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+)
+
+// Timeout limits the time a request may take. The read and write deadlines
+// of the connection are replaced, so routes like the long-polling of the
+// results can be given more time than the server's defaults, while all
+// other routes stay short. The context of the request is canceled when the
+// time is up, so waiting handlers can return in time.
+func Timeout(d time.Duration) func(http.HandlerFunc) http.HandlerFunc {
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			deadline := time.Now().Add(d)
+			rc := http.NewResponseController(writer)
+			if err := rc.SetReadDeadline(deadline); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				log.Println("could not set read deadline:", err)
+			}
+			// leave some time to write the response after the context is done
+			if err := rc.SetWriteDeadline(deadline.Add(5 * time.Second)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+				log.Println("could not set write deadline:", err)
+			}
+			ctx, cancel := context.WithDeadline(request.Context(), deadline)
+			defer cancel()
+			handler(writer, request.WithContext(ctx))
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	h := Timeout(50 * time.Millisecond)(func(writer http.ResponseWriter, request *http.Request) {
+		select {
+		case <-request.Context().Done():
+			writer.WriteHeader(http.StatusNoContent)
+		case <-time.After(5 * time.Second):
+			writer.WriteHeader(http.StatusOK)
+		}
+	})
+
+	server := httptest.NewServer(h)
+	defer server.Close()
+
+	start := time.Now()
+	resp, err := http.Get(server.URL)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"flashSurvey/randid"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// maxQuestionNumberLen is the maximum number of digits of the question
+	// number given by the query parameter "n"
+	maxQuestionNumberLen = 9
+	// maxDefinitionLen is the maximum length of the survey definition given
+	// by the query parameter "q"
+	maxDefinitionLen = 8 * 1024
+)
+
+// QueryError describes an invalid query parameter
+type QueryError struct {
+	// Param is the name of the query parameter
+	Param string
+	// Reason describes why the value is rejected
+	Reason string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("invalid query parameter %q: %s", e.Param, e.Reason)
+}
+
+// ValidateQuery returns a middleware which rejects requests containing
+// malformed values of the query parameters "id", "o", "n" and "q" with the
+// status 400, so they never reach the survey layer. Missing parameters are
+// not checked, the handlers deal with them as before.
+func ValidateQuery(handler http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if err := validateQuery(request); err != nil {
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		handler(writer, request)
+	}
+}
+
+// validateQuery returns a *QueryError if one of the checked query
+// parameters is invalid
+func validateQuery(request *http.Request) error {
+	query := request.URL.Query()
+	if query.Has("id") {
+		if err := validateSurveyId(query.Get("id")); err != nil {
+			return err
+		}
+	}
+	if query.Has("o") {
+		if err := validateOptions(query.Get("o")); err != nil {
+			return err
+		}
+	}
+	if query.Has("n") {
+		if err := validateNumber(query.Get("n")); err != nil {
+			return err
+		}
+	}
+	if query.Has("q") {
+		if err := validateDefinition(query.Get("q")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSurveyId checks that the id has the length and the characters of
+// the ids created by randid.String
+func validateSurveyId(id string) error {
+	if len(id) != randid.Length() {
+		return &QueryError{Param: "id", Reason: fmt.Sprintf("length must be %d", randid.Length())}
+	}
+	if !randid.Valid(id) {
+		return &QueryError{Param: "id", Reason: "only the characters of the ids are allowed"}
+	}
+	return nil
+}
+
+// validateOptions checks the comma separated list of option indices, empty
+// elements are allowed because the vote page may send a trailing comma.
+// Every option may only be selected once.
+func validateOptions(list string) error {
+	options := strings.Split(list, ",")
+	if len(options) > maxOptionFields+1 {
+		return &QueryError{Param: "o", Reason: "too many options"}
+	}
+	seen := make(map[int]bool, len(options))
+	for _, o := range options {
+		if len(o) > len(fmt.Sprint(maxOptionFields)) || !isDigits(o) {
+			return &QueryError{Param: "o", Reason: "only option numbers are allowed"}
+		}
+		if o == "" {
+			continue
+		}
+		n, _ := strconv.Atoi(o)
+		if seen[n] {
+			return &QueryError{Param: "o", Reason: "options must not be repeated"}
+		}
+		seen[n] = true
+	}
+	return nil
+}
+
+func validateNumber(n string) error {
+	if n == "" || len(n) > maxQuestionNumberLen || !isDigits(n) {
+		return &QueryError{Param: "n", Reason: "must be a question number"}
+	}
+	return nil
+}
+
+// validateDefinition checks the survey definition which is used to prefill
+// the create form
+func validateDefinition(q string) error {
+	if len(q) > maxDefinitionLen {
+		return &QueryError{Param: "q", Reason: "too long"}
+	}
+	if !utf8.ValidString(q) {
+		return &QueryError{Param: "q", Reason: "invalid utf-8"}
+	}
+	if strings.ContainsFunc(q, unicode.IsControl) {
+		return &QueryError{Param: "q", Reason: "control characters are not allowed"}
+	}
+	return nil
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"errors"
+	"flashSurvey/randid"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateQuery(t *testing.T) {
+	id := strings.Repeat("a", randid.Length())
+	tests := []struct {
+		query string
+		param string
+	}{
+		{query: "", param: ""},
+		{query: "id=" + id + "&o=0,2,&n=3", param: ""},
+		{query: "id=" + id + "&o=&n=1", param: ""},
+		{query: "q=Frage%3Bs%3BJa%3BNein", param: ""},
+		{query: "id=short", param: "id"},
+		{query: "id=" + id[1:] + "%00", param: "id"},
+		{query: "id=" + id + "&o=1,x", param: "o"},
+		{query: "o=-1", param: "o"},
+		{query: "o=0,0,0", param: "o"},
+		{query: "o=1,01", param: "o"},
+		{query: "o=" + strings.Repeat("1,", maxOptionFields+1), param: "o"},
+		{query: "n=", param: "n"},
+		{query: "n=1e5", param: "n"},
+		{query: "n=" + strings.Repeat("9", maxQuestionNumberLen+1), param: "n"},
+		{query: "q=" + strings.Repeat("x", maxDefinitionLen+1), param: "q"},
+		{query: "q=a%0Ab", param: "q"},
+		{query: "q=%FF", param: "q"},
+	}
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			err := validateQuery(httptest.NewRequest(http.MethodGet, "/?"+test.query, nil))
+			if test.param == "" {
+				assert.NoError(t, err)
+			} else {
+				var qe *QueryError
+				if assert.True(t, errors.As(err, &qe)) {
+					assert.Equal(t, test.param, qe.Param)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateQueryMiddleware(t *testing.T) {
+	called := false
+	h := ValidateQuery(func(writer http.ResponseWriter, request *http.Request) {
+		called = true
+	})
+
+	w := httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/voteRest/?id=junk", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, called)
+
+	w = httptest.NewRecorder()
+	h(w, httptest.NewRequest(http.MethodGet, "/voteRest/?id="+strings.Repeat("a", randid.Length())+"&o=1&n=1", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, called)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"context"
+	"flashSurvey/survey"
+	"log"
+	"net/http"
+	"net/url"
+)
+
+// Viewer returns a middleware which allows the access to the result by the
+// viewer token given in the query parameters "id" and "t". The request is
+// executed on behalf of the creator, but no cookies are read or set, so
+// the projector does not get the identity of the creator. The middleware
+// must only be used for handlers which show the result.
+func Viewer(s *survey.Surveys) func(http.HandlerFunc) http.HandlerFunc {
+	return func(handler http.HandlerFunc) http.HandlerFunc {
+		return func(writer http.ResponseWriter, request *http.Request) {
+			query := request.URL.Query()
+			surveyId := survey.SurveyId(query.Get("id"))
+			userId, ok := s.Viewer(surveyId, query.Get("t"))
+			if !ok {
+				http.Error(writer, "invalid viewer token", http.StatusForbidden)
+				return
+			}
+			ctx := context.WithValue(request.Context(), "id", string(userId))
+			ctx = context.WithValue(ctx, "sid", surveyId)
+			ctx = context.WithValue(ctx, "viewer", true)
+			handler(writer, request.WithContext(ctx))
+		}
+	}
+}
+
+func isViewer(request *http.Request) bool {
+	v, _ := request.Context().Value("viewer").(bool)
+	return v
+}
+
+// ViewerLink redirects the creator to the result page of the viewer, so
+// the url can be opened on the projector.
+func ViewerLink(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+
+		token, err := s.ViewerToken(userId, surveyId)
+		if err != nil {
+			log.Println(err)
+			httpError(writer, request, err, errorStatus(err, http.StatusBadRequest))
+			return
+		}
+		q := url.Values{}
+		q.Set("id", string(surveyId))
+		q.Set("t", token)
+		http.Redirect(writer, request, "/display/?"+q.Encode(), http.StatusSeeOther)
+	}
+}
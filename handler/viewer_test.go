@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"context"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestViewer(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	_, err = s.ViewerToken("other", sid)
+	assert.Error(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/viewer/", nil)
+	r.AddCookie(&http.Cookie{Name: "sid", Value: string(sid)})
+	r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+	w := httptest.NewRecorder()
+	ViewerLink(s)(w, r)
+	assert.Equal(t, http.StatusSeeOther, w.Code)
+	link, err := url.Parse(w.Header().Get("Location"))
+	assert.NoError(t, err)
+	assert.Equal(t, "/display/", link.Path)
+
+	display := func(query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/display/?"+query, nil)
+		w := httptest.NewRecorder()
+		Viewer(s)(Result(s))(w, r)
+		return w
+	}
+
+	w = display(link.RawQuery)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `class="viewer"`)
+	assert.Contains(t, w.Body.String(), "Test")
+	assert.Empty(t, w.Result().Cookies())
+
+	q := link.Query()
+	q.Set("t", "invalid")
+	assert.Equal(t, http.StatusForbidden, display(q.Encode()).Code)
+
+	// the token is bound to the survey
+	other, err := s.New("creator", "", survey.SurveyQuestion{Title: "Other", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	q = link.Query()
+	q.Set("id", string(other))
+	assert.Equal(t, http.StatusForbidden, display(q.Encode()).Code)
+
+	s.Clear(sid, "creator")
+	assert.Equal(t, http.StatusForbidden, display(link.RawQuery).Code)
+}
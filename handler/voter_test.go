@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"context"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// voteRest sends a vote for the first option of the first question
+func voteRest(s VoteBackend, sid survey.SurveyId, cookieId, query string) string {
+	r := httptest.NewRequest(http.MethodGet, "/voteRest/?id="+string(sid)+"&n=1&o=0"+query, nil)
+	r = r.WithContext(context.WithValue(r.Context(), "id", cookieId))
+	w := httptest.NewRecorder()
+	VoteRest(s)(w, r)
+	return w.Body.String()
+}
+
+func TestVoterToken(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+
+	// without registration, an arbitrary token does not create a new voter
+	assert.Contains(t, voteRest(s, sid, "voter", "&t=1"), "Sie haben erfolgreich abgestimmt!")
+	assert.Contains(t, voteRest(s, sid, "voter", "&t=2"), "Sie haben bereits abgestimmt!")
+
+	// a signed token identifies the voter
+	SetVoterTokenVerifier(func(token string) bool { return strings.HasPrefix(token, "signed") })
+	defer SetVoterTokenVerifier(nil)
+	assert.Contains(t, voteRest(s, sid, "voter", "&t=signed1"), "Sie haben erfolgreich abgestimmt!")
+	assert.Contains(t, voteRest(s, sid, "voter", "&t=signed1"), "Sie haben bereits abgestimmt!")
+
+	// in the registration mode only the registered tokens are accepted
+	voters, err := s.RegisterVoters("creator", sid, []string{"a@example.com"})
+	require.NoError(t, err)
+	token := voters[0].URL[strings.Index(voters[0].URL, "&t=")+3:]
+	assert.Contains(t, voteRest(s, sid, "other", "&t=unknown"), "Sie sind für diese Umfrage nicht registriert!")
+	assert.Contains(t, voteRest(s, sid, "other", "&t="+token), "Sie haben erfolgreich abgestimmt!")
+}
@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"flashSurvey/survey"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// webSocketGUID is used to compute the accept key of the handshake, see RFC 6455
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	wsText  = 0x1
+	wsClose = 0x8
+	wsPing  = 0x9
+	wsPong  = 0xA
+)
+
+const (
+	// wsPingInterval is the time after which a ping is sent if nothing has changed
+	wsPingInterval = 30 * time.Second
+	// wsWriteTimeout is the maximum time to write a frame
+	wsWriteTimeout = 10 * time.Second
+	// wsMaxFrame is the maximum size of a frame sent by the client, which
+	// only sends control frames
+	wsMaxFrame = 4096
+)
+
+// webSocket is a minimal server side implementation of the WebSocket
+// protocol. It is only able to send text frames and to answer the control
+// frames of the client, which is all the result pages need.
+type webSocket struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	mutex  sync.Mutex
+}
+
+// headerContains returns true if the comma separated header contains the given token
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sameOrigin returns true if the request was sent by a page of this server.
+// The browsers send the cookies with WebSocket requests of other sites as
+// well, so the origin has to be checked.
+func sameOrigin(request *http.Request) bool {
+	origin := request.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(u.Host, request.Host)
+}
+
+// upgradeWebSocket performs the handshake. If the request is not a valid
+// WebSocket request, an error is sent to the client and nil is returned.
+func upgradeWebSocket(writer http.ResponseWriter, request *http.Request) *webSocket {
+	if !headerContains(request.Header, "Connection", "upgrade") || !headerContains(request.Header, "Upgrade", "websocket") {
+		http.Error(writer, "websocket upgrade expected", http.StatusBadRequest)
+		return nil
+	}
+	if request.Header.Get("Sec-WebSocket-Version") != "13" {
+		writer.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(writer, "unsupported websocket version", http.StatusUpgradeRequired)
+		return nil
+	}
+	key := request.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		http.Error(writer, "websocket key missing", http.StatusBadRequest)
+		return nil
+	}
+	if !sameOrigin(request) {
+		http.Error(writer, "origin not allowed", http.StatusForbidden)
+		return nil
+	}
+
+	conn, rw, err := http.NewResponseController(writer).Hijack()
+	if err != nil {
+		http.Error(writer, "websocket not supported: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+	// the deadlines of the server do not apply to the long-lived connection
+	err = conn.SetDeadline(time.Time{})
+	if err == nil {
+		h := sha1.Sum([]byte(key + webSocketGUID))
+		_, err = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+			"Upgrade: websocket\r\n" +
+			"Connection: Upgrade\r\n" +
+			"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(h[:]) + "\r\n\r\n")
+	}
+	if err == nil {
+		err = rw.Flush()
+	}
+	if err != nil {
+		log.Println("websocket handshake failed:", err)
+		conn.Close()
+		return nil
+	}
+	return &webSocket{conn: conn, reader: rw.Reader}
+}
+
+// writeFrame sends a single unmasked frame
+func (w *webSocket) writeFrame(op byte, payload []byte) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	frame := []byte{0x80 | op}
+	n := len(payload)
+	switch {
+	case n < 126:
+		frame = append(frame, byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, 126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, 127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+	frame = append(frame, payload...)
+
+	if err := w.conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout)); err != nil {
+		return err
+	}
+	_, err := w.conn.Write(frame)
+	return err
+}
+
+// readFrame reads a single frame sent by the client
+func (w *webSocket) readFrame() (byte, []byte, error) {
+	var h [2]byte
+	if _, err := io.ReadFull(w.reader, h[:]); err != nil {
+		return 0, nil, err
+	}
+	op := h[0] & 0x0F
+	if h[1]&0x80 == 0 {
+		return 0, nil, errors.New("websocket frame of client not masked")
+	}
+	n := uint64(h[1] & 0x7F)
+	switch n {
+	case 126:
+		var l [2]byte
+		if _, err := io.ReadFull(w.reader, l[:]); err != nil {
+			return 0, nil, err
+		}
+		n = uint64(binary.BigEndian.Uint16(l[:]))
+	case 127:
+		var l [8]byte
+		if _, err := io.ReadFull(w.reader, l[:]); err != nil {
+			return 0, nil, err
+		}
+		n = binary.BigEndian.Uint64(l[:])
+	}
+	if n > wsMaxFrame {
+		return 0, nil, errors.New("websocket frame too large")
+	}
+	var mask [4]byte
+	if _, err := io.ReadFull(w.reader, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, n)
+	if _, err := io.ReadFull(w.reader, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return op, payload, nil
+}
+
+// readLoop answers the control frames of the client until the connection
+// is closed. The data sent by the client is ignored.
+func (w *webSocket) readLoop() {
+	for {
+		op, payload, err := w.readFrame()
+		if err != nil {
+			return
+		}
+		switch op {
+		case wsPing:
+			if w.writeFrame(wsPong, payload) != nil {
+				return
+			}
+		case wsClose:
+			w.close(1000)
+			return
+		}
+	}
+}
+
+// close sends a close frame with the given status code
+func (w *webSocket) close(code uint16) {
+	_ = w.writeFrame(wsClose, binary.BigEndian.AppendUint16(nil, code))
+}
+
+// ResultWs pushes the result to the result page via a WebSocket whenever
+// the survey changes. Only the changes are sent if possible. This avoids
+// the overhead of the long-polling of ResultRest, which is used if the
+// WebSocket can not be established.
+func ResultWs(s *survey.Surveys) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userId := GetUserId(request)
+		surveyId := GetSurveyId(writer, request)
+
+		ws := upgradeWebSocket(writer, request)
+		if ws == nil {
+			return
+		}
+		defer ws.conn.Close()
+		metrics.webSockets.Add(1)
+		defer metrics.webSockets.Add(-1)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			ws.readLoop()
+			cancel()
+		}()
+
+		v := -1
+		if token := request.URL.Query().Get("r"); token != "" {
+			v = resumeVersion(s, userId, surveyId, token)
+		}
+		s.RecordResultAccess(userId, surveyId)
+		for {
+			if v > 0 {
+				if err := s.Wait(ctx, userId, surveyId, v, wsPingInterval); err != nil {
+					if errors.Is(err, survey.ErrShutdown) {
+						// service restart
+						ws.close(1012)
+					} else {
+						// try again later
+						ws.close(1013)
+					}
+					return
+				}
+				if ctx.Err() != nil {
+					return
+				}
+			}
+
+			data := resultData(s, userId, surveyId, v, true, request)
+			if v > 0 && data.Version == v {
+				// nothing has changed, check that the client is still there
+				if ws.writeFrame(wsPing, nil) != nil {
+					return
+				}
+				continue
+			}
+			jsonData, err := json.Marshal(data)
+			if err != nil {
+				log.Println("could not marshal result:", err)
+				ws.close(1011)
+				return
+			}
+			if ws.writeFrame(wsText, jsonData) != nil {
+				return
+			}
+			if data.Version < 0 {
+				// the survey was deleted
+				ws.close(1000)
+				return
+			}
+			v = data.Version
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"flashSurvey/survey"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func readServerFrame(t *testing.T, r *bufio.Reader) (byte, []byte) {
+	var h [2]byte
+	_, err := io.ReadFull(r, h[:])
+	assert.NoError(t, err)
+	n := int(h[1] & 0x7F)
+	switch n {
+	case 126:
+		var l [2]byte
+		_, err = io.ReadFull(r, l[:])
+		n = int(binary.BigEndian.Uint16(l[:]))
+	case 127:
+		var l [8]byte
+		_, err = io.ReadFull(r, l[:])
+		n = int(binary.BigEndian.Uint64(l[:]))
+	}
+	assert.NoError(t, err)
+	payload := make([]byte, n)
+	_, err = io.ReadFull(r, payload)
+	assert.NoError(t, err)
+	return h[0] & 0x0F, payload
+}
+
+func TestResultWs(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		request = request.WithContext(context.WithValue(request.Context(), "id", "creator"))
+		ResultWs(s)(writer, request)
+	}))
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	assert.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("GET /resultWs/ HTTP/1.1\r\n" +
+		"Host: " + strings.TrimPrefix(server.URL, "http://") + "\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Sec-WebSocket-Version: 13\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Cookie: sid=" + string(sid) + "\r\n\r\n"))
+	assert.NoError(t, err)
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	// example of RFC 6455
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+
+	op, payload := readServerFrame(t, r)
+	assert.Equal(t, byte(wsText), op)
+	var d ResultData
+	assert.NoError(t, json.Unmarshal(payload, &d))
+	assert.Equal(t, "Test", string(d.Title))
+	assert.Nil(t, d.Delta)
+
+	// the change is pushed
+	assert.NoError(t, s.Vote(sid, "a", []int{1}, 1))
+	op, payload = readServerFrame(t, r)
+	assert.Equal(t, byte(wsText), op)
+	var next ResultData
+	assert.NoError(t, json.Unmarshal(payload, &next))
+	assert.Greater(t, next.Version, d.Version)
+	assert.NotNil(t, next.Delta)
+	assert.Equal(t, 1, next.Delta.Votes)
+
+	// a masked close frame is answered
+	_, err = conn.Write([]byte{0x80 | wsClose, 0x80, 1, 2, 3, 4})
+	assert.NoError(t, err)
+	op, _ = readServerFrame(t, r)
+	assert.Equal(t, byte(wsClose), op)
+}
+
+func TestResultWsNoUpgrade(t *testing.T) {
+	s := survey.New("localhost", 30, false, true)
+	r := httptest.NewRequest(http.MethodGet, "/resultWs/", nil)
+	r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+	w := httptest.NewRecorder()
+	ResultWs(s)(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	r.Header.Set("Connection", "keep-alive, Upgrade")
+	r.Header.Set("Upgrade", "websocket")
+	r.Header.Set("Sec-WebSocket-Version", "13")
+	r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	r.Header.Set("Origin", "https://evil.example.com")
+	w = httptest.NewRecorder()
+	ResultWs(s)(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
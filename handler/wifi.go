@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// WiFi is the venue network shown next to the vote QR code, so the
+// audience is able to get online before voting
+type WiFi struct {
+	SSID string
+	// QRCode is the base64 encoded PNG of the network credentials
+	QRCode string
+}
+
+// wifi is the network configured by the operator, nil if there is none.
+// It is set once at startup.
+var wifi *WiFi
+
+// SetWiFi configures the network shown on the result page. The security
+// is "WPA", "WEP" or "nopass". If the ssid is empty, no network is shown.
+func SetWiFi(ssid, password, security string) error {
+	if ssid == "" {
+		wifi = nil
+		return nil
+	}
+	if password == "" {
+		security = "nopass"
+	}
+	switch security {
+	case "WPA", "WEP", "nopass":
+	default:
+		return errors.New("unknown wifi security " + security)
+	}
+
+	png, err := qrcode.Encode(wifiURI(ssid, password, security), qrcode.Medium, 256)
+	if err != nil {
+		return fmt.Errorf("could not create wifi qr code: %w", err)
+	}
+	wifi = &WiFi{SSID: ssid, QRCode: base64.StdEncoding.EncodeToString(png)}
+	return nil
+}
+
+// wifiURI returns the network credentials in the format understood by
+// the camera apps of the smartphones
+func wifiURI(ssid, password, security string) string {
+	uri := "WIFI:T:" + security + ";S:" + wifiEscape(ssid) + ";"
+	if security != "nopass" {
+		uri += "P:" + wifiEscape(password) + ";"
+	}
+	return uri + ";"
+}
+
+var wifiEscaper = strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `:`, `\:`, `"`, `\"`)
+
+func wifiEscape(s string) string {
+	return wifiEscaper.Replace(s)
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"context"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWiFiURI(t *testing.T) {
+	assert.Equal(t, "WIFI:T:WPA;S:Hörsaal;P:geheim;;", wifiURI("Hörsaal", "geheim", "WPA"))
+	assert.Equal(t, `WIFI:T:WPA;S:a\;b\:c;P:x\\y\,z\";;`, wifiURI("a;b:c", `x\y,z"`, "WPA"))
+	assert.Equal(t, "WIFI:T:nopass;S:Gast;;", wifiURI("Gast", "", "nopass"))
+}
+
+func TestWiFi(t *testing.T) {
+	defer SetWiFi("", "", "")
+
+	assert.Error(t, SetWiFi("Gast", "geheim", "WPA3"))
+	assert.NoError(t, SetWiFi("Gast", "", "WPA"))
+	assert.Equal(t, "Gast", wifi.SSID)
+	assert.NotEmpty(t, wifi.QRCode)
+
+	s := survey.New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", survey.SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	r := httptest.NewRequest(http.MethodGet, "/result/", nil)
+	r.AddCookie(&http.Cookie{Name: "sid", Value: string(sid)})
+	r = r.WithContext(context.WithValue(r.Context(), "id", "creator"))
+	w := httptest.NewRecorder()
+	Result(s)(w, r)
+	assert.Contains(t, w.Body.String(), "WLAN: Gast")
+
+	assert.NoError(t, SetWiFi("", "", ""))
+	assert.Nil(t, wifi)
+}
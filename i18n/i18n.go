@@ -0,0 +1,118 @@
+// Package i18n contains the translations of the texts shown on the pages
+// and the catalog of the error messages, which are identified by codes
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the language the texts are written in
+const Default = "de"
+
+// Language is a language the vote page can be shown in
+type Language struct {
+	Code string
+	Name string
+}
+
+// Languages contains all supported languages
+var Languages = []Language{
+	{Code: "de", Name: "Deutsch"},
+	{Code: "en", Name: "English"},
+}
+
+// Supported returns true if the language with the given code is supported
+func Supported(code string) bool {
+	for _, l := range Languages {
+		if l.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// Negotiate returns the supported language with the highest quality value
+// in the given Accept-Language header. If there is none, the default
+// language is returned.
+func Negotiate(header string) string {
+	type accepted struct {
+		code string
+		q    float64
+	}
+	var list []accepted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		code, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if Supported(code) && q > 0 {
+			list = append(list, accepted{code: code, q: q})
+		}
+	}
+	if len(list) == 0 {
+		return Default
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].q > list[j].q
+	})
+	return list[0].code
+}
+
+// Text returns the given german text in the given language. If there is
+// no translation, the text is returned unchanged.
+func Text(lang, text string) string {
+	if t, ok := texts[lang][text]; ok {
+		return t
+	}
+	if t, ok := pages[lang][text]; ok {
+		return t
+	}
+	return text
+}
+
+// HasText returns true if there is a translation of the given german text
+// to all supported languages
+func HasText(text string) bool {
+	for _, l := range Languages {
+		if l.Code == Default {
+			continue
+		}
+		_, inTexts := texts[l.Code][text]
+		_, inPages := pages[l.Code][text]
+		if !inTexts && !inPages {
+			return false
+		}
+	}
+	return true
+}
+
+// Message returns the message with the given code in the given language,
+// the arguments are inserted into the message. If the message is not
+// available in the language, the german message is used.
+func Message(lang, code string, args ...any) string {
+	m, ok := messages[code]
+	if !ok {
+		return code
+	}
+	format, ok := m[lang]
+	if !ok {
+		format = m[Default]
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Has returns true if the catalog contains a message with the given code
+func Has(code string) bool {
+	_, ok := messages[code]
+	return ok
+}
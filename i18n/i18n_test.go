@@ -0,0 +1,68 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "", want: "de"},
+		{header: "en", want: "en"},
+		{header: "en-US,en;q=0.9", want: "en"},
+		{header: "fr,en;q=0.5,de;q=0.8", want: "de"},
+		{header: "de;q=0,en;q=0.1", want: "en"},
+		{header: "fr,it", want: "de"},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.want, Negotiate(test.header), test.header)
+	}
+}
+
+func TestMessagesComplete(t *testing.T) {
+	for code, m := range messages {
+		for _, l := range Languages {
+			text, ok := m[l.Code]
+			if assert.True(t, ok, "message %s is missing in %s", code, l.Code) {
+				assert.Equal(t, strings.Count(m[Default], "%v"), strings.Count(text, "%v"), "arguments of %s in %s", code, l.Code)
+			}
+		}
+	}
+}
+
+func TestMessage(t *testing.T) {
+	assert.Equal(t, "Option 2 ist identisch mit Option 1!", Message("de", "optionDuplicate", 2, 1))
+	assert.Equal(t, "Option 2 is identical to option 1!", Message("en", "optionDuplicate", 2, 1))
+	// float64 is the type of the numbers decoded from json
+	assert.Equal(t, "At most 5 options are allowed!", Message("en", "maxOptions", float64(5)))
+	assert.Equal(t, "Sie haben bereits abgestimmt!", Message("fr", "alreadyVoted"))
+	assert.Equal(t, "unknown", Message("en", "unknown"))
+	assert.True(t, Has("alreadyVoted"))
+	assert.False(t, Has("unknown"))
+}
+
+func TestText(t *testing.T) {
+	assert.Equal(t, "Send", Text("en", "Senden"))
+	assert.Equal(t, "Senden", Text("de", "Senden"))
+	assert.Equal(t, "Unbekannt", Text("en", "Unbekannt"))
+}
+
+func TestPagesArguments(t *testing.T) {
+	for lang, p := range pages {
+		for text, translated := range p {
+			assert.Equal(t, strings.Count(text, "%v"), strings.Count(translated, "%v"), "arguments of %q in %s", text, lang)
+		}
+	}
+}
+
+func TestPageText(t *testing.T) {
+	assert.Equal(t, "Create survey", Text("en", "Umfrage erzeugen"))
+	assert.True(t, HasText("Umfrage erzeugen"))
+	assert.True(t, HasText("Senden"))
+	assert.False(t, HasText("Unbekannt"))
+}
@@ -0,0 +1,348 @@
+package i18n
+
+// messages contains the error messages by their codes, the arguments are
+// inserted with %v
+var messages = map[string]map[string]string{
+	"surveyNotFound": {
+		"de": "Diese Umfrage existiert nicht!",
+		"en": "This survey does not exist!",
+	},
+	"notCreator": {
+		"de": "Sie sind nicht der Ersteller dieser Umfrage!",
+		"en": "You are not the creator of this survey!",
+	},
+	"createNotAllowed": {
+		"de": "Sie dürfen keine Umfragen erstellen!",
+		"en": "You are not allowed to create surveys!",
+	},
+	"surveyExists": {
+		"de": "Umfrage mit ID %v existiert bereits!",
+		"en": "A survey with the id %v already exists!",
+	},
+	"tooManySurveys": {
+		"de": "Sie können höchstens %v Umfragen gleichzeitig durchführen!",
+		"en": "You can run at most %v surveys at the same time!",
+	},
+	"surveyOfOtherUser": {
+		"de": "Diese Umfrage existiert bereits und wurde von einem anderen Benutzer erstellt!",
+		"en": "This survey already exists and was created by another user!",
+	},
+	"lockedRestart": {
+		"de": "Die Umfrage ist gesperrt! Sie muss erst entsperrt werden, bevor sie neu gestartet werden kann.",
+		"en": "The survey is locked! It has to be unlocked before it can be started again.",
+	},
+	"lockedReset": {
+		"de": "Die Umfrage ist gesperrt! Sie muss erst entsperrt werden, bevor die Stimmen zurückgesetzt werden können.",
+		"en": "The survey is locked! It has to be unlocked before the votes can be reset.",
+	},
+	"invalidDefinition": {
+		"de": "Ungültige Umfrage-Definition!",
+		"en": "Invalid survey definition!",
+	},
+	"invalidKey": {
+		"de": "Ungültiger Schlüssel!",
+		"en": "Invalid key!",
+	},
+	"titleMissing": {
+		"de": "Es fehlt der Titel!",
+		"en": "The title is missing!",
+	},
+	"titleTooLong": {
+		"de": "Der Titel ist zu lang! Maximal %v Zeichen erlaubt.",
+		"en": "The title is too long! At most %v characters are allowed.",
+	},
+	"optionEmpty": {
+		"de": "Option %v ist leer!",
+		"en": "Option %v is empty!",
+	},
+	"optionTooLong": {
+		"de": "Option %v ist zu lang! Maximal %v Zeichen erlaubt.",
+		"en": "Option %v is too long! At most %v characters are allowed.",
+	},
+	"optionDuplicate": {
+		"de": "Option %v ist identisch mit Option %v!",
+		"en": "Option %v is identical to option %v!",
+	},
+	"twoOptions": {
+		"de": "Es müssen mindestens zwei Optionen angegeben werden!",
+		"en": "At least two options are required!",
+	},
+	"maxOptions": {
+		"de": "Es sind maximal %v Optionen erlaubt!",
+		"en": "At most %v options are allowed!",
+	},
+	"tooManyOptions": {
+		"de": "Zu viele Optionen!",
+		"en": "Too many options!",
+	},
+	"acclamationOption": {
+		"de": "Bei einer Anwesenheitsabfrage muss genau eine Option angegeben werden!",
+		"en": "An attendance check requires exactly one option!",
+	},
+	"invalidColor": {
+		"de": "Ungültige Farbe für Option %v!",
+		"en": "Invalid color of option %v!",
+	},
+	"groupTooLong": {
+		"de": "Die Gruppe der Option %v ist zu lang! Maximal %v Zeichen erlaubt.",
+		"en": "The group of option %v is too long! At most %v characters are allowed.",
+	},
+	"invalidPercentBase": {
+		"de": "Ungültige Prozentbasis!",
+		"en": "Invalid percentage base!",
+	},
+	"invalidChart": {
+		"de": "Ungültige Darstellung!",
+		"en": "Invalid chart!",
+	},
+	"hostNotAllowed": {
+		"de": "Diese Adresse ist nicht freigegeben!",
+		"en": "This address is not allowed!",
+	},
+	"timeoutFixed": {
+		"de": "Die Zeitbegrenzung kann nicht geändert werden!",
+		"en": "The timeout can not be changed!",
+	},
+	"timeoutRange": {
+		"de": "Die Zeitbegrenzung muss zwischen %v und %v Minuten liegen!",
+		"en": "The timeout must be between %v and %v minutes!",
+	},
+	"timeoutMin": {
+		"de": "Die Zeitbegrenzung muss mindestens eine Minute betragen!",
+		"en": "The timeout must be at least one minute!",
+	},
+	"countdownRange": {
+		"de": "Die Abstimmungszeit muss zwischen 0 und %v Sekunden liegen!",
+		"en": "The voting time must be between 0 and %v seconds!",
+	},
+	"rankedEncrypted": {
+		"de": "Rangfolgen können nicht verschlüsselt werden!",
+		"en": "Rankings can not be encrypted!",
+	},
+	"rankedRating": {
+		"de": "Eine Rangfolge kann keine Bewertung oder Anwesenheitsabfrage sein!",
+		"en": "A ranking can not be a rating or an attendance check!",
+	},
+	"ratingEncrypted": {
+		"de": "Bewertungen können nicht verschlüsselt werden!",
+		"en": "Ratings can not be encrypted!",
+	},
+	"ratingLevels": {
+		"de": "Eine Bewertung muss zwischen 2 und %v Stufen haben!",
+		"en": "A rating must have between 2 and %v levels!",
+	},
+	"ratingOptions": {
+		"de": "Für eine Bewertung mit %v Stufen müssen %v Optionen oder keine angegeben werden!",
+		"en": "A rating with %v levels requires %v options or none!",
+	},
+	"notRating": {
+		"de": "Die Frage ist keine Bewertung!",
+		"en": "The question is not a rating!",
+	},
+	"ratingRange": {
+		"de": "Die Bewertung muss zwischen 1 und %v liegen!",
+		"en": "The rating must be between 1 and %v!",
+	},
+	"draftEncrypted": {
+		"de": "Verschlüsselte Umfragen werden nicht zwischengespeichert!",
+		"en": "Encrypted surveys are not saved as drafts!",
+	},
+	"tooManyDrafts": {
+		"de": "Zu viele Entwürfe!",
+		"en": "Too many drafts!",
+	},
+	"correctOptionCount": {
+		"de": "Die Anzahl der Optionen kann nicht korrigiert werden!",
+		"en": "The number of options can not be corrected!",
+	},
+	"notEnoughVotes": {
+		"de": "Es sind noch nicht genug Stimmen abgegeben worden!",
+		"en": "Not enough votes have been cast yet!",
+	},
+	"revealNotPossible": {
+		"de": "Bei dieser Umfrage können die Optionen nicht einzeln aufgedeckt werden!",
+		"en": "The options of this survey can not be uncovered one at a time!",
+	},
+	"annotationHidden": {
+		"de": "Nur sichtbare Ergebnisse können kommentiert werden!",
+		"en": "Only visible results can be annotated!",
+	},
+	"annotationTooLong": {
+		"de": "Die Anmerkung ist zu lang!",
+		"en": "The annotation is too long!",
+	},
+	"viewerEncrypted": {
+		"de": "Verschlüsselte Umfragen können nur vom Ersteller angezeigt werden!",
+		"en": "Encrypted surveys can only be shown by their creator!",
+	},
+	"noEmailAddresses": {
+		"de": "Es wurden keine E-Mail-Adressen angegeben!",
+		"en": "No e-mail addresses were given!",
+	},
+	"codeCount": {
+		"de": "Es können zwischen 1 und %v Codes erzeugt werden!",
+		"en": "Between 1 and %v codes can be created!",
+	},
+	"thankYouTooLong": {
+		"de": "Die Dankesnachricht ist zu lang! Maximal %v Zeichen erlaubt.",
+		"en": "The thank-you message is too long! At most %v characters are allowed.",
+	},
+	"invalidRedirect": {
+		"de": "Die Weiterleitung muss eine http- oder https-Adresse sein!",
+		"en": "The redirect must be an http or https address!",
+	},
+	"seriesRange": {
+		"de": "Eine Serie kann höchstens %v Fragen haben!",
+		"en": "A series can have at most %v questions!",
+	},
+	"seriesPosition": {
+		"de": "Die Frage muss zwischen 1 und %v liegen!",
+		"en": "The question must be between 1 and %v!",
+	},
+	"invalidAdvance": {
+		"de": "Ungültige Zeit!",
+		"en": "Invalid time!",
+	},
+	"advanceRange": {
+		"de": "Die Zeit bis zur nächsten Frage muss zwischen 0 und %v Sekunden liegen!",
+		"en": "The time until the next question must be between 0 and %v seconds!",
+	},
+	"answerNotFound": {
+		"de": "Diese Antwort existiert nicht!",
+		"en": "This answer does not exist!",
+	},
+	"encryptedList": {
+		"de": "Bei verschlüsselten Umfragen kann die Liste nicht verwendet werden!",
+		"en": "The list can not be used in encrypted surveys!",
+	},
+	"invalidSeries": {
+		"de": "Ungültige Serie!",
+		"en": "Invalid series!",
+	},
+	"invalidSchedule": {
+		"de": "Ungültiger Termin!",
+		"en": "Invalid date!",
+	},
+	"invalidEMail": {
+		"de": "Ungültige E-Mail-Adresse!",
+		"en": "Invalid e-mail address!",
+	},
+	"invalidEMails": {
+		"de": "Ungültige E-Mail-Adressen gefunden!",
+		"en": "Invalid e-mail addresses found!",
+	},
+	"mailNotSent": {
+		"de": "Die E-Mail konnte nicht versendet werden!",
+		"en": "The e-mail could not be sent!",
+	},
+	"mailsNotSent": {
+		"de": "Nicht alle E-Mails konnten versendet werden!",
+		"en": "Not all e-mails could be sent!",
+	},
+	"loginFailed": {
+		"de": "Die Anmeldung ist fehlgeschlagen!",
+		"en": "The login has failed!",
+	},
+	"invalidCount": {
+		"de": "Ungültige Anzahl!",
+		"en": "Invalid number!",
+	},
+	"notRunning": {
+		"de": "Es gibt z.Z. keine Umfrage!",
+		"en": "There is no survey at the moment!",
+	},
+	"exportEncrypted": {
+		"de": "Verschlüsselte Umfragen können nur im Browser angezeigt werden!",
+		"en": "Encrypted surveys can only be shown in the browser!",
+	},
+	"resultHidden": {
+		"de": "Das Ergebnis ist noch verborgen!",
+		"en": "The result is still hidden!",
+	},
+	"bannerTooLong": {
+		"de": "Der Text ist zu lang! Maximal %v Zeichen erlaubt.",
+		"en": "The text is too long! At most %v characters are allowed.",
+	},
+	"bannerNotSaved": {
+		"de": "Der Text konnte nicht gespeichert werden!",
+		"en": "The text could not be saved!",
+	},
+	"archiveUnreadable": {
+		"de": "Die Datei konnte nicht gelesen werden: %v",
+		"en": "The file could not be read: %v",
+	},
+	"restoreFailed": {
+		"de": "Die Sicherung konnte nicht wiederhergestellt werden: %v",
+		"en": "The backup could not be restored: %v",
+	},
+
+	// the messages shown to the voters
+	"surveyEnded": {
+		"de": "Diese Umfrage war schon beendet!",
+		"en": "This survey has already ended!",
+	},
+	"resultVisible": {
+		"de": "Die Umfrageergebnisse sind bereits sichtbar!",
+		"en": "The results are already visible!",
+	},
+	"notRegistered": {
+		"de": "Sie sind für diese Umfrage nicht registriert!",
+		"en": "You are not registered for this survey!",
+	},
+	"votingTimeElapsed": {
+		"de": "Die Abstimmungszeit ist abgelaufen!",
+		"en": "The voting time has elapsed!",
+	},
+	"alreadyVoted": {
+		"de": "Sie haben bereits abgestimmt!",
+		"en": "You have already voted!",
+	},
+	"alreadyVotedDevice": {
+		"de": "Von diesem Gerät wurde bereits abgestimmt!",
+		"en": "A vote has already been cast from this device!",
+	},
+	"invalidOption": {
+		"de": "Ungültige Option!",
+		"en": "Invalid option!",
+	},
+	"rankAllOptions": {
+		"de": "Bitte ordnen Sie alle Optionen!",
+		"en": "Please rank all options!",
+	},
+	"answerEmpty": {
+		"de": "Die Antwort ist leer!",
+		"en": "The answer is empty!",
+	},
+	"answerTooLong": {
+		"de": "Die Antwort ist zu lang!",
+		"en": "The answer is too long!",
+	},
+	"codeRequired": {
+		"de": "Für diese Umfrage wird ein Zugangscode benötigt!",
+		"en": "An access code is required for this survey!",
+	},
+	"codeInvalid": {
+		"de": "Der Zugangscode ist ungültig oder wurde bereits verwendet!",
+		"en": "The access code is invalid or has already been used!",
+	},
+	"invalidLink": {
+		"de": "Der Link ist ungültig!",
+		"en": "The link is invalid!",
+	},
+	"reopenSurvey": {
+		"de": "Bitte öffnen Sie die Abstimmung erneut!",
+		"en": "Please open the survey again!",
+	},
+	"noSurvey": {
+		"de": "Es gibt noch keine Umfrage!",
+		"en": "There is no survey yet!",
+	},
+	"noNewSurvey": {
+		"de": "Es gibt noch keine neue Umfrage!",
+		"en": "There is no new survey yet!",
+	},
+	"invalidRating": {
+		"de": "Ungültige Bewertung!",
+		"en": "Invalid rating!",
+	},
+}
@@ -0,0 +1,332 @@
+package i18n
+
+// pages maps the german texts of the pages used by the creators and the
+// administrators to other languages
+var pages = map[string]map[string]string{
+	"en": {
+		"Impressum":   "Imprint",
+		"Datenschutz": "Privacy",
+		"Bei verschlüsselten Umfragen kann die Liste nicht verwendet werden. Bitte die Optionen einzeln eingeben.": "The list can not be used in encrypted surveys. Please enter the options one by one.",
+		"Die Texte dürfen maximal 100 Zeichen lang sein.":                                                          "The texts must not be longer than 100 characters.",
+		"Umfrage erzeugen": "Create survey",
+		"Fehler: Bitte die markierten Eingaben korrigieren!": "Error: Please correct the marked fields!",
+		"Fehler:":                                                  "Error:",
+		"Ergebnisse sind noch verborgen!":                          "The results are still hidden!",
+		"Ergebnisse sind sichtbar!":                                "The results are visible!",
+		"Noch keine Umfrage gestartet.":                            "No survey has been started yet.",
+		"Die Umfrage wird um %v Uhr wegen Inaktivität beendet!":    "The survey is ended at %v because of inactivity!",
+		"Verlängern":                                               "Extend",
+		"Der zuletzt bearbeitete Entwurf wurde wiederhergestellt.": "The last edited draft has been restored.",
+		"Verwerfen":                                                "Discard",
+		"Beim Neustart werden die %v bereits abgegebenen Stimmen gelöscht!": "Restarting deletes the %v votes already cast!",
+		"Trotzdem starten":                 "Start anyway",
+		"Abbrechen":                        "Cancel",
+		"Frage:":                           "Question:",
+		"Farbe der Option":                 "Color of the option",
+		"Gruppe":                           "Group",
+		"Gruppe der Option, z.B. Frontend": "Group of the option, e.g. frontend",
+		"Weitere Optionen, maximal %v":     "More options, at most %v",
+		"Liste:":                           "List:",
+		"Eine Option pro Zeile":            "One option per line",
+		"Die Zeilen ersetzen die einzelnen Optionen":    "The lines replace the single options",
+		"Übernimmt die Liste in die einzelnen Optionen": "Copies the list to the single options",
+		"Mehrfachauswahl erlauben":                      "Allow multiple choice",
+		"Die Umfrage hat nur eine Option, z.B. \"Ich bin da\". Gezählt wird die Anzahl der Teilnehmer.":                "The survey has only one option, e.g. \"I am here\". The number of participants is counted.",
+		"Anwesenheit/Zustimmung (nur eine Option)":                                                                     "Attendance/approval (only one option)",
+		"Die Teilnehmer bringen alle Optionen in eine Reihenfolge. Das Ergebnis wird nach der Borda-Zählung sortiert.": "The participants put all options in an order. The result is sorted by the Borda count.",
+		"Rangfolge":  "Ranking",
+		"Bewertung:": "Rating:",
+		"Die Teilnehmer vergeben einen Wert auf einer Skala. Die Optionen sind die Beschriftungen der Stufen, ohne Optionen werden die Zahlen verwendet.": "The participants choose a value on a scale. The options are the labels of the levels, without options the numbers are used.",
+		"keine":    "none",
+		"1 bis %v": "1 to %v",
+		"Optionen, die sich nur durch Groß- und Kleinschreibung oder Leerzeichen unterscheiden, werden zusammengefasst, statt einen Fehler anzuzeigen.": "Options which only differ in case or spaces are merged instead of showing an error.",
+		"Doppelte Optionen zusammenfassen": "Merge duplicate options",
+		"Die Teilnehmer können zusätzlich eine eigene Antwort eingeben. Diese werden getrennt angezeigt.": "The participants can additionally enter an answer of their own. These answers are shown separately.",
+		"Freie Antworten erlauben": "Allow free answers",
+		"Frage, Optionen und Ergebnis werden in diesem Browser verschlüsselt. Die Teilnehmer sehen nur die Nummern der Optionen, das Ergebnis kann nur in diesem Browser angezeigt werden.": "Question, options and result are encrypted in this browser. The participants only see the numbers of the options, the result can only be shown in this browser.",
+		"Ende-zu-Ende verschlüsseln": "End-to-end encryption",
+		"Zeigt die laufende Umfrage auf der Seite der öffentlichen Umfragen an.": "Shows the running survey on the page of the public surveys.",
+		"Öffentlich auflisten": "List publicly",
+		"Kündigt die Umfrage im Chat an und veröffentlicht das Ergebnis.": "Announces the survey in the chat and publishes the result.",
+		"Öffentlich ankündigen": "Announce publicly",
+		"Abstimmungszeit:":      "Voting time:",
+		"unbegrenzt":            "unlimited",
+		"Nach so vielen Sekunden werden keine Stimmen mehr angenommen und das Ergebnis wird angezeigt.": "After this many seconds no more votes are accepted and the result is shown.",
+		"Sekunden":        "seconds",
+		"Zeitbegrenzung:": "Time limit:",
+		"Die Umfrage wird nach so vielen Minuten ohne Aktivität beendet, erlaubt sind %v bis %v Minuten.": "The survey is ended after this many minutes without activity, %v to %v minutes are allowed.",
+		"Minuten":  "minutes",
+		"Adresse:": "Address:",
+		"Die Adresse, auf die der Link und der QR-Code zur Abstimmung zeigen": "The address the link and the QR code of the vote point to",
+		"Standard":                    "Default",
+		"Darstellung:":                "Chart:",
+		"Darstellung des Ergebnisses": "Chart of the result",
+		"Balken":                      "Bars",
+		"Torte":                       "Pie",
+		"Ring":                        "Donut",
+		"Zeigt die Option mit den meisten Stimmen zuerst.": "Shows the option with the most votes first.",
+		"sortiert":      "sorted",
+		"ohne Prozente": "without percentages",
+		"ohne Anzahl":   "without counts",
+		"Fasst die Stimmen der Optionen einer Gruppe zusammen.": "Adds up the votes of the options of a group.",
+		"nach Gruppen": "by groups",
+		"Bei Mehrfachauswahl beziehen sich die Prozente auf alle gewählten Optionen statt auf die Teilnehmer.": "In multiple choice the percentages refer to all selected options instead of the participants.",
+		"Prozent der Auswahlen": "Percent of selections",
+		"Zeigt, ob der Vorsprung der häufigsten Option vor der zweithäufigsten bei dieser Teilnehmerzahl statistisch signifikant ist (Vorzeichentest, 5%-Niveau).": "Shows whether the lead of the most frequent option over the second one is statistically significant with this number of participants (sign test, 5% level).",
+		"Signifikanz":                  "Significance",
+		"Sprache:":                     "Language:",
+		"Sprache der Abstimmungsseite": "Language of the vote page",
+		"Browser-Einstellung":          "Browser setting",
+		"Nach der Abstimmung:":         "After the vote:",
+		"Die Nachricht, die den Teilnehmern nach ihrer Stimmabgabe angezeigt wird":                                                     "The message shown to the participants after their vote",
+		"Die Teilnehmer werden nach ihrer Stimmabgabe auf diese Seite weitergeleitet, z.B. zu den Folien oder einem Feedback-Formular": "The participants are redirected to this page after their vote, e.g. to the slides or a feedback form",
+		"Hängt die Umfrage-ID und die Runde an die Adresse an, damit ein Formular die Antworten der Abstimmung zuordnen kann":          "Appends the survey id and the round to the address, so a form can relate the answers to the vote",
+		"Mit Kontext":                         "With context",
+		"Gilt für alle Fragen dieser Umfrage": "Applies to all questions of this survey",
+		"Übernehmen":                          "Apply",
+		"Serie:":                              "Series:",
+		"Frage":                               "Question",
+		"von":                                 "of",
+		"Den Teilnehmern wird angezeigt, wie viele Fragen der Serie noch folgen, die folgenden Fragen werden automatisch weitergezählt": "The participants are shown how many questions of the series follow, the following questions are counted automatically",
+		"Ohne Anzahl wird keine Serie angezeigt": "Without a number no series is shown",
+		"Automatisch weiter:":                    "Advance automatically:",
+		"Die vorgemerkten Fragen werden nacheinander automatisch gestartet, jeweils diese Zeit nach dem Aufdecken des Ergebnisses. Wird mit der Serie übernommen.": "The queued questions are started one after the other, each this time after the result has been uncovered. Is applied with the series.",
+		"s nach dem Aufdecken, %v Fragen vorgemerkt": "s after uncovering, %v questions queued",
+		"Löscht die vorgemerkten Fragen":             "Deletes the queued questions",
+		"Löschen":                                    "Delete",
+		"Die Umfrage ist gesperrt":                   "The survey is locked",
+		"Startet die Umfrage":                        "Starts the survey",
+		"Starten":                                    "Start",
+		"Merkt die Frage für die Serie vor, sie wird nach dem Aufdecken der vorherigen Frage automatisch gestartet": "Queues the question for the series, it is started automatically after the previous question has been uncovered",
+		"Vormerken": "Queue",
+		"Zeigt die Frage so, wie sie die Teilnehmer sehen werden": "Shows the question as the participants will see it",
+		"Ergebnisse anzeigen": "Show results",
+		"Verbirgt das Ergebnis wieder, die Abstimmung läuft weiter": "Hides the result again, the vote continues",
+		"Ergebnisse verbergen": "Hide results",
+		"Korrigiert den Titel und die Optionen der laufenden Umfrage, ohne die Stimmen zu löschen": "Corrects the title and the options of the running survey without deleting the votes",
+		"Korrigieren": "Correct",
+		"Löscht die Stimmen und stellt die laufende Frage erneut, das bisherige Ergebnis bleibt im Verlauf erhalten":   "Deletes the votes and asks the running question again, the former result is kept in the history",
+		"Alle Stimmen der laufenden Frage werden zurückgesetzt!":                                                       "All votes of the running question are reset!",
+		"Verhindert, dass die laufende Umfrage versehentlich neu gestartet wird und dabei alle Stimmen verloren gehen": "Prevents the running survey from being restarted by accident, which loses all votes",
+		"Entsperren":                       "Unlock",
+		"Sperren":                          "Lock",
+		"Öffnet die Seite mit dem QR-Code": "Opens the page with the QR code",
+		"Beamer/Ergebnis Seite":            "Projector/result page",
+		"Die Aufgabe ...":                  "The task ...",
+		"Das Thema ...":                    "The topic ...",
+		"Ja / Nein":                        "Yes / No",
+		"Erlaubt Ihnen, auch selbst eine Stimme abzugeben.": "Allows you to cast a vote yourself.",
+		"Selbst abstimmen": "Vote yourself",
+		"Holt die aktuelle Umfrage zurück in die Eingabefelder.": "Brings the current survey back into the input fields.",
+		"Zurückholen": "Retrieve",
+		"Erlaubt das Abspeichern einer Umfrage als Link im Browser.":          "Allows to save a survey as a link in the browser.",
+		"Lädt das aufgedeckte Ergebnis als CSV-Datei herunter.":               "Downloads the uncovered result as a CSV file.",
+		"Lädt das aufgedeckte Ergebnis als JSON-Datei herunter.":              "Downloads the uncovered result as a JSON file.",
+		"Zeigt die Ergebnisse aller bisher gestellten Fragen dieser Umfrage.": "Shows the results of all questions asked so far in this survey.",
+		"Verlauf": "History",
+		"Zeigt die Stimmen der laufenden Frage neben dem Ergebnis der vorherigen Frage, damit es weiter besprochen werden kann.": "Shows the votes of the running question next to the result of the previous question, so it can be discussed further.",
+		"Geteilte Ansicht": "Split view",
+		"Ein Link, der nur das Ergebnis anzeigt. Er kann auf einem unbeaufsichtigten Projektor geöffnet werden.": "A link which only shows the result. It can be opened on an unattended projector.",
+		"Projektor-Link": "Projector link",
+		"Erlaubt das Weitergeben der Umfrage an ein anderes Gerät!": "Allows to hand the survey over to another device!",
+		"Kontrolle Weitergeben":                                               "Hand over control",
+		"Versendet persönliche Abstimmungslinks per E-Mail.":                  "Sends personal vote links by e-mail.",
+		"Teilnehmer Einladen":                                                 "Invite participants",
+		"Erzeugt ausdruckbare Codes, die jeweils genau eine Stimme erlauben.": "Creates printable codes which allow exactly one vote each.",
+		"Zugangscodes": "Access codes",
+		"Erzeugt eine Kalendereinladung mit Link und QR-Code für die Teilnehmer.": "Creates a calendar invitation with the link and the QR code for the participants.",
+		"Termin Planen": "Schedule",
+		"Zeigt alle öffentlich aufgelisteten Umfragen.": "Shows all publicly listed surveys.",
+		"Öffentliche Umfragen":                          "Public surveys",
+		"Löschen der Umfrage und aller Access-Tokens":   "Deletes the survey and all access tokens",
+		"Beenden": "End",
+		"Mehrere Umfragen gleichzeitig durchführen und zwischen ihnen wechseln.": "Run several surveys at the same time and switch between them.",
+		"Meine Umfragen": "My surveys",
+		"Zeigt die Ergebnisse aller Umfragen Ihres Kontos nebeneinander.": "Shows the results of all surveys of your account side by side.",
+		"Übersicht":                               "Overview",
+		"Zeigt alle angemeldeten Geräte.":         "Shows all logged in devices.",
+		"Geräte":                                  "Devices",
+		"Schützt Ihr Konto mit einem Passkey.":    "Protects your account with a passkey.",
+		"Legt fest, wer Umfragen erstellen darf.": "Defines who is allowed to create surveys.",
+		"Rollen": "Roles",
+		"Zeigt einen Hinweis auf allen Seiten an.":                     "Shows a note on all pages.",
+		"Sichert alle gespeicherten Daten oder stellt sie wieder her.": "Backs up all stored data or restores it.",
+		"Datensicherung":    "Backup",
+		"Angemeldet als %v": "Logged in as %v",
+		"Abmelden":          "Log out",
+		"Erlaubt die Kontrolle der Umfragen von mehreren Geräten aus.": "Allows to control the surveys from several devices.",
+		"Anmelden": "Log in",
+		"Gibt diesem Browser eine neue Identität, z.B. auf einem gemeinsam genutzten Rechner.": "Gives this browser a new identity, e.g. on a shared computer.",
+		"Identität zurücksetzen": "Reset identity",
+		"Ergebnis":               "Result",
+		"%v Stimme abgegeben":    "%v vote cast",
+		"%v Stimmen abgegeben":   "%v votes cast",
+		"Die Umfrage ist verschlüsselt. Das Ergebnis kann nur im Browser des Erstellers angezeigt werden.": "The survey is encrypted. The result can only be shown in the browser of the creator.",
+		"Die Umfrage kann nicht entschlüsselt werden.":                                                     "The survey can not be decrypted.",
+		"Seite aktualisieren um QR-Code anzuzeigen!":                                                       "Reload the page to show the QR code!",
+		"WLAN":     "Wi-Fi",
+		"WLAN: %v": "Wi-Fi: %v",
+		"Deckt die Optionen mit den wenigsten Stimmen zuerst auf":   "Uncovers the options with the fewest votes first",
+		"Nächste Option aufdecken":                                  "Uncover next option",
+		"Alle aufdecken":                                            "Uncover all",
+		"Durchschnittlicher Rang":                                   "Average rank",
+		"Die Zahlen sind die Punkte der Borda-Zählung":              "The numbers are the points of the Borda count",
+		"Teilnehmer:":                                               "Participants:",
+		"Die Prozente beziehen sich auf alle gewählten Optionen":    "The percentages refer to all selected options",
+		"Auswahlen:":                                                "Selections:",
+		"Vorzeichentest der Stimmen der beiden häufigsten Optionen": "Sign test of the votes of the two most frequent options",
+		"%v vor %v:":                                                "%v ahead of %v:",
+		"signifikant":                                               "significant",
+		"nicht signifikant":                                         "not significant",
+		"(p %v)":                                                    "(p %v)",
+		"Mittelwert:":                                               "Mean:",
+		"Median:":                                                   "Median:",
+		"Weitere Antworten:":                                        "Other answers:",
+		"Als Option übernehmen":                                     "Add as an option",
+		"Verbergen":                                                 "Hide",
+		"Anmerkung, z.B. die getroffene Entscheidung":               "Note, e.g. the decision taken",
+		"Anmerkung":                                                 "Note",
+		"Die Anmerkung wird im Verlauf und im Export gespeichert": "The note is stored in the history and in the export",
+		"Speichern": "Save",
+		"Es wurden noch keine Fragen abgeschlossen.": "No questions have been completed yet.",
+		"Bericht":              "Report",
+		"verschlüsselte Frage": "encrypted question",
+		"%v bis %v":            "%v to %v",
+		"Zurück":               "Back",
+		"Im Druckdialog kann der Bericht auch als PDF gespeichert werden": "The print dialog can also save the report as a PDF",
+		"Drucken / PDF": "Print / PDF",
+		"Der Bericht und die Ergebnisse als CSV-Datei": "The report and the results as a CSV file",
+		"Herunterladen":        "Download",
+		"Beginn:":              "Start:",
+		"Erstellt:":            "Created:",
+		"Fragen:":              "Questions:",
+		"Stimmen:":             "Votes:",
+		"Teilnehmer je Frage:": "Participants per question:",
+		"Nr.":                  "No.",
+		"Stimmen":              "Votes",
+		"Gestartet":            "Started",
+		"Abrufe des Ergebnisses, davon im verborgenen Zustand": "Requests of the result, of these while hidden",
+		"Abrufe": "Requests",
+		"Anzahl verschiedener Betrachter des Ergebnisses": "Number of different viewers of the result",
+		"Betrachter":                             "Viewers",
+		"verschlüsselt":                          "encrypted",
+		"verborgen":                              "hidden",
+		"sichtbar":                               "visible",
+		"zuerst %v, zuletzt %v":                  "first %v, last %v",
+		"(%v verborgen)":                         "(%v hidden)",
+		"aktuelle Umfrage":                       "current survey",
+		"Steuert diese Umfrage mit dem Formular": "Controls this survey with the form",
+		"Wechseln":                               "Switch",
+		"Die Umfrage und alle Stimmen werden gelöscht!":                 "The survey and all votes are deleted!",
+		"Zur Zeit laufen keine Umfragen.":                               "No surveys are running at the moment.",
+		"Die laufenden Umfragen bleiben erhalten.":                      "The running surveys are kept.",
+		"Weitere Umfrage erstellen":                                     "Create another survey",
+		"Verschlüsselte Umfrage":                                        "Encrypted survey",
+		"Das Ergebnis kann nur auf der Ergebnisseite angezeigt werden.": "The result can only be shown on the result page.",
+		"Aufdecken": "Uncover",
+		"Zeigt die Umfragen nacheinander an, z.B. auf einem Bildschirm im Foyer.": "Shows the surveys one after the other, e.g. on a screen in the foyer.",
+		"Karussell":                           "Carousel",
+		"Ergebnisse":                          "Results",
+		"Aktuelle Frage":                      "Current question",
+		"Vorherige Frage":                     "Previous question",
+		"Es gibt noch keine vorherige Frage.": "There is no previous question yet.",
+		"Das Ergebnis kann nur im Verlauf angezeigt werden.": "The result can only be shown in the history.",
+		"Das Ergebnis wurde nicht aufgedeckt.":               "The result has not been uncovered.",
+		"Es wurden %v Codes erzeugt, davon wurden %v bereits verwendet. Nur mit einem unbenutzten Code kann abgestimmt werden.": "%v codes have been created, %v of them have already been used. Only an unused code allows to vote.",
+		"Jeder Code erlaubt genau eine Stimme. Die Codes können ausgedruckt und an die Teilnehmer verteilt werden, der Code wird entweder auf der Abstimmungsseite eingegeben oder mit dem QR-Code geöffnet. Nicht verwendete Codes bleiben auch für die nächste Frage gültig. Ein erneutes Erzeugen ersetzt die bisherigen Codes.": "Each code allows exactly one vote. The codes can be printed and handed out to the participants, the code is either entered on the vote page or opened with the QR code. Unused codes stay valid for the next question. Creating the codes again replaces the former codes.",
+		"Anzahl:":         "Number:",
+		"Codes erzeugen":  "Create codes",
+		"Codes entfernen": "Remove codes",
+		"Drucken":         "Print",
+		"Erzeugt eine Kalendereinladung mit dem Link und dem QR-Code der Umfrage, die Sie vorab an die Teilnehmer versenden können.": "Creates a calendar invitation with the link and the QR code of the survey, which you can send to the participants in advance.",
+		"Die Umfrage wird bis zum geplanten Termin nicht gelöscht.":                                                                  "The survey is not deleted until the scheduled date.",
+		"Dauer (Minuten):":                    "Duration (minutes):",
+		"Einladung herunterladen":             "Download invitation",
+		"Es wurden %v Einladungen versendet.": "%v invitations have been sent.",
+		"Es sind %v Teilnehmer registriert. Nur diese können abstimmen.": "%v participants are registered. Only they can vote.",
+		"Jede angegebene E-Mail-Adresse erhält einen persönlichen Link zur Abstimmung. Mit diesem Link kann pro Frage genau eine Stimme abgegeben werden. Andere Teilnehmer können nicht mehr abstimmen. Ein erneutes Einladen ersetzt die bisher registrierten Teilnehmer.": "Each given e-mail address receives a personal link to the vote. This link allows exactly one vote per question. Other participants can no longer vote. Inviting again replaces the registered participants.",
+		"E-Mail-Adressen (eine pro Zeile):":                    "E-mail addresses (one per line):",
+		"Einladungen versenden":                                "Send invitations",
+		"Auf diesem Server ist kein Mailversand konfiguriert.": "No mail delivery is configured on this server.",
+		"Es wurde ein Anmelde-Link an %v versendet. Bitte öffnen Sie diesen Link auf dem Gerät, das Sie anmelden möchten.":                                                                                    "A login link has been sent to %v. Please open this link on the device you want to log in.",
+		"Eine Anmeldung ist nicht erforderlich. Sie erlaubt es aber, Ihre Umfragen von mehreren Geräten aus zu steuern. Sie erhalten per E-Mail einen Link, mit dem Sie das jeweilige Gerät anmelden können.": "A login is not required. But it allows you to control your surveys from several devices. You receive a link by e-mail which logs in the device.",
+		"E-Mail:":                "E-mail:",
+		"Anmelde-Link anfordern": "Request login link",
+		"Anmeldung über Ihre Einrichtung (Single Sign-On)": "Login via your institution (single sign-on)",
+		"Anmeldung mit Passkey":                            "Login with passkey",
+		"Sie sind angemeldet als %v.":                      "You are logged in as %v.",
+		"Für Ihr Konto sind %v Passkeys registriert. Eine Anmeldung ist nur noch mit einem Passkey möglich.": "%v passkeys are registered for your account. A login is only possible with a passkey.",
+		"Mit einem Passkey schützen Sie Ihre Umfragen davor, von jemandem übernommen zu werden, der Ihre Cookies kopiert. Nach der Registrierung ist eine Anmeldung per E-Mail nicht mehr möglich, und alle nicht mit einem Passkey angemeldeten Geräte werden abgemeldet.": "A passkey protects your surveys from being taken over by someone who copies your cookies. After the registration a login by e-mail is no longer possible, and all devices not logged in with a passkey are logged out.",
+		"Passkey registrieren":                             "Register passkey",
+		"Mit Passkey anmelden":                             "Log in with passkey",
+		"Auf diesem Server sind Passkeys nicht verfügbar.": "Passkeys are not available on this server.",
+		"Angemeldete Geräte":                               "Logged in devices",
+		"Angemeldet als %v.":                               "Logged in as %v.",
+		"Gerät":                                            "Device",
+		"Angemeldet":                                       "Logged in",
+		"Zuletzt aktiv":                                    "Last active",
+		"unbekannt":                                        "unknown",
+		"ja":                                               "yes",
+		"nein":                                             "no",
+		"dieses Gerät":                                     "this device",
+		"Ergebnis-Feed":                                    "Result feed",
+		"Die aufgedeckten Ergebnisse Ihrer Umfragen können als Atom-Feed abonniert werden. Jeder, der diese Adresse kennt, kann die Ergebnisse lesen.": "The uncovered results of your surveys can be subscribed to as an Atom feed. Everyone who knows this address can read the results.",
+		"Sie sind nicht angemeldet.": "You are not logged in.",
+		"Weitergeben":                "Hand over",
+		"Umfrage weitergeben!":       "Hand over the survey!",
+		"Mit dem Scannen dieses QR-Codes können Sie die Kontrolle über die Umfrage an das scannende Gerät weitergeben! Sie können so z.B. die Umfrage auf Ihrem Tablet bedienen und die Ergebnisse weiterhin am Beamer anzeigen lassen.": "By scanning this QR code you can hand the control of the survey over to the scanning device! This way you can e.g. operate the survey on your tablet and still show the results on the projector.",
+		"QR-Code": "QR code",
+		"Dieser Browser erhält eine neue Identität. Danach können die bisherigen Umfragen von diesem Browser aus nicht mehr gesteuert werden. Das ist auf gemeinsam genutzten Rechnern sinnvoll, bevor der nächste Nutzer den Rechner verwendet.": "This browser receives a new identity. Afterwards the former surveys can no longer be controlled from this browser. This is useful on shared computers before the next user uses the computer.",
+		"Die Anmeldung als %v wird auf diesem Gerät beendet.": "The login as %v is ended on this device.",
+		"Die laufende Umfrage und alle Stimmen löschen":       "Delete the running survey and all votes",
+		"Keine Berechtigung":  "No permission",
+		"Keine Berechtigung!": "No permission!",
+		"Sie haben nicht die erforderliche Berechtigung, um diese Seite zu nutzen. Bitte": "You do not have the permission required to use this page. Please",
+		"melden Sie sich an":         "log in",
+		"Beendet":                    "Ended",
+		"Die Umfrage wurde beendet!": "The survey has been ended!",
+		"Zur Zeit gibt es keine öffentlichen Umfragen.": "There are no public surveys at the moment.",
+		"Weiter":                                "Continue",
+		"darf nur abstimmen,":                   "may only vote,",
+		"darf fremde Umfragen steuern,":         "may control the surveys of others,",
+		"darf Umfragen erstellen,":              "may create surveys,",
+		"darf zusätzlich die Rollen verwalten.": "may additionally manage the roles.",
+		"Rolle aller anderen Benutzer:":         "Role of all other users:",
+		"E-Mail":                                "E-mail",
+		"Rolle":                                 "Role",
+		"Entfernen":                             "Remove",
+		"Hinzufügen":                            "Add",
+		"Der Text wird oben auf allen Seiten angezeigt, z.B. für Wartungshinweise oder WLAN-Zugangsdaten.": "The text is shown on top of all pages, e.g. for maintenance notes or the Wi-Fi credentials.",
+		"Ein leerer Text entfernt das Banner.":                                                               "An empty text removes the banner.",
+		"%v Dokumente wurden wiederhergestellt. Bitte starten Sie den Server neu.":                           "%v documents have been restored. Please restart the server.",
+		"Die Sicherung enthält alle gespeicherten Daten wie Rollen, Konten und Ergebnis-Feeds.":              "The backup contains all stored data like roles, accounts and result feeds.",
+		"Verschlüsselte Daten bleiben verschlüsselt und können nur mit denselben Schlüsseln gelesen werden.": "Encrypted data stays encrypted and can only be read with the same keys.",
+		"Sicherung herunterladen": "Download backup",
+		"Wiederherstellen":        "Restore",
+		"Vorhandene Daten mit gleichem Namen werden überschrieben.": "Existing data with the same name is overwritten.",
+		"Anschließend muss der Server neu gestartet werden.":        "Afterwards the server has to be restarted.",
+		"Administration":         "Administration",
+		"Server":                 "Server",
+		"Umfragen":               "Surveys",
+		"Stimmen seit dem Start": "Votes since the start",
+		"Stimmen pro Sekunde":    "Votes per second",
+		"Abgelaufene Umfragen":   "Expired surveys",
+		"Wartende Clients":       "Waiting clients",
+		"Abgewiesene Clients":    "Rejected clients",
+		"Goroutinen":             "Goroutines",
+		"Einstellungen":          "Settings",
+		"Umfragen werden nach so vielen Minuten ohne Aktivität gelöscht, wenn der Ersteller keine Zeitbegrenzung gewählt hat": "Surveys are deleted after this many minutes without activity if the creator has not chosen a time limit",
+		"Abstimmen bei sichtbarem Ergebnis erlauben":                      "Allow voting while the result is visible",
+		"Die Einstellungen gelten bis zum nächsten Neustart des Servers.": "The settings apply until the next restart of the server.",
+		"Ersteller": "Creator",
+		"Alter":     "Age",
+		"Kopie":     "copy",
+		"Die Umfrage wird mit allen Stimmen gelöscht!":       "The survey is deleted with all votes!",
+		"Es laufen keine Umfragen.":                          "No surveys are running.",
+		"Die Umfrage wurde gelöscht.":                        "The survey has been deleted.",
+		"Die Kontrolle kann nicht weitergegeben werden!":     "The control can not be passed on!",
+		"Das Konto ist durch einen Passkey geschützt. Bitte": "The account is protected by a passkey. Please",
+		"melden Sie sich mit dem Passkey an":                 "log in with the passkey",
+		"Die Einstellungen wurden übernommen.":               "The settings have been applied.",
+	},
+}
@@ -0,0 +1,25 @@
+package i18n
+
+// texts maps the german texts shown to the voters to other languages, the
+// error messages are contained in the message catalog
+var texts = map[string]map[string]string{
+	"en": {
+		"Umfrage":                           "Survey",
+		"Senden":                            "Send",
+		"Netzwerkfehler":                    "Network error",
+		"Sie haben erfolgreich abgestimmt!": "Your vote has been counted!",
+		"Zur nächsten Frage":                "Next question",
+		"Die Umfrage existiert nicht!":      "This survey does not exist!",
+		"Neu laden":                         "Reload",
+		"Die Umfrage ist nicht erreichbar!": "The survey is not reachable!",
+		"Die Frage wird nur auf der Präsentation angezeigt.": "The question is only shown on the presentation.",
+		"Der Server ist ausgelastet, bitte warten...":        "The server is busy, please wait...",
+		"Andere Antwort": "Other answer",
+		"Vorschau":       "Preview",
+		"Wählen Sie die Optionen nach Ihrer Präferenz:": "Select the options by your preference:",
+		"Zurücksetzen":       "Reset",
+		"Verbleibende Zeit:": "Remaining time:",
+		"Zugangscode":        "Access code",
+		"Frage %d von %d":    "Question %d of %d",
+	},
+}
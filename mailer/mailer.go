@@ -0,0 +1,74 @@
+package mailer
+
+import (
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Mailer sends plain text mails using an SMTP server.
+type Mailer struct {
+	addr string
+	host string
+	auth smtp.Auth
+	from string
+}
+
+// New creates a new mailer. If server is empty, nil is returned which
+// means that sending mails is not possible.
+func New(server, user, password, from string) *Mailer {
+	if server == "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(server)
+	if err != nil {
+		host = server
+		server = server + ":587"
+	}
+	var auth smtp.Auth
+	if user != "" {
+		auth = smtp.PlainAuth("", user, password, host)
+	}
+	return &Mailer{
+		addr: server,
+		host: host,
+		auth: auth,
+		from: from,
+	}
+}
+
+// Available returns true if mails can be sent
+func (m *Mailer) Available() bool {
+	return m != nil
+}
+
+// Send sends a plain text mail to the given address
+func (m *Mailer) Send(to, subject, body string) error {
+	if m == nil {
+		return errors.New("no mail server configured")
+	}
+	if strings.ContainsAny(to, "\r\n") {
+		return errors.New("invalid recipient")
+	}
+
+	var b strings.Builder
+	b.WriteString("From: " + m.from + "\r\n")
+	b.WriteString("To: " + to + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("utf-8", subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	err := smtp.SendMail(m.addr, m.auth, m.from, []string{to}, []byte(b.String()))
+	if err != nil {
+		return fmt.Errorf("could not send mail to %s: %w", to, err)
+	}
+	return nil
+}
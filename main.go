@@ -2,15 +2,33 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"flag"
+	"flashSurvey/account"
+	"flashSurvey/archive"
+	"flashSurvey/chat"
+	"flashSurvey/federation"
+	"flashSurvey/feed"
 	"flashSurvey/handler"
+	"flashSurvey/mailer"
+	"flashSurvey/mastodon"
+	"flashSurvey/matrix"
+	"flashSurvey/meeting"
+	"flashSurvey/mqtt"
+	"flashSurvey/randid"
+	"flashSurvey/saml"
+	"flashSurvey/script"
+	"flashSurvey/store"
 	"flashSurvey/survey"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
+	"time"
 )
 
 func main() {
@@ -18,9 +36,89 @@ func main() {
 	cert := flag.String("cert", "", "certificate pem")
 	key := flag.String("key", "", "certificate key")
 	timeOutMin := flag.Int("timeout", 30, "timeout in minutes")
+	minTimeout := flag.Int("minTimeout", 5, "minimum timeout in minutes the creators can choose for a survey")
+	maxTimeout := flag.Int("maxTimeout", 0, "maximum timeout in minutes the creators can choose for a survey, if not larger than minTimeout, the timeout can not be chosen")
 	voteIfVisible := flag.Bool("viv", false, "If this option is enabled, voting is still possible even if the results are already visible.")
 	debug := flag.Bool("debug", false, "debug mode")
 	port := flag.Int("port", 8080, "port")
+	smtpServer := flag.String("smtp", "", "smtp server used to send mails, e.g. mail.example.com:587")
+	smtpUser := flag.String("smtpUser", "", "smtp user")
+	smtpPass := flag.String("smtpPass", "", "smtp password")
+	mailFrom := flag.String("mailFrom", "", "sender address of mails")
+	storeDir := flag.String("store", "", "directory used to persist data, if empty, nothing is persisted")
+	backupFile := flag.String("backup", "", "writes all stored data to the given archive file and exits")
+	restoreFile := flag.String("restore", "", "restores the stored data from the given archive file and exits")
+	migrateTo := flag.String("migrateTo", "", "copies all stored data to the given store directory, verifies the copy and exits")
+	rotateStoreKey := flag.Bool("rotateStoreKey", false, "re-encrypts all stored documents with the first key of "+store.KeysEnv+" and exits")
+	samlMetadata := flag.String("samlIdp", "", "metadata file of the SAML identity provider, enables the SAML login")
+	samlAttr := flag.String("samlAttr", "mail", "SAML attribute used as the account id, if missing, the NameID is used")
+	admins := flag.String("admin", "", "comma separated list of e-mail addresses which always have the admin role")
+	imprintFile := flag.String("imprint", "", "Markdown file containing the imprint")
+	privacyFile := flag.String("privacy", "", "Markdown file containing the privacy policy")
+	hookScript := flag.String("hookScript", "", "template file containing hooks executed on survey events")
+	mqttBroker := flag.String("mqtt", "", "mqtt broker the results are published to, e.g. tcp://broker:1883")
+	mqttTopic := flag.String("mqttTopic", "flashSurvey", "mqtt topic prefix, the results are published to <prefix>/<hash of the survey id>")
+	mqttUser := flag.String("mqttUser", "", "mqtt user")
+	mqttPass := flag.String("mqttPass", "", "mqtt password")
+	matrixServer := flag.String("matrix", "", "matrix homeserver used to announce surveys, e.g. https://matrix.org")
+	matrixToken := flag.String("matrixToken", "", "access token of the matrix bot")
+	matrixRoom := flag.String("matrixRoom", "", "id of the matrix room the surveys are announced in")
+	mastodonServer := flag.String("mastodon", "", "mastodon server the results are posted to, e.g. https://mastodon.social")
+	mastodonToken := flag.String("mastodonToken", "", "access token of the mastodon account")
+	mastodonVisibility := flag.String("mastodonVisibility", "unlisted", "visibility of the mastodon posts: public, unlisted or private")
+	mastodonPoll := flag.Int("mastodonPoll", 0, "if set, announced surveys are mirrored as mastodon polls running the given number of minutes")
+	archiveEndpoint := flag.String("archive", "", "S3 compatible object storage the results of closed and expired surveys are archived to, e.g. https://s3.eu-central-1.amazonaws.com")
+	archiveBucket := flag.String("archiveBucket", "", "bucket of the archive")
+	archiveRegion := flag.String("archiveRegion", "us-east-1", "region of the archive")
+	archiveKey := flag.String("archiveKey", "", "access key of the archive")
+	archiveSecret := flag.String("archiveSecret", "", "secret key of the archive")
+	archivePrefix := flag.String("archivePrefix", "flashSurvey/", "prefix of the archived objects")
+	archiveDays := flag.Int("archiveDays", 0, "if set, the archived objects are deleted by the storage after the given number of days")
+	twitchChannel := flag.String("twitchChannel", "", "twitch channel whose chat can vote on announced surveys")
+	twitchUser := flag.String("twitchUser", "", "twitch user used to read the chat")
+	twitchToken := flag.String("twitchToken", "", "oauth token of the twitch user")
+	youtubeChat := flag.String("youtubeChat", "", "id of the youtube live chat which can vote on announced surveys")
+	youtubeKey := flag.String("youtubeKey", "", "youtube data api key")
+	bbbSecret := flag.String("bbbSecret", "", "shared secret used to sign the BigBlueButton launch links")
+	zoomSecret := flag.String("zoomSecret", "", "client secret of the Zoom App")
+	federationPeers := flag.String("federationPeers", "", "comma separated list of name=secret pairs of the relays allowed to forward votes")
+	federationOrigin := flag.String("federationOrigin", "", "if set, this instance relays the votes on surveys of the given origin instance")
+	federationName := flag.String("federationName", "", "name of this relay known to the origin")
+	federationSecret := flag.String("federationSecret", "", "secret shared with the origin")
+	replicaOf := flag.String("replicaOf", "", "if set, this instance is a read replica of the given primary instance, it authenticates by federationName and federationSecret")
+	readTimeout := flag.Duration("readTimeout", 10*time.Second, "maximum duration for reading a request")
+	writeTimeout := flag.Duration("writeTimeout", 15*time.Second, "maximum duration for writing a response")
+	idleTimeout := flag.Duration("idleTimeout", 2*time.Minute, "maximum time an idle keep-alive connection is kept open")
+	expiryWarning := flag.Duration("expiryWarning", 5*time.Minute, "time before the timeout of a survey its creator is warned")
+	hosts := flag.String("hosts", "", "comma separated list of additional hosts the creators can use in the vote links, e.g. https://vote.conf.example")
+	wifiSsid := flag.String("wifiSsid", "", "if set, a QR code to join this Wi-Fi network is shown next to the vote QR code")
+	wifiPass := flag.String("wifiPass", "", "password of the Wi-Fi network")
+	wifiSecurity := flag.String("wifiSecurity", "WPA", "security of the Wi-Fi network: WPA, WEP or nopass")
+	sharedIdle := flag.Duration("sharedIdle", 0, "if set, the creator identity of a browser expires after the given idle time, intended for shared podium computers")
+	longPollTimeout := flag.Duration("longPollTimeout", 40*time.Second, "maximum duration of the long-polling requests of the result page")
+	uploadTimeout := flag.Duration("uploadTimeout", 5*time.Minute, "maximum duration of the upload and download of backups")
+	maxVoteRequests := flag.Int("maxVoteRequests", 500, "maximum number of vote requests handled at the same time, 0 means unlimited")
+	retryAfter := flag.Duration("retryAfter", 2*time.Second, "time after which rejected vote requests are retried by the browser")
+	voteBatch := flag.Duration("voteBatch", 0, "if set, the votes are aggregated in the given interval, which helps with very large audiences")
+	profiling := flag.Bool("pprof", false, "serves the runtime profiles at /debug/pprof/ to administrators")
+	statsInterval := flag.Duration("stats", 0, "if set, memory and goroutine statistics are logged in the given interval")
+	maxWaiters := flag.Int("maxWaiters", 1000, "maximum number of clients waiting for the result of a single survey, 0 means unlimited")
+	apiTokens := flag.String("apiTokens", "", "comma separated list of name=token pairs of the external tools allowed to use the API at /api/v1/")
+	metricsToken := flag.String("metricsToken", "", "if set, the metrics are served at /metrics to scrapers sending this bearer token")
+	adminAddr := flag.String("adminAddr", "", "address of the management listener, e.g. 127.0.0.1:9090, if set, the admin pages, the metrics, the profiles and the health check are only served by this listener")
+	adminCA := flag.String("adminCA", "", "PEM file of the certificate authorities issuing the client certificates of the management listener, if set, the listener uses TLS and requires a client certificate, its default address is :8443")
+	strictVoting := flag.Bool("strictVoting", false, "deduplicates the votes by a key in the vote links, a signed cookie and a fingerprint of the address and user agent, the clients must connect directly and not share their address, e.g. by a NAT")
+	maxOptions := flag.Int("maxOptions", 30, "maximum number of options of a survey, 0 means unlimited")
+	voteRate := flag.Int("voteRate", 0, "maximum number of requests per minute of a single address to the vote pages, 0 means unlimited, all voters behind a NAT share the limit")
+	voteBurst := flag.Int("voteBurst", 0, "number of vote requests a single address can send at once, 0 means voteRate")
+	createRate := flag.Int("createRate", 0, "maximum number of requests per minute of a single address to the create page, 0 means unlimited")
+	createBurst := flag.Int("createBurst", 0, "number of requests to the create page a single address can send at once, 0 means createRate")
+	maxSurveys := flag.Int("maxSurveys", 0, "maximum number of surveys a single user can run at the same time, 0 means unlimited")
+	idLength := flag.Int("idLength", randid.DefaultLength, "number of characters of the survey and user ids, changing it invalidates the existing links and user ids")
+	idAlphabet := flag.String("idAlphabet", randid.DefaultAlphabet, "characters of the survey and user ids, letters, digits, '-' and '_' are allowed")
+	adminPassword := flag.String("adminPassword", "", "password of the user admin of the operator page /admin/, it can also be given as a hash created by htpasswd -m or -s")
+	adminHtpasswd := flag.String("adminHtpasswd", "", "htpasswd file containing the users of the operator page /admin/")
+	strictEscaping := flag.Bool("strictEscaping", false, "removes bidi control characters from the results and the questions and checks their markup before it is sent to the projector or the voters")
 	flag.Parse()
 
 	log.Println("QR-Host:", *host)
@@ -28,49 +126,447 @@ func main() {
 	log.Println("voteIfVisible:", *voteIfVisible)
 	log.Println("port:", *port)
 
+	err := randid.Configure(*idLength, *idAlphabet)
+	if err != nil {
+		log.Fatal(err)
+	}
 	surveys := survey.New(*host, *timeOutMin, *voteIfVisible, *debug)
+	surveys.EnableVoteBatching(*voteBatch)
+	surveys.SetMaxWaiters(*maxWaiters)
+	surveys.SetMaxOptions(*maxOptions)
+	surveys.SetMaxSurveys(*maxSurveys)
+	if *strictVoting {
+		surveys.EnableStrictVoting()
+	}
+	surveys.SetExpiryWarning(*expiryWarning)
+	surveys.SetTimeoutBounds(*minTimeout, *maxTimeout)
+	surveys.SetHosts(splitHosts(*hosts))
+	handler.SetStrictEscaping(*strictEscaping)
+	err = handler.SetWiFi(*wifiSsid, *wifiPass, *wifiSecurity)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logStats(*statsInterval, surveys)
+	m := mailer.New(*smtpServer, *smtpUser, *smtpPass, *mailFrom)
+	sc, err := script.Load(*hookScript, m)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if sc != nil {
+		survey.RegisterHooks(sc.Hooks())
+	}
+	if *mqttBroker != "" {
+		mc, err := mqtt.NewClient(*mqttBroker, "flashSurvey-"+randid.String()[:8], *mqttUser, *mqttPass)
+		if err != nil {
+			log.Fatal(err)
+		}
+		survey.RegisterHooks(mqtt.Hooks(mc, *mqttTopic))
+	}
+	rawStore, err := store.New(*storeDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *migrateTo != "" {
+		dst, err := store.New(*migrateTo)
+		if err != nil {
+			log.Fatal(err)
+		}
+		n, digest, err := store.Migrate(rawStore, dst)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("migrated %d documents to %s, digest %s", n, *migrateTo, digest)
+		return
+	}
+	if *backupFile != "" || *restoreFile != "" {
+		err = backupOrRestore(rawStore, *backupFile, *restoreFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	st := rawStore
+	if keys := os.Getenv(store.KeysEnv); keys != "" {
+		st, err = store.NewEncrypted(st, keys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Println("store encryption enabled")
+	}
+	if *rotateStoreKey {
+		n, err := store.Rotate(st)
+		if err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("re-encrypted %d documents", n)
+		return
+	}
+	accounts := account.New(*host)
+	err = accounts.LoadRoles(st, strings.Split(*admins, ","))
+	if err != nil {
+		log.Fatal(err)
+	}
+	surveys.SetAccounts(accounts)
+	feeds, err := feed.New(st, accounts, *host)
+	if err != nil {
+		log.Fatal(err)
+	}
+	survey.RegisterHooks(feeds.Hooks())
+	bot, err := matrix.New(*matrixServer, *matrixToken, *matrixRoom, surveys)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if bot != nil {
+		survey.RegisterHooks(bot.Hooks())
+	}
+	masto, err := mastodon.New(*mastodonServer, *mastodonToken, *mastodonVisibility)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if masto != nil {
+		survey.RegisterHooks(masto.Hooks())
+		if *mastodonPoll > 0 {
+			survey.RegisterHooks(masto.PollHooks(surveys, time.Duration(*mastodonPoll)*time.Minute))
+		}
+	}
+	arch, err := archive.New(*archiveEndpoint, *archiveBucket, *archiveRegion, *archiveKey, *archiveSecret, *archivePrefix)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if arch != nil {
+		if *archiveDays > 0 {
+			err = arch.SetExpiration(*archiveDays)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		survey.RegisterHooks(arch.Hooks())
+	}
+	streamChat := *twitchChannel != "" || *youtubeChat != ""
+	if streamChat {
+		in := chat.NewIngest(surveys)
+		survey.RegisterHooks(in.Hooks())
+		_, err = chat.NewTwitch(in, *twitchChannel, *twitchUser, *twitchToken)
+		if err != nil {
+			log.Fatal(err)
+		}
+		_, err = chat.NewYouTube(in, *youtubeChat, *youtubeKey)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	announce := bot != nil || masto != nil || streamChat
+	err = handler.LoadBanner(st)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sp, err := saml.New(*samlMetadata, *host, *samlAttr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	imprint, err := handler.Legal("Impressum", "imprint", *imprintFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	privacy, err := handler.Legal("Datenschutz", "privacy", *privacyFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	peers, err := federation.ParsePeers(*federationPeers)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tokens, err := handler.ParseAPITokens(*apiTokens)
+	if err != nil {
+		log.Fatal(err)
+	}
+	relay, err := federation.NewRelay(surveys, *federationOrigin, *federationName, *federationSecret)
+	if err != nil {
+		log.Fatal(err)
+	}
+	replica, err := federation.NewReplica(surveys, *replicaOf, *federationName, *federationSecret)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if replica != nil && relay != nil {
+		log.Fatal("an instance can not be a relay and a read replica")
+	}
+	var votes handler.VoteBackend = surveys
+	if relay != nil {
+		votes = relay
+	}
+	ensureUserId := handler.EnsureUserId(accounts)
+	canCreate := handler.RequireRole(accounts, account.Role.CanCreate)
+	canControl := handler.RequireRole(accounts, account.Role.CanControl)
+	// canWatch is used for the result updates which are no activity of the creator
+	canWatch := canControl
+	if *sharedIdle > 0 {
+		shared := handler.NewSharedComputer(*sharedIdle)
+		requireControl := canControl
+		canControl = func(h http.HandlerFunc) http.HandlerFunc {
+			return shared.Active(requireControl(h))
+		}
+		canWatch = func(h http.HandlerFunc) http.HandlerFunc {
+			return shared.Passive(requireControl(h))
+		}
+	}
+	canAdminister := handler.RequireRole(accounts, account.Role.CanAdminister)
+	viewer := handler.Viewer(surveys)
+	longPoll := handler.Timeout(*longPollTimeout)
+	upload := handler.Timeout(*uploadTimeout)
+	voteLimit := handler.Limit(*maxVoteRequests, *retryAfter)
+	voteRateLimit := handler.RateLimit(*voteRate, *voteBurst)
+	createRateLimit := handler.RateLimit(*createRate, *createBurst)
+	validate := handler.ValidateQuery
 
-	http.HandleFunc("/", handler.EnsureUserId(handler.Create(surveys)))
-	http.Handle("/static/", Cache(handler.Static(), 300, !*debug))
-	http.HandleFunc("/result/", handler.EnsureUserId(handler.Result(surveys)))
-	http.HandleFunc("/resultRest/", handler.EnsureUserId(handler.ResultRest(surveys)))
-	http.HandleFunc("/vote/", handler.EnsureUserId(handler.Vote(surveys)))
-	http.HandleFunc("/voteRest/", handler.EnsureUserId(handler.VoteRest(surveys)))
-	http.HandleFunc("/move/", handler.EnsureUserId(handler.Move(surveys)))
-	http.HandleFunc("/clear/", handler.EnsureUserId(handler.Clear(surveys)))
+	http.HandleFunc("/", createRateLimit(validate(ensureUserId(canControl(handler.Create(surveys, accounts, announce))))))
+	http.HandleFunc("/draft/", validate(ensureUserId(canControl(handler.Draft(surveys, announce)))))
+	static := Cache(handler.Static(), 300, !*debug)
+	http.Handle("/static/", static)
+	http.HandleFunc("/result/", validate(ensureUserId(canControl(handler.Result(surveys)))))
+	http.HandleFunc("/resultWs/", ensureUserId(canWatch(handler.ResultWs(surveys))))
+	http.HandleFunc("/resultRest/", longPoll(validate(ensureUserId(canWatch(handler.ResultRest(surveys))))))
+	http.HandleFunc("/resultHeartbeat/", ensureUserId(canWatch(handler.Heartbeat(surveys))))
+	http.HandleFunc("/viewer/", ensureUserId(canControl(handler.ViewerLink(surveys))))
+	http.HandleFunc("/display/", validate(viewer(handler.Result(surveys))))
+	http.HandleFunc("/displayWs/", validate(viewer(handler.ResultWs(surveys))))
+	http.HandleFunc("/displayRest/", longPoll(validate(viewer(handler.ResultRest(surveys)))))
+	http.HandleFunc("/displayHeartbeat/", validate(viewer(handler.Heartbeat(surveys))))
+	http.HandleFunc("/promote/", ensureUserId(canControl(handler.Promote(surveys))))
+	http.HandleFunc("/annotate/", ensureUserId(canControl(handler.Annotate(surveys))))
+	http.HandleFunc("/reveal/", validate(ensureUserId(canControl(handler.Reveal(surveys)))))
+	http.HandleFunc("/my/", ensureUserId(canControl(handler.My(surveys))))
+	http.HandleFunc("/dashboard/", ensureUserId(canControl(handler.Dashboard)))
+	http.HandleFunc("/carousel/", ensureUserId(canControl(handler.Carousel)))
+	http.HandleFunc("/split/", ensureUserId(canControl(handler.Split)))
+	http.HandleFunc("/splitRest/", ensureUserId(canWatch(handler.SplitRest(surveys))))
+	http.HandleFunc("/dashboardRest/", validate(ensureUserId(canWatch(handler.DashboardRest(surveys)))))
+	http.HandleFunc("/vote/", voteRateLimit(validate(ensureUserId(handler.Vote(votes)))))
+	http.HandleFunc("/voteEvents/", validate(handler.VoteEvents(surveys)))
+	http.HandleFunc("/voteRest/", voteRateLimit(voteLimit(validate(ensureUserId(handler.VoteRest(votes))))))
+	http.HandleFunc("/move/", ensureUserId(canControl(handler.Move(surveys))))
+	http.HandleFunc("/login/", ensureUserId(handler.Login(surveys, accounts, m, sp, *host)))
+	http.HandleFunc("/saml/login", handler.SAMLLogin(sp))
+	http.HandleFunc("/saml/metadata", handler.SAMLMetadata(sp))
+	http.HandleFunc("/saml/acs", handler.ResubmitWithCookies(ensureUserId(handler.SAMLACS(surveys, accounts, sp))))
+	http.HandleFunc("/logout/", ensureUserId(handler.Logout(accounts)))
+	http.HandleFunc("/reset-identity", ensureUserId(handler.ResetIdentity(surveys, accounts)))
+	http.HandleFunc("/sessions/", ensureUserId(handler.Sessions(surveys, accounts, feeds)))
+	http.HandleFunc("/passkey/", ensureUserId(handler.Passkey(accounts)))
+	http.HandleFunc("/passkeyRest/", ensureUserId(handler.PasskeyRest(accounts)))
+	http.HandleFunc("/register/", ensureUserId(canCreate(handler.Register(surveys, m))))
+	http.HandleFunc("/codes/", ensureUserId(canCreate(handler.Codes(surveys))))
+	http.HandleFunc("/calendar/", ensureUserId(canCreate(handler.Calendar(surveys))))
+	http.HandleFunc("/history/", validate(ensureUserId(canControl(handler.History(surveys)))))
+	http.HandleFunc("/historyRest/", ensureUserId(canControl(handler.HistoryRest(surveys))))
+	http.HandleFunc("/report/", validate(ensureUserId(canControl(handler.Report(surveys)))))
+	http.HandleFunc("/export/", validate(ensureUserId(canControl(handler.Export(surveys)))))
+	http.HandleFunc("/extend/", validate(ensureUserId(canControl(handler.Extend(surveys)))))
+	http.HandleFunc("/clear/", validate(ensureUserId(canControl(handler.Clear(surveys)))))
 	http.HandleFunc("/finished/", handler.Finished)
+	http.HandleFunc("/badge/", validate(handler.Badge(surveys)))
+	http.HandleFunc("/browse/", handler.Browse(surveys))
+	if len(peers) > 0 {
+		http.HandleFunc("/federation/", longPoll(validate(handler.Federation(surveys, peers))))
+	}
+	if len(tokens) > 0 {
+		api := handler.API(surveys, tokens)
+		http.HandleFunc("/api/v1/surveys", api)
+		http.HandleFunc("/api/v1/surveys/", api)
+	}
+	http.HandleFunc("/feed/", handler.Feed(feeds))
+	http.Handle("/imprint", imprint)
+	if l := meeting.New(*bbbSecret, *zoomSecret); l != nil {
+		handler.SetVoterTokenVerifier(l.VerifyVoterToken)
+		http.HandleFunc("/meeting/", ensureUserId(handler.Meeting(surveys, accounts, l)))
+	}
+	http.Handle("/privacy", privacy)
+
+	// if the management listener is enabled, the management routes are only
+	// served by it, so the reverse proxy of the public listener never has to
+	// route them. The listener replaces the metrics token, a client
+	// certificate also replaces the login required for the profiles.
+	admin := http.DefaultServeMux
+	certified := *adminCA != ""
+	if certified && *adminAddr == "" {
+		*adminAddr = ":8443"
+	}
+	separate := *adminAddr != ""
+	if separate {
+		admin = http.NewServeMux()
+		admin.Handle("/static/", static)
+		handler.SeparateAdminPages()
+	}
+	admin.HandleFunc("/healthz", handler.Health(surveys))
+	admin.HandleFunc("/roles/", ensureUserId(canAdminister(handler.Roles(accounts))))
+	admin.HandleFunc("/banner/", ensureUserId(canAdminister(handler.Banner)))
+	admin.HandleFunc("/bannerRest/", ensureUserId(canAdminister(handler.BannerRest)))
+	admin.HandleFunc("/backup/", upload(ensureUserId(canAdminister(handler.Backup(rawStore)))))
+	operators, err := adminUsers(*adminPassword, *adminHtpasswd)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if operators != nil {
+		admin.HandleFunc("/admin/", handler.AdminAuth(operators)(handler.Admin(surveys)))
+	}
+	if *metricsToken != "" || separate {
+		admin.HandleFunc("/metrics", handler.Metrics(surveys, *metricsToken))
+	}
+	if *profiling {
+		if certified {
+			admin.HandleFunc("/debug/pprof/", handler.Profile)
+		} else {
+			admin.HandleFunc("/debug/pprof/", ensureUserId(canAdminister(handler.Profile)))
+		}
+	}
+
+	// the timeouts protect against slow clients, routes which need more
+	// time extend them by the Timeout middleware
+	serv := &http.Server{
+		Addr:              ":" + strconv.Itoa(*port),
+		ReadHeaderTimeout: *readTimeout,
+		ReadTimeout:       *readTimeout,
+		WriteTimeout:      *writeTimeout,
+		IdleTimeout:       *idleTimeout,
+	}
+	if replica != nil {
+		log.Println("read replica of", replica.Primary())
+		serv.Handler = replicaMux(replica, surveys, ensureUserId, longPoll, voteLimit, voteRateLimit, static)
+	}
+
+	var certs *certReloader
+	if *cert != "" && *key != "" {
+		certs, err = newCertReloader(*cert, *key)
+		if err != nil {
+			log.Fatal(err)
+		}
+		serv.TLSConfig = &tls.Config{GetCertificate: certs.GetCertificate}
+	}
+	var adminServ *http.Server
+	if separate {
+		adminServ = &http.Server{
+			Addr:              *adminAddr,
+			Handler:           admin,
+			ReadHeaderTimeout: *readTimeout,
+			ReadTimeout:       *readTimeout,
+			WriteTimeout:      *writeTimeout,
+			IdleTimeout:       *idleTimeout,
+		}
+		if certified {
+			adminServ.TLSConfig, err = adminTLS(*adminCA, certs)
+			if err != nil {
+				log.Fatal(err)
+			}
+			adminServ.Handler = logClient(admin)
+		}
+		go func() {
+			var err error
+			if certified {
+				log.Println("Starting management server with client certificates on", *adminAddr)
+				err = adminServ.ListenAndServeTLS("", "")
+			} else {
+				log.Println("Starting management server on", *adminAddr)
+				err = adminServ.ListenAndServe()
+			}
+			if !errors.Is(err, http.ErrServerClosed) {
+				log.Fatal(err)
+			}
+		}()
+	}
 
-	serv := &http.Server{Addr: ":" + strconv.Itoa(*port)}
+	// on SIGHUP the certificate and the legal texts are read again, the
+	// running server and its open connections are kept
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			log.Print("reloading configuration")
+			if certs != nil {
+				if err := certs.Reload(); err != nil {
+					log.Println(err)
+				}
+			}
+			for _, l := range []*handler.LegalPage{imprint, privacy} {
+				if err := l.Reload(); err != nil {
+					log.Println(err)
+				}
+			}
+		}
+	}()
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+	drained := make(chan struct{})
 	go func() {
 		sig := <-c
 		log.Print("terminated by signal ", sig.String())
 
+		// release the long-polling requests, otherwise the shutdown
+		// waits for their timeouts
+		surveys.Close()
 		err := serv.Shutdown(context.Background())
 		if err != nil {
 			log.Println(err)
 		}
+		// the health check reports the shutdown until the requests are drained
+		if adminServ != nil {
+			err := adminServ.Shutdown(context.Background())
+			if err != nil {
+				log.Println(err)
+			}
+		}
+		close(drained)
 		for {
 			<-c
 		}
 	}()
 
-	var err error
-	if *cert != "" && *key != "" {
+	if certs != nil {
 		log.Println("Starting server with TLS")
-		err = serv.ListenAndServeTLS(*cert, *key)
+		err = serv.ListenAndServeTLS("", "")
 	} else {
 		log.Println("Starting server without TLS")
 		err = serv.ListenAndServe()
 	}
-	if err != nil {
+	if errors.Is(err, http.ErrServerClosed) {
+		// wait until the running requests are completed
+		<-drained
+	} else if err != nil {
 		log.Println(err)
 	}
 
 }
 
+// backupOrRestore writes the stored documents to the backup file or
+// restores them from the restore file
+func backupOrRestore(st store.Store, backupFile, restoreFile string) error {
+	if backupFile != "" {
+		f, err := os.Create(backupFile)
+		if err != nil {
+			return err
+		}
+		n, err := store.Backup(st, f)
+		if err != nil {
+			f.Close()
+			return err
+		}
+		log.Printf("wrote %d documents to %s", n, backupFile)
+		return f.Close()
+	}
+
+	f, err := os.Open(restoreFile)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	n, err := store.Restore(st, f)
+	if err != nil {
+		return err
+	}
+	log.Printf("restored %d documents from %s", n, restoreFile)
+	return nil
+}
+
 func Cache(parent http.Handler, minutes int, enableCache bool) http.Handler {
 	if enableCache {
 		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
@@ -82,3 +578,15 @@ func Cache(parent http.Handler, minutes int, enableCache bool) http.Handler {
 		return parent
 	}
 }
+
+// splitHosts returns the hosts of the comma separated list without a trailing slash
+func splitHosts(list string) []string {
+	var hosts []string
+	for _, h := range strings.Split(list, ",") {
+		h = strings.TrimSuffix(strings.TrimSpace(h), "/")
+		if h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
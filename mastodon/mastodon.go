@@ -0,0 +1,169 @@
+// Package mastodon posts the results of surveys to a Mastodon account.
+package mastodon
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flashSurvey/chart"
+	"flashSurvey/survey"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const maxStatusLen = 500
+
+// Client posts the uncovered results of the surveys the creator has
+// chosen to announce.
+type Client struct {
+	server     string
+	token      string
+	visibility string
+	client     *http.Client
+}
+
+// New creates a new client using the given access token, which requires the
+// scopes write:media and write:statuses. If server is empty, nil is returned.
+func New(server, token, visibility string) (*Client, error) {
+	if server == "" {
+		return nil, nil
+	}
+	if token == "" {
+		return nil, errors.New("posting to mastodon requires an access token")
+	}
+	switch visibility {
+	case "public", "unlisted", "private":
+	default:
+		return nil, fmt.Errorf("invalid mastodon visibility %q", visibility)
+	}
+	return &Client{
+		server:     strings.TrimSuffix(server, "/"),
+		token:      token,
+		visibility: visibility,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+// Hooks returns the hooks which post the results
+func (c *Client) Hooks() survey.Hooks {
+	return survey.Hooks{
+		AfterUncover: func(e survey.ResultEvent) {
+			if e.Question.Announce {
+				go func() {
+					err := c.postResult(e)
+					if err != nil {
+						log.Println("mastodon:", err)
+					}
+				}()
+			}
+		},
+	}
+}
+
+func (c *Client) postResult(e survey.ResultEvent) error {
+	text := statusText(e)
+
+	var mediaIds []string
+	// the numbered list of the status text serves as the image description
+	img, err := chart.PNG(e.Result)
+	if err != nil {
+		log.Println("mastodon: could not create chart:", err)
+	} else {
+		id, err := c.uploadMedia(img, text)
+		if err != nil {
+			// post the text anyway
+			log.Println("mastodon: could not upload chart:", err)
+		} else {
+			mediaIds = append(mediaIds, id)
+		}
+	}
+
+	form := url.Values{}
+	form.Set("status", text)
+	form.Set("visibility", c.visibility)
+	for _, id := range mediaIds {
+		form.Add("media_ids[]", id)
+	}
+	return c.call("/api/v1/statuses", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()), nil)
+}
+
+func statusText(e survey.ResultEvent) string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s (%d Stimmen)\n\n", e.Question.Title, e.Result.Votes))
+	for i, r := range e.Result.Result {
+		b.WriteString(fmt.Sprintf("%d. %s\n", i+1, r.String()))
+	}
+	text := b.String()
+	if r := []rune(text); len(r) > maxStatusLen {
+		text = string(r[:maxStatusLen-1]) + "…"
+	}
+	return text
+}
+
+func (c *Client) uploadMedia(png []byte, description string) (string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	err := w.WriteField("description", description)
+	if err != nil {
+		return "", err
+	}
+	f, err := w.CreateFormFile("file", "result.png")
+	if err != nil {
+		return "", err
+	}
+	_, err = f.Write(png)
+	if err != nil {
+		return "", err
+	}
+	err = w.Close()
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		Id string `json:"id"`
+	}
+	err = c.call("/api/v2/media", w.FormDataContentType(), &body, &resp)
+	if err != nil {
+		return "", err
+	}
+	if resp.Id == "" {
+		return "", errors.New("no media id returned")
+	}
+	return resp.Id, nil
+}
+
+func (c *Client) call(path, contentType string, body io.Reader, result any) error {
+	return c.request(http.MethodPost, path, contentType, body, result)
+}
+
+func (c *Client) request(method, path, contentType string, body io.Reader, result any) error {
+	req, err := http.NewRequest(method, c.server+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// media may be processed asynchronously, which is signaled by 202
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1000))
+		return fmt.Errorf("%s returned %s: %s", path, resp.Status, msg)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
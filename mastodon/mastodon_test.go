@@ -0,0 +1,110 @@
+package mastodon
+
+import (
+	"bytes"
+	"flashSurvey/survey"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func result(t *testing.T) survey.ResultEvent {
+	s := survey.New("localhost", 30, false, true)
+	q := survey.SurveyQuestion{Title: "Q", Options: []string{"a", "b"}, Announce: true}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "v1", []int{0}, 1))
+	assert.NoError(t, s.Vote(sid, "v2", []int{1}, 1))
+	assert.NoError(t, s.Vote(sid, "v3", []int{1}, 1))
+	assert.NoError(t, s.Uncover("creator", sid))
+	return survey.ResultEvent{
+		Event:  survey.Event{SurveyId: sid, Number: 1, Question: q},
+		Result: s.GetResult("creator", sid),
+	}
+}
+
+func TestPostResult(t *testing.T) {
+	var status string
+	var mediaIds []string
+	var description string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.EqualValues(t, "Bearer token", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/api/v2/media":
+			f, _, err := r.FormFile("file")
+			assert.NoError(t, err)
+			data, _ := io.ReadAll(f)
+			_, err = png.Decode(bytes.NewReader(data))
+			assert.NoError(t, err)
+			description = r.FormValue("description")
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"id":"42"}`))
+		case "/api/v1/statuses":
+			assert.NoError(t, r.ParseForm())
+			status = r.FormValue("status")
+			mediaIds = r.Form["media_ids[]"]
+			assert.EqualValues(t, "unlisted", r.FormValue("visibility"))
+			w.Write([]byte(`{}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, "token", "unlisted")
+	assert.NoError(t, err)
+	assert.NoError(t, c.postResult(result(t)))
+	assert.EqualValues(t, "Q (3 Stimmen)\n\n1. a: 1 (33.3%)\n2. b: 2 (66.7%)\n", status)
+	assert.EqualValues(t, status, description)
+	assert.EqualValues(t, []string{"42"}, mediaIds)
+}
+
+func TestNew(t *testing.T) {
+	c, err := New("", "", "")
+	assert.NoError(t, err)
+	assert.Nil(t, c)
+	_, err = New("https://example.com", "token", "everyone")
+	assert.Error(t, err)
+}
+
+func TestPoll(t *testing.T) {
+	pollInterval = time.Millisecond
+	fetched := make(chan struct{}, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/statuses":
+			assert.NoError(t, r.ParseForm())
+			assert.EqualValues(t, []string{"a", "b"}, r.Form["poll[options][]"])
+			assert.EqualValues(t, "true", r.FormValue("poll[hide_totals]"))
+			assert.EqualValues(t, "300", r.FormValue("poll[expires_in]"))
+			w.Write([]byte(`{"poll":{"id":"7"}}`))
+		case "/api/v1/polls/7":
+			w.Write([]byte(`{"id":"7","expired":true,"votes_count":3,"voters_count":null,"options":[{"votes_count":1},{"votes_count":2}]}`))
+			fetched <- struct{}{}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	s := survey.New("localhost", 30, false, true)
+	q := survey.SurveyQuestion{Title: "Q", Options: []string{"a", "b"}, Announce: true}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+
+	c, err := New(server.URL, "token", "unlisted")
+	assert.NoError(t, err)
+	// the poll is expired after the first fetch, so mirror returns
+	c.mirror(s, survey.Event{SurveyId: sid, Number: 1, Question: q}, minPollDuration)
+	assert.EqualValues(t, 1, len(fetched))
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.EqualValues(t, 3, r.Votes)
+	assert.EqualValues(t, 2, r.Result[1].VoteCount())
+}
@@ -0,0 +1,116 @@
+package mastodon
+
+import (
+	"errors"
+	"flashSurvey/survey"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxPollOptions   = 4
+	maxPollOptionLen = 50
+	minPollDuration  = 5 * time.Minute
+)
+
+// pollInterval is the interval the poll results are fetched in
+var pollInterval = 30 * time.Second
+
+// PollHooks returns the hooks which mirror every announced survey as a native
+// Fediverse poll, which is federated by the Mastodon server via ActivityPub.
+// The votes of the poll are merged into the result of the survey. The server
+// makes sure that every actor votes only once. The totals of the poll are
+// hidden until it ends, so the result is not revealed before it is uncovered.
+func (c *Client) PollHooks(s *survey.Surveys, duration time.Duration) survey.Hooks {
+	if duration < minPollDuration {
+		duration = minPollDuration
+	}
+	return survey.Hooks{
+		OnStart: func(e survey.Event) {
+			if e.Question.Announce {
+				go c.mirror(s, e, duration)
+			}
+		},
+	}
+}
+
+type poll struct {
+	Id          string `json:"id"`
+	Expired     bool   `json:"expired"`
+	VotesCount  int    `json:"votes_count"`
+	VotersCount *int   `json:"voters_count"`
+	Options     []struct {
+		VotesCount *int `json:"votes_count"`
+	} `json:"options"`
+}
+
+// mirror posts the poll and merges its votes until the poll expires or the survey ends
+func (c *Client) mirror(s *survey.Surveys, e survey.Event, duration time.Duration) {
+	pollId, err := c.postPoll(e, duration)
+	if err != nil {
+		log.Println("mastodon: could not post poll:", err)
+		return
+	}
+	source := "mastodon:" + pollId
+	for {
+		time.Sleep(pollInterval)
+		var p poll
+		err := c.request(http.MethodGet, "/api/v1/polls/"+url.PathEscape(pollId), "", nil, &p)
+		if err != nil {
+			log.Println("mastodon: could not fetch poll:", err)
+			continue
+		}
+		votes := make([]int, len(p.Options))
+		for i, o := range p.Options {
+			if o.VotesCount != nil {
+				votes[i] = *o.VotesCount
+			}
+		}
+		voters := p.VotesCount
+		if p.VotersCount != nil {
+			voters = *p.VotersCount
+		}
+		err = s.SetRemoteVotes(e.SurveyId, e.Number, source, votes, voters)
+		if err != nil {
+			// the survey has ended or a new question was started
+			return
+		}
+		if p.Expired {
+			return
+		}
+	}
+}
+
+func (c *Client) postPoll(e survey.Event, duration time.Duration) (string, error) {
+	if len(e.Question.Options) > maxPollOptions {
+		return "", errors.New("too many options for a poll")
+	}
+	form := url.Values{}
+	form.Set("status", e.Question.Title)
+	form.Set("visibility", c.visibility)
+	for _, o := range e.Question.Options {
+		if r := []rune(o); len(r) > maxPollOptionLen {
+			o = string(r[:maxPollOptionLen-1]) + "…"
+		}
+		form.Add("poll[options][]", o)
+	}
+	form.Set("poll[expires_in]", strconv.Itoa(int(duration/time.Second)))
+	form.Set("poll[multiple]", strconv.FormatBool(e.Question.Multiple))
+	form.Set("poll[hide_totals]", "true")
+
+	var status struct {
+		Poll *poll `json:"poll"`
+	}
+	err := c.call("/api/v1/statuses", "application/x-www-form-urlencoded", strings.NewReader(form.Encode()), &status)
+	if err != nil {
+		return "", err
+	}
+	if status.Poll == nil || status.Poll.Id == "" {
+		return "", errors.New("no poll created")
+	}
+	return status.Poll.Id, nil
+}
@@ -0,0 +1,298 @@
+// Package matrix implements a bot which announces surveys in a Matrix room
+// and accepts votes from the members of the room.
+package matrix
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flashSurvey/survey"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	syncTimeout = 30 * time.Second
+	retryDelay  = 10 * time.Second
+)
+
+// Bot announces surveys in a Matrix room. Room members vote by reacting
+// to the announcement with the number of an option or by replying to it
+// with a comma separated list of numbers. The uncovered result is posted
+// to the room as well.
+type Bot struct {
+	homeserver string
+	token      string
+	room       string
+	surveys    *survey.Surveys
+	client     *http.Client
+
+	mutex         sync.Mutex
+	txn           int
+	announcements map[string]announcement
+}
+
+type announcement struct {
+	surveyId survey.SurveyId
+	number   int
+	multiple bool
+}
+
+// New creates a new bot which uses the given access token. The bot joins the
+// given room and starts listening for votes. If homeserver is empty, nil is
+// returned.
+func New(homeserver, token, room string, s *survey.Surveys) (*Bot, error) {
+	if homeserver == "" {
+		return nil, nil
+	}
+	if token == "" || room == "" {
+		return nil, errors.New("the matrix bot requires an access token and a room")
+	}
+	b := &Bot{
+		homeserver:    strings.TrimSuffix(homeserver, "/"),
+		token:         token,
+		room:          room,
+		surveys:       s,
+		client:        &http.Client{Timeout: syncTimeout + 10*time.Second},
+		announcements: make(map[string]announcement),
+	}
+	err := b.call(http.MethodPost, "/join/"+url.PathEscape(room), struct{}{}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("could not join matrix room: %w", err)
+	}
+	go b.run()
+	return b, nil
+}
+
+// Hooks returns the hooks which announce the surveys. Only surveys
+// the creator has chosen to announce are posted to the room.
+func (b *Bot) Hooks() survey.Hooks {
+	return survey.Hooks{
+		OnStart: func(e survey.Event) {
+			if e.Question.Announce {
+				go b.announce(e)
+			}
+		},
+		AfterUncover: func(e survey.ResultEvent) {
+			if e.Question.Announce {
+				go b.send(resultText(e))
+			}
+		},
+		OnExpire: func(e survey.ResultEvent) {
+			b.mutex.Lock()
+			defer b.mutex.Unlock()
+			for id, a := range b.announcements {
+				if a.surveyId == e.SurveyId {
+					delete(b.announcements, id)
+				}
+			}
+		},
+	}
+}
+
+func (b *Bot) announce(e survey.Event) {
+	var text strings.Builder
+	text.WriteString("Neue Umfrage: " + e.Question.Title + "\n")
+	for i, o := range e.Question.Options {
+		text.WriteString(fmt.Sprintf("%d. %s\n", i+1, o))
+	}
+	if e.Question.Multiple {
+		text.WriteString("Antworten Sie mit den Nummern, z.B. \"1,3\".")
+	} else {
+		text.WriteString("Reagieren Sie mit der Nummer oder antworten Sie mit ihr.")
+	}
+
+	eventId, err := b.send(text.String())
+	if err != nil {
+		return
+	}
+	b.mutex.Lock()
+	b.announcements[eventId] = announcement{surveyId: e.SurveyId, number: e.Number, multiple: e.Question.Multiple}
+	b.mutex.Unlock()
+}
+
+func resultText(e survey.ResultEvent) string {
+	var text strings.Builder
+	text.WriteString(fmt.Sprintf("Ergebnis: %s (%d Stimmen)\n", e.Question.Title, e.Result.Votes))
+	for _, r := range e.Result.Result {
+		text.WriteString(r.String() + "\n")
+	}
+	return text.String()
+}
+
+func (b *Bot) send(text string) (string, error) {
+	b.mutex.Lock()
+	b.txn++
+	txn := strconv.FormatInt(time.Now().UnixNano(), 36) + "-" + strconv.Itoa(b.txn)
+	b.mutex.Unlock()
+
+	var resp struct {
+		EventId string `json:"event_id"`
+	}
+	err := b.call(http.MethodPut, "/rooms/"+url.PathEscape(b.room)+"/send/m.room.message/"+txn,
+		map[string]string{"msgtype": "m.text", "body": text}, &resp)
+	if err != nil {
+		log.Println("matrix: could not send message:", err)
+		return "", err
+	}
+	return resp.EventId, nil
+}
+
+func (b *Bot) call(method, path string, body any, result any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(context.Background(), method, b.homeserver+"/_matrix/client/v3"+path, r)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1000))
+		return fmt.Errorf("matrix returned %s: %s", resp.Status, msg)
+	}
+	if result == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+type syncResponse struct {
+	NextBatch string `json:"next_batch"`
+	Rooms     struct {
+		Join map[string]struct {
+			Timeline struct {
+				Events []event `json:"events"`
+			} `json:"timeline"`
+		} `json:"join"`
+	} `json:"rooms"`
+}
+
+type event struct {
+	Type    string `json:"type"`
+	Sender  string `json:"sender"`
+	Content struct {
+		Body      string `json:"body"`
+		RelatesTo struct {
+			RelType   string `json:"rel_type"`
+			EventId   string `json:"event_id"`
+			Key       string `json:"key"`
+			InReplyTo struct {
+				EventId string `json:"event_id"`
+			} `json:"m.in_reply_to"`
+		} `json:"m.relates_to"`
+	} `json:"content"`
+}
+
+func (b *Bot) run() {
+	filter := `{"room":{"rooms":[` + strconv.Quote(b.room) + `],"timeline":{"limit":50}},"presence":{"not_types":["*"]},"account_data":{"not_types":["*"]}}`
+	since := ""
+	for {
+		q := url.Values{}
+		q.Set("filter", filter)
+		if since == "" {
+			// skip the history
+			q.Set("timeout", "0")
+		} else {
+			q.Set("since", since)
+			q.Set("timeout", strconv.Itoa(int(syncTimeout/time.Millisecond)))
+		}
+		var resp syncResponse
+		err := b.call(http.MethodGet, "/sync?"+q.Encode(), nil, &resp)
+		if err != nil {
+			log.Println("matrix: sync failed:", err)
+			time.Sleep(retryDelay)
+			continue
+		}
+		if since != "" {
+			for _, e := range resp.Rooms.Join[b.room].Timeline.Events {
+				b.handle(e)
+			}
+		}
+		since = resp.NextBatch
+	}
+}
+
+// handle processes a single event of the room
+func (b *Bot) handle(e event) {
+	var target string
+	var answer string
+	switch e.Type {
+	case "m.reaction":
+		if e.Content.RelatesTo.RelType != "m.annotation" {
+			return
+		}
+		target = e.Content.RelatesTo.EventId
+		answer = e.Content.RelatesTo.Key
+	case "m.room.message":
+		target = e.Content.RelatesTo.InReplyTo.EventId
+		answer = stripReplyFallback(e.Content.Body)
+	default:
+		return
+	}
+
+	b.mutex.Lock()
+	a, ok := b.announcements[target]
+	b.mutex.Unlock()
+	if !ok {
+		return
+	}
+
+	options, ok := parseOptions(answer)
+	if !ok || (!a.multiple && len(options) > 1) {
+		return
+	}
+	err := b.surveys.Vote(a.surveyId, survey.UserId("matrix:"+e.Sender), options, a.number)
+	if err != nil {
+		log.Println("matrix: vote rejected:", err)
+	}
+}
+
+// stripReplyFallback removes the quoted message clients add to replies
+func stripReplyFallback(body string) string {
+	var lines []string
+	for _, l := range strings.Split(body, "\n") {
+		if !strings.HasPrefix(l, ">") {
+			lines = append(lines, l)
+		}
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// parseOptions parses a comma separated list of one based option numbers.
+// Keycap emojis like 1️⃣ are accepted as well. An option given several
+// times is counted only once.
+func parseOptions(answer string) ([]int, bool) {
+	answer = strings.NewReplacer("\ufe0f", "", "\u20e3", "").Replace(answer)
+	var options []int
+	for _, part := range strings.Split(answer, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n < 1 {
+			return nil, false
+		}
+		if !slices.Contains(options, n-1) {
+			options = append(options, n-1)
+		}
+	}
+	return options, len(options) > 0
+}
@@ -0,0 +1,93 @@
+package matrix
+
+import (
+	"encoding/json"
+	"flashSurvey/survey"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOptions(t *testing.T) {
+	o, ok := parseOptions("2")
+	assert.True(t, ok)
+	assert.EqualValues(t, []int{1}, o)
+	o, ok = parseOptions("1️⃣")
+	assert.True(t, ok)
+	assert.EqualValues(t, []int{0}, o)
+	o, ok = parseOptions(" 1, 3 ")
+	assert.True(t, ok)
+	assert.EqualValues(t, []int{0, 2}, o)
+	o, ok = parseOptions("1,1,1")
+	assert.True(t, ok)
+	assert.EqualValues(t, []int{0}, o)
+	o, ok = parseOptions("2, 1️⃣, 2")
+	assert.True(t, ok)
+	assert.EqualValues(t, []int{1, 0}, o)
+	_, ok = parseOptions("👍")
+	assert.False(t, ok)
+	_, ok = parseOptions("0")
+	assert.False(t, ok)
+
+	assert.EqualValues(t, "2", stripReplyFallback("> <@a:b> Neue Umfrage\n> 1. a\n\n2"))
+}
+
+func TestVote(t *testing.T) {
+	var messages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.EqualValues(t, "Bearer token", r.Header.Get("Authorization"))
+		var body map[string]string
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		messages = append(messages, body["body"])
+		assert.True(t, strings.HasPrefix(r.URL.Path, "/_matrix/client/v3/rooms/!room:example.com/send/m.room.message/"), r.URL.Path)
+		w.Write([]byte(`{"event_id":"$announcement"}`))
+	}))
+	defer server.Close()
+
+	s := survey.New("localhost", 30, false, true)
+	q := survey.SurveyQuestion{Title: "Q", Options: []string{"a", "b"}, Announce: true}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+
+	b := &Bot{
+		homeserver:    server.URL,
+		token:         "token",
+		room:          "!room:example.com",
+		surveys:       s,
+		client:        server.Client(),
+		announcements: make(map[string]announcement),
+	}
+	b.announce(survey.Event{SurveyId: sid, Number: 1, Question: q})
+	assert.EqualValues(t, 1, len(messages))
+	assert.Contains(t, messages[0], "2. b")
+
+	var reaction event
+	reaction.Type = "m.reaction"
+	reaction.Sender = "@alice:example.com"
+	reaction.Content.RelatesTo.RelType = "m.annotation"
+	reaction.Content.RelatesTo.EventId = "$announcement"
+	reaction.Content.RelatesTo.Key = "2️⃣"
+	b.handle(reaction)
+
+	var reply event
+	reply.Type = "m.room.message"
+	reply.Sender = "@bob:example.com"
+	reply.Content.Body = "> quoted\n\n2"
+	reply.Content.RelatesTo.InReplyTo.EventId = "$announcement"
+	b.handle(reply)
+
+	// second vote of the same user is ignored
+	b.handle(reaction)
+	// not a reply to the announcement
+	reply.Sender = "@carol:example.com"
+	reply.Content.RelatesTo.InReplyTo.EventId = "$other"
+	b.handle(reply)
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.EqualValues(t, 2, r.Votes)
+	assert.EqualValues(t, 2, r.Result[1].VoteCount())
+}
@@ -0,0 +1,61 @@
+package meeting
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// bbbCall is the name of the call used to compute the checksum
+const bbbCall = "launch"
+
+// bbbParticipant verifies a launch link. The link is signed like a call of
+// the BigBlueButton API, so it can be created by every system which is able
+// to create BBB join links:
+//
+//	/meeting/?meetingID=...&userID=...&role=MODERATOR&checksum=...
+//
+// The checksum is the hex encoded sha256 or sha1 hash of
+// "launch" + query without checksum + shared secret.
+func bbbParticipant(rawQuery, secret string) (Participant, error) {
+	i := strings.LastIndex(rawQuery, "&checksum=")
+	if i < 0 {
+		return Participant{}, errors.New("checksum missing")
+	}
+	query := rawQuery[:i]
+	checksum := strings.ToLower(rawQuery[i+len("&checksum="):])
+
+	var expected string
+	switch len(checksum) {
+	case 2 * sha256.Size:
+		h := sha256.Sum256([]byte(bbbCall + query + secret))
+		expected = hex.EncodeToString(h[:])
+	case 2 * sha1.Size:
+		h := sha1.Sum([]byte(bbbCall + query + secret))
+		expected = hex.EncodeToString(h[:])
+	default:
+		return Participant{}, errors.New("invalid checksum")
+	}
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(checksum)) != 1 {
+		return Participant{}, errors.New("checksum mismatch")
+	}
+
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return Participant{}, err
+	}
+	p := Participant{
+		Platform: "bbb",
+		Meeting:  values.Get("meetingID"),
+		User:     values.Get("userID"),
+		Host:     strings.EqualFold(values.Get("role"), "moderator"),
+	}
+	if p.Meeting == "" || p.User == "" {
+		return Participant{}, errors.New("meeting or user missing")
+	}
+	return p, nil
+}
@@ -0,0 +1,117 @@
+// Package meeting allows to start flashSurvey from within a video
+// conference. The hosts of a meeting share an account and create the
+// surveys, the participants are mapped to stable voter ids, so that every
+// participant votes once, regardless of the device used.
+package meeting
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"flashSurvey/survey"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Participant is a verified participant of a meeting
+type Participant struct {
+	// Platform is either "bbb" or "zoom"
+	Platform string
+	Meeting  string
+	User     string
+	Host     bool
+}
+
+// Account returns the account shared by all hosts of the meeting
+func (p Participant) Account() string {
+	return p.Platform + ":" + p.Meeting
+}
+
+// Launcher verifies the launch requests sent by the conference systems
+type Launcher struct {
+	bbbSecret  string
+	zoomSecret string
+	secret     []byte
+
+	mutex sync.Mutex
+	hosts map[string]survey.UserId
+}
+
+// New creates a new launcher. The bbbSecret is the shared secret used to
+// sign the launch links, the zoomSecret is the client secret of the Zoom App.
+// If both are empty, nil is returned.
+func New(bbbSecret, zoomSecret string) *Launcher {
+	if bbbSecret == "" && zoomSecret == "" {
+		return nil
+	}
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		panic(err)
+	}
+	return &Launcher{
+		bbbSecret:  bbbSecret,
+		zoomSecret: zoomSecret,
+		secret:     secret,
+		hosts:      map[string]survey.UserId{},
+	}
+}
+
+// Participant returns the participant launching the request
+func (l *Launcher) Participant(r *http.Request) (Participant, error) {
+	if context := r.Header.Get(zoomContextHeader); context != "" {
+		if l.zoomSecret == "" {
+			return Participant{}, errors.New("zoom is not configured")
+		}
+		return zoomParticipant(context, l.zoomSecret)
+	}
+	if l.bbbSecret == "" {
+		return Participant{}, errors.New("bbb is not configured")
+	}
+	return bbbParticipant(r.URL.RawQuery, l.bbbSecret)
+}
+
+// SetHost stores the user id of the host who launched the meeting most recently
+func (l *Launcher) SetHost(p Participant, userId survey.UserId) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.hosts[p.Account()] = userId
+}
+
+// Host returns the user id of a host of the participant's meeting
+func (l *Launcher) Host(p Participant) (survey.UserId, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	userId, ok := l.hosts[p.Account()]
+	return userId, ok
+}
+
+// VoterToken returns the voter id of the participant. It is the same for
+// all surveys of the meeting and can not be guessed by other participants.
+// The token is signed, so the vote handler can tell it from an arbitrary
+// id chosen by a client, see VerifyVoterToken.
+func (l *Launcher) VoterToken(p Participant) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(p.Account()))
+	mac.Write([]byte{0})
+	mac.Write([]byte(p.User))
+	id := base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:18])
+	return id + "." + l.signVoter(id)
+}
+
+// VerifyVoterToken returns true if the token was created by VoterToken
+func (l *Launcher) VerifyVoterToken(token string) bool {
+	id, signature, ok := strings.Cut(token, ".")
+	return ok && hmac.Equal([]byte(signature), []byte(l.signVoter(id)))
+}
+
+func (l *Launcher) signVoter(id string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte("voter"))
+	mac.Write([]byte{0})
+	mac.Write([]byte(id))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:12])
+}
@@ -0,0 +1,107 @@
+package meeting
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sign(query, secret string) string {
+	h := sha256.Sum256([]byte("launch" + query + secret))
+	return query + "&checksum=" + hex.EncodeToString(h[:])
+}
+
+func TestBBB(t *testing.T) {
+	l := New("secret", "")
+	r := httptest.NewRequest("GET", "/meeting/?"+sign("meetingID=m1&userID=u1&role=MODERATOR", "secret"), nil)
+	p, err := l.Participant(r)
+	assert.NoError(t, err)
+	assert.EqualValues(t, Participant{Platform: "bbb", Meeting: "m1", User: "u1", Host: true}, p)
+	assert.EqualValues(t, "bbb:m1", p.Account())
+
+	query := "meetingID=m1&userID=u2&role=VIEWER"
+	h := sha1.Sum([]byte("launch" + query + "secret"))
+	p, err = bbbParticipant(query+"&checksum="+hex.EncodeToString(h[:]), "secret")
+	assert.NoError(t, err)
+	assert.False(t, p.Host)
+
+	_, err = bbbParticipant(sign("meetingID=m1&userID=u1&role=MODERATOR", "other"), "secret")
+	assert.Error(t, err)
+	_, err = bbbParticipant(sign("meetingID=m1&userID=u1", "secret")+"&role=MODERATOR", "secret")
+	assert.Error(t, err)
+	_, err = bbbParticipant("meetingID=m1&userID=u1", "secret")
+	assert.Error(t, err)
+	_, err = bbbParticipant(sign("meetingID=m1", "secret"), "secret")
+	assert.Error(t, err)
+}
+
+func zoomContextOf(t *testing.T, plain, secret string) string {
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	assert.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	assert.NoError(t, err)
+	iv := make([]byte, gcm.NonceSize())
+	aad := []byte("aad")
+	sealed := gcm.Seal(nil, iv, []byte(plain), aad)
+	cipherText, tag := sealed[:len(plain)], sealed[len(plain):]
+
+	data := []byte{byte(len(iv))}
+	data = append(data, iv...)
+	data = binary.LittleEndian.AppendUint16(data, uint16(len(aad)))
+	data = append(data, aad...)
+	data = binary.LittleEndian.AppendUint32(data, uint32(len(cipherText)))
+	data = append(data, cipherText...)
+	data = append(data, tag...)
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestZoom(t *testing.T) {
+	l := New("", "secret")
+	r := httptest.NewRequest("GET", "/meeting/", nil)
+	r.Header.Set(zoomContextHeader, zoomContextOf(t, `{"typ":"meeting","uid":"u1","mid":"m1","attendrole":"coHost"}`, "secret"))
+	p, err := l.Participant(r)
+	assert.NoError(t, err)
+	assert.EqualValues(t, Participant{Platform: "zoom", Meeting: "m1", User: "u1", Host: true}, p)
+
+	_, err = zoomParticipant(zoomContextOf(t, `{"typ":"meeting","uid":"u1","mid":"m1"}`, "other"), "secret")
+	assert.Error(t, err)
+	_, err = zoomParticipant(zoomContextOf(t, `{"typ":"panel","uid":"u1"}`, "secret"), "secret")
+	assert.Error(t, err)
+	_, err = zoomParticipant("AQ", "secret")
+	assert.Error(t, err)
+
+	// bbb links are not accepted if only zoom is configured
+	_, err = l.Participant(httptest.NewRequest("GET", "/meeting/?"+sign("meetingID=m1&userID=u1", ""), nil))
+	assert.Error(t, err)
+}
+
+func TestVoterToken(t *testing.T) {
+	l := New("secret", "")
+	p := Participant{Platform: "bbb", Meeting: "m1", User: "u1"}
+	assert.EqualValues(t, l.VoterToken(p), l.VoterToken(p))
+	p2 := p
+	p2.User = "u2"
+	assert.NotEqual(t, l.VoterToken(p), l.VoterToken(p2))
+	assert.True(t, l.VerifyVoterToken(l.VoterToken(p)))
+	assert.False(t, l.VerifyVoterToken("1"))
+	assert.False(t, l.VerifyVoterToken(l.VoterToken(p)+"x"))
+	assert.False(t, New("other", "").VerifyVoterToken(l.VoterToken(p)))
+
+	_, ok := l.Host(p)
+	assert.False(t, ok)
+	l.SetHost(p, "host")
+	h, ok := l.Host(p2)
+	assert.True(t, ok)
+	assert.EqualValues(t, "host", h)
+
+	assert.Nil(t, New("", ""))
+}
@@ -0,0 +1,95 @@
+package meeting
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// zoomContextHeader is sent by the Zoom client if the app is opened in a meeting
+const zoomContextHeader = "X-Zoom-App-Context"
+
+type zoomContext struct {
+	Typ        string `json:"typ"`
+	Uid        string `json:"uid"`
+	Mid        string `json:"mid"`
+	AttendRole string `json:"attendrole"`
+}
+
+// zoomParticipant decrypts the app context. The context is encrypted with
+// AES-GCM using the sha256 hash of the client secret as the key and is
+// encoded as: iv length (1 byte), iv, aad length (2 bytes), aad,
+// cipher text length (4 bytes), cipher text, tag.
+func zoomParticipant(context, secret string) (Participant, error) {
+	context = strings.NewReplacer("-", "+", "_", "/").Replace(strings.TrimRight(context, "="))
+	data, err := base64.RawStdEncoding.DecodeString(context)
+	if err != nil {
+		return Participant{}, err
+	}
+
+	next := func(n int) ([]byte, error) {
+		if n > len(data) {
+			return nil, errors.New("zoom context too short")
+		}
+		b := data[:n]
+		data = data[n:]
+		return b, nil
+	}
+	b, err := next(1)
+	if err != nil {
+		return Participant{}, err
+	}
+	iv, err := next(int(b[0]))
+	if err != nil {
+		return Participant{}, err
+	}
+	if b, err = next(2); err != nil {
+		return Participant{}, err
+	}
+	aad, err := next(int(binary.LittleEndian.Uint16(b)))
+	if err != nil {
+		return Participant{}, err
+	}
+	if b, err = next(4); err != nil {
+		return Participant{}, err
+	}
+	cipherText, err := next(int(binary.LittleEndian.Uint32(b)))
+	if err != nil {
+		return Participant{}, err
+	}
+	tag := data
+
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return Participant{}, err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return Participant{}, err
+	}
+	plain, err := gcm.Open(nil, iv, append(cipherText, tag...), aad)
+	if err != nil {
+		return Participant{}, err
+	}
+
+	var c zoomContext
+	err = json.Unmarshal(plain, &c)
+	if err != nil {
+		return Participant{}, err
+	}
+	if c.Typ != "meeting" || c.Mid == "" || c.Uid == "" {
+		return Participant{}, errors.New("app not opened in a meeting")
+	}
+	return Participant{
+		Platform: "zoom",
+		Meeting:  c.Mid,
+		User:     c.Uid,
+		Host:     c.AttendRole == "host" || c.AttendRole == "coHost",
+	}, nil
+}
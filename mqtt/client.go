@@ -0,0 +1,280 @@
+// Package mqtt implements a minimal MQTT 3.1.1 client which is only able to
+// publish retained messages with QoS 0.
+package mqtt
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+)
+
+const (
+	packetConnect = 0x10
+	packetConnAck = 0x20
+	packetPublish = 0x30
+	packetPingReq = 0xc0
+
+	flagRetain = 0x01
+
+	keepAlive    = 60 * time.Second
+	dialTimeout  = 10 * time.Second
+	maxReconnect = time.Minute
+)
+
+// Client publishes messages to an MQTT broker. The messages are queued and
+// sent in the background. If there are several messages for the same topic
+// waiting to be sent, only the latest one is sent. If the connection is
+// lost, the client reconnects.
+type Client struct {
+	addr     string
+	useTLS   bool
+	clientId string
+	user     string
+	password string
+
+	mutex   sync.Mutex
+	pending map[string][]byte
+	order   []string
+	notify  chan struct{}
+}
+
+// NewClient creates a new client. The broker is given as an url like
+// tcp://broker:1883 or tls://broker:8883, user and password are optional.
+func NewClient(broker, clientId, user, password string) (*Client, error) {
+	u, err := url.Parse(broker)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mqtt broker: %w", err)
+	}
+	c := &Client{
+		addr:     u.Host,
+		clientId: clientId,
+		user:     user,
+		password: password,
+		pending:  make(map[string][]byte),
+		notify:   make(chan struct{}, 1),
+	}
+	switch u.Scheme {
+	case "tcp", "mqtt":
+		if u.Port() == "" {
+			c.addr = net.JoinHostPort(u.Host, "1883")
+		}
+	case "tls", "ssl", "mqtts":
+		c.useTLS = true
+		if u.Port() == "" {
+			c.addr = net.JoinHostPort(u.Host, "8883")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported mqtt scheme %q", u.Scheme)
+	}
+	go c.run()
+	return c, nil
+}
+
+// Publish queues a retained message. An empty payload removes the
+// retained message of the topic from the broker.
+func (c *Client) Publish(topic string, payload []byte) {
+	c.mutex.Lock()
+	if _, ok := c.pending[topic]; !ok {
+		c.order = append(c.order, topic)
+	}
+	c.pending[topic] = payload
+	c.mutex.Unlock()
+
+	c.wake()
+}
+
+func (c *Client) wake() {
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+type message struct {
+	topic   string
+	payload []byte
+}
+
+func (c *Client) take() []message {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	list := make([]message, 0, len(c.order))
+	for _, t := range c.order {
+		list = append(list, message{topic: t, payload: c.pending[t]})
+	}
+	c.pending = make(map[string][]byte)
+	c.order = nil
+	return list
+}
+
+// putBack queues messages again which could not be sent. They are sent
+// before the messages published in the meantime, a newer payload of the
+// same topic replaces the old one.
+func (c *Client) putBack(list []message) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var order []string
+	for _, m := range list {
+		if _, ok := c.pending[m.topic]; !ok {
+			c.pending[m.topic] = m.payload
+			order = append(order, m.topic)
+		}
+	}
+	c.order = append(order, c.order...)
+}
+
+func (c *Client) run() {
+	delay := time.Second
+	for {
+		start := time.Now()
+		err := c.session()
+		log.Println("mqtt:", err)
+		if time.Since(start) > maxReconnect {
+			delay = time.Second
+		}
+		time.Sleep(delay)
+		delay *= 2
+		if delay > maxReconnect {
+			delay = maxReconnect
+		}
+	}
+}
+
+// session connects to the broker and sends the queued messages until
+// an error occurs.
+func (c *Client) session() error {
+	<-c.notify // connect only if there is something to send
+
+	conn, err := c.dial()
+	if err != nil {
+		// keep the messages for the next attempt
+		c.wake()
+		return err
+	}
+	defer conn.Close()
+
+	w := bufio.NewWriter(conn)
+	r := bufio.NewReader(conn)
+	err = c.connect(w, r)
+	if err != nil {
+		c.wake()
+		return err
+	}
+	log.Println("mqtt: connected to", c.addr)
+
+	// the broker only sends ping responses, they are read and discarded
+	readErr := make(chan error, 1)
+	go func() {
+		_, err := io.Copy(io.Discard, r)
+		if err == nil {
+			err = io.EOF
+		}
+		readErr <- err
+	}()
+
+	ping := time.NewTicker(keepAlive / 2)
+	defer ping.Stop()
+
+	c.wake()
+	for {
+		select {
+		case err := <-readErr:
+			return err
+		case <-ping.C:
+			err = writePacket(w, packetPingReq, nil)
+		case <-c.notify:
+			list := c.take()
+			for i, m := range list {
+				err = writePacket(w, packetPublish|flagRetain, append(encodeString(m.topic), m.payload...))
+				if err != nil {
+					// the messages are sent again after the reconnect
+					c.putBack(list[i:])
+					break
+				}
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (c *Client) dial() (net.Conn, error) {
+	d := &net.Dialer{Timeout: dialTimeout}
+	if c.useTLS {
+		return tls.DialWithDialer(d, "tcp", c.addr, nil)
+	}
+	return d.Dial("tcp", c.addr)
+}
+
+func (c *Client) connect(w *bufio.Writer, r *bufio.Reader) error {
+	flags := byte(0x02) // clean session
+	if c.user != "" {
+		flags |= 0x80
+		if c.password != "" {
+			flags |= 0x40
+		}
+	}
+	var body []byte
+	body = append(body, encodeString("MQTT")...)
+	body = append(body, 4, flags)
+	body = binary.BigEndian.AppendUint16(body, uint16(keepAlive/time.Second))
+	body = append(body, encodeString(c.clientId)...)
+	if c.user != "" {
+		body = append(body, encodeString(c.user)...)
+		if c.password != "" {
+			body = append(body, encodeString(c.password)...)
+		}
+	}
+	err := writePacket(w, packetConnect, body)
+	if err != nil {
+		return err
+	}
+
+	var ack [4]byte
+	_, err = io.ReadFull(r, ack[:])
+	if err != nil {
+		return fmt.Errorf("no connack: %w", err)
+	}
+	if ack[0] != packetConnAck || ack[1] != 2 {
+		return errors.New("invalid connack")
+	}
+	if ack[3] != 0 {
+		return fmt.Errorf("connection refused by broker, code %d", ack[3])
+	}
+	return nil
+}
+
+func encodeString(s string) []byte {
+	b := binary.BigEndian.AppendUint16(nil, uint16(len(s)))
+	return append(b, s...)
+}
+
+func writePacket(w *bufio.Writer, header byte, body []byte) error {
+	w.WriteByte(header)
+	// remaining length as variable length integer
+	l := len(body)
+	for {
+		b := byte(l % 128)
+		l /= 128
+		if l > 0 {
+			b |= 0x80
+		}
+		w.WriteByte(b)
+		if l == 0 {
+			break
+		}
+	}
+	w.Write(body)
+	return w.Flush()
+}
@@ -0,0 +1,140 @@
+package mqtt
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"flashSurvey/survey"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type packet struct {
+	header byte
+	body   []byte
+}
+
+func readPacket(r *bufio.Reader) (packet, error) {
+	h, err := r.ReadByte()
+	if err != nil {
+		return packet{}, err
+	}
+	l := 0
+	for shift := 0; ; shift += 7 {
+		b, err := r.ReadByte()
+		if err != nil {
+			return packet{}, err
+		}
+		l |= int(b&0x7f) << shift
+		if b&0x80 == 0 {
+			break
+		}
+	}
+	body := make([]byte, l)
+	_, err = io.ReadFull(r, body)
+	return packet{header: h, body: body}, err
+}
+
+// broker accepts a single connection and sends all received packets to the channel
+func broker(t *testing.T) (string, chan packet) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	packets := make(chan packet, 10)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			p, err := readPacket(r)
+			if err != nil {
+				return
+			}
+			if p.header == packetConnect {
+				conn.Write([]byte{packetConnAck, 2, 0, 0})
+			}
+			packets <- p
+		}
+	}()
+	return "tcp://" + l.Addr().String(), packets
+}
+
+func next(t *testing.T, packets chan packet) packet {
+	select {
+	case p := <-packets:
+		return p
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout")
+		return packet{}
+	}
+}
+
+func TestPublish(t *testing.T) {
+	addr, packets := broker(t)
+	c, err := NewClient(addr, "test", "user", "pass")
+	assert.NoError(t, err)
+
+	h := Hooks(c, "fs")
+	h.OnStart(survey.Event{SurveyId: "id1", Number: 1, Question: survey.SurveyQuestion{Title: "Q", Options: []string{"a", "b"}}})
+
+	p := next(t, packets)
+	assert.EqualValues(t, packetConnect, p.header)
+	assert.EqualValues(t, "\x00\x04MQTT\x04\xc2", string(p.body[:8]))
+	assert.EqualValues(t, "\x00\x04test\x00\x04user\x00\x04pass", string(p.body[10:]))
+
+	p = next(t, packets)
+	assert.EqualValues(t, packetPublish|flagRetain, p.header)
+	topicLen := binary.BigEndian.Uint16(p.body)
+	topic := Topic("fs", "id1")
+	assert.EqualValues(t, topic, string(p.body[2:2+topicLen]))
+	assert.NotContains(t, string(p.body), "id1")
+
+	var m ResultMessage
+	assert.NoError(t, json.Unmarshal(p.body[2+topicLen:], &m))
+	assert.EqualValues(t, "Q", m.Title)
+	assert.True(t, m.Hidden)
+	assert.EqualValues(t, 2, len(m.Options))
+	assert.Nil(t, m.Options[0].Votes)
+
+	h.OnExpire(survey.ResultEvent{Event: survey.Event{SurveyId: "id1"}})
+	p = next(t, packets)
+	assert.EqualValues(t, string(encodeString(topic)), string(p.body))
+
+	// the retained message is also removed if the creator closes the survey
+	h.OnStart(survey.Event{SurveyId: "id2", Number: 1, Question: survey.SurveyQuestion{Title: "Q", Options: []string{"a", "b"}}})
+	next(t, packets)
+	h.OnClose(survey.ResultEvent{Event: survey.Event{SurveyId: "id2"}})
+	p = next(t, packets)
+	assert.EqualValues(t, string(encodeString(Topic("fs", "id2"))), string(p.body))
+}
+
+func TestInvalidBroker(t *testing.T) {
+	_, err := NewClient("http://example.com", "test", "", "")
+	assert.Error(t, err)
+}
+
+func TestPutBack(t *testing.T) {
+	c := &Client{pending: make(map[string][]byte)}
+	c.Publish("a", []byte("1"))
+	c.Publish("b", []byte("1"))
+	list := c.take()
+
+	// b was published again while a could not be sent
+	c.Publish("b", []byte("2"))
+	c.Publish("c", []byte("1"))
+	c.putBack(list)
+
+	assert.EqualValues(t, []message{
+		{topic: "a", payload: []byte("1")},
+		{topic: "b", payload: []byte("2")},
+		{topic: "c", payload: []byte("1")},
+	}, c.take())
+}
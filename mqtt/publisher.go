@@ -0,0 +1,97 @@
+package mqtt
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"flashSurvey/survey"
+	"log"
+)
+
+// ResultMessage is the json message published for every survey, it does
+// not contain the survey id, see Topic
+type ResultMessage struct {
+	Number  int             `json:"number"`
+	Title   string          `json:"title"`
+	Votes   int             `json:"votes"`
+	Hidden  bool            `json:"hidden"`
+	Options []OptionMessage `json:"options"`
+	// Annotation is the note of the creator on the uncovered result
+	Annotation string `json:"annotation,omitempty"`
+}
+
+// OptionMessage is the result of a single option. If the result is
+// hidden, votes and percent are omitted.
+type OptionMessage struct {
+	Title   string   `json:"title"`
+	Color   string   `json:"color,omitempty"`
+	Votes   *int     `json:"votes,omitempty"`
+	Percent *float64 `json:"percent,omitempty"`
+}
+
+// Topic returns the topic of the survey. The survey id allows to vote, so
+// it is not published. The topic contains a hash of the id instead, which
+// can be computed by everyone who knows the id.
+func Topic(prefix string, surveyId survey.SurveyId) string {
+	h := sha256.Sum256([]byte("mqtt\x00" + surveyId))
+	return prefix + "/" + base64.RawURLEncoding.EncodeToString(h[:12])
+}
+
+// Hooks returns the hooks which publish the result of every survey to the
+// topic returned by Topic whenever it changes. If a survey expires or is
+// closed, its retained message is removed.
+func Hooks(c *Client, prefix string) survey.Hooks {
+	publish := func(e survey.Event, r survey.Result) {
+		c.Publish(Topic(prefix, e.SurveyId), resultMessage(e, r))
+	}
+	remove := func(e survey.ResultEvent) {
+		c.Publish(Topic(prefix, e.SurveyId), nil)
+	}
+	return survey.Hooks{
+		OnStart: func(e survey.Event) {
+			publish(e, survey.Result{})
+		},
+		AfterVote: func(e survey.VoteEvent) {
+			publish(e.Event, e.Result)
+		},
+		OnVisibility: func(e survey.ResultEvent) {
+			publish(e.Event, e.Result)
+		},
+		OnAnnotate: func(e survey.ResultEvent) {
+			publish(e.Event, e.Result)
+		},
+		OnExpire: remove,
+		OnClose:  remove,
+	}
+}
+
+func resultMessage(e survey.Event, r survey.Result) []byte {
+	m := ResultMessage{
+		Number:     e.Number,
+		Title:      e.Question.Title,
+		Votes:      r.Votes,
+		Hidden:     true,
+		Annotation: r.Annotation,
+	}
+	for _, o := range r.Result {
+		om := OptionMessage{Title: o.Title, Color: o.Color()}
+		if v := o.VoteCount(); v >= 0 {
+			p := o.PercentValue()
+			om.Votes = &v
+			om.Percent = &p
+			m.Hidden = false
+		}
+		m.Options = append(m.Options, om)
+	}
+	if r.Result == nil {
+		// a new question has no result yet
+		for _, o := range e.Question.Options {
+			m.Options = append(m.Options, OptionMessage{Title: o})
+		}
+	}
+	data, err := json.Marshal(m)
+	if err != nil {
+		log.Println(err)
+	}
+	return data
+}
@@ -0,0 +1,131 @@
+// Package randid creates the random ids of the surveys and the users. The
+// ids are taken from crypto/rand, so they can not be predicted from ids
+// seen before.
+package randid
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// DefaultLength is the default number of characters of an id
+	DefaultLength = 30
+	// DefaultAlphabet contains the default characters of an id
+	DefaultAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	// minBits is the minimum entropy of an id in bits
+	minBits = 96
+	// urlSafe contains the characters which can be used in the links and
+	// cookies without escaping
+	urlSafe = DefaultAlphabet + "-_"
+)
+
+// Generator creates random ids of a fixed length using the characters of
+// an alphabet
+type Generator struct {
+	length   int
+	alphabet string
+	// limit is the largest multiple of the alphabet size not larger than
+	// 256, larger random bytes are dropped to avoid a bias
+	limit int
+}
+
+// New creates a generator of ids with the given length and alphabet. The
+// alphabet must consist of distinct letters, digits, '-' or '_' and the
+// ids must contain at least 96 bits of randomness.
+func New(length int, alphabet string) (*Generator, error) {
+	if len(alphabet) < 2 || len(alphabet) > 256 {
+		return nil, errors.New("the alphabet must contain between 2 and 256 characters")
+	}
+	for i := 0; i < len(alphabet); i++ {
+		c := alphabet[i]
+		if !strings.ContainsRune(urlSafe, rune(c)) {
+			return nil, fmt.Errorf("the character %q of the alphabet is not allowed", c)
+		}
+		if strings.IndexByte(alphabet[i+1:], c) >= 0 {
+			return nil, fmt.Errorf("the character %q is contained twice in the alphabet", c)
+		}
+	}
+	if float64(length)*math.Log2(float64(len(alphabet))) < minBits {
+		return nil, fmt.Errorf("ids of %d characters of this alphabet contain less than %d random bits", length, minBits)
+	}
+	return &Generator{
+		length:   length,
+		alphabet: alphabet,
+		limit:    256 - 256%len(alphabet),
+	}, nil
+}
+
+// String returns a new random id
+func (g *Generator) String() string {
+	result := make([]byte, 0, g.length)
+	buf := make([]byte, g.length)
+	for len(result) < g.length {
+		_, err := rand.Read(buf)
+		if err != nil {
+			panic(err)
+		}
+		for _, b := range buf {
+			if int(b) < g.limit && len(result) < g.length {
+				result = append(result, g.alphabet[int(b)%len(g.alphabet)])
+			}
+		}
+	}
+	return string(result)
+}
+
+// Length returns the number of characters of the ids
+func (g *Generator) Length() int {
+	return g.length
+}
+
+// Valid returns true if the id could have been created by this generator
+func (g *Generator) Valid(id string) bool {
+	if len(id) != g.length {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if strings.IndexByte(g.alphabet, id[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+var generator = must(New(DefaultLength, DefaultAlphabet))
+
+func must(g *Generator, err error) *Generator {
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// Configure sets the length and the alphabet of the ids. Must be called
+// before the first id is created, the ids created before become invalid.
+func Configure(length int, alphabet string) error {
+	g, err := New(length, alphabet)
+	if err != nil {
+		return err
+	}
+	generator = g
+	return nil
+}
+
+// String returns a new random id
+func String() string {
+	return generator.String()
+}
+
+// Length returns the number of characters of the ids
+func Length() int {
+	return generator.Length()
+}
+
+// Valid returns true if the id has the length and the characters of the ids
+func Valid(id string) bool {
+	return generator.Valid(id)
+}
@@ -0,0 +1,54 @@
+package randid
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerator(t *testing.T) {
+	g, err := New(DefaultLength, DefaultAlphabet)
+	require.NoError(t, err)
+
+	seen := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		id := g.String()
+		assert.Len(t, id, DefaultLength)
+		assert.True(t, g.Valid(id))
+		assert.False(t, seen[id])
+		seen[id] = true
+	}
+
+	assert.False(t, g.Valid(strings.Repeat("a", DefaultLength-1)))
+	assert.False(t, g.Valid(strings.Repeat("a", DefaultLength-1)+"-"))
+}
+
+func TestGeneratorAlphabet(t *testing.T) {
+	g, err := New(100, "01")
+	require.NoError(t, err)
+	counts := map[rune]int{}
+	for _, c := range g.String() + g.String() {
+		counts[c]++
+	}
+	assert.Len(t, counts, 2)
+	assert.Equal(t, 200, counts['0']+counts['1'])
+	assert.False(t, g.Valid(strings.Repeat("2", 100)))
+}
+
+func TestGeneratorInvalid(t *testing.T) {
+	for _, test := range []struct {
+		length   int
+		alphabet string
+	}{
+		{length: 30, alphabet: "a"},
+		{length: 30, alphabet: "abca"},
+		{length: 30, alphabet: "ab/c"},
+		{length: 10, alphabet: DefaultAlphabet},
+		{length: 95, alphabet: "01"},
+	} {
+		_, err := New(test.length, test.alphabet)
+		assert.Error(t, err, test.alphabet)
+	}
+}
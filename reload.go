@@ -0,0 +1,41 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sync"
+)
+
+// certReloader holds the TLS certificate and allows to replace it while
+// the server is running. Established connections are not affected.
+type certReloader struct {
+	certFile string
+	keyFile  string
+
+	mutex sync.Mutex
+	cert  *tls.Certificate
+}
+
+func newCertReloader(certFile, keyFile string) (*certReloader, error) {
+	c := &certReloader{certFile: certFile, keyFile: keyFile}
+	return c, c.Reload()
+}
+
+// Reload reads the certificate files again. If they can not be read,
+// the former certificate is kept.
+func (c *certReloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return fmt.Errorf("could not load certificate: %w", err)
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.cert = &cert
+	return nil
+}
+
+func (c *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.cert, nil
+}
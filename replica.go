@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flashSurvey/federation"
+	"flashSurvey/handler"
+	"flashSurvey/survey"
+	"net/http"
+)
+
+// middleware wraps a handler
+type middleware func(http.HandlerFunc) http.HandlerFunc
+
+// replicaMux returns the routes of a read replica. The vote pages, the
+// result pages of the viewers and the badges are served from the mirrored
+// surveys, all other requests are redirected to the primary.
+func replicaMux(replica *federation.Replica, surveys *survey.Surveys, ensureUserId, longPoll, voteLimit, voteRateLimit middleware, static http.Handler) *http.ServeMux {
+	follow := replica.Follow
+	viewer := handler.Viewer(surveys)
+	// the ids are checked before a survey is followed, so an invalid id
+	// never causes a request to the primary
+	validate := handler.ValidateQuery
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", replica.Redirect)
+	mux.Handle("/static/", static)
+	mux.HandleFunc("/vote/", voteRateLimit(validate(ensureUserId(handler.Vote(replica)))))
+	mux.HandleFunc("/voteEvents/", validate(follow(handler.VoteEvents(surveys))))
+	mux.HandleFunc("/voteRest/", voteRateLimit(voteLimit(validate(ensureUserId(handler.VoteRest(replica))))))
+	mux.HandleFunc("/display/", validate(follow(viewer(handler.Result(surveys)))))
+	mux.HandleFunc("/displayWs/", validate(follow(viewer(handler.ResultWs(surveys)))))
+	mux.HandleFunc("/displayRest/", longPoll(validate(follow(viewer(handler.ResultRest(surveys))))))
+	mux.HandleFunc("/displayHeartbeat/", validate(follow(viewer(handler.Heartbeat(surveys)))))
+	mux.HandleFunc("/badge/", validate(follow(handler.Badge(surveys))))
+	return mux
+}
@@ -0,0 +1,207 @@
+package saml
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalize(t *testing.T) {
+	// example taken from the exclusive canonicalization specification
+	root, err := parseTree([]byte(`<n0:local xmlns:n0="foo:bar" xmlns:n3="ftp://example.org"><n1:elem2 xmlns:n1="http://example.net" xml:lang="en"><n3:stuff xmlns:n3="ftp://example.org"/></n1:elem2></n0:local>`))
+	assert.NoError(t, err)
+	elem2 := root.children[0].(*node)
+	assert.EqualValues(t,
+		`<n1:elem2 xmlns:n1="http://example.net" xml:lang="en"><n3:stuff xmlns:n3="ftp://example.org"></n3:stuff></n1:elem2>`,
+		string(canonicalize(elem2, nil, nil)))
+
+	root, err = parseTree([]byte(`<a xmlns="urn:a" xmlns:b="urn:b" z="1" b:y="2" a="&lt;&amp;&quot;"><b:c>x &gt; y</b:c><d xmlns=""/></a>`))
+	assert.NoError(t, err)
+	assert.EqualValues(t,
+		`<a xmlns="urn:a" xmlns:b="urn:b" a="&lt;&amp;&quot;" z="1" b:y="2"><b:c>x &gt; y</b:c><d xmlns=""></d></a>`,
+		string(canonicalize(root, nil, nil)))
+}
+
+const (
+	testHost = "https://survey.example.com"
+	testIdp  = "https://idp.example.com"
+)
+
+type testIdentityProvider struct {
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+}
+
+func newTestIdentityProvider(t *testing.T) *testIdentityProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "idp"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	return &testIdentityProvider{key: key, cert: cert}
+}
+
+func (idp *testIdentityProvider) metadata() []byte {
+	return []byte(`<md:EntityDescriptor xmlns:md="urn:oasis:names:tc:SAML:2.0:metadata" xmlns:ds="http://www.w3.org/2000/09/xmldsig#" entityID="` + testIdp + `">
+  <md:IDPSSODescriptor protocolSupportEnumeration="urn:oasis:names:tc:SAML:2.0:protocol">
+    <md:KeyDescriptor use="signing"><ds:KeyInfo><ds:X509Data><ds:X509Certificate>
+` + base64.StdEncoding.EncodeToString(idp.cert.Raw) + `
+    </ds:X509Certificate></ds:X509Data></ds:KeyInfo></md:KeyDescriptor>
+    <md:SingleSignOnService Binding="urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect" Location="` + testIdp + `/sso"/>
+  </md:IDPSSODescriptor>
+</md:EntityDescriptor>`)
+}
+
+// response creates a response with a signed assertion
+func (idp *testIdentityProvider) response(t *testing.T, requestId string, now time.Time, user string) string {
+	assertion := `<saml:Assertion xmlns:saml="urn:oasis:names:tc:SAML:2.0:assertion" xmlns:xs="http://www.w3.org/2001/XMLSchema" ID="_a1" Version="2.0" IssueInstant="` + now.UTC().Format(timeFormat) + `">
+    <saml:Issuer>` + testIdp + `</saml:Issuer>
+    SIGNATURE
+    <saml:Subject>
+      <saml:NameID>transient-123</saml:NameID>
+      <saml:SubjectConfirmation Method="urn:oasis:names:tc:SAML:2.0:cm:bearer">
+        <saml:SubjectConfirmationData InResponseTo="` + requestId + `" Recipient="` + testHost + `/saml/acs" NotOnOrAfter="` + now.Add(5*time.Minute).UTC().Format(timeFormat) + `"/>
+      </saml:SubjectConfirmation>
+    </saml:Subject>
+    <saml:Conditions NotBefore="` + now.Add(-time.Minute).UTC().Format(timeFormat) + `" NotOnOrAfter="` + now.Add(5*time.Minute).UTC().Format(timeFormat) + `">
+      <saml:AudienceRestriction><saml:Audience>` + testHost + `/saml/metadata</saml:Audience></saml:AudienceRestriction>
+    </saml:Conditions>
+    <saml:AttributeStatement>
+      <saml:Attribute Name="urn:oid:0.9.2342.19200300.100.1.3" FriendlyName="mail">
+        <saml:AttributeValue xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="xs:string">` + user + `</saml:AttributeValue>
+      </saml:Attribute>
+    </saml:AttributeStatement>
+  </saml:Assertion>`
+
+	unsigned, err := parseTree([]byte(strings.Replace(assertion, "SIGNATURE", "", 1)))
+	assert.NoError(t, err)
+	digest := sha256.Sum256(canonicalize(unsigned, nil, []string{"xs"}))
+
+	signature := `<ds:Signature xmlns:ds="http://www.w3.org/2000/09/xmldsig#"><ds:SignedInfo>
+      <ds:CanonicalizationMethod Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"/>
+      <ds:SignatureMethod Algorithm="http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"/>
+      <ds:Reference URI="#_a1"><ds:Transforms>
+        <ds:Transform Algorithm="http://www.w3.org/2000/09/xmldsig#enveloped-signature"/>
+        <ds:Transform Algorithm="http://www.w3.org/2001/10/xml-exc-c14n#"><ec:InclusiveNamespaces xmlns:ec="http://www.w3.org/2001/10/xml-exc-c14n#" PrefixList="xs"/></ds:Transform>
+      </ds:Transforms>
+      <ds:DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/>
+      <ds:DigestValue>` + base64.StdEncoding.EncodeToString(digest[:]) + `</ds:DigestValue>
+      </ds:Reference></ds:SignedInfo><ds:SignatureValue>VALUE</ds:SignatureValue></ds:Signature>`
+
+	response := `<samlp:Response xmlns:samlp="urn:oasis:names:tc:SAML:2.0:protocol" ID="_r1" Version="2.0" Destination="` + testHost + `/saml/acs" InResponseTo="` + requestId + `">
+  <samlp:Status><samlp:StatusCode Value="urn:oasis:names:tc:SAML:2.0:status:Success"/></samlp:Status>
+  ` + strings.Replace(assertion, "SIGNATURE", signature, 1) + `
+</samlp:Response>`
+
+	doc, err := parseTree([]byte(response))
+	assert.NoError(t, err)
+	var signedInfo *node
+	doc.walk(func(n *node) {
+		if n.is(nsDS, "SignedInfo") {
+			signedInfo = n
+		}
+	})
+	hashed := sha256.Sum256(canonicalize(signedInfo, nil, nil))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, idp.key, crypto.SHA256, hashed[:])
+	assert.NoError(t, err)
+
+	response = strings.Replace(response, "VALUE", base64.StdEncoding.EncodeToString(sig), 1)
+	return base64.StdEncoding.EncodeToString([]byte(response))
+}
+
+func newTestServiceProvider(t *testing.T, idp *testIdentityProvider) *ServiceProvider {
+	sp := &ServiceProvider{
+		entityId:  testHost + "/saml/metadata",
+		acsUrl:    testHost + "/saml/acs",
+		attribute: "mail",
+		pending:   make(map[string]time.Time),
+	}
+	assert.NoError(t, sp.readMetadata(idp.metadata()))
+	return sp
+}
+
+func requestId(t *testing.T, sp *ServiceProvider) string {
+	_, id, err := sp.LoginURL()
+	assert.NoError(t, err)
+	sp.pending[id] = time.Now().Add(time.Minute)
+	return id
+}
+
+func TestResponse(t *testing.T) {
+	idp := newTestIdentityProvider(t)
+	sp := newTestServiceProvider(t, idp)
+	now := time.Now()
+
+	id := requestId(t, sp)
+	response := idp.response(t, id, now, "creator@example.com")
+	user, err := sp.parseResponse(response, id, now)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "creator@example.com", user)
+
+	// replay is rejected
+	_, err = sp.parseResponse(response, id, now)
+	assert.Error(t, err)
+
+	// the response to a request of an other browser is rejected
+	other := requestId(t, sp)
+	id = requestId(t, sp)
+	_, err = sp.parseResponse(idp.response(t, other, now, "creator@example.com"), id, now)
+	assert.Error(t, err)
+
+	// expired
+	id = requestId(t, sp)
+	_, err = sp.parseResponse(idp.response(t, id, now, "creator@example.com"), id, now.Add(time.Hour))
+	assert.Error(t, err)
+}
+
+func TestMaxPending(t *testing.T) {
+	sp := newTestServiceProvider(t, newTestIdentityProvider(t))
+	for range maxPending {
+		requestId(t, sp)
+	}
+	_, _, err := sp.LoginURL()
+	assert.Error(t, err)
+
+	// expired requests are removed if the limit is reached
+	for id := range sp.pending {
+		sp.pending[id] = time.Now().Add(-time.Second)
+	}
+	requestId(t, sp)
+	assert.Len(t, sp.pending, 1)
+}
+
+func TestTamperedResponse(t *testing.T) {
+	idp := newTestIdentityProvider(t)
+	sp := newTestServiceProvider(t, idp)
+	now := time.Now()
+
+	id := requestId(t, sp)
+	data, err := base64.StdEncoding.DecodeString(idp.response(t, id, now, "creator@example.com"))
+	assert.NoError(t, err)
+	tampered := strings.Replace(string(data), "creator@example.com", "admin@example.com", 1)
+	_, err = sp.parseResponse(base64.StdEncoding.EncodeToString([]byte(tampered)), id, now)
+	assert.Error(t, err)
+
+	// signed by an other identity provider
+	other := newTestIdentityProvider(t)
+	id = requestId(t, sp)
+	_, err = sp.parseResponse(other.response(t, id, now, "creator@example.com"), id, now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,177 @@
+package saml
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"math/big"
+	"strings"
+)
+
+const (
+	nsDS         = "http://www.w3.org/2000/09/xmldsig#"
+	nsExcC14N    = "http://www.w3.org/2001/10/xml-exc-c14n#"
+	algEnveloped = "http://www.w3.org/2000/09/xmldsig#enveloped-signature"
+	algRSASHA256 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256"
+	algRSASHA512 = "http://www.w3.org/2001/04/xmldsig-more#rsa-sha512"
+	algECDSA256  = "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"
+	algDigest256 = "http://www.w3.org/2001/04/xmlenc#sha256"
+	algDigest512 = "http://www.w3.org/2001/04/xmlenc#sha512"
+)
+
+// verifySignature checks the enveloped signature of the element el.
+// Only the algorithms commonly used by SAML identity providers are
+// supported, SHA-1 based algorithms are rejected.
+func verifySignature(el *node, certs []*x509.Certificate) error {
+	signatures := el.childNodes(nsDS, "Signature")
+	if len(signatures) == 0 {
+		return errNotSigned
+	}
+	if len(signatures) > 1 {
+		return errors.New("more than one signature")
+	}
+	sig := signatures[0]
+
+	id := el.attr("ID")
+	if id == "" {
+		return errors.New("signed element has no ID")
+	}
+	// the ID must be unique in the whole document to avoid signature wrapping attacks
+	root := el
+	for root.parent != nil {
+		root = root.parent
+	}
+	count := 0
+	root.walk(func(n *node) {
+		if n.attr("ID") == id {
+			count++
+		}
+	})
+	if count != 1 {
+		return errors.New("duplicate ID")
+	}
+
+	signedInfo := sig.child(nsDS, "SignedInfo")
+	if signedInfo == nil {
+		return errors.New("no SignedInfo")
+	}
+	c14n := signedInfo.child(nsDS, "CanonicalizationMethod")
+	if c14n == nil || c14n.attr("Algorithm") != nsExcC14N {
+		return errors.New("unsupported canonicalization")
+	}
+	sigMethod := signedInfo.child(nsDS, "SignatureMethod")
+	if sigMethod == nil {
+		return errors.New("no SignatureMethod")
+	}
+
+	ref := signedInfo.child(nsDS, "Reference")
+	if ref == nil || ref.attr("URI") != "#"+id {
+		return errors.New("signature does not reference the signed element")
+	}
+
+	var inclusive []string
+	transforms := ref.child(nsDS, "Transforms")
+	if transforms == nil {
+		return errors.New("no transforms")
+	}
+	enveloped := false
+	for _, c := range transforms.childNodes(nsDS, "Transform") {
+		switch c.attr("Algorithm") {
+		case algEnveloped:
+			enveloped = true
+		case nsExcC14N:
+			inclusive = inclusivePrefixes(c)
+		default:
+			return errors.New("unsupported transform")
+		}
+	}
+	if !enveloped {
+		return errors.New("signature is not enveloped")
+	}
+
+	digestMethod := ref.child(nsDS, "DigestMethod")
+	if digestMethod == nil {
+		return errors.New("no DigestMethod")
+	}
+	var digestHash crypto.Hash
+	switch digestMethod.attr("Algorithm") {
+	case algDigest256:
+		digestHash = crypto.SHA256
+	case algDigest512:
+		digestHash = crypto.SHA512
+	default:
+		return errors.New("unsupported digest")
+	}
+	digestValue := ref.child(nsDS, "DigestValue")
+	if digestValue == nil {
+		return errors.New("no DigestValue")
+	}
+	expectedDigest, err := decodeBase64(digestValue.text())
+	if err != nil {
+		return err
+	}
+	h := digestHash.New()
+	h.Write(canonicalize(el, sig, inclusive))
+	if !bytes.Equal(h.Sum(nil), expectedDigest) {
+		return errors.New("digest mismatch")
+	}
+
+	sigValue := sig.child(nsDS, "SignatureValue")
+	if sigValue == nil {
+		return errors.New("no SignatureValue")
+	}
+	signature, err := decodeBase64(sigValue.text())
+	if err != nil {
+		return err
+	}
+
+	var sigHash crypto.Hash
+	switch sigMethod.attr("Algorithm") {
+	case algRSASHA256, algECDSA256:
+		sigHash = crypto.SHA256
+	case algRSASHA512:
+		sigHash = crypto.SHA512
+	default:
+		return errors.New("unsupported signature method")
+	}
+	h = sigHash.New()
+	h.Write(canonicalize(signedInfo, nil, inclusivePrefixes(c14n)))
+	hashed := h.Sum(nil)
+
+	for _, cert := range certs {
+		switch key := cert.PublicKey.(type) {
+		case *rsa.PublicKey:
+			if sigMethod.attr("Algorithm") != algECDSA256 && rsa.VerifyPKCS1v15(key, sigHash, hashed, signature) == nil {
+				return nil
+			}
+		case *ecdsa.PublicKey:
+			// XML-DSig uses the concatenation of r and s
+			if sigMethod.attr("Algorithm") == algECDSA256 && len(signature)%2 == 0 {
+				r := new(big.Int).SetBytes(signature[:len(signature)/2])
+				s := new(big.Int).SetBytes(signature[len(signature)/2:])
+				if ecdsa.Verify(key, hashed, r, s) {
+					return nil
+				}
+			}
+		}
+	}
+	return errors.New("invalid signature")
+}
+
+var errNotSigned = errors.New("not signed")
+
+func inclusivePrefixes(transform *node) []string {
+	in := transform.child(nsExcC14N, "InclusiveNamespaces")
+	if in == nil {
+		return nil
+	}
+	return strings.Fields(in.attr("PrefixList"))
+}
+
+func decodeBase64(str string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(str), ""))
+}
@@ -0,0 +1,366 @@
+package saml
+
+import (
+	"bytes"
+	"compress/flate"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	nsSAML        = "urn:oasis:names:tc:SAML:2.0:assertion"
+	nsSAMLP       = "urn:oasis:names:tc:SAML:2.0:protocol"
+	nsMD          = "urn:oasis:names:tc:SAML:2.0:metadata"
+	bindingPost   = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-POST"
+	bindingRedir  = "urn:oasis:names:tc:SAML:2.0:bindings:HTTP-Redirect"
+	statusSuccess = "urn:oasis:names:tc:SAML:2.0:status:Success"
+	bearer        = "urn:oasis:names:tc:SAML:2.0:cm:bearer"
+	timeFormat    = "2006-01-02T15:04:05Z"
+
+	requestTimeout = 10 * time.Minute
+	clockSkew      = 2 * time.Minute
+	// maxPending is the maximum number of requests waiting for a response,
+	// the login page can be opened by anyone
+	maxPending = 10000
+)
+
+// ServiceProvider implements a minimal SAML 2.0 service provider which
+// supports the HTTP-Redirect binding for requests and the HTTP-POST binding
+// for responses. The responses or the assertions must be signed by the
+// identity provider. Encrypted assertions are not supported.
+// All methods can be called on a nil value, which means that SAML is disabled.
+type ServiceProvider struct {
+	entityId    string
+	acsUrl      string
+	idpEntityId string
+	idpSSOUrl   string
+	idpCerts    []*x509.Certificate
+	attribute   string
+
+	mutex   sync.Mutex
+	pending map[string]time.Time
+}
+
+// New creates a new service provider. The identity provider is configured
+// by its metadata file. The given attribute of the assertion is used as the
+// account id. If the attribute is missing, the NameID is used.
+// If metadataFile is empty, nil is returned.
+func New(metadataFile, host, attribute string) (*ServiceProvider, error) {
+	if metadataFile == "" {
+		return nil, nil
+	}
+	if host == "" {
+		return nil, errors.New("SAML requires the host to be set")
+	}
+
+	data, err := os.ReadFile(metadataFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read idp metadata: %w", err)
+	}
+
+	sp := &ServiceProvider{
+		entityId:  host + "/saml/metadata",
+		acsUrl:    host + "/saml/acs",
+		attribute: attribute,
+		pending:   make(map[string]time.Time),
+	}
+	err = sp.readMetadata(data)
+	if err != nil {
+		return nil, err
+	}
+	log.Println("SAML identity provider:", sp.idpEntityId)
+	return sp, nil
+}
+
+func (sp *ServiceProvider) readMetadata(data []byte) error {
+	root, err := parseTree(data)
+	if err != nil {
+		return fmt.Errorf("could not parse idp metadata: %w", err)
+	}
+	if !root.is(nsMD, "EntityDescriptor") {
+		return errors.New("idp metadata contains no EntityDescriptor")
+	}
+	sp.idpEntityId = root.attr("entityID")
+
+	idp := root.child(nsMD, "IDPSSODescriptor")
+	if idp == nil {
+		return errors.New("idp metadata contains no IDPSSODescriptor")
+	}
+	for _, sso := range idp.childNodes(nsMD, "SingleSignOnService") {
+		if sso.attr("Binding") == bindingRedir {
+			sp.idpSSOUrl = sso.attr("Location")
+		}
+	}
+	if sp.idpSSOUrl == "" {
+		return errors.New("idp supports no HTTP-Redirect binding")
+	}
+
+	for _, kd := range idp.childNodes(nsMD, "KeyDescriptor") {
+		if use := kd.attr("use"); use != "" && use != "signing" {
+			continue
+		}
+		certNode := kd.path(nsDS, "KeyInfo", "X509Data", "X509Certificate")
+		if certNode == nil {
+			continue
+		}
+		der, err := decodeBase64(certNode.text())
+		if err != nil {
+			return fmt.Errorf("invalid idp certificate: %w", err)
+		}
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return fmt.Errorf("invalid idp certificate: %w", err)
+		}
+		sp.idpCerts = append(sp.idpCerts, cert)
+	}
+	if len(sp.idpCerts) == 0 {
+		return errors.New("idp metadata contains no signing certificate")
+	}
+	return nil
+}
+
+// Available returns true if SAML is configured
+func (sp *ServiceProvider) Available() bool {
+	return sp != nil
+}
+
+// Metadata returns the metadata of this service provider
+func (sp *ServiceProvider) Metadata() []byte {
+	return []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<md:EntityDescriptor xmlns:md="` + nsMD + `" entityID="` + escapeAttr(sp.entityId) + `">
+  <md:SPSSODescriptor AuthnRequestsSigned="false" WantAssertionsSigned="true" protocolSupportEnumeration="` + nsSAMLP + `">
+    <md:AssertionConsumerService Binding="` + bindingPost + `" Location="` + escapeAttr(sp.acsUrl) + `" index="0" isDefault="true"/>
+  </md:SPSSODescriptor>
+</md:EntityDescriptor>
+`)
+}
+
+// LoginURL creates an authentication request and returns the URL of the
+// identity provider the browser has to be redirected to and the id of the
+// request. The id has to be stored in the browser, only a response to this
+// request is accepted from the same browser, see ParseResponse.
+func (sp *ServiceProvider) LoginURL() (string, string, error) {
+	if sp == nil {
+		return "", "", errors.New("SAML is not configured")
+	}
+
+	b := make([]byte, 20)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", "", err
+	}
+	id := "_" + hex.EncodeToString(b)
+	now := time.Now()
+
+	sp.mutex.Lock()
+	if len(sp.pending) >= maxPending {
+		for i, t := range sp.pending {
+			if now.After(t) {
+				delete(sp.pending, i)
+			}
+		}
+	}
+	if len(sp.pending) >= maxPending {
+		sp.mutex.Unlock()
+		return "", "", errors.New("too many pending requests")
+	}
+	sp.pending[id] = now.Add(requestTimeout)
+	sp.mutex.Unlock()
+
+	req := `<samlp:AuthnRequest xmlns:samlp="` + nsSAMLP + `" xmlns:saml="` + nsSAML + `" ID="` + id +
+		`" Version="2.0" IssueInstant="` + now.UTC().Format(timeFormat) +
+		`" Destination="` + escapeAttr(sp.idpSSOUrl) +
+		`" AssertionConsumerServiceURL="` + escapeAttr(sp.acsUrl) +
+		`" ProtocolBinding="` + bindingPost + `"><saml:Issuer>` + escapeText(sp.entityId) +
+		`</saml:Issuer><samlp:NameIDPolicy AllowCreate="true"/></samlp:AuthnRequest>`
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.DefaultCompression)
+	if err != nil {
+		return "", "", err
+	}
+	_, err = w.Write([]byte(req))
+	if err != nil {
+		return "", "", err
+	}
+	err = w.Close()
+	if err != nil {
+		return "", "", err
+	}
+
+	u, err := url.Parse(sp.idpSSOUrl)
+	if err != nil {
+		return "", "", err
+	}
+	q := u.Query()
+	q.Set("SAMLRequest", base64.StdEncoding.EncodeToString(buf.Bytes()))
+	u.RawQuery = q.Encode()
+	return u.String(), id, nil
+}
+
+// ParseResponse verifies the base64 encoded response sent by the identity
+// provider and returns the id of the authenticated user. The requestId is
+// the id returned by LoginURL to the browser which sent the response.
+// Responses to requests started by other browsers are rejected, so nobody
+// can log in a victim to the attacker's account.
+func (sp *ServiceProvider) ParseResponse(samlResponse, requestId string) (string, error) {
+	if sp == nil {
+		return "", errors.New("SAML is not configured")
+	}
+	return sp.parseResponse(samlResponse, requestId, time.Now())
+}
+
+func (sp *ServiceProvider) parseResponse(samlResponse, requestId string, now time.Time) (string, error) {
+	data, err := decodeBase64(samlResponse)
+	if err != nil {
+		return "", fmt.Errorf("invalid response encoding: %w", err)
+	}
+	root, err := parseTree(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid response: %w", err)
+	}
+	if !root.is(nsSAMLP, "Response") {
+		return "", errors.New("not a SAML response")
+	}
+	if d := root.attr("Destination"); d != "" && d != sp.acsUrl {
+		return "", errors.New("wrong destination")
+	}
+	status := root.path(nsSAMLP, "Status", "StatusCode")
+	if status == nil || status.attr("Value") != statusSuccess {
+		return "", errors.New("authentication failed at the identity provider")
+	}
+
+	if root.child(nsSAML, "EncryptedAssertion") != nil {
+		return "", errors.New("encrypted assertions are not supported")
+	}
+	assertion := root.child(nsSAML, "Assertion")
+	if assertion == nil {
+		return "", errors.New("response contains not exactly one assertion")
+	}
+
+	// either the response or the assertion has to be signed
+	responseErr := verifySignature(root, sp.idpCerts)
+	if responseErr != nil && !errors.Is(responseErr, errNotSigned) {
+		return "", fmt.Errorf("response signature: %w", responseErr)
+	}
+	assertionErr := verifySignature(assertion, sp.idpCerts)
+	if assertionErr != nil && !errors.Is(assertionErr, errNotSigned) {
+		return "", fmt.Errorf("assertion signature: %w", assertionErr)
+	}
+	if responseErr != nil && assertionErr != nil {
+		return "", errors.New("neither response nor assertion is signed")
+	}
+
+	issuer := assertion.child(nsSAML, "Issuer")
+	if issuer == nil || issuer.text() != sp.idpEntityId {
+		return "", errors.New("wrong issuer")
+	}
+
+	conditions := assertion.child(nsSAML, "Conditions")
+	if conditions == nil {
+		return "", errors.New("assertion has no conditions")
+	}
+	err = checkTime(conditions, now)
+	if err != nil {
+		return "", err
+	}
+	audienceOk := false
+	for _, ar := range conditions.childNodes(nsSAML, "AudienceRestriction") {
+		for _, a := range ar.childNodes(nsSAML, "Audience") {
+			if a.text() == sp.entityId {
+				audienceOk = true
+			}
+		}
+	}
+	if !audienceOk {
+		return "", errors.New("wrong audience")
+	}
+
+	subject := assertion.child(nsSAML, "Subject")
+	if subject == nil {
+		return "", errors.New("assertion has no subject")
+	}
+	confirmed := false
+	for _, sc := range subject.childNodes(nsSAML, "SubjectConfirmation") {
+		if sc.attr("Method") != bearer {
+			continue
+		}
+		scd := sc.child(nsSAML, "SubjectConfirmationData")
+		if scd == nil || scd.attr("Recipient") != sp.acsUrl || scd.attr("NotOnOrAfter") == "" {
+			continue
+		}
+		if checkTime(scd, now) != nil {
+			continue
+		}
+		if !sp.takeRequest(scd.attr("InResponseTo"), requestId, now) {
+			return "", errors.New("unknown or reused request")
+		}
+		confirmed = true
+		break
+	}
+	if !confirmed {
+		return "", errors.New("subject not confirmed")
+	}
+
+	if sp.attribute != "" {
+		if as := assertion.child(nsSAML, "AttributeStatement"); as != nil {
+			for _, a := range as.childNodes(nsSAML, "Attribute") {
+				if a.attr("Name") == sp.attribute || a.attr("FriendlyName") == sp.attribute {
+					if v := a.childNodes(nsSAML, "AttributeValue"); len(v) > 0 && v[0].text() != "" {
+						return v[0].text(), nil
+					}
+				}
+			}
+		}
+	}
+
+	nameId := subject.child(nsSAML, "NameID")
+	if nameId == nil || nameId.text() == "" {
+		return "", errors.New("no user id found in assertion")
+	}
+	return nameId.text(), nil
+}
+
+func (sp *ServiceProvider) takeRequest(id, requestId string, now time.Time) bool {
+	if id == "" || id != requestId {
+		return false
+	}
+
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	expires, ok := sp.pending[id]
+	delete(sp.pending, id)
+	return ok && now.Before(expires)
+}
+
+func checkTime(n *node, now time.Time) error {
+	if nb := n.attr("NotBefore"); nb != "" {
+		t, err := time.Parse(time.RFC3339, nb)
+		if err != nil {
+			return fmt.Errorf("invalid time: %w", err)
+		}
+		if now.Add(clockSkew).Before(t) {
+			return errors.New("assertion not yet valid")
+		}
+	}
+	if na := n.attr("NotOnOrAfter"); na != "" {
+		t, err := time.Parse(time.RFC3339, na)
+		if err != nil {
+			return fmt.Errorf("invalid time: %w", err)
+		}
+		if !now.Add(-clockSkew).Before(t) {
+			return errors.New("assertion expired")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package saml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+const nsXML = "http://www.w3.org/XML/1998/namespace"
+
+// node is a minimal DOM used to verify signatures. In contrast to
+// the encoding/xml unmarshaller it keeps the namespace prefixes and
+// declarations, which are required for the canonicalization.
+type node struct {
+	parent   *node
+	name     xml.Name // Space contains the prefix
+	attrs    []xml.Attr
+	children []any // *node or string
+}
+
+func parseTree(data []byte) (*node, error) {
+	d := xml.NewDecoder(bytes.NewReader(data))
+	var root *node
+	var current *node
+	for {
+		t, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			n := &node{parent: current, name: t.Name, attrs: append([]xml.Attr(nil), t.Attr...)}
+			if current == nil {
+				if root != nil {
+					return nil, errors.New("more than one root element")
+				}
+				root = n
+			} else {
+				current.children = append(current.children, n)
+			}
+			current = n
+		case xml.EndElement:
+			if current == nil || current.name != t.Name {
+				return nil, errors.New("unbalanced xml")
+			}
+			current = current.parent
+		case xml.CharData:
+			if current != nil {
+				current.children = append(current.children, string(t))
+			}
+		case xml.Directive:
+			return nil, errors.New("xml directives are not allowed")
+		}
+	}
+	if root == nil || current != nil {
+		return nil, errors.New("incomplete xml")
+	}
+	return root, nil
+}
+
+// lookupNS returns the namespace bound to the given prefix.
+// The empty prefix denotes the default namespace.
+func (n *node) lookupNS(prefix string) (string, bool) {
+	if prefix == "xml" {
+		return nsXML, true
+	}
+	for e := n; e != nil; e = e.parent {
+		for _, a := range e.attrs {
+			if prefix == "" && a.Name.Space == "" && a.Name.Local == "xmlns" {
+				return a.Value, true
+			}
+			if prefix != "" && a.Name.Space == "xmlns" && a.Name.Local == prefix {
+				return a.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+func (n *node) space() string {
+	ns, _ := n.lookupNS(n.name.Space)
+	return ns
+}
+
+func (n *node) is(space, local string) bool {
+	return n.name.Local == local && n.space() == space
+}
+
+// attr returns the value of the unprefixed attribute with the given name
+func (n *node) attr(local string) string {
+	for _, a := range n.attrs {
+		if a.Name.Space == "" && a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func (n *node) childNodes(space, local string) []*node {
+	var list []*node
+	for _, c := range n.children {
+		if cn, ok := c.(*node); ok && cn.is(space, local) {
+			list = append(list, cn)
+		}
+	}
+	return list
+}
+
+// child returns the only child with the given name. If there is no
+// such child or more than one, nil is returned.
+func (n *node) child(space, local string) *node {
+	list := n.childNodes(space, local)
+	if len(list) != 1 {
+		return nil
+	}
+	return list[0]
+}
+
+// path follows a path of children in the given namespace
+func (n *node) path(space string, locals ...string) *node {
+	e := n
+	for _, l := range locals {
+		if e == nil {
+			return nil
+		}
+		e = e.child(space, l)
+	}
+	return e
+}
+
+func (n *node) text() string {
+	var b strings.Builder
+	for _, c := range n.children {
+		if s, ok := c.(string); ok {
+			b.WriteString(s)
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// walk calls f for all elements of the tree
+func (n *node) walk(f func(*node)) {
+	f(n)
+	for _, c := range n.children {
+		if cn, ok := c.(*node); ok {
+			cn.walk(f)
+		}
+	}
+}
+
+// canonicalize implements the exclusive XML canonicalization without comments
+// (http://www.w3.org/2001/10/xml-exc-c14n#) of the subtree n. The element
+// exclude and its children are omitted, which implements the enveloped
+// signature transform. The inclusive prefixes are treated as described by the
+// InclusiveNamespaces PrefixList.
+func canonicalize(n *node, exclude *node, inclusive []string) []byte {
+	var b bytes.Buffer
+	writeCanonical(&b, n, exclude, inclusive, map[string]string{})
+	return b.Bytes()
+}
+
+type nsDecl struct {
+	prefix string
+	uri    string
+}
+
+func writeCanonical(b *bytes.Buffer, n *node, exclude *node, inclusive []string, rendered map[string]string) {
+	needed := map[string]bool{n.name.Space: true}
+	for _, a := range n.attrs {
+		if a.Name.Space != "" && a.Name.Space != "xmlns" && a.Name.Space != "xml" {
+			needed[a.Name.Space] = true
+		}
+	}
+	for _, p := range inclusive {
+		if p == "#default" {
+			p = ""
+		}
+		if _, ok := n.lookupNS(p); ok {
+			needed[p] = true
+		}
+	}
+
+	var decls []nsDecl
+	for p := range needed {
+		if p == "xml" {
+			continue
+		}
+		uri, _ := n.lookupNS(p)
+		prev, ok := rendered[p]
+		if ok && prev == uri {
+			continue
+		}
+		if !ok && uri == "" {
+			continue
+		}
+		decls = append(decls, nsDecl{prefix: p, uri: uri})
+	}
+	sort.Slice(decls, func(i, j int) bool {
+		return decls[i].prefix < decls[j].prefix
+	})
+
+	if len(decls) > 0 {
+		r := make(map[string]string, len(rendered)+len(decls))
+		for k, v := range rendered {
+			r[k] = v
+		}
+		for _, d := range decls {
+			r[d.prefix] = d.uri
+		}
+		rendered = r
+	}
+
+	type attr struct {
+		space string
+		qname string
+		local string
+		value string
+	}
+	var attrs []attr
+	for _, a := range n.attrs {
+		if a.Name.Space == "xmlns" || (a.Name.Space == "" && a.Name.Local == "xmlns") {
+			continue
+		}
+		var space string
+		qname := a.Name.Local
+		if a.Name.Space != "" {
+			space, _ = n.lookupNS(a.Name.Space)
+			qname = a.Name.Space + ":" + a.Name.Local
+		}
+		attrs = append(attrs, attr{space: space, qname: qname, local: a.Name.Local, value: a.Value})
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].space != attrs[j].space {
+			return attrs[i].space < attrs[j].space
+		}
+		return attrs[i].local < attrs[j].local
+	})
+
+	qname := n.name.Local
+	if n.name.Space != "" {
+		qname = n.name.Space + ":" + n.name.Local
+	}
+
+	b.WriteString("<" + qname)
+	for _, d := range decls {
+		if d.prefix == "" {
+			b.WriteString(` xmlns="`)
+		} else {
+			b.WriteString(` xmlns:` + d.prefix + `="`)
+		}
+		b.WriteString(escapeAttr(d.uri))
+		b.WriteString(`"`)
+	}
+	for _, a := range attrs {
+		b.WriteString(" " + a.qname + `="` + escapeAttr(a.value) + `"`)
+	}
+	b.WriteString(">")
+	for _, c := range n.children {
+		switch c := c.(type) {
+		case string:
+			b.WriteString(escapeText(c))
+		case *node:
+			if c != exclude {
+				writeCanonical(b, c, exclude, inclusive, rendered)
+			}
+		}
+	}
+	b.WriteString("</" + qname + ">")
+}
+
+var textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+var attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+
+func escapeAttr(s string) string {
+	return attrEscaper.Replace(s)
+}
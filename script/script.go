@@ -0,0 +1,179 @@
+// Package script allows operators to add hooks to the survey life cycle
+// without rebuilding the binary. A hook script is a text/template file which
+// defines templates named after the events:
+//
+//	{{define "beforeVote"}}{{if gt (len .Options) 2}}{{.Reject "Maximal zwei Antworten!"}}{{end}}{{end}}
+//	{{define "afterUncover"}}{{.Mail "teacher@example.com" .Question.Title .Summary}}{{end}}
+//
+// Available events are onStart, beforeVote, afterVote, afterUncover,
+// onAnnotate and onExpire. The templates can only access the methods of Context and the
+// builtin template functions, so they are not able to access the file
+// system or the network. The output of a template is written to the log.
+package script
+
+import (
+	"bytes"
+	"errors"
+	"flashSurvey/mailer"
+	"flashSurvey/survey"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/template"
+)
+
+const (
+	onStart      = "onStart"
+	beforeVote   = "beforeVote"
+	afterVote    = "afterVote"
+	afterUncover = "afterUncover"
+	onExpire     = "onExpire"
+	onAnnotate   = "onAnnotate"
+)
+
+// Script is a loaded hook script
+type Script struct {
+	name   string
+	tmpl   *template.Template
+	mailer *mailer.Mailer
+}
+
+// Load reads the hook script from the given file.
+// If file is empty, nil is returned.
+func Load(file string, m *mailer.Mailer) (*Script, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("could not read hook script: %w", err)
+	}
+	return parse(file, string(data), m)
+}
+
+func parse(name, text string, m *mailer.Mailer) (*Script, error) {
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse hook script: %w", err)
+	}
+	found := false
+	for _, event := range []string{onStart, beforeVote, afterVote, afterUncover, onAnnotate, onExpire} {
+		if tmpl.Lookup(event) != nil {
+			log.Printf("hook script %s handles %s", name, event)
+			found = true
+		}
+	}
+	if !found {
+		return nil, errors.New("hook script defines no event")
+	}
+	return &Script{name: name, tmpl: tmpl, mailer: m}, nil
+}
+
+// Hooks returns the hooks which execute the script
+func (s *Script) Hooks() survey.Hooks {
+	var h survey.Hooks
+	if s.tmpl.Lookup(onStart) != nil {
+		h.OnStart = func(e survey.Event) {
+			s.execute(onStart, &Context{Event: e})
+		}
+	}
+	if s.tmpl.Lookup(beforeVote) != nil {
+		h.BeforeVote = func(e survey.VoteEvent) error {
+			c := &Context{Event: e.Event, VoterId: e.VoterId, Options: e.Options}
+			s.execute(beforeVote, c)
+			return c.rejected
+		}
+	}
+	if s.tmpl.Lookup(afterVote) != nil {
+		h.AfterVote = func(e survey.VoteEvent) {
+			s.execute(afterVote, &Context{Event: e.Event, VoterId: e.VoterId, Options: e.Options, Result: e.Result})
+		}
+	}
+	if s.tmpl.Lookup(afterUncover) != nil {
+		h.AfterUncover = func(e survey.ResultEvent) {
+			s.execute(afterUncover, &Context{Event: e.Event, Result: e.Result})
+		}
+	}
+	if s.tmpl.Lookup(onAnnotate) != nil {
+		h.OnAnnotate = func(e survey.ResultEvent) {
+			s.execute(onAnnotate, &Context{Event: e.Event, Result: e.Result})
+		}
+	}
+	if s.tmpl.Lookup(onExpire) != nil {
+		h.OnExpire = func(e survey.ResultEvent) {
+			s.execute(onExpire, &Context{Event: e.Event, Result: e.Result})
+		}
+	}
+	return h
+}
+
+// execute runs the template of the given event. Errors are only logged,
+// so a broken script never rejects a vote.
+func (s *Script) execute(event string, c *Context) {
+	c.mailer = s.mailer
+	var out bytes.Buffer
+	err := s.tmpl.ExecuteTemplate(&out, event, c)
+	if err != nil {
+		log.Printf("hook script %s failed on %s: %v", s.name, event, err)
+		c.rejected = nil
+		return
+	}
+	if msg := strings.TrimSpace(out.String()); msg != "" {
+		log.Printf("hook script %s on %s: %s", s.name, event, msg)
+	}
+}
+
+// Context is the data the scripts are executed with
+type Context struct {
+	survey.Event
+	// VoterId and Options are only available for vote events
+	VoterId survey.UserId
+	Options []int
+	// Result is only available for afterVote, afterUncover, onAnnotate and onExpire
+	Result survey.Result
+
+	mailer   *mailer.Mailer
+	rejected error
+}
+
+// Reject rejects the vote with the given message. It is only effective in beforeVote.
+func (c *Context) Reject(msg string) string {
+	c.rejected = errors.New(msg)
+	return ""
+}
+
+// Mail sends a mail in the background
+func (c *Context) Mail(to, subject, body string) string {
+	if !c.mailer.Available() {
+		return "no mail server configured"
+	}
+	go func() {
+		err := c.mailer.Send(to, subject, body)
+		if err != nil {
+			log.Println(err)
+		}
+	}()
+	return ""
+}
+
+// OptionTitles returns the titles of the options chosen by the voter
+func (c *Context) OptionTitles() []string {
+	var titles []string
+	for _, o := range c.Options {
+		if o >= 0 && o < len(c.Question.Options) {
+			titles = append(titles, c.Question.Options[o])
+		}
+	}
+	return titles
+}
+
+// Summary returns the result as plain text
+func (c *Context) Summary() string {
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("%s\n%d Stimmen\n\n", c.Question.Title, c.Result.Votes))
+	for _, r := range c.Result.Result {
+		b.WriteString(r.String() + "\n")
+	}
+	return b.String()
+}
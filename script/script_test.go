@@ -0,0 +1,49 @@
+package script
+
+import (
+	"flashSurvey/survey"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBeforeVote(t *testing.T) {
+	s, err := parse("test", `
+{{define "beforeVote"}}
+  {{if gt (len .Options) 1}}{{.Reject "Nur eine Antwort!"}}{{end}}
+  {{if eq .VoterId "broken"}}{{index .Options 10}}{{.Reject "never reached"}}{{end}}
+{{end}}`, nil)
+	assert.NoError(t, err)
+
+	h := s.Hooks()
+	assert.Nil(t, h.AfterVote)
+
+	e := survey.VoteEvent{Event: survey.Event{Question: survey.SurveyQuestion{Title: "Q", Options: []string{"a", "b"}}}}
+	e.VoterId = "v1"
+	e.Options = []int{0}
+	assert.NoError(t, h.BeforeVote(e))
+
+	e.Options = []int{0, 1}
+	assert.EqualError(t, h.BeforeVote(e), "Nur eine Antwort!")
+
+	// a failing script does not reject the vote
+	e.VoterId = "broken"
+	e.Options = []int{0}
+	assert.NoError(t, h.BeforeVote(e))
+}
+
+func TestContext(t *testing.T) {
+	c := &Context{
+		Event:   survey.Event{Question: survey.SurveyQuestion{Title: "Q", Options: []string{"a", "b", "c"}}},
+		Options: []int{2, 0, 5},
+	}
+	assert.EqualValues(t, []string{"c", "a"}, c.OptionTitles())
+	assert.EqualValues(t, "no mail server configured", c.Mail("a@example.com", "s", "b"))
+}
+
+func TestInvalidScript(t *testing.T) {
+	_, err := parse("test", `{{define "unknown"}}{{end}}`, nil)
+	assert.Error(t, err)
+	_, err = parse("test", `{{define "onStart"}}{{.Unknown}}{{end`, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flashSurvey/survey"
+	"log"
+	"runtime"
+	"time"
+)
+
+// logStats periodically logs the memory usage, the number of goroutines and
+// the number of surveys. A steadily growing number of goroutines usually
+// indicates stuck long-poll requests.
+func logStats(interval time.Duration, surveys *survey.Surveys) {
+	if interval <= 0 {
+		return
+	}
+	go func() {
+		for range time.Tick(interval) {
+			var m runtime.MemStats
+			runtime.ReadMemStats(&m)
+			w := surveys.WaiterStats()
+			log.Printf("stats: heap %d kB, sys %d kB, gc %d, goroutines %d, surveys %d, waiting %d (notified %d, timed out %d, canceled %d, rejected %d)",
+				m.HeapAlloc/1024, m.Sys/1024, m.NumGC, runtime.NumGoroutine(), surveys.Count(),
+				w.Waiting, w.Notified, w.TimedOut, w.Canceled, w.Rejected)
+		}
+	}()
+}
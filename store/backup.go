@@ -0,0 +1,82 @@
+package store
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// archiveVersion is incremented if the format of the archive changes
+const archiveVersion = 1
+
+// archive contains all documents of a store. Encrypted documents are
+// archived as they are, so the archive can only be restored together
+// with the store keys.
+type archive struct {
+	Version   int
+	Created   time.Time
+	Documents map[string]json.RawMessage
+}
+
+// Backup writes all documents of the store as a gzip compressed archive to w.
+// It returns the number of documents.
+func Backup(st Store, w io.Writer) (int, error) {
+	keys, err := st.Keys()
+	if err != nil {
+		return 0, err
+	}
+	a := archive{Version: archiveVersion, Created: time.Now(), Documents: map[string]json.RawMessage{}}
+	for _, k := range keys {
+		var raw json.RawMessage
+		found, err := st.Load(k, &raw)
+		if err != nil {
+			return 0, err
+		}
+		if found {
+			a.Documents[k] = raw
+		}
+	}
+
+	gz := gzip.NewWriter(w)
+	err = json.NewEncoder(gz).Encode(a)
+	if err != nil {
+		return 0, err
+	}
+	return len(a.Documents), gz.Close()
+}
+
+// Restore reads an archive created by Backup and stores all its documents.
+// Existing documents with the same key are replaced, all others are kept.
+// The archive is checked completely before the first document is written.
+func Restore(st Store, r io.Reader) (int, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return 0, fmt.Errorf("not a backup archive: %w", err)
+	}
+	var a archive
+	err = json.NewDecoder(gz).Decode(&a)
+	if err != nil {
+		return 0, fmt.Errorf("not a backup archive: %w", err)
+	}
+	if a.Version != archiveVersion {
+		return 0, fmt.Errorf("unsupported archive version %d", a.Version)
+	}
+	for k, doc := range a.Documents {
+		if err := checkKey(k); err != nil {
+			return 0, err
+		}
+		if !json.Valid(doc) {
+			return 0, fmt.Errorf("document %s is corrupted", k)
+		}
+	}
+
+	for k, doc := range a.Documents {
+		err = st.Save(k, doc)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(a.Documents), nil
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackup(t *testing.T) {
+	src, err := New(t.TempDir())
+	assert.NoError(t, err)
+	assert.NoError(t, src.Save("roles", doc{Title: "roles"}))
+	enc, err := NewEncrypted(src, newKey(t, "k"))
+	assert.NoError(t, err)
+	assert.NoError(t, enc.Save("feed-1", doc{Title: "secret"}))
+
+	var b bytes.Buffer
+	n, err := Backup(src, &b)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+	assert.False(t, bytes.Contains(b.Bytes(), []byte("secret")))
+
+	dst, err := New("")
+	assert.NoError(t, err)
+	assert.NoError(t, dst.Save("other", doc{Title: "kept"}))
+	n, err = Restore(dst, bytes.NewReader(b.Bytes()))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+
+	var d doc
+	_, err = dst.Load("roles", &d)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "roles", d.Title)
+	_, err = dst.Load("other", &d)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "kept", d.Title)
+
+	// the encrypted documents are restored as they are
+	enc.(*encryptedStore).parent = dst
+	_, err = enc.Load("feed-1", &d)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "secret", d.Title)
+}
+
+func TestRestoreInvalid(t *testing.T) {
+	st, err := New("")
+	assert.NoError(t, err)
+	_, err = Restore(st, bytes.NewReader([]byte("no archive")))
+	assert.Error(t, err)
+
+	for _, a := range []string{
+		`{"Version":2,"Documents":{}}`,
+		`{"Version":1,"Documents":{"../x":{}}}`,
+	} {
+		var b bytes.Buffer
+		gz := gzip.NewWriter(&b)
+		gz.Write([]byte(a))
+		gz.Close()
+		_, err = Restore(st, &b)
+		assert.Error(t, err)
+	}
+	keys, err := st.Keys()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, len(keys))
+}
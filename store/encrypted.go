@@ -0,0 +1,170 @@
+package store
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// KeysEnv is the environment variable containing the keys used to encrypt
+// the stored documents. It contains a comma separated list of id:key pairs,
+// the key being 32 base64 encoded random bytes. The first key is used to
+// encrypt, the others are only used to decrypt documents not yet re-encrypted
+// after a key rotation. The variable can be filled from a secret manager or
+// KMS by the service manager.
+const KeysEnv = "FLASHSURVEY_STORE_KEYS"
+
+// envelope is the stored form of an encrypted document
+type envelope struct {
+	EncryptedWith string
+	Data          []byte
+}
+
+type encryptedStore struct {
+	parent  Store
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// parseKeys parses the keys in the format described at KeysEnv
+func parseKeys(list string) ([]string, map[string]cipher.AEAD, error) {
+	var ids []string
+	keys := map[string]cipher.AEAD{}
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, k, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return nil, nil, errors.New("store keys must be given as id:key")
+		}
+		if _, exists := keys[id]; exists {
+			return nil, nil, fmt.Errorf("duplicate store key %q", id)
+		}
+		raw, err := base64.StdEncoding.DecodeString(k)
+		if err != nil || len(raw) != 32 {
+			return nil, nil, fmt.Errorf("store key %q must be 32 base64 encoded bytes", id)
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, nil, err
+		}
+		ids = append(ids, id)
+		keys[id] = aead
+	}
+	if len(ids) == 0 {
+		return nil, nil, errors.New("no store key given")
+	}
+	return ids, keys, nil
+}
+
+// NewEncrypted returns a store which encrypts the documents before they
+// are passed to the parent store. Documents stored before the encryption
+// was enabled, or encrypted with an old key, are re-encrypted with the
+// current key when they are loaded.
+func NewEncrypted(parent Store, keyList string) (Store, error) {
+	ids, keys, err := parseKeys(keyList)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedStore{parent: parent, current: ids[0], keys: keys}, nil
+}
+
+func (e *encryptedStore) Load(key string, v any) (bool, error) {
+	var raw json.RawMessage
+	found, err := e.parent.Load(key, &raw)
+	if err != nil || !found {
+		return found, err
+	}
+
+	var env envelope
+	err = json.Unmarshal(raw, &env)
+	if err != nil || env.EncryptedWith == "" {
+		// stored before the encryption was enabled
+		err = json.Unmarshal(raw, v)
+		if err != nil {
+			return true, err
+		}
+		return true, e.resave(key, raw)
+	}
+
+	aead, ok := e.keys[env.EncryptedWith]
+	if !ok {
+		return true, fmt.Errorf("%s is encrypted with the unknown key %q", key, env.EncryptedWith)
+	}
+	size := aead.NonceSize()
+	if len(env.Data) < size {
+		return true, fmt.Errorf("%s is corrupted", key)
+	}
+	data, err := aead.Open(nil, env.Data[:size], env.Data[size:], []byte(key))
+	if err != nil {
+		return true, fmt.Errorf("could not decrypt %s: %w", key, err)
+	}
+	err = json.Unmarshal(data, v)
+	if err != nil {
+		return true, err
+	}
+	if env.EncryptedWith != e.current {
+		return true, e.resave(key, data)
+	}
+	return true, nil
+}
+
+func (e *encryptedStore) resave(key string, data json.RawMessage) error {
+	log.Printf("re-encrypting %s with key %s", key, e.current)
+	return e.Save(key, data)
+}
+
+func (e *encryptedStore) Save(key string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	aead := e.keys[e.current]
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return err
+	}
+	// the key is used as additional data, so documents can not be swapped
+	return e.parent.Save(key, envelope{
+		EncryptedWith: e.current,
+		Data:          aead.Seal(nonce, nonce, data, []byte(key)),
+	})
+}
+
+func (e *encryptedStore) Keys() ([]string, error) {
+	return e.parent.Keys()
+}
+
+// Rotate re-encrypts all documents of the store with its current key.
+// It returns the number of documents.
+func Rotate(st Store) (int, error) {
+	keys, err := st.Keys()
+	if err != nil {
+		return 0, err
+	}
+	for _, k := range keys {
+		var raw json.RawMessage
+		_, err = st.Load(k, &raw)
+		if err != nil {
+			return 0, err
+		}
+		err = st.Save(k, raw)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(keys), nil
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type doc struct {
+	Title   string
+	Options []string
+}
+
+func newKey(t *testing.T, id string) string {
+	k := make([]byte, 32)
+	_, err := rand.Read(k)
+	assert.NoError(t, err)
+	return id + ":" + base64.StdEncoding.EncodeToString(k)
+}
+
+func TestEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	plain, err := New(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, plain.Save("old", doc{Title: "stored before"}))
+
+	k1 := newKey(t, "k1")
+	st, err := NewEncrypted(plain, k1)
+	assert.NoError(t, err)
+	assert.NoError(t, st.Save("survey", doc{Title: "secret question", Options: []string{"a", "b"}}))
+
+	data, err := os.ReadFile(filepath.Join(dir, "survey.json"))
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(data), "secret"))
+
+	var d doc
+	found, err := st.Load("survey", &d)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.EqualValues(t, "secret question", d.Title)
+
+	// plain documents are encrypted when loaded
+	found, err = st.Load("old", &d)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.EqualValues(t, "stored before", d.Title)
+	data, err = os.ReadFile(filepath.Join(dir, "old.json"))
+	assert.NoError(t, err)
+	assert.False(t, strings.Contains(string(data), "stored before"))
+
+	found, err = st.Load("missing", &d)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	// rotation to a new key
+	k2 := newKey(t, "k2")
+	rotated, err := NewEncrypted(plain, k2+","+k1)
+	assert.NoError(t, err)
+	n, err := Rotate(rotated)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+
+	onlyNew, err := NewEncrypted(plain, k2)
+	assert.NoError(t, err)
+	found, err = onlyNew.Load("survey", &d)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "secret question", d.Title)
+
+	// the old key can not read the rotated documents
+	_, err = st.Load("survey", &d)
+	assert.Error(t, err)
+}
+
+func TestEncryptedSwap(t *testing.T) {
+	plain, err := New("")
+	assert.NoError(t, err)
+	st, err := NewEncrypted(plain, newKey(t, "k"))
+	assert.NoError(t, err)
+	assert.NoError(t, st.Save("a", doc{Title: "a"}))
+
+	// a document copied to another key is rejected
+	var raw json.RawMessage
+	_, err = plain.Load("a", &raw)
+	assert.NoError(t, err)
+	assert.NoError(t, plain.Save("b", raw))
+	var d doc
+	_, err = st.Load("b", &d)
+	assert.Error(t, err)
+}
+
+func TestParseKeys(t *testing.T) {
+	_, err := NewEncrypted(nil, "")
+	assert.Error(t, err)
+	_, err = NewEncrypted(nil, "k:c2hvcnQ=")
+	assert.Error(t, err)
+	k := newKey(t, "k")
+	_, err = NewEncrypted(nil, k+","+k)
+	assert.Error(t, err)
+	ids, _, err := parseKeys(k + ", " + newKey(t, "j"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"k", "j"}, ids)
+}
@@ -0,0 +1,80 @@
+package store
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Migrate copies all documents from src to dst. Afterwards the digests of
+// the documents in both stores are compared. The returned digest can be
+// used to compare the stores later on. The documents are copied as they
+// are stored, so encrypted documents stay encrypted.
+func Migrate(src, dst Store) (int, string, error) {
+	keys, err := src.Keys()
+	if err != nil {
+		return 0, "", err
+	}
+
+	n := 0
+	for _, k := range keys {
+		var raw json.RawMessage
+		found, err := src.Load(k, &raw)
+		if err != nil {
+			return 0, "", fmt.Errorf("could not read %s: %w", k, err)
+		}
+		if !found {
+			continue
+		}
+		err = dst.Save(k, raw)
+		if err != nil {
+			return 0, "", fmt.Errorf("could not write %s: %w", k, err)
+		}
+		n++
+	}
+
+	srcDigest, err := Digest(src, keys)
+	if err != nil {
+		return 0, "", err
+	}
+	dstDigest, err := Digest(dst, keys)
+	if err != nil {
+		return 0, "", err
+	}
+	if srcDigest != dstDigest {
+		return 0, "", errors.New("verification failed, the copied documents differ")
+	}
+	return n, dstDigest, nil
+}
+
+// Digest returns a sha256 digest of the documents with the given keys.
+// The digest does not depend on the formatting of the documents.
+func Digest(st Store, keys []string) (string, error) {
+	h := sha256.New()
+	for _, k := range keys {
+		var raw json.RawMessage
+		found, err := st.Load(k, &raw)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			continue
+		}
+		c, err := compact(raw)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\n%d\n", k, len(c))
+		h.Write(c)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func compact(raw json.RawMessage) ([]byte, error) {
+	var b bytes.Buffer
+	err := json.Compact(&b, raw)
+	return b.Bytes(), err
+}
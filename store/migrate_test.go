@@ -0,0 +1,41 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type brokenStore struct {
+	Store
+}
+
+func (b brokenStore) Save(key string, v any) error {
+	return b.Store.Save(key, doc{Title: "changed"})
+}
+
+func TestMigrate(t *testing.T) {
+	src, err := New("")
+	assert.NoError(t, err)
+	assert.NoError(t, src.Save("roles", doc{Title: "roles"}))
+	assert.NoError(t, src.Save("banner", doc{Title: "banner", Options: []string{"a"}}))
+
+	dst, err := New(t.TempDir())
+	assert.NoError(t, err)
+	n, digest, err := Migrate(src, dst)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, n)
+
+	keys, err := dst.Keys()
+	assert.NoError(t, err)
+	assert.EqualValues(t, []string{"banner", "roles"}, keys)
+	// the file store indents the documents, which does not change the digest
+	d, err := Digest(src, keys)
+	assert.NoError(t, err)
+	assert.EqualValues(t, digest, d)
+
+	broken, err := New("")
+	assert.NoError(t, err)
+	_, _, err = Migrate(src, brokenStore{broken})
+	assert.Error(t, err)
+}
@@ -0,0 +1,154 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Store persists data as JSON documents identified by a key
+type Store interface {
+	// Load reads the document with the given key into v.
+	// If there is no such document, false is returned.
+	Load(key string, v any) (bool, error)
+	// Save stores v as the document with the given key
+	Save(key string, v any) error
+	// Keys returns the keys of all documents
+	Keys() ([]string, error)
+}
+
+var validKey = regexp.MustCompile("^[a-zA-Z0-9_-]+$")
+
+func checkKey(key string) error {
+	if !validKey.MatchString(key) {
+		return fmt.Errorf("invalid store key %q", key)
+	}
+	return nil
+}
+
+// New creates a store which keeps the documents in the given directory.
+// If dir is empty, the documents are kept in memory only.
+func New(dir string) (Store, error) {
+	if dir == "" {
+		return &memoryStore{docs: make(map[string][]byte)}, nil
+	}
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("could not create store directory: %w", err)
+	}
+	return &fileStore{dir: dir}, nil
+}
+
+type memoryStore struct {
+	mutex sync.Mutex
+	docs  map[string][]byte
+}
+
+func (m *memoryStore) Load(key string, v any) (bool, error) {
+	if err := checkKey(key); err != nil {
+		return false, err
+	}
+
+	m.mutex.Lock()
+	data, ok := m.docs[key]
+	m.mutex.Unlock()
+
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func (m *memoryStore) Save(key string, v any) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.docs[key] = data
+	return nil
+}
+
+func (m *memoryStore) Keys() ([]string, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	keys := make([]string, 0, len(m.docs))
+	for k := range m.docs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+type fileStore struct {
+	mutex sync.Mutex
+	dir   string
+}
+
+func (f *fileStore) Load(key string, v any) (bool, error) {
+	if err := checkKey(key); err != nil {
+		return false, err
+	}
+
+	f.mutex.Lock()
+	data, err := os.ReadFile(filepath.Join(f.dir, key+".json"))
+	f.mutex.Unlock()
+
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("could not read %s: %w", key, err)
+	}
+	return true, json.Unmarshal(data, v)
+}
+
+func (f *fileStore) Save(key string, v any) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	// write to a temporary file first to never leave a partially written document
+	name := filepath.Join(f.dir, key+".json")
+	err = os.WriteFile(name+".tmp", data, 0600)
+	if err != nil {
+		return fmt.Errorf("could not write %s: %w", key, err)
+	}
+	return os.Rename(name+".tmp", name)
+}
+
+func (f *fileStore) Keys() ([]string, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	entries, err := os.ReadDir(f.dir)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, e := range entries {
+		if key, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() && checkKey(key) == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,69 @@
+package survey
+
+import "time"
+
+// maxViewers limits the number of distinct viewers recorded per survey
+const maxViewers = 1000
+
+// AccessLog describes the accesses to the result of a survey. It allows
+// the creator to detect if a link to the result has leaked.
+type AccessLog struct {
+	// Count is the number of result requests
+	Count int
+	// Hidden is the number of result requests while the result was hidden
+	Hidden int
+	// Viewers is the number of distinct users who requested the result
+	Viewers int
+	First   time.Time
+	Last    time.Time
+}
+
+type accessLog struct {
+	AccessLog
+	viewers map[UserId]struct{}
+}
+
+func (a *accessLog) add(userId UserId, hidden bool, now time.Time) {
+	if a.Count == 0 {
+		a.First = now
+	}
+	a.Count++
+	if hidden {
+		a.Hidden++
+	}
+	a.Last = now
+	if a.viewers == nil {
+		a.viewers = make(map[UserId]struct{})
+	}
+	if _, known := a.viewers[userId]; !known && len(a.viewers) < maxViewers {
+		a.viewers[userId] = struct{}{}
+		a.Viewers = len(a.viewers)
+	}
+}
+
+// RecordResultAccess records that the given user has requested the
+// result of the survey.
+func (s *Surveys) RecordResultAccess(userId UserId, surveyId SurveyId) {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	survey.access.add(userId, survey.resultHidden, time.Now())
+}
+
+// ResultAccess returns the access log of the result of the survey
+func (s *Surveys) ResultAccess(userId UserId, surveyId SurveyId) (AccessLog, bool) {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return AccessLog{}, false
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	return survey.access.AccessLog, true
+}
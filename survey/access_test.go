@@ -0,0 +1,34 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResultAccess(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	s.RecordResultAccess("creator", sid)
+	s.RecordResultAccess("creator", sid)
+	// not the creator
+	s.RecordResultAccess("other", sid)
+
+	a, ok := s.ResultAccess("creator", sid)
+	assert.True(t, ok)
+	assert.Equal(t, 2, a.Count)
+	assert.Equal(t, 2, a.Hidden)
+	assert.Equal(t, 1, a.Viewers)
+	assert.False(t, a.First.After(a.Last))
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	s.RecordResultAccess("creator", sid)
+	a, _ = s.ResultAccess("creator", sid)
+	assert.Equal(t, 3, a.Count)
+	assert.Equal(t, 2, a.Hidden)
+
+	_, ok = s.ResultAccess("other", sid)
+	assert.False(t, ok)
+}
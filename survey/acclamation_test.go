@@ -0,0 +1,36 @@
+package survey
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAcclamation(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Attendance", Options: []string{"I'm here"}}
+	_, err := s.New("creator", "", q)
+	assert.Error(t, err)
+
+	q.Acclamation = true
+	q.Multiple = true
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	assert.False(t, s.GetQuestion(sid).Question.Multiple)
+
+	for _, v := range []UserId{"a", "b", "c"} {
+		assert.NoError(t, s.Vote(sid, v, []int{0}, 1))
+	}
+	assert.NoError(t, s.Uncover("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.Equal(t, 3, r.Votes)
+	assert.Equal(t, "3", r.Result[0].Votes())
+	assert.True(t, r.Display.HidePercent)
+
+	q.Options = []string{"Yes", "No"}
+	_, err = s.New("creator", "", q)
+	var v ValidationError
+	assert.True(t, errors.As(err, &v))
+	assert.NotEmpty(t, v.Field("option1"))
+}
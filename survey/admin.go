@@ -0,0 +1,102 @@
+package survey
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"sort"
+	"time"
+)
+
+// AdminInfo describes a survey on the admin page. The owner is given by a
+// hash, so the operators can see which surveys belong together without
+// learning the user ids, which are the credentials of the creators.
+type AdminInfo struct {
+	Id      SurveyId
+	Owner   string
+	Number  int
+	Votes   int
+	Created time.Time
+	// Mirror is set if the survey is a copy of a survey of the primary instance
+	Mirror bool
+}
+
+// Age returns the time since the running question was started
+func (a AdminInfo) Age() time.Duration {
+	return time.Since(a.Created).Truncate(time.Second)
+}
+
+// ownerHash returns the pseudonym of the user shown to the operators
+func (s *Surveys) ownerHash(userId UserId) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(userId))
+	return hex.EncodeToString(mac.Sum(nil))[:12]
+}
+
+// AdminList returns all surveys, the oldest first
+func (s *Surveys) AdminList() []AdminInfo {
+	s.mutex.RLock()
+	all := make([]*Survey, 0, len(s.surveys))
+	for _, survey := range s.surveys {
+		all = append(all, survey)
+	}
+	s.mutex.RUnlock()
+
+	list := make([]AdminInfo, 0, len(all))
+	for _, survey := range all {
+		survey.Lock()
+		survey.applyPending()
+		list = append(list, AdminInfo{
+			Id:      survey.surveyId,
+			Owner:   s.ownerHash(survey.userId),
+			Number:  survey.number,
+			Votes:   survey.voteCount(),
+			Created: survey.creationTime,
+			Mirror:  survey.mirror,
+		})
+		survey.Unlock()
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Created.Before(list[j].Created)
+	})
+	return list
+}
+
+// AdminDelete deletes the survey regardless of its creator, e.g. if it is
+// stuck. The hooks are called as if the creator had closed it.
+func (s *Surveys) AdminDelete(surveyId SurveyId) error {
+	s.mutex.Lock()
+	survey, exists := s.surveys[surveyId]
+	if exists {
+		delete(s.surveys, surveyId)
+	}
+	s.mutex.Unlock()
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	e := survey.finalEvent()
+	survey.waiters.close()
+	survey.subscribers.close()
+	mirror := survey.mirror
+	survey.Unlock()
+
+	log.Printf("survey deleted by the operator, %d surveys remaining\n", s.getSurveyCount())
+	if !mirror {
+		onClose(e)
+	}
+	return nil
+}
+
+// SetVoteIfResultVisible allows or forbids to vote while the result is
+// visible. It can be called while the surveys are used.
+func (s *Surveys) SetVoteIfResultVisible(allowed bool) {
+	s.voteIfResultVisible.Store(allowed)
+}
+
+// VoteIfResultVisible returns true if voting is possible while the result is visible
+func (s *Surveys) VoteIfResultVisible() bool {
+	return s.voteIfResultVisible.Load()
+}
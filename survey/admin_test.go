@@ -0,0 +1,57 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdminList(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B"}}
+	sid1, err := s.New("creator1", "", q)
+	require.NoError(t, err)
+	sid2, err := s.New("creator2", "", q)
+	require.NoError(t, err)
+	require.NoError(t, s.Vote(sid2, "voter", []int{0}, 1))
+
+	list := s.AdminList()
+	require.Len(t, list, 2)
+	assert.Equal(t, sid1, list[0].Id)
+	assert.Equal(t, sid2, list[1].Id)
+	assert.Equal(t, 0, list[0].Votes)
+	assert.Equal(t, 1, list[1].Votes)
+	// the user ids are not shown, but the surveys of a creator can be told apart
+	assert.NotEqual(t, list[0].Owner, list[1].Owner)
+	assert.NotContains(t, list[0].Owner, "creator")
+	assert.Equal(t, s.ownerHash("creator1"), list[0].Owner)
+}
+
+func TestAdminDelete(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, s.AdminDelete("unknown"), ErrSurveyNotFound)
+	require.NoError(t, s.AdminDelete(sid))
+	assert.Empty(t, s.AdminList())
+	assert.ErrorIs(t, s.Vote(sid, "voter", []int{0}, 1), ErrSurveyNotFound)
+}
+
+func TestRuntimeSettings(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+	require.NoError(t, s.Uncover("creator", sid))
+	assert.ErrorIs(t, s.Vote(sid, "voter", []int{0}, 1), ErrResultVisible)
+
+	s.SetVoteIfResultVisible(true)
+	assert.True(t, s.VoteIfResultVisible())
+	assert.NoError(t, s.Vote(sid, "voter", []int{0}, 1))
+
+	assert.Equal(t, 30, s.DefaultTimeout())
+	assert.Error(t, s.SetTimeout(0))
+	require.NoError(t, s.SetTimeout(90))
+	assert.Equal(t, 90, s.DefaultTimeout())
+}
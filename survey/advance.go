@@ -0,0 +1,160 @@
+package survey
+
+import (
+	"log"
+	"time"
+)
+
+// maxAdvance is the maximum time in seconds after uncovering a result until
+// the next question of a series is started automatically
+const maxAdvance = 60 * 60
+
+// Advance is the automatic advance of a series of questions, e.g. a fully
+// timed quiz. The prepared questions are started one after the other, each
+// the given time after the result of the running question was uncovered.
+type Advance struct {
+	// Seconds is the time after uncovering the result until the next
+	// question is started, zero disables the automatic advance
+	Seconds int
+	// Queued is the number of prepared questions
+	Queued int
+}
+
+// SetAdvance sets the time in seconds after uncovering the result until the
+// next prepared question is started, zero disables the automatic advance
+func (s *Surveys) SetAdvance(userId UserId, surveyId SurveyId, seconds int) error {
+	if seconds < 0 || seconds > maxAdvance {
+		return newError("advanceRange", maxAdvance)
+	}
+
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if !s.mayControl(survey, userId) {
+		return ErrNotOwner
+	}
+
+	survey.advance = time.Duration(seconds) * time.Second
+	return nil
+}
+
+// QueueQuestion prepares a question which is started automatically after
+// the running one, see SetAdvance
+func (s *Surveys) QueueQuestion(userId UserId, surveyId SurveyId, def SurveyQuestion) error {
+	if !def.Valid() {
+		return newError("invalidDefinition")
+	}
+	// the question is checked completely, so it can be started later on
+	// without any further checks
+	p, err := s.prepare(userId, def)
+	if err != nil {
+		return err
+	}
+
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if !s.mayControl(survey, userId) {
+		return ErrNotOwner
+	}
+	if len(survey.queue) >= maxSeries {
+		return newError("seriesRange", maxSeries)
+	}
+
+	survey.queue = append(survey.queue, p)
+	return nil
+}
+
+// ClearQueue deletes the prepared questions
+func (s *Surveys) ClearQueue(userId UserId, surveyId SurveyId) error {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if !s.mayControl(survey, userId) {
+		return ErrNotOwner
+	}
+
+	survey.queue = nil
+	return nil
+}
+
+// GetAdvance returns the automatic advance of the survey
+func (s *Surveys) GetAdvance(userId UserId, surveyId SurveyId) Advance {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return Advance{}
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	return Advance{Seconds: int(survey.advance / time.Second), Queued: len(survey.queue)}
+}
+
+// scheduleAdvance starts the next prepared question after the result of
+// the given question was uncovered
+func (s *Surveys) scheduleAdvance(e Event) {
+	survey, exists := s.getSurveyToVote(e.SurveyId)
+	if !exists {
+		return
+	}
+	survey.Lock()
+	advance := survey.advance
+	queued := len(survey.queue)
+	survey.Unlock()
+
+	if advance <= 0 || queued == 0 {
+		return
+	}
+	time.AfterFunc(advance, func() {
+		err := s.advanceQuestion(e.SurveyId, e.Number)
+		if err != nil {
+			log.Println("advance:", err)
+		}
+	})
+}
+
+// advanceQuestion starts the next prepared question if the question with
+// the given number is still running and its result is uncovered. The check
+// and the start happen under the same lock, so a question started by the
+// creator in the meantime is never replaced.
+func (s *Surveys) advanceQuestion(surveyId SurveyId, number int) error {
+	survey, exists := s.getSurveyToVote(surveyId)
+	if !exists {
+		return nil
+	}
+
+	survey.Lock()
+	if survey.number != number || survey.resultHidden || survey.locked.Load() || len(survey.queue) == 0 {
+		survey.Unlock()
+		return nil
+	}
+	next := survey.queue[0]
+	if err := survey.update(next.def, next.opt, next.host); err != nil {
+		// the question is kept, so the creator can see that it is still
+		// waiting and clear the queue
+		survey.Unlock()
+		return err
+	}
+	survey.queue = survey.queue[1:]
+	survey.Unlock()
+
+	log.Printf("advanced survey to the next prepared question with %d options", len(next.opt))
+	s.startEvent(surveyId)
+	return nil
+}
@@ -0,0 +1,80 @@
+package survey
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvance(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "First", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+
+	assert.Error(t, s.SetAdvance("creator", sid, -1))
+	assert.Error(t, s.SetAdvance("creator", sid, maxAdvance+1))
+	assert.ErrorIs(t, s.SetAdvance("other", sid, 5), ErrSurveyNotFound)
+	assert.Error(t, s.QueueQuestion("creator", sid, SurveyQuestion{Title: "Invalid"}))
+
+	require.NoError(t, s.SetAdvance("creator", sid, 1))
+	require.NoError(t, s.QueueQuestion("creator", sid, SurveyQuestion{Title: "Second", Options: []string{"C", "D"}}))
+	require.NoError(t, s.QueueQuestion("creator", sid, SurveyQuestion{Title: "Third", Options: []string{"E", "F"}}))
+	assert.Equal(t, Advance{Seconds: 1, Queued: 2}, s.GetAdvance("creator", sid))
+
+	// the next question is not started while the result is hidden
+	require.NoError(t, s.advanceQuestion(sid, 1))
+	assert.Equal(t, "First", s.GetQuestion(sid).Question.Title)
+
+	require.NoError(t, s.Uncover("creator", sid))
+	assert.Eventually(t, func() bool {
+		return s.GetQuestion(sid).Question.Title == "Second"
+	}, 3*time.Second, 50*time.Millisecond)
+	assert.Equal(t, Advance{Seconds: 1, Queued: 1}, s.GetAdvance("creator", sid))
+
+	// an outdated timer does not skip a question
+	require.NoError(t, s.Uncover("creator", sid))
+	require.NoError(t, s.advanceQuestion(sid, 1))
+	assert.Equal(t, "Second", s.GetQuestion(sid).Question.Title)
+
+	require.NoError(t, s.ClearQueue("creator", sid))
+	assert.Equal(t, 0, s.GetAdvance("creator", sid).Queued)
+}
+
+func TestAdvanceLocked(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "First", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+	require.NoError(t, s.QueueQuestion("creator", sid, SurveyQuestion{Title: "Second", Options: []string{"C", "D"}}))
+	require.NoError(t, s.Uncover("creator", sid))
+	require.NoError(t, s.SetLocked("creator", sid, true))
+
+	require.NoError(t, s.advanceQuestion(sid, 1))
+	assert.Equal(t, "First", s.GetQuestion(sid).Question.Title)
+	assert.Equal(t, 1, s.GetAdvance("creator", sid).Queued)
+}
+
+func TestAdvanceInvalid(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "First", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+	// an acclamation has a single option, this is detected before it is queued
+	invalid := SurveyQuestion{Title: "Second", Options: []string{"C", "D"}, Acclamation: true}
+	assert.Error(t, s.QueueQuestion("creator", sid, invalid))
+	assert.Equal(t, 0, s.GetAdvance("creator", sid).Queued)
+}
+
+func TestAdvanceTransferred(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "First", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+	require.NoError(t, s.QueueQuestion("creator", sid, SurveyQuestion{Title: "Second", Options: []string{"C", "D"}}))
+	require.NoError(t, s.Uncover("creator", sid))
+
+	// the question is started although the creator has handed over the control
+	assert.Equal(t, 1, s.TransferCreator("creator", "next"))
+	require.NoError(t, s.advanceQuestion(sid, 1))
+	assert.Equal(t, "Second", s.GetQuestion(sid).Question.Title)
+	assert.Equal(t, 0, s.GetAdvance("next", sid).Queued)
+}
@@ -0,0 +1,67 @@
+package survey
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxAnnotationLen is the maximum number of characters of an annotation
+const maxAnnotationLen = 500
+
+// Uncovered returns true if the result of a survey which is not encrypted
+// is visible, only then it can be annotated
+func (r Result) Uncovered() bool {
+	return !r.Encrypted && len(r.Result) > 0 && !r.Covered()
+}
+
+// Hidden returns true if the result of a survey which is not encrypted is
+// still covered, then only the number of votes and the options uncovered
+// one at a time are known
+func (r Result) Hidden() bool {
+	return !r.Encrypted && r.Covered()
+}
+
+// Covered returns true if the votes of at least one option are not visible
+func (r Result) Covered() bool {
+	return slices.ContainsFunc(r.Result, func(o OptionResult) bool {
+		return o.votes < 0
+	})
+}
+
+// Annotate attaches a note of the creator, e.g. the decision taken or a
+// follow-up, to the uncovered result of the running question. The note is
+// kept in the history of the survey. An empty text removes the note.
+func (s *Surveys) Annotate(userId UserId, surveyId SurveyId, text string) error {
+	e, err := s.annotate(userId, surveyId, text)
+	if err != nil {
+		return err
+	}
+	onAnnotate(e)
+	return nil
+}
+
+func (s *Surveys) annotate(userId UserId, surveyId SurveyId, text string) (ResultEvent, error) {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ResultEvent{}, ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if !s.mayControl(survey, userId) {
+		return ResultEvent{}, ErrNotOwner
+	}
+	if survey.resultHidden || survey.question.Encrypted() {
+		return ResultEvent{}, newError("annotationHidden")
+	}
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) > maxAnnotationLen {
+		return ResultEvent{}, newError("annotationTooLong")
+	}
+
+	survey.annotation = text
+	survey.changed()
+	return survey.resultEvent(), nil
+}
@@ -0,0 +1,46 @@
+package survey
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotate(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B"}}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+
+	t.Cleanup(func() { registered.hooks = nil })
+	var annotated []ResultEvent
+	RegisterHooks(Hooks{OnAnnotate: func(e ResultEvent) {
+		if e.SurveyId == sid {
+			annotated = append(annotated, e)
+		}
+	}})
+
+	// a hidden result can not be annotated
+	assert.False(t, s.GetResult("creator", sid).Uncovered())
+	assert.Error(t, s.Annotate("creator", sid, "Decision"))
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	assert.True(t, s.GetResult("creator", sid).Uncovered())
+	assert.Error(t, s.Annotate("other", sid, "Decision"))
+	assert.Error(t, s.Annotate("creator", sid, strings.Repeat("x", maxAnnotationLen+1)))
+	assert.NoError(t, s.Annotate("creator", sid, " Decision "))
+	assert.Equal(t, "Decision", s.GetResult("creator", sid).Annotation)
+	assert.Len(t, annotated, 1)
+	assert.Equal(t, "Decision", annotated[0].Result.Annotation)
+
+	// the annotation is kept in the history, the next question starts without
+	_, err = s.New("creator", sid, q)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Uncover("creator", sid))
+	assert.Equal(t, "", s.GetResult("creator", sid).Annotation)
+	rounds, err := s.History("creator", sid)
+	assert.NoError(t, err)
+	assert.Equal(t, "Decision", rounds[0].Result.Annotation)
+}
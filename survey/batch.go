@@ -0,0 +1,112 @@
+package survey
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// pendingVote is a vote which is accepted but not yet added to the tally
+type pendingVote struct {
+	number  int
+	options []int
+	ballot  string
+	writeIn string
+	next    *pendingVote
+}
+
+// voteQueue is a lock-free stack the accepted votes are pushed to. The
+// order of the votes is not preserved, which does not matter for counting.
+type voteQueue struct {
+	head atomic.Pointer[pendingVote]
+}
+
+func (q *voteQueue) push(v *pendingVote) {
+	for {
+		old := q.head.Load()
+		v.next = old
+		if q.head.CompareAndSwap(old, v) {
+			return
+		}
+	}
+}
+
+// take removes all votes from the queue
+func (q *voteQueue) take() *pendingVote {
+	return q.head.Swap(nil)
+}
+
+// applyPending adds the queued votes to the tally and notifies the
+// waiting clients once for all of them. The survey must be locked.
+func (s *Survey) applyPending() {
+	v := s.pending.take()
+	if v == nil {
+		return
+	}
+	for ; v != nil; v = v.next {
+		if v.number != s.number {
+			// the question has changed in the meantime
+			continue
+		}
+		if s.question.Ranked {
+			s.options.addRanking(v.options)
+		} else {
+			for _, o := range v.options {
+				s.options[o].Votes++
+			}
+		}
+		if v.ballot != "" {
+			s.ballots = append(s.ballots, v.ballot)
+		}
+		if v.writeIn != "" {
+			s.addWriteIn(v.writeIn)
+		}
+		s.counted++
+	}
+	s.changed()
+}
+
+// EnableVoteBatching makes the votes to be aggregated by a single
+// goroutine every interval instead of by the voters themselves. This
+// reduces the contention on the survey lock and avoids waking up all
+// waiting result pages on every single vote, which makes very large
+// audiences feasible. Reading the result always includes all accepted
+// votes. If AfterVote hooks are registered, the votes are not batched
+// because the hooks need the result including the vote.
+// Must be called before the surveys are used.
+func (s *Surveys) EnableVoteBatching(interval time.Duration) {
+	if interval <= 0 || s.dirty != nil {
+		return
+	}
+	log.Println("vote batching enabled, interval", interval)
+	s.dirty = make(chan *Survey, 1024)
+	go func() {
+		ticker := time.NewTicker(interval)
+		for range ticker.C {
+			s.aggregate()
+		}
+	}()
+}
+
+// schedule marks the survey as having pending votes
+func (s *Surveys) schedule(survey *Survey) {
+	if survey.scheduled.CompareAndSwap(false, true) {
+		s.dirty <- survey
+	}
+}
+
+// aggregate applies the pending votes of all scheduled surveys
+func (s *Surveys) aggregate() {
+	for {
+		select {
+		case survey := <-s.dirty:
+			// reset the flag before taking the votes, so no vote is missed
+			survey.scheduled.Store(false)
+			survey.Lock()
+			survey.applyPending()
+			survey.Unlock()
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package survey
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoteBatching(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	s.EnableVoteBatching(time.Hour)
+	sid, err := s.New("creator", "", description)
+	assert.NoError(t, err)
+	version := s.GetResult("creator", sid).Version
+
+	wg := sync.WaitGroup{}
+	for i := range voters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, s.Vote(sid, UserId(fmt.Sprint("v", i)), []int{i % 2}, 1))
+		}()
+	}
+	wg.Wait()
+
+	// the votes are not yet aggregated, so the waiting clients are not notified
+	survey, _ := s.getSurveyToVote(sid)
+	currentVersion := func() int {
+		survey.Lock()
+		defer survey.Unlock()
+		return survey.version
+	}
+	assert.Equal(t, version, currentVersion())
+	assert.Error(t, s.Vote(sid, "v0", []int{0}, 1))
+
+	s.aggregate()
+	assert.Greater(t, currentVersion(), version)
+
+	// the result includes all accepted votes even if they are still pending
+	assert.NoError(t, s.Vote(sid, "late", []int{0}, 1))
+	assert.NoError(t, s.Uncover("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.EqualValues(t, voters+1, r.Votes)
+	assert.EqualValues(t, voters/2+1, r.Result[0].votes)
+	assert.EqualValues(t, voters/2, r.Result[1].votes)
+}
+
+func TestVoteBatchingNewQuestion(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	s.EnableVoteBatching(time.Hour)
+	sid, err := s.New("creator", "", description)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+
+	// pending votes of the former question are discarded
+	_, err = s.New("creator", sid, SurveyQuestion{Title: "Other", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	s.aggregate()
+	r := s.GetResult("creator", sid)
+	assert.EqualValues(t, 0, r.Votes)
+}
+
+// benchmarkVote lets many voters vote in parallel while a number of result
+// pages are waiting for modifications.
+func benchmarkVote(b *testing.B, batching bool) {
+	s := New("localhost", 30, false, true)
+	if batching {
+		s.EnableVoteBatching(50 * time.Millisecond)
+	}
+	sid, err := s.New("creator", "", description)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for range 100 {
+		go func() {
+			v := 0
+			for ctx.Err() == nil {
+				_ = s.Wait(ctx, "creator", sid, v, time.Minute)
+				v = s.GetResult("creator", sid).Version
+			}
+		}()
+	}
+
+	var n sync.Mutex
+	i := 0
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			n.Lock()
+			i++
+			id := UserId(fmt.Sprint("v", i))
+			n.Unlock()
+			if err := s.Vote(sid, id, []int{i % 2}, 1); err != nil {
+				b.Error(err)
+			}
+		}
+	})
+}
+
+func BenchmarkVoteDirect(b *testing.B) {
+	benchmarkVote(b, false)
+}
+
+func BenchmarkVoteBatched(b *testing.B) {
+	benchmarkVote(b, true)
+}
@@ -0,0 +1,177 @@
+package survey
+
+import (
+	crand "crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	// maxCodes is the maximum number of access codes of a survey
+	maxCodes = 500
+	// codeAlphabet contains the characters of the access codes, the
+	// characters which are easily confused are left out
+	codeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	codeLength   = 8
+	// codeVoterPrefix is the prefix of the user ids of the voters using
+	// an access code
+	codeVoterPrefix = "code:"
+)
+
+// AccessCode is a single-use code which allows to vote once
+type AccessCode struct {
+	Code string
+	// URL is the vote link containing the code
+	URL string
+	// QRCode is the base64 encoded QR code of the URL
+	QRCode string
+}
+
+// GenerateCodes switches the survey to the access code mode and creates the
+// given number of codes. In this mode only voters holding an unused code are
+// allowed to vote, and each code is used up by the first vote. The unused
+// codes remain valid if the question is changed. A second call replaces the
+// previously created codes.
+func (s *Surveys) GenerateCodes(userId UserId, surveyId SurveyId, n int) ([]AccessCode, error) {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return nil, ErrSurveyNotFound
+	}
+
+	if n < 1 || n > maxCodes {
+		return nil, newError("codeCount", maxCodes)
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	link := voteLink(survey.host, surveyId, survey.voteKey)
+	codes := make(map[string]bool, n)
+	list := make([]AccessCode, 0, n)
+	for len(list) < n {
+		code, err := randomCode()
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := codes[code]; exists {
+			continue
+		}
+		url := link + "&a=" + code
+		qr, err := qrcode.Encode(url, qrcode.Medium, 256)
+		if err != nil {
+			return nil, fmt.Errorf("could not create qr code: %w", err)
+		}
+		codes[code] = false
+		list = append(list, AccessCode{Code: FormatCode(code), URL: url, QRCode: base64.StdEncoding.EncodeToString(qr)})
+	}
+
+	survey.accessCodes = codes
+	survey.changed()
+	return list, nil
+}
+
+// RemoveCodes ends the access code mode, afterwards everybody can vote again
+func (s *Surveys) RemoveCodes(userId UserId, surveyId SurveyId) {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if survey.accessCodes != nil {
+		survey.accessCodes = nil
+		survey.changed()
+	}
+}
+
+// CodeStats returns the number of created and of used access codes. If the
+// survey is not in the access code mode, both are zero.
+func (s *Surveys) CodeStats(userId UserId, surveyId SurveyId) (int, int) {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return 0, 0
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	used := 0
+	for _, u := range survey.accessCodes {
+		if u {
+			used++
+		}
+	}
+	return len(survey.accessCodes), used
+}
+
+// CodeVoter returns the user id of the voter using the given access code.
+// The code is normalized, so it can be typed in lower case and without the
+// hyphen.
+func CodeVoter(code string) UserId {
+	return UserId(codeVoterPrefix + normalizeCode(code))
+}
+
+// useCode checks if the voter holds an unused access code, it is called
+// before the vote is accepted. The survey must be locked.
+func (s *Survey) useCode(voterId UserId) (string, error) {
+	if s.accessCodes == nil {
+		return "", nil
+	}
+	code, ok := strings.CutPrefix(string(voterId), codeVoterPrefix)
+	if !ok {
+		return "", ErrCodeRequired
+	}
+	if used, exists := s.accessCodes[code]; !exists || used {
+		return "", ErrCodeInvalid
+	}
+	return code, nil
+}
+
+// releaseCodes allows to use the codes again which were used to vote on
+// the running question, it is called if the votes are discarded. The
+// survey must be locked.
+func (s *Survey) releaseCodes() {
+	for voterId := range s.votesCounted {
+		code, ok := strings.CutPrefix(string(voterId), codeVoterPrefix)
+		if _, exists := s.accessCodes[code]; ok && exists {
+			s.accessCodes[code] = false
+		}
+	}
+}
+
+// FormatCode inserts a hyphen in the middle of the code, which makes it
+// easier to type
+func FormatCode(code string) string {
+	if len(code) != codeLength {
+		return code
+	}
+	return code[:codeLength/2] + "-" + code[codeLength/2:]
+}
+
+func normalizeCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+func randomCode() (string, error) {
+	b := make([]byte, codeLength)
+	max := big.NewInt(int64(len(codeAlphabet)))
+	for i := range b {
+		n, err := crand.Int(crand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		b[i] = codeAlphabet[n.Int64()]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,56 @@
+package survey
+
+import (
+	"flashSurvey/randid"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessCodes(t *testing.T) {
+	s := New("https://example.com", 30, false, false)
+	userId := UserId(randid.String())
+	sid, err := s.New(userId, "", description)
+	assert.NoError(t, err)
+
+	_, err = s.GenerateCodes(userId, sid, 0)
+	assert.Error(t, err)
+	codes, err := s.GenerateCodes(userId, sid, 3)
+	assert.NoError(t, err)
+	assert.Len(t, codes, 3)
+	assert.True(t, s.GetQuestion(sid).CodeRequired)
+
+	u, err := url.Parse(codes[0].URL)
+	assert.NoError(t, err)
+	assert.Equal(t, normalizeCode(codes[0].Code), u.Query().Get("a"))
+
+	// voters without a code are rejected
+	assert.Error(t, s.Vote(sid, UserId(randid.String()), []int{0}, 1))
+	assert.Error(t, s.Vote(sid, CodeVoter("ABCD-EFGH"), []int{0}, 1))
+
+	// a rejected vote does not use up the code
+	assert.Error(t, s.Vote(sid, CodeVoter(codes[0].Code), []int{7}, 1))
+	assert.NoError(t, s.Vote(sid, CodeVoter(codes[0].Code), []int{0}, 1))
+	assert.NoError(t, s.Vote(sid, CodeVoter(codes[1].Code), []int{1}, 1))
+	issued, used := s.CodeStats(userId, sid)
+	assert.Equal(t, 3, issued)
+	assert.Equal(t, 2, used)
+
+	// the used codes are not valid for the next question
+	_, err = s.New(userId, sid, description)
+	assert.NoError(t, err)
+	assert.EqualError(t, s.Vote(sid, CodeVoter(codes[0].Code), []int{0}, 2), "Der Zugangscode ist ungültig oder wurde bereits verwendet!")
+	// the code is not case-sensitive
+	lower := []byte(codes[2].Code)
+	for i, c := range lower {
+		if c >= 'A' && c <= 'Z' {
+			lower[i] = c + 'a' - 'A'
+		}
+	}
+	assert.NoError(t, s.Vote(sid, CodeVoter(string(lower)), []int{0}, 2))
+
+	s.RemoveCodes(userId, sid)
+	assert.False(t, s.GetQuestion(sid).CodeRequired)
+	assert.NoError(t, s.Vote(sid, UserId(randid.String()), []int{0}, 2))
+}
@@ -0,0 +1,48 @@
+package survey
+
+import "slices"
+
+// Correct replaces the title and the texts of the options of the running
+// question, e.g. to fix a typo. Unlike a new question, the votes are kept.
+// Therefore, the number of options can not be changed, and the texts should
+// not change the meaning of the options.
+func (s *Surveys) Correct(userId UserId, surveyId SurveyId, title string, options []string) error {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if !s.mayControl(survey, userId) {
+		return ErrNotOwner
+	}
+
+	if len(options) != len(survey.options) {
+		return newError("correctOptionCount")
+	}
+	maxLen := maxStringLen
+	if survey.question.Encrypted() {
+		maxLen = maxCipherLen
+	}
+	title, corrected, verr := validateTexts(title, options, maxLen)
+	if len(verr) > 0 {
+		return verr
+	}
+
+	if title == survey.question.Title && slices.Equal(corrected, survey.question.Options) {
+		return nil
+	}
+
+	survey.applyPending()
+	survey.question.Title = title
+	survey.question.Options = corrected
+	survey.options = slices.Clone(survey.options)
+	for i := range survey.options {
+		survey.options[i].Title = corrected[i]
+	}
+	survey.revision++
+	survey.changed()
+	return nil
+}
@@ -0,0 +1,40 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrect(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Tets", Options: []string{"A", "Bb"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{1}, 1))
+
+	assert.Error(t, s.Correct("other", sid, "Test", []string{"A", "B"}))
+	assert.Error(t, s.Correct("creator", sid, "Test", []string{"A", "B", "C"}))
+	assert.Error(t, s.Correct("creator", sid, " ", []string{"A", "B"}))
+	assert.Error(t, s.Correct("creator", sid, "Test", []string{"A", ""}))
+
+	assert.NoError(t, s.Correct("creator", sid, "Test", []string{"A", "B"}))
+	q := s.GetQuestion(sid)
+	assert.Equal(t, 1, q.Number)
+	assert.Equal(t, 1, q.Revision)
+	assert.Equal(t, "Test", q.Question.Title)
+	assert.Equal(t, []string{"A", "B"}, q.Question.Options)
+
+	// the votes are kept
+	assert.True(t, s.HasVoted(sid, "a"))
+	assert.NoError(t, s.Vote(sid, "b", []int{1}, 1))
+	assert.NoError(t, s.Vote(sid, "c", []int{0}, 1))
+	assert.NoError(t, s.Uncover("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.Equal(t, "Test", r.Title)
+	assert.Equal(t, "B", r.Result[1].Title)
+	assert.Equal(t, "2", r.Result[1].Votes())
+
+	// nothing has changed
+	assert.NoError(t, s.Correct("creator", sid, "Test", []string{"A", "B"}))
+	assert.Equal(t, 1, s.GetQuestion(sid).Revision)
+}
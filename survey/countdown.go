@@ -0,0 +1,98 @@
+package survey
+
+import (
+	"log"
+	"math"
+	"time"
+)
+
+// maxCountdown is the maximum voting time of a question in seconds
+const maxCountdown = 60 * 60
+
+// validateCountdown checks the voting time chosen by the creator
+func validateCountdown(def SurveyQuestion) error {
+	if def.Countdown < 0 || def.Countdown > maxCountdown {
+		return newError("countdownRange", maxCountdown)
+	}
+	return nil
+}
+
+// deadline returns the time after which no more votes are accepted, it
+// is zero if the voting time is not limited. The survey must be locked.
+func (s *Survey) deadline() time.Time {
+	if s.question.Countdown <= 0 {
+		return time.Time{}
+	}
+	return s.creationTime.Add(time.Duration(s.question.Countdown) * time.Second)
+}
+
+// elapsed returns true if the voting time of the running question is over.
+// The survey must be locked.
+func (s *Survey) elapsed(now time.Time) bool {
+	d := s.deadline()
+	return !d.IsZero() && !now.Before(d)
+}
+
+// Remaining returns the remaining voting time in seconds, it is zero if the
+// time has elapsed or is not limited
+func (r Result) Remaining() int {
+	return remaining(r.Deadline, time.Now())
+}
+
+// Remaining returns the remaining voting time in seconds, it is zero if the
+// time has elapsed or is not limited
+func (q Question) Remaining() int {
+	return remaining(q.Deadline, time.Now())
+}
+
+func remaining(deadline, now time.Time) int {
+	if deadline.IsZero() || !now.Before(deadline) {
+		return 0
+	}
+	return int(math.Ceil(deadline.Sub(now).Seconds()))
+}
+
+// startCountdown uncovers the result of the given question when its voting
+// time has elapsed
+func (s *Surveys) startCountdown(surveyId SurveyId) {
+	survey, exists := s.getSurveyToVote(surveyId)
+	if !exists {
+		return
+	}
+	survey.Lock()
+	number := survey.number
+	deadline := survey.deadline()
+	survey.Unlock()
+
+	if deadline.IsZero() {
+		return
+	}
+	time.AfterFunc(time.Until(deadline), func() {
+		err := s.endCountdown(surveyId, number)
+		if err != nil {
+			log.Println("countdown:", err)
+		}
+	})
+}
+
+// endCountdown uncovers the result of the question with the given number
+// if it is still running and hidden
+func (s *Surveys) endCountdown(surveyId SurveyId, number int) error {
+	survey, exists := s.getSurveyToVote(surveyId)
+	if !exists {
+		return nil
+	}
+
+	survey.Lock()
+	if survey.number != number || !survey.resultHidden {
+		survey.Unlock()
+		return nil
+	}
+	e, first, err := s.reveal(survey)
+	survey.Unlock()
+	if err != nil {
+		return err
+	}
+	s.afterReveal(e, first)
+	return nil
+}
@@ -0,0 +1,61 @@
+package survey
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCountdown(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B"}, Countdown: -1}
+	_, err := s.New("creator", "", q)
+	assert.Error(t, err)
+	q.Countdown = maxCountdown + 1
+	_, err = s.New("creator", "", q)
+	assert.Error(t, err)
+
+	q.Countdown = 60
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	question := s.GetQuestion(sid)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), question.Deadline, time.Second)
+	assert.InDelta(t, 60, question.Remaining(), 1)
+	assert.Equal(t, question.Deadline, s.GetResult("creator", sid).Deadline)
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+
+	survey, _ := s.getSurveyToVote(sid)
+	survey.Lock()
+	survey.creationTime = time.Now().Add(-2 * time.Minute)
+	survey.Unlock()
+	assert.EqualError(t, s.Vote(sid, "b", []int{0}, 1), "Die Abstimmungszeit ist abgelaufen!")
+
+	// the countdown of a replaced question is ignored
+	assert.NoError(t, s.endCountdown(sid, 0))
+	assert.False(t, s.GetResult("creator", sid).Uncovered())
+
+	assert.NoError(t, s.endCountdown(sid, 1))
+	r := s.GetResult("creator", sid)
+	assert.True(t, r.Uncovered())
+	assert.True(t, r.Deadline.IsZero())
+	assert.Equal(t, 1, r.Votes)
+}
+
+func TestCountdownUncovers(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}, Countdown: 1})
+	assert.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		return s.GetResult("creator", sid).Uncovered()
+	}, 3*time.Second, 50*time.Millisecond)
+}
+
+func TestUnlimitedVotingTime(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	question := s.GetQuestion(sid)
+	assert.True(t, question.Deadline.IsZero())
+	assert.Equal(t, 0, question.Remaining())
+}
@@ -1,15 +1,18 @@
 package survey
 
 import (
+	"crypto/rand"
 	"encoding/base64"
-	"errors"
+	"flashSurvey/account"
+	"flashSurvey/randid"
 	"fmt"
 	"github.com/skip2/go-qrcode"
 	"log"
-	"math/rand"
-	"strconv"
+	"slices"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -20,6 +23,12 @@ const (
 type Option struct {
 	Title string
 	Votes int
+	// Color is the color of the option in the charts
+	Color string
+	// Group is the group the option belongs to, it may be empty
+	Group string
+	// Points is the Borda count of the option of a ranked question
+	Points int
 }
 
 type Options []Option
@@ -28,6 +37,11 @@ type OptionResult struct {
 	Title   string
 	votes   int
 	percent float64
+	color   string
+	// averageRank is the average rank of the option of a ranked question
+	averageRank float64
+	// locale is used to format the numbers, see Result.Localize
+	locale string
 }
 
 func (o OptionResult) PercentVal(max float64) float64 {
@@ -48,20 +62,39 @@ func (o OptionResult) Percent() string {
 	if o.votes < 0 {
 		return "-"
 	}
-	return strconv.FormatFloat(o.percent, 'f', 1, 64)
+	return FormatNumber(o.percent, 1, o.locale)
 }
 
 func (o OptionResult) Votes() string {
 	if o.votes < 0 {
 		return "-"
 	}
-	return strconv.Itoa(o.votes)
+	return FormatNumber(float64(o.votes), 0, o.locale)
+}
+
+// VoteCount returns the number of votes, or -1 if the result is hidden
+func (o OptionResult) VoteCount() int {
+	return o.votes
+}
+
+// PercentValue returns the percentage of the votes
+func (o OptionResult) PercentValue() float64 {
+	return o.percent
 }
 
 func (o OptionResult) String() string {
 	return fmt.Sprintf("%s: %d (%.1f%%)", o.Title, o.votes, o.percent)
 }
 
+// selections returns the number of selected options
+func (o Options) selections() int {
+	n := 0
+	for _, option := range o {
+		n += option.Votes
+	}
+	return n
+}
+
 func (o Options) result(sum int, hidden bool) ([]OptionResult, float64) {
 	if sum <= 0 {
 		sum = 1
@@ -76,6 +109,7 @@ func (o Options) result(sum int, hidden bool) ([]OptionResult, float64) {
 				Title:   option.Title,
 				votes:   -1,
 				percent: 0,
+				color:   option.Color,
 			})
 			maxPercent = 100.0
 		}
@@ -86,6 +120,7 @@ func (o Options) result(sum int, hidden bool) ([]OptionResult, float64) {
 				Title:   option.Title,
 				votes:   option.Votes,
 				percent: percent,
+				color:   option.Color,
 			})
 			if percent > maxPercent {
 				maxPercent = percent
@@ -106,46 +141,147 @@ type Survey struct {
 	question SurveyQuestion
 	surveyId SurveyId
 	userId   UserId
-	qrCode   string
-	options  Options
+	// host is the host of the vote link encoded in the QR code
+	host    string
+	qrCode  string
+	options Options
 	// The number is the number of times the survey has been updated.
 	// This is incremented whenever the question or options are changed.
 	// It is not incremented for votes.
 	number       int
 	votesCounted map[UserId]struct{}
 	resultHidden bool
+	// revealed is the number of options uncovered one at a time while the
+	// result is still hidden
+	revealed int
+	// uncovered is set if the result of the running question was uncovered
+	// at least once, it stays set if the result is hidden again
+	uncovered    bool
 	creationTime time.Time
+	// If set, the survey is not deleted before this time plus the timeout.
+	scheduledTime time.Time
+	// activeTime is the time of the last activity which defers the timeout,
+	// e.g. an extension by the creator or the heartbeat of a result page
+	activeTime time.Time
+	// expires is the time the survey is deleted if the creator has been warned
+	expires time.Time
 	// The version is incremented whenever the survey is changed.
 	// This includes votes.
-	version       int
-	changedNotify chan struct{}
+	version int
+	waiters waiters
+	// If not nil, only the registered voters are allowed to vote.
+	voterTokens map[UserId]struct{}
+	// If not nil, only the voters holding an unused access code are allowed
+	// to vote. The map contains the codes and whether they have been used.
+	accessCodes map[string]bool
+	// voteKey is the key of the vote links in the strict mode
+	voteKey string
+	// fingerprints maps the fingerprints of the devices to the voters of
+	// the running question in the strict mode
+	fingerprints map[string]UserId
+	// remoteVotes contains the votes of external sources, e.g. Fediverse polls
+	remoteVotes map[string]remoteTally
+	// ballots contains the votes of an end-to-end encrypted survey
+	ballots []string
+	// pending contains the accepted votes not yet added to the tally
+	pending voteQueue
+	// counted is the number of local votes added to the tally
+	counted int
+	// scheduled is set if the survey waits for the aggregation of its votes
+	scheduled atomic.Bool
+	// snapshot is the result of the current version, it is reset on every change
+	snapshot atomic.Pointer[Result]
+	// history contains the most recent snapshots of the current question
+	history []*Result
+	// writeIns contains the free text answers by their normalized text
+	writeIns map[string]*WriteIn
+	// promoted is the number of options added from the write-ins, the
+	// external sources do not know them
+	promoted int
+	// annotation is the note of the creator on the uncovered result
+	annotation string
+	// revision is incremented whenever the texts of the running question are corrected
+	revision int
+	// locked is set if the question must not be replaced
+	locked atomic.Bool
+	// subscribers are notified if the question changes
+	subscribers subscribers
+	// access records the requests of the result
+	access accessLog
+	// rounds contains the completed questions of the survey
+	rounds []Round
+	// mirror is set if the survey is a read-only copy of a survey of the
+	// primary instance, see Surveys.Mirror
+	mirror bool
+	// mirroredViewerToken is the viewer token of the mirrored survey
+	mirroredViewerToken string
+	// thankYou is shown to the voters after they have voted
+	thankYou ThankYou
+	// seriesStart is the number of the first question of the announced
+	// series and seriesTotal the number of its questions, see SetSeries
+	seriesStart int
+	seriesTotal int
+	// queue contains the prepared questions of the series which are
+	// started automatically after advance, see SetAdvance
+	queue   []prepared
+	advance time.Duration
 }
 
-func NewSurvey(userId UserId, def SurveyQuestion, opt []Option, host string) (*Survey, error) {
-	surveyId := SurveyId(RandomString())
+// maxHistory is the number of snapshots kept to compute the changes since
+// the version a client has seen
+const maxHistory = 16
 
-	url := host + "/vote/?id=" + string(surveyId)
+func NewSurvey(userId UserId, def SurveyQuestion, opt []Option, host string) (*Survey, error) {
+	return newSurvey(SurveyId(randid.String()), userId, def, opt, host, "")
+}
 
-	qrCode, err := qrcode.Encode(url, qrcode.Medium, 512)
+// newSurvey creates a survey, if voteKey is not empty, it is added to the
+// vote links
+func newSurvey(surveyId SurveyId, userId UserId, def SurveyQuestion, opt []Option, host string, voteKey string) (*Survey, error) {
+	qrCode, err := voteQRCode(voteLink(host, surveyId, voteKey))
 	if err != nil {
-		return nil, fmt.Errorf("could not create qr code: %w", err)
+		return nil, err
 	}
 
 	return &Survey{
-		question:      def,
-		surveyId:      surveyId,
-		qrCode:        base64.StdEncoding.EncodeToString(qrCode),
-		userId:        userId,
-		options:       opt,
-		number:        1,
-		votesCounted:  make(map[UserId]struct{}),
-		resultHidden:  true,
-		creationTime:  time.Now(),
-		version:       1,
-		changedNotify: make(chan struct{}),
+		question:     def,
+		surveyId:     surveyId,
+		host:         host,
+		qrCode:       qrCode,
+		voteKey:      voteKey,
+		userId:       userId,
+		options:      opt,
+		number:       1,
+		votesCounted: make(map[UserId]struct{}),
+		resultHidden: true,
+		creationTime: time.Now(),
+		version:      1,
+		waiters:      newWaiters(),
+		subscribers:  subscribers{last: QuestionChange{Number: 1}},
 	}, nil
 }
 
+func voteUrl(host string, surveyId SurveyId) string {
+	return host + "/vote/?id=" + string(surveyId)
+}
+
+// voteLink returns the vote link which contains the key of the strict mode if it is set
+func voteLink(host string, surveyId SurveyId, voteKey string) string {
+	if voteKey == "" {
+		return voteUrl(host, surveyId)
+	}
+	return voteUrl(host, surveyId) + "&k=" + voteKey
+}
+
+// voteQRCode returns the base64 encoded QR code of the vote link
+func voteQRCode(link string) (string, error) {
+	qrCode, err := qrcode.Encode(link, qrcode.Medium, 512)
+	if err != nil {
+		return "", fmt.Errorf("could not create qr code: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(qrCode), nil
+}
+
 func (s *Survey) Lock() {
 	s.mutex.Lock()
 }
@@ -155,22 +291,56 @@ func (s *Survey) Unlock() {
 }
 
 func (s *Survey) changed() {
+	s.snapshot.Store(nil)
 	s.version++
-	if s.changedNotify != nil {
-		close(s.changedNotify)
-	}
-	s.changedNotify = make(chan struct{})
+	s.waiters.release()
+	s.subscribers.notify(QuestionChange{Number: s.number, Revision: s.revision})
 }
 
-func (s *Survey) Update(def SurveyQuestion, opt []Option) {
+// Update replaces the question of the survey. The vote links of the new
+// question use the given host.
+func (s *Survey) Update(def SurveyQuestion, opt []Option, host string) error {
 	s.Lock()
 	defer s.Unlock()
+	return s.update(def, opt, host)
+}
+
+// update starts the given question. The survey must be locked.
+func (s *Survey) update(def SurveyQuestion, opt []Option, host string) error {
+	if host != s.host {
+		qrCode, err := voteQRCode(voteLink(host, s.surveyId, s.voteKey))
+		if err != nil {
+			return err
+		}
+		s.host = host
+		s.qrCode = qrCode
+	}
+	s.restart(def, opt)
+	return nil
+}
+
+// restart starts the given question, the running question is added to the
+// completed questions. The survey must be locked.
+func (s *Survey) restart(def SurveyQuestion, opt []Option) {
+	s.addRound()
 	s.question = def
 	s.options = opt
 	s.number++
 	s.votesCounted = make(map[UserId]struct{})
+	s.fingerprints = nil
+	s.remoteVotes = nil
+	s.ballots = nil
+	s.pending.take()
+	s.counted = 0
+	s.history = nil
+	s.writeIns = nil
+	s.promoted = 0
+	s.annotation = ""
 	s.resultHidden = true
+	s.revealed = 0
+	s.uncovered = false
 	s.creationTime = time.Now()
+	s.expires = time.Time{}
 	s.changed()
 }
 
@@ -181,31 +351,179 @@ type Result struct {
 	Result     []OptionResult
 	MaxPercent float64
 	Version    int
+	// Number is the number of the question
+	Number int
+	// Display contains the presentation settings
+	Display Display
+	// Groups contains the votes aggregated by the groups of the options
+	// if Display.ByGroup is set
+	Groups          []OptionResult
+	GroupMaxPercent float64
+	// Encrypted is set if the title, the options and the ballots are end-to-end encrypted
+	Encrypted bool
+	// Ballots contains the encrypted votes if the result is visible
+	Ballots []string
+	// WriteIns contains the free text answers if the result is visible
+	WriteIns []WriteIn
+	// Rating contains the statistics of a rating question if the result
+	// is visible and there are votes
+	Rating *Rating
+	// Ranked is set if the votes are the Borda counts of a ranked question
+	Ranked bool
+	// Multiple is set if more than one option can be selected
+	Multiple bool
+	// Expires is the time the survey is deleted because of the timeout, it
+	// is only set shortly before
+	Expires time.Time
+	// Started is the time the question was started
+	Started time.Time
+	// Language is the language of the vote page chosen by the creator
+	Language string
+	// Selections is the number of selected options if the result is visible
+	Selections int
+	// Lead is the lead of the top option if Display.Significance is set
+	// and the result is visible
+	Lead *Lead
+	// Annotation is the note of the creator on the uncovered result
+	Annotation string
+	// Deadline is the end of the voting time, it is zero if the voting
+	// time is not limited
+	Deadline time.Time
 }
 
+// Result returns the result of the survey. The survey must be locked.
+// The result is computed only once per version and shared by all callers,
+// so it must not be modified.
 func (s *Survey) Result() Result {
-	result, maxPercent := s.options.result(len(s.votesCounted), s.resultHidden)
-	return Result{
-		Title:      s.question.Title,
-		QRCode:     s.qrCode,
-		Votes:      len(s.votesCounted),
-		MaxPercent: maxPercent,
-		Result:     result,
-		Version:    s.version,
+	s.applyPending()
+	if r := s.snapshot.Load(); r != nil {
+		return *r
 	}
+	r := s.computeResult()
+	s.snapshot.Store(&r)
+	s.history = append(s.history, &r)
+	if len(s.history) > maxHistory {
+		s.history = s.history[1:]
+	}
+	return r
+}
+
+// snapshotResult returns the result without locking the survey if the
+// snapshot of the current version is available. So rendering the result
+// pages does not block the incoming votes.
+func (s *Survey) snapshotResult() Result {
+	if r := s.snapshot.Load(); r != nil && s.pending.head.Load() == nil {
+		return *r
+	}
+	s.Lock()
+	defer s.Unlock()
+	return s.Result()
+}
+
+func (s *Survey) computeResult() Result {
+	votes := s.voteCount()
+	tally := s.tally()
+	selections := tally.selections()
+	base := votes
+	if s.question.Display.PerSelection() {
+		base = selections
+	}
+	var result []OptionResult
+	var maxPercent float64
+	if s.question.Ranked {
+		result, maxPercent = tally.rankedResult(votes, s.resultHidden)
+	} else {
+		result, maxPercent = tally.result(base, s.resultHidden)
+	}
+	var groups []OptionResult
+	var groupMaxPercent float64
+	if s.resultHidden && s.revealed > 0 {
+		result = s.partialResult(tally, base, result)
+	}
+	if s.question.Display.ByGroup && !s.question.Encrypted() {
+		groups, groupMaxPercent = tally.groups().result(base, s.resultHidden)
+	}
+	if s.question.Display.SortByVotes && !s.resultHidden && !s.question.Encrypted() {
+		sortByVotes(result)
+		sortByVotes(groups)
+	}
+	r := Result{
+		Groups:          groups,
+		GroupMaxPercent: groupMaxPercent,
+		Title:           s.question.Title,
+		QRCode:          s.qrCode,
+		Votes:           votes,
+		MaxPercent:      maxPercent,
+		Result:          result,
+		Version:         s.version,
+		Number:          s.number,
+		Display:         s.question.Display,
+		Encrypted:       s.question.Encrypted(),
+		Ranked:          s.question.Ranked,
+		Multiple:        s.question.Multiple,
+		Expires:         s.expires,
+		Started:         s.creationTime,
+		Language:        s.question.Language,
+	}
+	if s.resultHidden {
+		r.Deadline = s.deadline()
+	}
+	if r.Encrypted && !s.resultHidden {
+		r.Ballots = slices.Clone(s.ballots)
+	}
+	if !s.resultHidden {
+		r.Annotation = s.annotation
+		if s.question.Display.Significance && !s.question.Encrypted() {
+			r.Lead = tally.lead()
+		}
+		r.Selections = selections
+		r.WriteIns = s.writeInResult()
+		if s.question.Rating > 0 {
+			r.Rating = tally.rating()
+		}
+	}
+	return r
 }
 
 type Question struct {
 	Number   int
 	SurveyId SurveyId
 	Question SurveyQuestion
+	// Revision is incremented whenever the texts of the question are corrected
+	Revision int
+	// Deadline is the time after which no more votes are accepted, it is
+	// zero if the voting time is not limited
+	Deadline time.Time
+	// VoteKey is the key of the vote links in the strict mode
+	VoteKey string
+	// CodeRequired is set if an access code is required to vote
+	CodeRequired bool
+	// TokenRequired is set if only registered voters can vote, see RegisterVoters
+	TokenRequired bool
+	// ThankYou is shown to the voters after they have voted
+	ThankYou ThankYou
+	// Series is the position of the question in the announced series
+	Series Series
+}
+
+// FollowUp returns the link the voters of this question are sent to
+// after they have voted, or an empty string if there is none
+func (q Question) FollowUp() string {
+	return q.ThankYou.Link(q.SurveyId, q.Number)
 }
 
 func (s *Survey) Question() Question {
 	return Question{
-		Number:   s.number,
-		SurveyId: s.surveyId,
-		Question: s.question,
+		Number:        s.number,
+		SurveyId:      s.surveyId,
+		Question:      s.question,
+		Revision:      s.revision,
+		Deadline:      s.deadline(),
+		VoteKey:       s.voteKey,
+		CodeRequired:  s.accessCodes != nil,
+		TokenRequired: s.voterTokens != nil,
+		ThankYou:      s.thankYou,
+		Series:        s.seriesPosition(),
 	}
 }
 
@@ -214,23 +532,87 @@ type Surveys struct {
 	surveys             map[SurveyId]*Survey
 	host                string
 	debug               bool
-	voteIfResultVisible bool
+	voteIfResultVisible atomic.Bool
+	secret              []byte
+	accounts            *account.Accounts
+	// dirty receives the surveys with pending votes if vote batching is enabled
+	dirty       chan *Survey
+	maxWaiters  int
+	waiterStats waiterStats
+	// maxOptions is the maximum number of options of a survey, zero means unlimited
+	maxOptions int
+	drafts     drafts
+	// expiryWarning is the time before the timeout the creator is warned
+	expiryWarning time.Duration
+	// hosts contains the hosts the creators can select for the vote links
+	hosts []string
+	stats stats
+	// timeout is the default time of inactivity after which a survey is
+	// deleted, it can be changed at runtime, see SetTimeout
+	timeout atomic.Int64
+	// minTimeout and maxTimeout are the bounds of the timeout in minutes the creators can choose
+	minTimeout int
+	maxTimeout int
+	// shutdown is closed if the server shuts down, see Close
+	shutdown  chan struct{}
+	closeOnce sync.Once
+	// strictVoting is set if the votes are deduplicated beyond the user id, see EnableStrictVoting
+	strictVoting bool
+	// maxSurveys is the maximum number of surveys of a single user, zero means unlimited
+	maxSurveys int
+	// newId creates the ids of new surveys
+	newId func() string
+}
+
+// SetMaxSurveys limits the number of surveys a single user can run at the
+// same time, so a user can not flood the server with surveys. If max is
+// zero, the number is not limited. Must be called before the surveys are used.
+func (s *Surveys) SetMaxSurveys(max int) {
+	s.maxSurveys = max
+}
+
+// tooManySurveys returns true if the user must not create another survey,
+// the caller has to hold the mutex
+func (s *Surveys) tooManySurveys(userId UserId) bool {
+	if s.maxSurveys <= 0 {
+		return false
+	}
+	n := 0
+	for _, su := range s.surveys {
+		if su.userId == userId {
+			n++
+		}
+	}
+	return n >= s.maxSurveys
 }
 
-var closedChannel chan struct{}
+// SetMaxOptions limits the number of options of each survey. If max is
+// zero, the number is not limited. Must be called before the surveys are used.
+func (s *Surveys) SetMaxOptions(max int) {
+	s.maxOptions = max
+}
 
-func init() {
-	closedChannel = make(chan struct{})
-	close(closedChannel)
+// MaxOptions returns the maximum number of options of a survey, zero means unlimited
+func (s *Surveys) MaxOptions() int {
+	return s.maxOptions
+}
+
+// tooManyOptions returns true if a survey must not have the given number of options
+func (s *Surveys) tooManyOptions(n int) bool {
+	return s.maxOptions > 0 && n > s.maxOptions
 }
 
 func New(host string, timeoutMin int, voteIfResultVisible, debug bool) *Surveys {
 	s := &Surveys{
-		surveys:             make(map[SurveyId]*Survey),
-		host:                host,
-		voteIfResultVisible: voteIfResultVisible,
-		debug:               debug,
+		surveys:       make(map[SurveyId]*Survey),
+		host:          host,
+		debug:         debug,
+		secret:        randomSecret(),
+		expiryWarning: defaultExpiryWarning,
+		shutdown:      make(chan struct{}),
+		newId:         randid.String,
 	}
+	s.voteIfResultVisible.Store(voteIfResultVisible)
 	s.startSurveyTimeoutCheck(timeoutMin)
 	return s
 }
@@ -239,10 +621,58 @@ type SurveyQuestion struct {
 	Title    string
 	Options  []string
 	Multiple bool
+	// Language is the language of the vote page, if empty the browser settings are used
+	Language string
+	// Announce is set if the survey is announced by the chat and social media integrations
+	Announce bool
+	// Public is set if the survey is listed on the browse page while it is running
+	Public bool
+	// PublicKey is set if the survey is end-to-end encrypted, see Encrypted
+	PublicKey string
+	// Display contains the presentation settings of the result
+	Display Display
+	// Colors contains the colors of the options, if missing, the default colors are used
+	Colors []string
+	// Groups contains the groups of the options, see Display.ByGroup
+	Groups []string
+	// WriteIn allows the voters to give a free text answer, see WriteIn
+	WriteIn bool
+	// MergeDuplicates removes the options which only differ from a previous
+	// option by case or whitespace instead of rejecting the question
+	MergeDuplicates bool
+	// Acclamation is set if the question has a single option, e.g. "I'm here",
+	// which is used to count the participants or to agree to a proposal
+	Acclamation bool
+	// Rating is the number of points of the scale of a rating question, e.g.
+	// five stars or a Likert scale. The option with index i is the scale
+	// value i+1. Zero means the question is not a rating.
+	Rating int
+	// Ranked is set if the voters order all options by preference instead
+	// of selecting them. The result is ordered by the Borda count.
+	Ranked bool
+	// Host is the host used in the vote links, e.g. the domain of an event.
+	// It must be one of the hosts approved by the operator, if empty, the
+	// host of the instance is used.
+	Host string
+	// Timeout is the time of inactivity in minutes after which the survey
+	// is deleted, if zero, the default of the instance is used
+	Timeout int
+	// Countdown is the voting time in seconds, after it has elapsed, no
+	// more votes are accepted and the result is uncovered. Zero means the
+	// voting time is not limited.
+	Countdown int
 }
 
 func (d SurveyQuestion) Valid() bool {
-	return d.Title != "" && len(d.Options) >= 2
+	return d.Title != "" && len(d.Options) >= d.minOptions()
+}
+
+// minOptions returns the minimum number of options of the question
+func (d SurveyQuestion) minOptions() int {
+	if d.Acclamation {
+		return 1
+	}
+	return 2
 }
 
 func (d SurveyQuestion) String() string {
@@ -269,7 +699,7 @@ func (d SurveyQuestion) clean(o string) string {
 func DefinitionFromString(str string) (SurveyQuestion, error) {
 	parts := strings.Split(str, ";")
 	if len(parts) < 4 {
-		return SurveyQuestion{}, errors.New("Ungültige Umfrage-Definition!")
+		return SurveyQuestion{}, newError("invalidDefinition")
 	}
 
 	def := SurveyQuestion{
@@ -285,64 +715,199 @@ func DefinitionFromString(str string) (SurveyQuestion, error) {
 	}
 
 	if !def.Valid() {
-		return SurveyQuestion{}, errors.New("Ungültige Umfrage-Definition!")
+		return SurveyQuestion{}, newError("invalidDefinition")
 	}
 
 	return def, nil
 }
 
-func (s *Surveys) New(userId UserId, knownSurveyId SurveyId, def SurveyQuestion) (SurveyId, error) {
-	opt := make([]Option, len(def.Options))
-	for i, option := range def.Options {
-		option = strings.TrimSpace(option)
-		if option == "" {
-			return "", fmt.Errorf("Option %d ist leer!", i+1)
-		} else if len(option) > maxStringLen {
-			return "", fmt.Errorf("Option %d ist zu lang! Maximal %d Zeichen erlaubt.", i+1, maxStringLen)
+// prepared is a question which has been checked and completed by prepare
+type prepared struct {
+	def  SurveyQuestion
+	opt  []Option
+	host string
+}
+
+// prepare checks the question and creates its options
+func (s *Surveys) prepare(userId UserId, def SurveyQuestion) (prepared, error) {
+	if !s.accounts.RoleOf(string(userId)).CanCreate() {
+		return prepared{}, ErrCreateNotAllowed
+	}
+
+	maxLen := maxStringLen
+	if def.Encrypted() {
+		if len(def.PublicKey) > maxKeyLen {
+			return prepared{}, newError("invalidKey")
+		}
+		// the ciphertexts are longer than the texts
+		maxLen = maxCipherLen
+		def.Announce = false
+		def.Public = false
+		// the groups are not encrypted, the votes can not be aggregated anyway
+		def.Groups = nil
+		def.Display.ByGroup = false
+		def.WriteIn = false
+	}
+
+	var ratingErr error
+	if def.Ranked {
+		if def.Encrypted() {
+			ratingErr = newError("rankedEncrypted")
+		} else if def.Rating > 0 || def.Acclamation {
+			ratingErr = newError("rankedRating")
+		}
+		def.Multiple = false
+		def.WriteIn = false
+		def.Display.SortByVotes = false
+		def.Display.ByGroup = false
+		// the points are not the votes of the options
+		def.Display.Significance = false
+	} else if def.Rating > 0 {
+		if def.Encrypted() {
+			// the mean can not be computed from the encrypted ballots
+			ratingErr = newError("ratingEncrypted")
+		} else {
+			def, ratingErr = def.ratingOptions()
+		}
+		// every voter gives a single scale value
+		def.Multiple = false
+		def.WriteIn = false
+		def.Acclamation = false
+		def.MergeDuplicates = false
+		def.Display.SortByVotes = false
+		def.Display.ByGroup = false
+		def.Display.Significance = false
+	}
+
+	if def.MergeDuplicates {
+		def = def.mergeDuplicates()
+	}
+	title, options, verr := validateTexts(def.Title, def.Options, maxLen)
+	if ratingErr != nil {
+		verr.add("rating", ratingErr)
+	}
+	def.Title = title
+	opt := make([]Option, len(options))
+	for i, option := range options {
+		color, err := def.color(i)
+		if err != nil {
+			verr.add(optionField(i), err)
+		}
+		group, err := def.group(i)
+		if err != nil {
+			verr.add(optionField(i), err)
+		}
+		opt[i] = Option{Title: option, Votes: 0, Color: color, Group: group}
+	}
+
+	if def.Acclamation {
+		if len(opt) != 1 {
+			verr.add(optionField(min(len(opt), 1)), newError("acclamationOption"))
 		}
-		opt[i] = Option{Title: option, Votes: 0}
+		// every voter selects the single option, so only the number of voters is of interest
+		def.Multiple = false
+		def.WriteIn = false
+		def.Display.HidePercent = true
+	} else if len(opt) < 2 {
+		verr.add(optionField(len(opt)), newError("twoOptions"))
+	} else if s.tooManyOptions(len(opt)) {
+		verr.add(optionField(s.maxOptions-1), newError("maxOptions", s.maxOptions))
 	}
 
-	def.Title = strings.TrimSpace(def.Title)
-	if def.Title == "" {
-		return "", errors.New("Es fehlt der Titel!")
-	} else if len(def.Title) > maxStringLen {
-		return "", fmt.Errorf("Der Titel ist zu lang! Maximal %d Zeichen erlaubt.", maxStringLen)
+	if !def.Multiple || def.Encrypted() {
+		// the base makes no difference, the encrypted ballots are counted by the browser
+		def.Display.PercentBase = ""
+	}
+	if err := def.Display.validate(); err != nil {
+		verr.add("chart", err)
+	}
+	host, err := s.voteHost(def)
+	if err != nil {
+		verr.add("host", err)
+	}
+	if err := s.validateTimeout(def); err != nil {
+		verr.add("timeout", err)
+	}
+	if err := validateCountdown(def); err != nil {
+		verr.add("countdown", err)
 	}
 
-	if len(opt) < 2 {
-		return "", errors.New("Es müssen mindestens zwei Optionen angegeben werden!")
+	if len(verr) > 0 {
+		return prepared{}, verr
 	}
+	return prepared{def: def, opt: opt, host: host}, nil
+}
 
-	if len(knownSurveyId) == IdLength {
-		ok, err := s.tryUpdate(userId, knownSurveyId, def, opt)
+func (s *Surveys) New(userId UserId, knownSurveyId SurveyId, def SurveyQuestion) (SurveyId, error) {
+	p, err := s.prepare(userId, def)
+	if err != nil {
+		return "", err
+	}
+	def, opt, host := p.def, p.opt, p.host
+
+	if randid.Valid(string(knownSurveyId)) {
+		ok, err := s.tryUpdate(userId, knownSurveyId, def, opt, host)
 		if err != nil {
 			return "", err
 		}
 		if ok { // There was an existing survey to update
 			log.Printf("updated survey with %d options, in total %d surveys", len(opt), s.getSurveyCount())
+			s.startEvent(knownSurveyId)
 			return knownSurveyId, nil
 		}
 	}
 
-	su, err := NewSurvey(userId, def, opt, s.host)
+	su, err := s.insertNew(userId, def, opt, host)
 	if err != nil {
 		return "", err
 	}
-	log.Printf("created survey with %d options, in total %d surveys", len(opt), s.getSurveyCount()+1)
+	log.Printf("created survey with %d options, in total %d surveys", len(opt), s.getSurveyCount())
 
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+	s.startEvent(su.surveyId)
+	return su.surveyId, nil
+}
 
-	if _, exists := s.surveys[su.surveyId]; exists {
-		// Almost impossible, but just in case
-		log.Printf("survey with ID %s already exists, this should not happen!", su.surveyId)
-		return "", fmt.Errorf("Umfrage mit ID %s existiert bereits!", su.surveyId)
-	}
+// maxIdAttempts is the number of ids tried if a new survey id is already used
+const maxIdAttempts = 5
 
-	s.surveys[su.surveyId] = su
+// insertNew creates a survey with a new id and adds it to the surveys. If
+// the id is already used, another one is tried.
+func (s *Surveys) insertNew(userId UserId, def SurveyQuestion, opt []Option, host string) (*Survey, error) {
+	var surveyId SurveyId
+	for range maxIdAttempts {
+		surveyId = SurveyId(s.newId())
+		su, err := newSurvey(surveyId, userId, def, opt, host, s.newVoteKey(surveyId))
+		if err != nil {
+			return nil, err
+		}
 
-	return su.surveyId, nil
+		s.mutex.Lock()
+		if s.tooManySurveys(userId) {
+			s.mutex.Unlock()
+			return nil, newError("tooManySurveys", s.maxSurveys)
+		}
+		if _, exists := s.surveys[surveyId]; exists {
+			s.mutex.Unlock()
+			log.Printf("survey id %s is already used, trying another one", surveyId)
+			continue
+		}
+		s.surveys[surveyId] = su
+		s.mutex.Unlock()
+		return su, nil
+	}
+	return nil, newError("surveyExists", surveyId)
+}
+
+func (s *Surveys) startEvent(surveyId SurveyId) {
+	survey, exists := s.getSurveyToVote(surveyId)
+	if !exists {
+		return
+	}
+	survey.Lock()
+	e := survey.event()
+	survey.Unlock()
+	onStart(e)
+	s.startCountdown(surveyId)
 }
 
 func (s *Surveys) getSurveyCount() int {
@@ -352,30 +917,155 @@ func (s *Surveys) getSurveyCount() int {
 	return len(s.surveys)
 }
 
-func (s *Surveys) tryUpdate(userId UserId, oldSurveyId SurveyId, def SurveyQuestion, opt []Option) (bool, error) {
+// Count returns the number of surveys
+func (s *Surveys) Count() int {
+	return s.getSurveyCount()
+}
+
+func (s *Surveys) tryUpdate(userId UserId, oldSurveyId SurveyId, def SurveyQuestion, opt []Option, host string) (bool, error) {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
 	if existingSurvey, exists := s.surveys[oldSurveyId]; exists {
-		if existingSurvey.userId != userId {
-			return false, errors.New("Diese Umfrage existiert bereits und wurde von einem anderen Benutzer erstellt!")
+		if !s.isCreator(existingSurvey, userId) {
+			return false, ErrSurveyOfOtherUser
 		}
-		existingSurvey.Update(def, opt)
-		return true, nil
+		if existingSurvey.locked.Load() {
+			return false, ErrLocked
+		}
+		return true, existingSurvey.Update(def, opt, host)
 	} else {
 		return false, nil
 	}
 }
 
-const IdLength = 30
+func randomSecret() []byte {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		panic(err)
+	}
+	return secret
+}
 
-func RandomString() string {
-	from := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	result := make([]byte, IdLength)
-	for i := range result {
-		result[i] = from[rand.Intn(len(from))]
+// SetAccounts enables the creator accounts. If set, all user ids
+// bound to the same account are treated as the creator of a survey.
+func (s *Surveys) SetAccounts(accounts *account.Accounts) {
+	s.accounts = accounts
+}
+
+// isCreator checks if the given user is the creator of the survey.
+// Either the surveys mutex or the survey lock must be held.
+func (s *Surveys) isCreator(survey *Survey, userId UserId) bool {
+	return survey.userId == userId || s.accounts.SameAccount(string(survey.userId), string(userId))
+}
+
+// SurveyOfCreator returns the most recently updated survey created by the given
+// user or by another user bound to the same account.
+func (s *Surveys) SurveyOfCreator(userId UserId) (SurveyId, bool) {
+	var found *Survey
+	var foundTime time.Time
+	for _, survey := range s.creatorSurveys(userId) {
+		survey.Lock()
+		t := survey.creationTime
+		survey.Unlock()
+		if found == nil || t.After(foundTime) {
+			found = survey
+			foundTime = t
+		}
+	}
+	if found == nil {
+		return "", false
+	}
+	return found.surveyId, true
+}
+
+// SurveysOfCreator returns the ids of all surveys created by the given user
+// or by another user bound to the same account, e.g. the surveys of parallel
+// workshop rooms. The ids are sorted, so the order does not change if a new
+// question is started.
+func (s *Surveys) SurveysOfCreator(userId UserId) []SurveyId {
+	var ids []SurveyId
+	for _, survey := range s.creatorSurveys(userId) {
+		ids = append(ids, survey.surveyId)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// SurveyInfo describes a survey in the list of the surveys of a user
+type SurveyInfo struct {
+	Id        SurveyId
+	Title     string
+	Number    int
+	Votes     int
+	Hidden    bool
+	Encrypted bool
+	Created   time.Time
+	Access    AccessLog
+}
+
+// ListByUser returns the surveys created by the given user or by another
+// user bound to the same account. The most recently started question comes first.
+func (s *Surveys) ListByUser(userId UserId) []SurveyInfo {
+	var list []SurveyInfo
+	for _, survey := range s.creatorSurveys(userId) {
+		survey.Lock()
+		survey.applyPending()
+		list = append(list, SurveyInfo{
+			Id:        survey.surveyId,
+			Title:     survey.question.Title,
+			Number:    survey.number,
+			Votes:     survey.voteCount(),
+			Hidden:    survey.resultHidden,
+			Encrypted: survey.question.Encrypted(),
+			Created:   survey.creationTime,
+			Access:    survey.access.AccessLog,
+		})
+		survey.Unlock()
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Created.After(list[j].Created)
+	})
+	return list
+}
+
+func (s *Surveys) creatorSurveys(userId UserId) []*Survey {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	var found []*Survey
+	for _, survey := range s.surveys {
+		if s.isCreator(survey, userId) {
+			found = append(found, survey)
+		}
+	}
+	return found
+}
+
+// mayControl checks if the given user is allowed to control the survey.
+// This is the creator or a moderator.
+// Either the surveys mutex or the survey lock must be held.
+func (s *Surveys) mayControl(survey *Survey, userId UserId) bool {
+	return s.isCreator(survey, userId) || s.accounts.RoleOf(string(userId)).CanModerate()
+}
+
+// TransferCreator makes the user 'to' the creator of all surveys
+// created by the user 'from'.
+func (s *Surveys) TransferCreator(from, to UserId) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	n := 0
+	for _, survey := range s.surveys {
+		survey.Lock()
+		if survey.userId == from {
+			survey.userId = to
+			n++
+		}
+		survey.Unlock()
 	}
-	return string(result)
+	return n
 }
 
 func (s *Surveys) getSurveyToVote(surveyId SurveyId) (*Survey, bool) {
@@ -392,7 +1082,7 @@ func (s *Surveys) getSurveyCheckUser(userId UserId, surveyId SurveyId) (*Survey,
 	if !exists {
 		return nil, false
 	}
-	if survey.userId != userId {
+	if !s.mayControl(survey, userId) {
 		return nil, false
 	}
 	return survey, exists
@@ -405,7 +1095,7 @@ func (s *Surveys) deleteSurvey(userId UserId, surveyId SurveyId) (*Survey, bool)
 	if !exists {
 		return nil, false
 	}
-	if survey.userId != userId {
+	if !s.mayControl(survey, userId) {
 		return nil, false
 	}
 
@@ -418,25 +1108,27 @@ func (s *Surveys) Clear(surveyId SurveyId, userId UserId) {
 	survey, exists := s.deleteSurvey(userId, surveyId)
 	if exists {
 		survey.Lock()
-		defer survey.Unlock()
-
-		close(survey.changedNotify)
+		e := survey.finalEvent()
+		survey.waiters.close()
+		survey.subscribers.close()
+		survey.Unlock()
 
 		log.Printf("deleted survey, %d surveys remaining\n", s.getSurveyCount())
+		onClose(e)
 	}
 }
 
 func (s *Surveys) GiveAwayQRCode(surveyId SurveyId, userId UserId) (string, error) {
 	survey, exists := s.getSurveyCheckUser(userId, surveyId)
 	if !exists {
-		return "", errors.New("Diese Umfrage existiert nicht!")
+		return "", ErrSurveyNotFound
 	}
 
 	survey.Lock()
 	defer survey.Unlock()
 
-	if survey.userId != userId {
-		return "", errors.New("Sie sind nicht der Ersteller dieser Umfrage!")
+	if !s.mayControl(survey, userId) {
+		return "", ErrNotOwner
 	}
 
 	url := fmt.Sprintf("%s/?tuid=%s&tsid=%s", s.host, userId, surveyId)
@@ -450,55 +1142,143 @@ func (s *Surveys) GiveAwayQRCode(surveyId SurveyId, userId UserId) (string, erro
 }
 
 func (s *Surveys) Uncover(userid UserId, surveyId SurveyId) error {
+	e, first, err := s.uncover(userid, surveyId)
+	if err != nil {
+		return err
+	}
+	s.afterReveal(e, first)
+	return nil
+}
+
+func (s *Surveys) uncover(userid UserId, surveyId SurveyId) (ResultEvent, bool, error) {
 	survey, exists := s.getSurveyCheckUser(userid, surveyId)
 	if !exists {
-		return errors.New("Diese Umfrage existiert nicht!")
+		return ResultEvent{}, false, ErrSurveyNotFound
 	}
 
 	survey.Lock()
 	defer survey.Unlock()
 
-	if survey.userId != userid {
-		return errors.New("Sie sind nicht der Ersteller dieser Umfrage!")
+	if !s.mayControl(survey, userid) {
+		return ResultEvent{}, false, ErrNotOwner
 	}
 
-	votes := len(survey.votesCounted)
+	return s.reveal(survey)
+}
+
+// enoughVotes checks if the result may be uncovered, which protects the
+// secrecy of the first votes. The survey must be locked.
+func (s *Surveys) enoughVotes(survey *Survey) error {
+	survey.applyPending()
+	votes := survey.voteCount()
 	if !s.debug && votes > 0 && votes <= 2 {
-		return errors.New("Es sind noch nicht genug Stimmen abgegeben worden!")
+		return ErrNotEnoughVotes
+	}
+	return nil
+}
+
+// reveal makes the result of the running question visible. It returns
+// true if the result is uncovered for the first time. The survey must be
+// locked.
+func (s *Surveys) reveal(survey *Survey) (ResultEvent, bool, error) {
+	if err := s.enoughVotes(survey); err != nil {
+		return ResultEvent{}, false, err
 	}
 
+	first := !survey.uncovered
 	survey.resultHidden = false
+	survey.revealed = 0
+	survey.uncovered = true
 	survey.changed()
-	return nil
+	return survey.resultEvent(), first, nil
+}
+
+// afterReveal calls the hooks after the result was uncovered. The result
+// is announced only the first time, if it was hidden again in between
+// only the displays are updated. The survey must not be locked.
+func (s *Surveys) afterReveal(e ResultEvent, first bool) {
+	onVisibility(e)
+	if first {
+		afterUncover(e)
+	}
+	s.scheduleAdvance(e.Event)
 }
 
-func (s *Surveys) WaitForModification(userId UserId, surveyId SurveyId, clientVersion int) chan struct{} {
+func (s *Surveys) GetResult(userId UserId, surveyId SurveyId) Result {
 	survey, exists := s.getSurveyCheckUser(userId, surveyId)
 	if !exists {
-		return nil
+		return Result{Title: "Es gibt z.Z. keine Umfrage!", Version: -1}
+	}
+
+	return survey.snapshotResult()
+}
+
+// ResultSince returns the current result and, if it is still known, the
+// result of the given version. This allows to send only the changes to a
+// client which already knows the former result.
+func (s *Surveys) ResultSince(userId UserId, surveyId SurveyId, version int) (Result, *Result) {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return Result{Title: "Es gibt z.Z. keine Umfrage!", Version: -1}, nil
 	}
 
 	survey.Lock()
 	defer survey.Unlock()
 
-	if survey.version > clientVersion {
-		// immediate notification if the version is higher than the client's version
-		return closedChannel
+	r := survey.Result()
+	for _, h := range survey.history {
+		if h.Version == version {
+			return r, h
+		}
+	}
+	return r, nil
+}
+
+// PublicSurveys returns the running surveys which are listed publicly,
+// most recently started first. Surveys which require a registration or an
+// access code are not listed, because nobody else could vote.
+func (s *Surveys) PublicSurveys() []Question {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	type listed struct {
+		question Question
+		started  time.Time
+	}
+	var found []listed
+	for _, survey := range s.surveys {
+		survey.Lock()
+		if survey.question.Public && survey.resultHidden && survey.voterTokens == nil && survey.accessCodes == nil {
+			found = append(found, listed{question: survey.Question(), started: survey.creationTime})
+		}
+		survey.Unlock()
 	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].started.After(found[j].started)
+	})
 
-	return survey.changedNotify
+	questions := make([]Question, len(found))
+	for i, f := range found {
+		questions[i] = f.question
+	}
+	return questions
 }
 
-func (s *Surveys) GetResult(userId UserId, surveyId SurveyId) Result {
-	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+// UncoveredResult returns the result of the survey if it has been uncovered
+// by its creator. It does not require any permissions.
+func (s *Surveys) UncoveredResult(surveyId SurveyId) (Result, bool) {
+	survey, exists := s.getSurveyToVote(surveyId)
 	if !exists {
-		return Result{Title: "Es gibt z.Z. keine Umfrage!", Version: -1}
+		return Result{}, false
 	}
 
 	survey.Lock()
 	defer survey.Unlock()
 
-	return survey.Result()
+	if survey.resultHidden {
+		return Result{}, false
+	}
+	return survey.Result(), true
 }
 
 func (s *Surveys) GetRunningSurvey(userId UserId, surveyId SurveyId) (SurveyQuestion, bool) {
@@ -525,41 +1305,132 @@ func (s *Surveys) IsHiddenRunning(userId UserId, surveyId SurveyId) (bool, bool)
 	return survey.resultHidden, true
 }
 
+// CollectedVotes returns the number of votes of the running question, which
+// are deleted if a new question is started
+func (s *Surveys) CollectedVotes(userId UserId, surveyId SurveyId) int {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return 0
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	survey.applyPending()
+	return survey.voteCount()
+}
+
 func (s *Surveys) Vote(surveyId SurveyId, voterId UserId, option []int, number int) error {
+	e, err := s.vote(surveyId, voterId, option, "", "", number)
+	if err != nil {
+		return err
+	}
+	afterVote(e)
+	return nil
+}
+
+func (s *Surveys) vote(surveyId SurveyId, voterId UserId, option []int, ballot, writeIn string, number int) (VoteEvent, error) {
 	survey, exists := s.getSurveyToVote(surveyId)
 	if !exists {
-		return errors.New("Diese Umfrage existiert nicht!")
+		return VoteEvent{}, ErrSurveyNotFound
+	}
+
+	e, err := s.accept(survey, voterId, option, ballot, writeIn, number)
+	if err != nil {
+		return VoteEvent{}, err
+	}
+
+	survey.pending.push(&pendingVote{number: number, options: option, ballot: ballot, writeIn: writeIn})
+	s.countVote()
+	if s.dirty != nil && !afterVoteRegistered() {
+		s.schedule(survey)
+		return e, nil
 	}
 
+	survey.Lock()
+	defer survey.Unlock()
+	e.Result = survey.Result()
+	return e, nil
+}
+
+// accept checks the vote and marks the voter as voted
+func (s *Surveys) accept(survey *Survey, voterId UserId, option []int, ballot, writeIn string, number int) (VoteEvent, error) {
 	survey.Lock()
 	defer survey.Unlock()
 
 	if number != survey.number {
-		return errors.New("Diese Umfrage war schon beendet!")
+		return VoteEvent{}, ErrRoundClosed
 	}
 
-	if !s.voteIfResultVisible {
+	if !s.voteIfResultVisible.Load() {
 		if !survey.resultHidden {
-			return errors.New("Die Umfrageergebnisse sind bereits sichtbar!")
+			return VoteEvent{}, ErrResultVisible
 		}
 	}
 
+	if survey.voterTokens != nil {
+		if _, registered := survey.voterTokens[voterId]; !registered {
+			return VoteEvent{}, ErrNotRegistered
+		}
+	}
+
+	code, err := survey.useCode(voterId)
+	if err != nil {
+		return VoteEvent{}, err
+	}
+
+	if survey.elapsed(time.Now()) {
+		return VoteEvent{}, ErrVotingTimeElapsed
+	}
+
 	if _, voted := survey.votesCounted[voterId]; voted {
-		return errors.New("Sie haben bereits abgestimmt!")
+		return VoteEvent{}, ErrAlreadyVoted
 	}
 
-	survey.votesCounted[voterId] = struct{}{}
+	if survey.question.Encrypted() != (ballot != "") {
+		return VoteEvent{}, ErrInvalidOption
+	}
 
+	seen := make(map[int]bool, len(option))
 	for _, opt := range option {
-		if opt < 0 || opt >= len(survey.options) {
-			return errors.New("Ungültige Option!")
+		if opt < 0 || opt >= len(survey.options) || seen[opt] {
+			return VoteEvent{}, ErrInvalidOption
+		}
+		seen[opt] = true
+	}
+	if ballot == "" && !survey.question.Ranked {
+		// a ballot selects a single option unless it is a multiple choice
+		// question, only a write-in is allowed without a selected option
+		if len(option) > 1 && !survey.question.Multiple {
+			return VoteEvent{}, ErrInvalidOption
+		}
+		if len(option) == 0 && writeIn == "" {
+			return VoteEvent{}, ErrInvalidOption
 		}
-		survey.options[opt].Votes++
 	}
 
-	survey.changed()
+	if survey.question.Ranked {
+		if err := checkRanking(option, len(survey.options)); err != nil {
+			return VoteEvent{}, err
+		}
+	}
 
-	return nil
+	if writeIn != "" {
+		if !survey.question.WriteIn || (!survey.question.Multiple && len(option) > 0) {
+			return VoteEvent{}, ErrInvalidOption
+		}
+	}
+
+	e := VoteEvent{Event: survey.event(), VoterId: voterId, Options: option}
+	if err := beforeVote(e); err != nil {
+		return VoteEvent{}, err
+	}
+
+	survey.votesCounted[voterId] = struct{}{}
+	if code != "" {
+		survey.accessCodes[code] = true
+	}
+	return e, nil
 }
 
 func (s *Surveys) HasVoted(surveyId SurveyId, voterId UserId) bool {
@@ -589,11 +1460,13 @@ func (s *Surveys) GetQuestion(surveyId SurveyId) Question {
 
 func (s *Surveys) startSurveyTimeoutCheck(timeOutInMin int) {
 	surveyTimeout := time.Duration(timeOutInMin) * time.Minute
+	s.timeout.Store(int64(surveyTimeout))
 	go func() {
 		log.Println("Starting survey cleanup routine, timeout", surveyTimeout)
 		for {
-			time.Sleep(surveyTimeout / 2)
-			deleted, remaining := s.cleanup(surveyTimeout)
+			// the cleanup also warns the creators, so it runs at least every minute
+			time.Sleep(min(s.defaultTimeout()/2, time.Minute))
+			deleted, remaining := s.cleanup(s.defaultTimeout())
 			if deleted > 0 {
 				log.Printf("Deleted %d old surveys, %d surveys remaining\n", deleted, remaining)
 			}
@@ -603,15 +1476,38 @@ func (s *Surveys) startSurveyTimeoutCheck(timeOutInMin int) {
 
 func (s *Surveys) cleanup(surveyTimeout time.Duration) (int, int) {
 	s.mutex.Lock()
-	defer s.mutex.Unlock()
 
-	deleteCount := 0
+	var expired []*Survey
 	for id, survey := range s.surveys {
-		if time.Since(survey.creationTime) > surveyTimeout {
+		survey.Lock()
+		if survey.mirror {
+			// the copies are deleted by the replication
+			survey.Unlock()
+			continue
+		}
+		expires := survey.lastActive().Add(survey.timeout(surveyTimeout))
+		if time.Now().After(expires) {
 			delete(s.surveys, id)
-			deleteCount++
+			expired = append(expired, survey)
+		} else if time.Until(expires) <= s.expiryWarning {
+			survey.warnExpiry(expires)
 		}
+		survey.Unlock()
+	}
+	remaining := len(s.surveys)
+	s.mutex.Unlock()
+	s.stats.expired.Add(int64(len(expired)))
+
+	s.drafts.cleanup(surveyTimeout)
+
+	for _, survey := range expired {
+		survey.Lock()
+		e := survey.finalEvent()
+		survey.waiters.close()
+		survey.subscribers.close()
+		survey.Unlock()
+		onExpire(e)
 	}
 
-	return deleteCount, len(s.surveys)
+	return len(expired), remaining
 }
@@ -1,8 +1,11 @@
 package survey
 
 import (
+	"flashSurvey/account"
+	"flashSurvey/randid"
 	"sync"
 	"testing"
+	"time"
 )
 import "github.com/stretchr/testify/assert"
 
@@ -30,7 +33,7 @@ var description = SurveyQuestion{
 }
 
 func voting(t *testing.T, s *Surveys, mainWg *sync.WaitGroup) {
-	userId := UserId(RandomString())
+	userId := UserId(randid.String())
 	sid, err := s.New(userId, "", description)
 	assert.NoError(t, err)
 
@@ -39,7 +42,7 @@ func voting(t *testing.T, s *Surveys, mainWg *sync.WaitGroup) {
 	for range voters {
 		wg.Add(1)
 		go func() {
-			voterId := UserId(RandomString())
+			voterId := UserId(randid.String())
 			<-start
 			err := s.Vote(sid, voterId, []int{1}, 1)
 			assert.NoError(t, err)
@@ -60,3 +63,156 @@ func voting(t *testing.T, s *Surveys, mainWg *sync.WaitGroup) {
 	s.Clear(sid, userId)
 	mainWg.Done()
 }
+
+func TestAccountCreator(t *testing.T) {
+	s := New("localhost", 30, false, false)
+	a := account.New("https://example.com")
+	s.SetAccounts(a)
+
+	device1 := UserId(randid.String())
+	device2 := UserId(randid.String())
+	sid, err := s.New(device1, "", description)
+	assert.NoError(t, err)
+
+	_, ok := s.GetRunningSurvey(device2, sid)
+	assert.False(t, ok)
+
+	for _, d := range []UserId{device1, device2} {
+		token, err := a.RequestMagicLink("creator@example.com")
+		assert.NoError(t, err)
+		_, err = a.ConfirmMagicLink(token, string(d))
+		assert.NoError(t, err)
+	}
+
+	_, ok = s.GetRunningSurvey(device2, sid)
+	assert.True(t, ok)
+	found, ok := s.SurveyOfCreator(device2)
+	assert.True(t, ok)
+	assert.EqualValues(t, sid, found)
+
+	a.Logout(string(device2))
+	_, ok = s.GetRunningSurvey(device2, sid)
+	assert.False(t, ok)
+}
+
+func TestPublicSurveys(t *testing.T) {
+	s := New("localhost", 30, false, false)
+
+	public := description
+	public.Public = true
+	first, err := s.New("user1", "", public)
+	assert.NoError(t, err)
+	_, err = s.New("user2", "", description)
+	assert.NoError(t, err)
+	second, err := s.New("user3", "", public)
+	assert.NoError(t, err)
+
+	// the newest survey is listed first
+	for i, sid := range []SurveyId{first, second} {
+		survey, _ := s.getSurveyToVote(sid)
+		survey.Lock()
+		survey.creationTime = time.Date(2024, 1, 1, i, 0, 0, 0, time.UTC)
+		survey.Unlock()
+	}
+
+	list := s.PublicSurveys()
+	assert.EqualValues(t, 2, len(list))
+	assert.EqualValues(t, second, list[0].SurveyId)
+	assert.EqualValues(t, first, list[1].SurveyId)
+
+	// uncovered surveys and surveys requiring a registration are not listed
+	assert.NoError(t, s.Uncover("user1", first))
+	_, err = s.RegisterVoters("user3", second, []string{"a@example.com"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, len(s.PublicSurveys()))
+}
+
+func TestResultSnapshot(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", description)
+	assert.NoError(t, err)
+
+	r1 := s.GetResult("creator", sid)
+	r2 := s.GetResult("creator", sid)
+	// the same version shares the same result
+	assert.Same(t, &r1.Result[0], &r2.Result[0])
+
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+	r3 := s.GetResult("creator", sid)
+	assert.Greater(t, r3.Version, r1.Version)
+	assert.EqualValues(t, 1, r3.Votes)
+
+	// holding the survey lock does not block reading the snapshot
+	survey, _ := s.getSurveyToVote(sid)
+	survey.Lock()
+	r4 := s.GetResult("creator", sid)
+	survey.Unlock()
+	assert.EqualValues(t, r3.Version, r4.Version)
+}
+
+func TestMaxSurveys(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	s.SetMaxSurveys(2)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B"}}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	_, err = s.New("creator", "", q)
+	assert.NoError(t, err)
+	_, err = s.New("creator", "", q)
+	assert.ErrorIs(t, err, ErrTooManySurveys)
+	assert.EqualError(t, err, "Sie können höchstens 2 Umfragen gleichzeitig durchführen!")
+
+	// an existing survey can still be updated, other users are not affected
+	_, err = s.New("creator", sid, q)
+	assert.NoError(t, err)
+	_, err = s.New("other", "", q)
+	assert.NoError(t, err)
+}
+
+func TestNewIdCollision(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B"}}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+
+	// the first id is already used, so another one is tried
+	ids := []string{string(sid), randid.String()}
+	s.newId = func() string {
+		id := ids[0]
+		ids = ids[1:]
+		return id
+	}
+	sid2, err := s.New("other", "", q)
+	assert.NoError(t, err)
+	assert.NotEqual(t, sid, sid2)
+	assert.Equal(t, 2, s.Count())
+
+	s.newId = func() string { return string(sid) }
+	_, err = s.New("other", "", q)
+	assert.Error(t, err)
+}
+
+func TestInvalidBallots(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", description)
+	assert.NoError(t, err)
+
+	// a single choice question accepts a single option only once
+	assert.ErrorIs(t, s.Vote(sid, "a", []int{0, 0, 0}, 1), ErrInvalidOption)
+	assert.ErrorIs(t, s.Vote(sid, "a", []int{0, 1}, 1), ErrInvalidOption)
+	assert.ErrorIs(t, s.Vote(sid, "a", []int{}, 1), ErrInvalidOption)
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+
+	multiple := description
+	multiple.Multiple = true
+	_, err = s.New("creator", sid, multiple)
+	assert.NoError(t, err)
+	assert.ErrorIs(t, s.Vote(sid, "a", []int{1, 1}, 2), ErrInvalidOption)
+	assert.NoError(t, s.Vote(sid, "a", []int{0, 1}, 2))
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.EqualValues(t, 1, r.Votes)
+	assert.EqualValues(t, 1, r.Result[0].votes)
+	assert.EqualValues(t, 1, r.Result[1].votes)
+}
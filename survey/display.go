@@ -0,0 +1,169 @@
+package survey
+
+import (
+	"fmt"
+	"html/template"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Display contains the presentation settings of the result
+type Display struct {
+	// SortByVotes shows the option with the most votes first
+	SortByVotes bool
+	HidePercent bool
+	HideCounts  bool
+	// Chart is the kind of chart, see Charts
+	Chart string
+	// ByGroup shows the votes aggregated by the groups of the options
+	ByGroup bool
+	// PercentBase is the base of the percentages of a multiple choice
+	// question, see PercentBases
+	PercentBase string
+	// Significance shows if the lead of the top option is statistically significant
+	Significance bool
+}
+
+// Charts contains the available kinds of charts, the empty string is the bar chart
+var Charts = []string{"", "pie", "donut"}
+
+// PercentBases contains the available bases of the percentages, the empty
+// string is the number of voters, "selections" the number of selected options
+var PercentBases = []string{"", "selections"}
+
+func (d Display) validate() error {
+	if !slices.Contains(PercentBases, d.PercentBase) {
+		return newError("invalidPercentBase")
+	}
+	for _, c := range Charts {
+		if d.Chart == c {
+			return nil
+		}
+	}
+	return newError("invalidChart")
+}
+
+// PerSelection returns true if the percentages are relative to the number
+// of selected options instead of the number of voters. So the percentages
+// of a multiple choice question add up to 100.
+func (d Display) PerSelection() bool {
+	return d.PercentBase == "selections"
+}
+
+// Pie returns true if the result is shown as a pie or donut chart
+func (d Display) Pie() bool {
+	return d.Chart == "pie" || d.Chart == "donut"
+}
+
+// palette contains the default colors of the options
+var palette = []string{"#4e79a7", "#f28e2b", "#e15759", "#76b7b2", "#59a14f", "#edc948", "#b07aa1", "#ff9da7", "#9c755f", "#bab0ac"}
+
+var colorRegex = regexp.MustCompile("^#[0-9a-fA-F]{6}$")
+
+// DefaultColor returns the default color of the option with the given index
+func DefaultColor(i int) string {
+	return palette[i%len(palette)]
+}
+
+// color returns the color of the option with the given index
+func (d SurveyQuestion) color(i int) (string, error) {
+	if i >= len(d.Colors) || d.Colors[i] == "" {
+		return DefaultColor(i), nil
+	}
+	if !colorRegex.MatchString(d.Colors[i]) {
+		return "", newError("invalidColor", i+1)
+	}
+	return strings.ToLower(d.Colors[i]), nil
+}
+
+// Color returns the color of the option
+func (o OptionResult) Color() string {
+	return o.color
+}
+
+// sortByVotes sorts the options by their votes, the original order is kept for equal votes
+func sortByVotes(res []OptionResult) {
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].votes > res[j].votes
+	})
+}
+
+// PieGradient returns the CSS background of the pie chart. The slices are
+// proportional to the votes of the options.
+func (r Result) PieGradient() template.CSS {
+	sum := 0
+	for _, o := range r.Rows() {
+		if o.votes < 0 {
+			return "lightgray"
+		}
+		sum += o.votes
+	}
+	if sum == 0 {
+		return "lightgray"
+	}
+	var b strings.Builder
+	b.WriteString("conic-gradient(")
+	start := 0.0
+	for i, o := range r.Rows() {
+		end := start + float64(o.votes)/float64(sum)*100
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(fmt.Sprintf("%s %.2f%% %.2f%%", o.color, start, end))
+		start = end
+	}
+	b.WriteString(")")
+	return template.CSS(b.String())
+}
+
+// group returns the group of the option with the given index
+func (d SurveyQuestion) group(i int) (string, error) {
+	if i >= len(d.Groups) {
+		return "", nil
+	}
+	g := strings.TrimSpace(d.Groups[i])
+	if len(g) > maxStringLen {
+		return "", newError("groupTooLong", i+1, maxStringLen)
+	}
+	return g, nil
+}
+
+// groups aggregates the votes of the options by their groups. The groups
+// are ordered by their first option, options without a group are kept.
+// The group has the color of its first option.
+func (o Options) groups() Options {
+	var groups Options
+	index := map[string]int{}
+	for _, option := range o {
+		if option.Group == "" {
+			groups = append(groups, option)
+			continue
+		}
+		if i, ok := index[option.Group]; ok {
+			groups[i].Votes += option.Votes
+			continue
+		}
+		index[option.Group] = len(groups)
+		groups = append(groups, Option{Title: option.Group, Votes: option.Votes, Color: option.Color, Group: option.Group})
+	}
+	return groups
+}
+
+// Rows returns the rows shown in the result view, these are the groups if
+// the votes are aggregated by group, otherwise the options.
+func (r Result) Rows() []OptionResult {
+	if r.Display.ByGroup && r.Groups != nil {
+		return r.Groups
+	}
+	return r.Result
+}
+
+// RowsMaxPercent returns the maximum percentage of the rows
+func (r Result) RowsMaxPercent() float64 {
+	if r.Display.ByGroup && r.Groups != nil {
+		return r.GroupMaxPercent
+	}
+	return r.MaxPercent
+}
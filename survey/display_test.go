@@ -0,0 +1,116 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisplay(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B", "C"}, Display: Display{SortByVotes: true, Chart: "pie"}}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+
+	assert.EqualValues(t, "lightgray", s.GetResult("creator", sid).PieGradient())
+
+	assert.NoError(t, s.Vote(sid, "a", []int{2}, 1))
+	assert.NoError(t, s.Vote(sid, "b", []int{2}, 1))
+	assert.NoError(t, s.Vote(sid, "c", []int{1}, 1))
+
+	// the order is kept while the result is hidden
+	r := s.GetResult("creator", sid)
+	assert.Equal(t, "A", r.Result[0].Title)
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	r = s.GetResult("creator", sid)
+	assert.Equal(t, []string{"C", "B", "A"}, []string{r.Result[0].Title, r.Result[1].Title, r.Result[2].Title})
+	// the colors stay with the options
+	assert.Equal(t, palette[2], r.Result[0].Color())
+	assert.EqualValues(t, "conic-gradient(#e15759 0.00% 66.67%, #f28e2b 66.67% 100.00%, #4e79a7 100.00% 100.00%)", r.PieGradient())
+
+	q.Display.Chart = "bubble"
+	_, err = s.New("creator", sid, q)
+	assert.Error(t, err)
+}
+
+func TestColors(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B"}, Colors: []string{"#FF0000"}}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+
+	r := s.GetResult("creator", sid)
+	assert.Equal(t, "#ff0000", r.Result[0].Color())
+	assert.Equal(t, DefaultColor(1), r.Result[1].Color())
+
+	q.Colors = []string{"red"}
+	_, err = s.New("creator", sid, q)
+	assert.Error(t, err)
+}
+
+func TestGroups(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{
+		Title:    "Priorities",
+		Options:  []string{"Login", "Database", "Layout", "Docs"},
+		Groups:   []string{"Frontend", "Backend", " Frontend "},
+		Multiple: true,
+		Display:  Display{ByGroup: true, SortByVotes: true},
+	}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{0, 2}, 1))
+	assert.NoError(t, s.Vote(sid, "b", []int{1}, 1))
+	assert.NoError(t, s.Uncover("creator", sid))
+
+	r := s.GetResult("creator", sid)
+	assert.Len(t, r.Result, 4)
+	rows := r.Rows()
+	assert.Len(t, rows, 3)
+	assert.Equal(t, "Frontend", rows[0].Title)
+	assert.EqualValues(t, 2, rows[0].VoteCount())
+	assert.Equal(t, "Backend", rows[1].Title)
+	assert.EqualValues(t, 1, rows[1].VoteCount())
+	assert.Equal(t, "Docs", rows[2].Title)
+	assert.EqualValues(t, 100, r.RowsMaxPercent())
+}
+
+func TestPercentBase(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B"}, Multiple: true}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{0, 1}, 1))
+	assert.NoError(t, s.Vote(sid, "b", []int{0}, 1))
+	assert.NoError(t, s.Uncover("creator", sid))
+
+	// by default the base is the number of voters
+	r := s.GetResult("creator", sid)
+	assert.Equal(t, "100.0", r.Result[0].Percent())
+	assert.Equal(t, "50.0", r.Result[1].Percent())
+	assert.Equal(t, 3, r.Selections)
+
+	q.Display.PercentBase = "selections"
+	_, err = s.New("creator", sid, q)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{0, 1}, 2))
+	assert.NoError(t, s.Vote(sid, "b", []int{0}, 2))
+	assert.NoError(t, s.Uncover("creator", sid))
+	r = s.GetResult("creator", sid)
+	assert.True(t, r.Display.PerSelection())
+	assert.Equal(t, "66.7", r.Result[0].Percent())
+	assert.Equal(t, "33.3", r.Result[1].Percent())
+	assert.Equal(t, 2, r.Votes)
+
+	// a single choice question always uses the voters
+	q.Multiple = false
+	_, err = s.New("creator", sid, q)
+	assert.NoError(t, err)
+	assert.False(t, s.GetResult("creator", sid).Display.PerSelection())
+
+	q.Multiple = true
+	q.Display.PercentBase = "options"
+	_, err = s.New("creator", sid, q)
+	assert.Error(t, err)
+}
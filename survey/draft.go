@@ -0,0 +1,74 @@
+package survey
+
+import (
+	"sync"
+	"time"
+)
+
+// maxDrafts is the maximum number of drafts stored at the same time
+const maxDrafts = 10000
+
+type draft struct {
+	question SurveyQuestion
+	saved    time.Time
+}
+
+// drafts contains the questions being edited in the create form by the user
+// ids, so they are not lost if the page is reloaded
+type drafts struct {
+	mutex sync.Mutex
+	byId  map[UserId]draft
+}
+
+// SaveDraft stores the question the user is editing. The draft is deleted
+// if the user starts the question or after the survey timeout.
+func (s *Surveys) SaveDraft(userId UserId, question SurveyQuestion) error {
+	if question.Encrypted() {
+		// the texts of an encrypted survey must not be sent to the server
+		return newError("draftEncrypted")
+	}
+	if s.tooManyOptions(len(question.Options)) {
+		return newError("tooManyOptions")
+	}
+
+	s.drafts.mutex.Lock()
+	defer s.drafts.mutex.Unlock()
+
+	if _, ok := s.drafts.byId[userId]; !ok && len(s.drafts.byId) >= maxDrafts {
+		return newError("tooManyDrafts")
+	}
+	if s.drafts.byId == nil {
+		s.drafts.byId = make(map[UserId]draft)
+	}
+	s.drafts.byId[userId] = draft{question: question, saved: time.Now()}
+	return nil
+}
+
+// Draft returns the question the user was editing
+func (s *Surveys) Draft(userId UserId) (SurveyQuestion, bool) {
+	s.drafts.mutex.Lock()
+	defer s.drafts.mutex.Unlock()
+
+	d, ok := s.drafts.byId[userId]
+	return d.question, ok
+}
+
+// DeleteDraft deletes the question the user was editing
+func (s *Surveys) DeleteDraft(userId UserId) {
+	s.drafts.mutex.Lock()
+	defer s.drafts.mutex.Unlock()
+
+	delete(s.drafts.byId, userId)
+}
+
+// cleanup deletes the drafts not saved within the timeout
+func (d *drafts) cleanup(timeout time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for id, dr := range d.byId {
+		if time.Since(dr.saved) > timeout {
+			delete(d.byId, id)
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package survey
+
+const (
+	// maxCipherLen is the maximum length of an encrypted title or option
+	maxCipherLen = 400
+	maxKeyLen    = 1024
+	maxBallotLen = 1024
+)
+
+// Encrypted returns true if the survey is end-to-end encrypted. In this case
+// the title and the options are encrypted by the creator's browser with a
+// key the server never sees, and the votes are encrypted by the voters'
+// browsers with the public key of the creator. The server only stores the
+// encrypted ballots, which are counted by the creator's browser.
+func (d SurveyQuestion) Encrypted() bool {
+	return d.PublicKey != ""
+}
+
+// VoteEncrypted counts the encrypted ballot of a voter of an end-to-end
+// encrypted survey.
+func (s *Surveys) VoteEncrypted(surveyId SurveyId, voterId UserId, ballot string, number int) error {
+	if ballot == "" || len(ballot) > maxBallotLen {
+		return ErrInvalidOption
+	}
+	e, err := s.vote(surveyId, voterId, nil, ballot, "", number)
+	if err != nil {
+		return err
+	}
+	afterVote(e)
+	return nil
+}
@@ -0,0 +1,57 @@
+package survey
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncrypted(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	cipher := strings.Repeat("A", 300)
+	def := SurveyQuestion{
+		Title:     cipher,
+		Options:   []string{cipher, "b"},
+		PublicKey: "key",
+		Announce:  true,
+	}
+	sid, err := s.New("creator", "", def)
+	assert.NoError(t, err)
+	q, _ := s.GetRunningSurvey("creator", sid)
+	assert.True(t, q.Encrypted())
+	assert.False(t, q.Announce)
+
+	// plain votes are not accepted
+	assert.Error(t, s.Vote(sid, "v1", []int{0}, 1))
+	assert.NoError(t, s.VoteEncrypted(sid, "v1", "ballot1", 1))
+	assert.NoError(t, s.VoteEncrypted(sid, "v2", "ballot2", 1))
+	assert.EqualError(t, s.VoteEncrypted(sid, "v2", "ballot3", 1), "Sie haben bereits abgestimmt!")
+	assert.Error(t, s.VoteEncrypted(sid, "v3", strings.Repeat("A", maxBallotLen+1), 1))
+
+	r := s.GetResult("creator", sid)
+	assert.True(t, r.Encrypted)
+	assert.EqualValues(t, 2, r.Votes)
+	assert.Nil(t, r.Ballots)
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	r = s.GetResult("creator", sid)
+	assert.EqualValues(t, []string{"ballot1", "ballot2"}, r.Ballots)
+	// the client needs to know if a ballot may select several options
+	assert.False(t, r.Multiple)
+
+	// the ballots are removed if the question changes
+	_, err = s.New("creator", sid, def)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Uncover("creator", sid))
+	assert.Nil(t, s.GetResult("creator", sid).Ballots)
+
+	// encrypted ballots are not accepted by plain surveys
+	plain, err := s.New("creator", "", SurveyQuestion{Title: "Q", Options: []string{"a", "b"}})
+	assert.NoError(t, err)
+	assert.Error(t, s.VoteEncrypted(plain, "v1", "ballot", 1))
+
+	def.Title = strings.Repeat("A", maxCipherLen+1)
+	_, err = s.New("creator", "", def)
+	assert.Error(t, err)
+}
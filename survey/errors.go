@@ -0,0 +1,57 @@
+package survey
+
+import "flashSurvey/i18n"
+
+// Error is an error which is shown to the creator or the voters. It is
+// identified by a code of the message catalog, so REST clients can
+// localize it.
+type Error struct {
+	// Code is the code of the message in the catalog
+	Code string
+	// Args are the values inserted into the message
+	Args []any
+}
+
+// The errors the handlers distinguish, they are compared by their code,
+// so errors.Is also matches an error with arguments.
+var (
+	ErrSurveyNotFound    = newError("surveyNotFound")
+	ErrNotOwner          = newError("notCreator")
+	ErrCreateNotAllowed  = newError("createNotAllowed")
+	ErrSurveyOfOtherUser = newError("surveyOfOtherUser")
+	ErrLocked            = newError("lockedRestart")
+	ErrLockedReset       = newError("lockedReset")
+	ErrRoundClosed       = newError("surveyEnded")
+	ErrVotingTimeElapsed = newError("votingTimeElapsed")
+	ErrResultVisible     = newError("resultVisible")
+	ErrAlreadyVoted      = newError("alreadyVoted")
+	ErrDeviceVoted       = newError("alreadyVotedDevice")
+	ErrNotRegistered     = newError("notRegistered")
+	ErrCodeRequired      = newError("codeRequired")
+	ErrCodeInvalid       = newError("codeInvalid")
+	ErrInvalidOption     = newError("invalidOption")
+	ErrNotEnoughVotes    = newError("notEnoughVotes")
+	ErrAnswerNotFound    = newError("answerNotFound")
+	ErrTooManySurveys    = newError("tooManySurveys")
+	ErrInvalidAdvance    = newError("invalidAdvance")
+)
+
+func newError(code string, args ...any) *Error {
+	return &Error{Code: code, Args: args}
+}
+
+// Error returns the german message
+func (e *Error) Error() string {
+	return e.Localize(i18n.Default)
+}
+
+// Is returns true if the target is an Error with the same code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Localize returns the message in the given language
+func (e *Error) Localize(lang string) string {
+	return i18n.Message(lang, e.Code, e.Args...)
+}
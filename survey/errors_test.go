@@ -0,0 +1,69 @@
+package survey
+
+import (
+	"errors"
+	"flashSurvey/i18n"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestErrorCodes checks that all codes used by the package are contained
+// in the message catalog
+func TestErrorCodes(t *testing.T) {
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+	codeRegex := regexp.MustCompile(`newError\("([^"]+)"`)
+	found := 0
+	for _, f := range files {
+		src, err := os.ReadFile(f)
+		require.NoError(t, err)
+		for _, m := range codeRegex.FindAllStringSubmatch(string(src), -1) {
+			assert.True(t, i18n.Has(m[1]), "code %s used in %s is not in the catalog", m[1], f)
+			found++
+		}
+	}
+	assert.Greater(t, found, 0)
+}
+
+func TestErrorIs(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	assert.ErrorIs(t, s.Vote("unknown", "voter", []int{0}, 1), ErrSurveyNotFound)
+
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+	// the surveys of other users are not found
+	assert.ErrorIs(t, s.Uncover("other", sid), ErrSurveyNotFound)
+	assert.ErrorIs(t, s.Vote(sid, "voter", []int{5}, 1), ErrInvalidOption)
+	assert.ErrorIs(t, s.Vote(sid, "voter", []int{0}, 2), ErrRoundClosed)
+
+	// the arguments are not compared
+	assert.ErrorIs(t, newError("maxOptions", 5), newError("maxOptions"))
+}
+
+func TestErrorLocalize(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+	require.NoError(t, s.Vote(sid, "voter", []int{0}, 1))
+
+	err = s.Vote(sid, "voter", []int{1}, 1)
+	var se *Error
+	require.True(t, errors.As(err, &se))
+	assert.Equal(t, "alreadyVoted", se.Code)
+	assert.ErrorIs(t, err, ErrAlreadyVoted)
+	assert.NotErrorIs(t, err, ErrRoundClosed)
+	assert.EqualError(t, err, "Sie haben bereits abgestimmt!")
+	assert.Equal(t, "You have already voted!", se.Localize("en"))
+
+	_, err = s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "a"}})
+	var ve ValidationError
+	require.True(t, errors.As(err, &ve))
+	assert.Equal(t, "optionDuplicate", ve[0].Code)
+	assert.Equal(t, "Option 2 is identical to option 1!", ve.Localize("en"))
+	assert.Equal(t, "Option 2 ist identisch mit Option 1!", ve.Field("option1"))
+}
@@ -0,0 +1,78 @@
+package survey
+
+import "time"
+
+// defaultExpiryWarning is the time before the timeout the creator is warned
+const defaultExpiryWarning = 5 * time.Minute
+
+// SetExpiryWarning sets the time before the timeout of a survey the creator
+// is warned. Must be called before the surveys are used.
+func (s *Surveys) SetExpiryWarning(warning time.Duration) {
+	s.expiryWarning = warning
+}
+
+// lastActive returns the time the timeout of the survey starts. The survey must be locked.
+func (s *Survey) lastActive() time.Time {
+	t := s.creationTime
+	if s.scheduledTime.After(t) {
+		t = s.scheduledTime
+	}
+	if s.activeTime.After(t) {
+		t = s.activeTime
+	}
+	return t
+}
+
+// warnExpiry marks the survey as expiring at the given time, so the
+// creator views are able to show a warning. The survey must be locked.
+func (s *Survey) warnExpiry(expires time.Time) {
+	if s.expires.Equal(expires) {
+		return
+	}
+	s.expires = expires
+	s.changed()
+}
+
+// Expires returns the time the survey is deleted if the creator has been
+// warned, otherwise the zero time is returned.
+func (s *Surveys) Expires(userId UserId, surveyId SurveyId) time.Time {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return time.Time{}
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	return survey.expires
+}
+
+// Extend restarts the timeout of the survey
+func (s *Surveys) Extend(userId UserId, surveyId SurveyId) error {
+	return s.activity(userId, surveyId)
+}
+
+// Heartbeat is sent periodically by the open result pages of the survey.
+// It restarts the timeout, so a survey is not deleted while its result
+// is shown.
+func (s *Surveys) Heartbeat(userId UserId, surveyId SurveyId) error {
+	return s.activity(userId, surveyId)
+}
+
+func (s *Surveys) activity(userId UserId, surveyId SurveyId) error {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if !s.mayControl(survey, userId) {
+		return ErrNotOwner
+	}
+
+	survey.activeTime = time.Now()
+	survey.warnExpiry(time.Time{})
+	return nil
+}
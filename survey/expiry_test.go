@@ -0,0 +1,60 @@
+package survey
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpiryWarning(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	deleted, _ := s.cleanup(30 * time.Minute)
+	assert.Equal(t, 0, deleted)
+	assert.True(t, s.Expires("creator", sid).IsZero())
+
+	survey, _ := s.getSurveyToVote(sid)
+	survey.Lock()
+	survey.creationTime = time.Now().Add(-27 * time.Minute)
+	survey.Unlock()
+	version := s.GetResult("creator", sid).Version
+
+	deleted, _ = s.cleanup(30 * time.Minute)
+	assert.Equal(t, 0, deleted)
+	expires := s.Expires("creator", sid)
+	assert.WithinDuration(t, time.Now().Add(3*time.Minute), expires, time.Second)
+	r := s.GetResult("creator", sid)
+	assert.Equal(t, expires, r.Expires)
+	// the result pages are woken up
+	assert.Greater(t, r.Version, version)
+
+	assert.Error(t, s.Extend("other", sid))
+	assert.NoError(t, s.Extend("creator", sid))
+	assert.True(t, s.Expires("creator", sid).IsZero())
+	assert.True(t, s.GetResult("creator", sid).Expires.IsZero())
+
+	deleted, _ = s.cleanup(30 * time.Minute)
+	assert.Equal(t, 0, deleted)
+	assert.True(t, s.Expires("creator", sid).IsZero())
+}
+
+func TestHeartbeat(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	survey, _ := s.getSurveyToVote(sid)
+	survey.Lock()
+	survey.creationTime = time.Now().Add(-45 * time.Minute)
+	survey.Unlock()
+
+	assert.Error(t, s.Heartbeat("other", sid))
+	assert.NoError(t, s.Heartbeat("creator", sid))
+
+	deleted, _ := s.cleanup(30 * time.Minute)
+	assert.Equal(t, 0, deleted)
+	assert.True(t, s.Expires("creator", sid).IsZero())
+}
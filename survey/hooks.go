@@ -0,0 +1,187 @@
+package survey
+
+import (
+	"log"
+	"sync"
+)
+
+// Event describes the survey an event belongs to
+type Event struct {
+	SurveyId SurveyId
+	// Creator is the user id of the creator of the survey
+	Creator UserId
+	// Number is incremented every time the question changes
+	Number   int
+	Question SurveyQuestion
+}
+
+// VoteEvent describes a vote
+type VoteEvent struct {
+	Event
+	VoterId UserId
+	Options []int
+	// Result is only available in AfterVote
+	Result Result
+}
+
+// ResultEvent describes a survey together with its result
+type ResultEvent struct {
+	Event
+	Result Result
+	// Rounds contains the final results of all questions of the survey,
+	// the current one is the last. It is only available in OnExpire and OnClose.
+	Rounds []Round
+}
+
+// Hooks allows to add custom logic to the life cycle of the surveys without
+// modifying the handlers. All functions are optional.
+// BeforeVote is called while the survey is locked, so it must not call any
+// methods of Surveys. All other functions are called after the survey is
+// unlocked.
+type Hooks struct {
+	// OnStart is called if a new question is started
+	OnStart func(e Event)
+	// BeforeVote is called before a vote is counted. If it returns an error,
+	// the vote is rejected and the error is shown to the voter.
+	BeforeVote func(e VoteEvent) error
+	// AfterVote is called after a vote has been counted
+	AfterVote func(e VoteEvent)
+	// AfterUncover is called after the result has been made visible for
+	// the first time, it is not called again if the result was hidden and
+	// is uncovered again
+	AfterUncover func(e ResultEvent)
+	// OnVisibility is called every time the result is uncovered or hidden
+	// again, the result of the event tells if it is visible
+	OnVisibility func(e ResultEvent)
+	// OnExpire is called if a survey is deleted because of the timeout
+	OnExpire func(e ResultEvent)
+	// OnAnnotate is called after the creator has annotated the result
+	OnAnnotate func(e ResultEvent)
+	// OnClose is called if the creator deletes a survey
+	OnClose func(e ResultEvent)
+}
+
+var registered struct {
+	mutex sync.RWMutex
+	hooks []*Hooks
+}
+
+// RegisterHooks registers hooks which are called on survey events. This is
+// usually done in the init function of a package which is compiled into the
+// binary. The hooks are called in the order of their registration.
+// The returned function removes the hooks again, it is used by the tests.
+func RegisterHooks(h Hooks) func() {
+	registered.mutex.Lock()
+	defer registered.mutex.Unlock()
+	p := &h
+	registered.hooks = append(registered.hooks, p)
+	return func() {
+		registered.mutex.Lock()
+		defer registered.mutex.Unlock()
+		// the slice is replaced, because it may be used by a running event
+		var hooks []*Hooks
+		for _, r := range registered.hooks {
+			if r != p {
+				hooks = append(hooks, r)
+			}
+		}
+		registered.hooks = hooks
+	}
+}
+
+func registeredHooks() []*Hooks {
+	registered.mutex.RLock()
+	defer registered.mutex.RUnlock()
+	return registered.hooks
+}
+
+func (s *Survey) event() Event {
+	return Event{
+		SurveyId: s.surveyId,
+		Creator:  s.userId,
+		Number:   s.number,
+		Question: s.question,
+	}
+}
+
+func (s *Survey) resultEvent() ResultEvent {
+	return ResultEvent{Event: s.event(), Result: s.Result()}
+}
+
+func onStart(e Event) {
+	for _, h := range registeredHooks() {
+		if h.OnStart != nil {
+			h.OnStart(e)
+		}
+	}
+}
+
+func beforeVote(e VoteEvent) error {
+	for _, h := range registeredHooks() {
+		if h.BeforeVote != nil {
+			if err := h.BeforeVote(e); err != nil {
+				log.Println("vote rejected by hook:", err)
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// afterVoteRegistered returns true if there is an AfterVote hook
+func afterVoteRegistered() bool {
+	for _, h := range registeredHooks() {
+		if h.AfterVote != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func afterVote(e VoteEvent) {
+	for _, h := range registeredHooks() {
+		if h.AfterVote != nil {
+			h.AfterVote(e)
+		}
+	}
+}
+
+func afterUncover(e ResultEvent) {
+	for _, h := range registeredHooks() {
+		if h.AfterUncover != nil {
+			h.AfterUncover(e)
+		}
+	}
+}
+
+func onVisibility(e ResultEvent) {
+	for _, h := range registeredHooks() {
+		if h.OnVisibility != nil {
+			h.OnVisibility(e)
+		}
+	}
+}
+
+func onAnnotate(e ResultEvent) {
+	for _, h := range registeredHooks() {
+		if h.OnAnnotate != nil {
+			h.OnAnnotate(e)
+		}
+	}
+}
+
+func onClose(e ResultEvent) {
+	for _, h := range registeredHooks() {
+		if h.OnClose != nil {
+			h.OnClose(e)
+		}
+	}
+}
+
+func onExpire(e ResultEvent) {
+	for _, h := range registeredHooks() {
+		if h.OnExpire != nil {
+			h.OnExpire(e)
+		}
+	}
+}
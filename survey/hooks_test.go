@@ -0,0 +1,70 @@
+package survey
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHooks(t *testing.T) {
+	t.Cleanup(func() { registered.hooks = nil })
+
+	var started, votes, uncovered, expired int
+	RegisterHooks(Hooks{
+		OnStart: func(e Event) { started++ },
+		BeforeVote: func(e VoteEvent) error {
+			if e.VoterId == "blocked" {
+				return errors.New("blocked")
+			}
+			return nil
+		},
+		AfterVote: func(e VoteEvent) { votes++ },
+		AfterUncover: func(e ResultEvent) {
+			uncovered++
+			assert.EqualValues(t, 3, e.Result.Votes)
+		},
+		OnExpire: func(e ResultEvent) { expired++ },
+	})
+
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", description)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, started)
+
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+	assert.NoError(t, s.Vote(sid, "b", []int{1}, 1))
+	assert.Error(t, s.Vote(sid, "blocked", []int{1}, 1))
+	assert.NoError(t, s.Vote(sid, "c", []int{1}, 1))
+	assert.EqualValues(t, 3, votes)
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	assert.EqualValues(t, 1, uncovered)
+
+	_, err = s.New("creator", sid, description)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, started)
+
+	deleted, _ := s.cleanup(-time.Second)
+	assert.EqualValues(t, 1, deleted)
+	assert.EqualValues(t, 1, expired)
+}
+
+func TestUnregisterHooks(t *testing.T) {
+	t.Cleanup(func() { registered.hooks = nil })
+
+	var first, second int
+	unregister := RegisterHooks(Hooks{OnStart: func(e Event) { first++ }})
+	RegisterHooks(Hooks{OnStart: func(e Event) { second++ }})
+
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", description)
+	assert.NoError(t, err)
+
+	unregister()
+	_, err = s.New("creator", sid, description)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, first)
+	assert.EqualValues(t, 2, second)
+}
@@ -0,0 +1,26 @@
+package survey
+
+import "slices"
+
+// SetHosts sets the hosts approved by the operator which the creators
+// can select as the host of the vote links and QR codes, e.g. a short
+// domain of an event. Must be called before the surveys are used.
+func (s *Surveys) SetHosts(hosts []string) {
+	s.hosts = hosts
+}
+
+// Hosts returns the hosts the creators can select
+func (s *Surveys) Hosts() []string {
+	return s.hosts
+}
+
+// voteHost returns the host used in the vote links of the question
+func (s *Surveys) voteHost(def SurveyQuestion) (string, error) {
+	if def.Host == "" || def.Host == s.host {
+		return s.host, nil
+	}
+	if !slices.Contains(s.hosts, def.Host) {
+		return "", newError("hostNotAllowed")
+	}
+	return def.Host, nil
+}
@@ -0,0 +1,40 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostOverride(t *testing.T) {
+	s := New("https://survey.example", 30, false, true)
+	s.SetHosts([]string{"https://vote.conf.example"})
+
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	survey, _ := s.getSurveyToVote(sid)
+	defaultQR := survey.qrCode
+	assert.Equal(t, "https://survey.example", survey.host)
+
+	_, err = s.New("creator", sid, SurveyQuestion{Title: "Test", Options: []string{"A", "B"}, Host: "https://evil.example"})
+	var verr ValidationError
+	assert.ErrorAs(t, err, &verr)
+	assert.NotEmpty(t, verr.Field("host"))
+
+	_, err = s.New("creator", sid, SurveyQuestion{Title: "Test", Options: []string{"A", "B"}, Host: "https://vote.conf.example"})
+	assert.NoError(t, err)
+	survey.Lock()
+	assert.Equal(t, "https://vote.conf.example", survey.host)
+	assert.NotEqual(t, defaultQR, survey.qrCode)
+	survey.Unlock()
+
+	voters, err := s.RegisterVoters("creator", sid, []string{"a@example.com"})
+	assert.NoError(t, err)
+	assert.Contains(t, voters[0].URL, "https://vote.conf.example/vote/?id="+string(sid))
+
+	_, err = s.New("creator", sid, SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	survey.Lock()
+	assert.Equal(t, defaultQR, survey.qrCode)
+	survey.Unlock()
+}
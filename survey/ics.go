@@ -0,0 +1,85 @@
+package survey
+
+import (
+	"bytes"
+	"strings"
+	"time"
+)
+
+const icsTimeFormat = "20060102T150405Z"
+
+// Schedule marks the survey to take place at the given time.
+// A scheduled survey is not deleted by the cleanup routine before
+// the scheduled time plus the timeout has passed.
+func (s *Surveys) Schedule(userId UserId, surveyId SurveyId, start time.Time) error {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	survey.scheduledTime = start
+	return nil
+}
+
+// CalendarInvite creates an ICS file containing an event which can be sent
+// to the participants ahead of time. The event contains the URL to join the
+// survey and the QR code as an attached image.
+func (s *Surveys) CalendarInvite(userId UserId, surveyId SurveyId, start time.Time, duration time.Duration) ([]byte, error) {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return nil, ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	url := voteLink(survey.host, surveyId, survey.voteKey)
+
+	var b bytes.Buffer
+	writeIcsLine(&b, "BEGIN:VCALENDAR")
+	writeIcsLine(&b, "VERSION:2.0")
+	writeIcsLine(&b, "PRODID:-//flashSurvey//DE")
+	writeIcsLine(&b, "METHOD:PUBLISH")
+	writeIcsLine(&b, "BEGIN:VEVENT")
+	writeIcsLine(&b, "UID:"+string(surveyId)+"-"+start.UTC().Format(icsTimeFormat)+"@flashSurvey")
+	writeIcsLine(&b, "DTSTAMP:"+time.Now().UTC().Format(icsTimeFormat))
+	writeIcsLine(&b, "DTSTART:"+start.UTC().Format(icsTimeFormat))
+	writeIcsLine(&b, "DTEND:"+start.Add(duration).UTC().Format(icsTimeFormat))
+	writeIcsLine(&b, "SUMMARY:"+icsEscape("Umfrage: "+survey.question.Title))
+	writeIcsLine(&b, "DESCRIPTION:"+icsEscape("Zur Teilnahme an der Umfrage bitte folgenden Link öffnen:\n"+url))
+	writeIcsLine(&b, "URL:"+url)
+	writeIcsLine(&b, "ATTACH;FMTTYPE=image/png;ENCODING=BASE64;VALUE=BINARY;X-FILENAME=qrcode.png:"+survey.qrCode)
+	writeIcsLine(&b, "END:VEVENT")
+	writeIcsLine(&b, "END:VCALENDAR")
+
+	return b.Bytes(), nil
+}
+
+// icsEscape escapes a text value as required by RFC 5545
+func icsEscape(str string) string {
+	str = strings.ReplaceAll(str, "\\", "\\\\")
+	str = strings.ReplaceAll(str, ";", "\\;")
+	str = strings.ReplaceAll(str, ",", "\\,")
+	str = strings.ReplaceAll(str, "\n", "\\n")
+	return str
+}
+
+// writeIcsLine writes a content line and folds it after 75 octets as
+// required by RFC 5545. Multibyte characters are not split.
+func writeIcsLine(b *bytes.Buffer, line string) {
+	const maxLen = 75
+	n := 0
+	for _, r := range line {
+		l := len(string(r))
+		if n+l > maxLen {
+			b.WriteString("\r\n ")
+			n = 1
+		}
+		b.WriteRune(r)
+		n += l
+	}
+	b.WriteString("\r\n")
+}
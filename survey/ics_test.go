@@ -0,0 +1,34 @@
+package survey
+
+import (
+	"flashSurvey/randid"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalendarInvite(t *testing.T) {
+	s := New("https://example.com", 30, false, false)
+	userId := UserId(randid.String())
+	sid, err := s.New(userId, "", description)
+	assert.NoError(t, err)
+
+	start := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	assert.NoError(t, s.Schedule(userId, sid, start))
+
+	ics, err := s.CalendarInvite(userId, sid, start, time.Hour)
+	assert.NoError(t, err)
+
+	str := string(ics)
+	assert.Contains(t, str, "DTSTART:20250301T100000Z\r\n")
+	assert.Contains(t, str, "DTEND:20250301T110000Z\r\n")
+	assert.Contains(t, str, "ATTACH;FMTTYPE=image/png")
+	for _, line := range strings.Split(str, "\r\n") {
+		assert.LessOrEqual(t, len(line), 75)
+	}
+
+	_, err = s.CalendarInvite(UserId(randid.String()), sid, start, time.Hour)
+	assert.Error(t, err)
+}
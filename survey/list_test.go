@@ -0,0 +1,34 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListByUser(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	a, err := s.New("creator", "", SurveyQuestion{Title: "A", Options: []string{"1", "2"}})
+	assert.NoError(t, err)
+	b, err := s.New("creator", "", SurveyQuestion{Title: "B", Options: []string{"1", "2"}})
+	assert.NoError(t, err)
+	_, err = s.New("other", "", SurveyQuestion{Title: "C", Options: []string{"1", "2"}})
+	assert.NoError(t, err)
+	assert.NotEqual(t, a, b)
+
+	assert.NoError(t, s.Vote(a, "v", []int{0}, 1))
+
+	list := s.ListByUser("creator")
+	assert.Len(t, list, 2)
+	votes := map[SurveyId]int{}
+	for _, i := range list {
+		votes[i.Id] = i.Votes
+	}
+	assert.Equal(t, map[SurveyId]int{a: 1, b: 0}, votes)
+
+	s.Clear(a, "creator")
+	list = s.ListByUser("creator")
+	assert.Len(t, list, 1)
+	assert.Equal(t, b, list[0].Id)
+	assert.Equal(t, "B", list[0].Title)
+}
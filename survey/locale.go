@@ -0,0 +1,88 @@
+package survey
+
+import (
+	"strconv"
+	"strings"
+)
+
+// numberFormat contains the separators used to format numbers in a locale
+type numberFormat struct {
+	decimal string
+	group   string
+}
+
+// numberFormats contains the number formats of the supported locales,
+// numbers are formatted without grouping if the locale is not known
+var numberFormats = map[string]numberFormat{
+	"de": {decimal: ",", group: "."},
+	"en": {decimal: ".", group: ","},
+}
+
+// DecimalComma returns true if the given locale uses a comma as decimal
+// separator
+func DecimalComma(locale string) bool {
+	return numberFormats[locale].decimal == ","
+}
+
+// FormatNumber formats the number with the given number of decimal places
+// using the decimal and grouping separators of the given locale. A
+// negative prec uses the smallest number of digits necessary.
+func FormatNumber(f float64, prec int, locale string) string {
+	str := strconv.FormatFloat(f, 'f', prec, 64)
+	nf, ok := numberFormats[locale]
+	if !ok {
+		return str
+	}
+
+	sign := ""
+	if strings.HasPrefix(str, "-") {
+		sign = "-"
+		str = str[1:]
+	}
+	intPart, frac, hasFrac := strings.Cut(str, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, c := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(nf.group)
+		}
+		b.WriteRune(c)
+	}
+	if hasFrac {
+		b.WriteString(nf.decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// Localize returns a copy of the result which formats its numbers
+// according to the given locale. The result itself is not modified
+// because it is shared by all callers.
+func (r Result) Localize(locale string) Result {
+	r.Result = localizeOptions(r.Result, locale)
+	r.Groups = localizeOptions(r.Groups, locale)
+	if r.Rating != nil {
+		rating := *r.Rating
+		rating.locale = locale
+		r.Rating = &rating
+	}
+	if r.Lead != nil {
+		lead := *r.Lead
+		lead.locale = locale
+		r.Lead = &lead
+	}
+	return r
+}
+
+func localizeOptions(options []OptionResult, locale string) []OptionResult {
+	if options == nil {
+		return nil
+	}
+	l := make([]OptionResult, len(options))
+	for i, o := range options {
+		o.locale = locale
+		l[i] = o
+	}
+	return l
+}
@@ -0,0 +1,47 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		f      float64
+		prec   int
+		locale string
+		want   string
+	}{
+		{66.666, 1, "", "66.7"},
+		{66.666, 1, "de", "66,7"},
+		{66.666, 1, "en", "66.7"},
+		{1234567, 0, "de", "1.234.567"},
+		{1234567, 0, "en", "1,234,567"},
+		{1234.5, 1, "de", "1.234,5"},
+		{123, 0, "de", "123"},
+		{-1234.5, -1, "en", "-1,234.5"},
+		{1234, 0, "fr", "1234"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, FormatNumber(tt.f, tt.prec, tt.locale))
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B", "C"}, Rating: 3})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+	assert.NoError(t, s.Vote(sid, "b", []int{1}, 1))
+	assert.NoError(t, s.Vote(sid, "c", []int{1}, 1))
+	assert.NoError(t, s.Uncover("creator", sid))
+
+	r := s.GetResult("creator", sid)
+	l := r.Localize("de")
+	assert.Equal(t, "66,7", l.Result[1].Percent())
+	assert.Equal(t, "1,7", l.Rating.MeanString())
+	// the shared result is not modified
+	assert.Equal(t, "66.7", r.Result[1].Percent())
+	assert.Equal(t, "1.7", r.Rating.MeanString())
+}
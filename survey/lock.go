@@ -0,0 +1,30 @@
+package survey
+
+// SetLocked locks or unlocks the survey. A locked survey can not be replaced
+// by a new question, which would delete all votes, until it is unlocked.
+// Corrections of the texts are still possible.
+func (s *Surveys) SetLocked(userId UserId, surveyId SurveyId, locked bool) error {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if !s.mayControl(survey, userId) {
+		return ErrNotOwner
+	}
+
+	survey.locked.Store(locked)
+	return nil
+}
+
+// IsLocked returns true if the survey is locked
+func (s *Surveys) IsLocked(userId UserId, surveyId SurveyId) bool {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return false
+	}
+	return survey.locked.Load()
+}
@@ -0,0 +1,34 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocked(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B"}}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{1}, 1))
+
+	assert.Error(t, s.SetLocked("other", sid, true))
+	assert.False(t, s.IsLocked("creator", sid))
+	assert.NoError(t, s.SetLocked("creator", sid, true))
+	assert.True(t, s.IsLocked("creator", sid))
+
+	// the votes are not deleted
+	_, err = s.New("creator", sid, q)
+	assert.Error(t, err)
+	assert.Equal(t, 1, s.GetQuestion(sid).Number)
+	assert.True(t, s.HasVoted(sid, "a"))
+
+	// corrections are still possible
+	assert.NoError(t, s.Correct("creator", sid, "Test!", []string{"A", "B"}))
+
+	assert.NoError(t, s.SetLocked("creator", sid, false))
+	_, err = s.New("creator", sid, q)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, s.GetQuestion(sid).Number)
+}
@@ -0,0 +1,171 @@
+package survey
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Replica is the state of a survey sent to the read replicas
+type Replica struct {
+	Question Question
+	// Result is the result as seen by the creator, its version is -1 if
+	// the survey does not exist anymore
+	Result  Result
+	Creator UserId
+	// ViewerToken is the viewer token of the survey, so the viewers can
+	// use the replicas, it is empty if the survey is encrypted
+	ViewerToken string
+}
+
+// Replicate waits until the survey has a version higher than the given
+// version, the timeout has elapsed or the context is canceled, and returns
+// the state of the survey.
+func (s *Surveys) Replicate(ctx context.Context, surveyId SurveyId, version int, timeout time.Duration) (Replica, error) {
+	deleted := Replica{Result: Result{Version: -1}}
+	survey, exists := s.getSurveyToVote(surveyId)
+	if !exists {
+		return deleted, nil
+	}
+	survey.Lock()
+	creator := survey.userId
+	survey.Unlock()
+
+	err := s.Wait(ctx, creator, surveyId, version, timeout)
+	if err != nil {
+		return Replica{}, err
+	}
+
+	survey, exists = s.getSurveyToVote(surveyId)
+	if !exists {
+		return deleted, nil
+	}
+	survey.Lock()
+	defer survey.Unlock()
+
+	r := Replica{
+		Question: survey.Question(),
+		Result:   survey.Result(),
+		Creator:  survey.userId,
+	}
+	if !survey.question.Encrypted() {
+		r.ViewerToken = s.viewerToken(surveyId)
+	}
+	return r, nil
+}
+
+// Mirror creates or updates the copy of a survey of the primary instance
+// on a read replica. The copy only shows the result of the primary, the
+// votes have to be forwarded to the primary. If the version of the result
+// is negative, the copy is deleted. The copies are neither deleted by the
+// timeout nor do they trigger any hooks.
+func (s *Surveys) Mirror(surveyId SurveyId, r Replica) {
+	if r.Result.Version < 0 {
+		s.mutex.Lock()
+		survey, exists := s.surveys[surveyId]
+		if exists && survey.mirror {
+			delete(s.surveys, surveyId)
+		}
+		s.mutex.Unlock()
+		if exists && survey.mirror {
+			survey.Lock()
+			survey.waiters.close()
+			survey.subscribers.close()
+			survey.Unlock()
+		}
+		return
+	}
+
+	s.mutex.Lock()
+	survey, exists := s.surveys[surveyId]
+	if !exists {
+		survey = &Survey{
+			surveyId:     surveyId,
+			mirror:       true,
+			votesCounted: make(map[UserId]struct{}),
+			waiters:      newWaiters(),
+		}
+		s.surveys[surveyId] = survey
+	}
+	if !survey.mirror {
+		s.mutex.Unlock()
+		return
+	}
+
+	// the creator is read while only the surveys mutex is held, so both
+	// locks are needed to update it, see isCreator
+	survey.Lock()
+	defer survey.Unlock()
+
+	if r.Result.Version <= survey.version {
+		s.mutex.Unlock()
+		return
+	}
+	survey.userId = r.Creator
+	s.mutex.Unlock()
+	result := r.Result
+	survey.question = r.Question.Question
+	survey.number = r.Question.Number
+	survey.revision = r.Question.Revision
+	survey.voteKey = r.Question.VoteKey
+	survey.thankYou = r.Question.ThankYou
+	survey.setSeries(r.Question.Series)
+	// the codes are checked by the primary
+	survey.accessCodes = nil
+	if r.Question.CodeRequired {
+		survey.accessCodes = map[string]bool{}
+	}
+	// the tokens of the registered voters are checked by the primary
+	survey.voterTokens = nil
+	if r.Question.TokenRequired {
+		survey.voterTokens = map[UserId]struct{}{}
+	}
+	survey.mirroredViewerToken = r.ViewerToken
+	survey.creationTime = result.Started
+	survey.resultHidden = result.Covered()
+	survey.version = result.Version
+	survey.snapshot.Store(&result)
+	survey.waiters.release()
+	survey.subscribers.notify(QuestionChange{Number: survey.number, Revision: survey.revision})
+}
+
+// IsMirror returns true if the survey is a copy of a survey of the primary instance
+func (s *Surveys) IsMirror(surveyId SurveyId) bool {
+	survey, exists := s.getSurveyToVote(surveyId)
+	return exists && survey.mirror
+}
+
+// optionResultJSON is used to transfer the results to the replicas
+type optionResultJSON struct {
+	Title       string
+	Votes       int
+	Percent     float64
+	Color       string  `json:",omitempty"`
+	AverageRank float64 `json:",omitempty"`
+}
+
+func (o OptionResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal(optionResultJSON{
+		Title:       o.Title,
+		Votes:       o.votes,
+		Percent:     o.percent,
+		Color:       o.color,
+		AverageRank: o.averageRank,
+	})
+}
+
+func (o *OptionResult) UnmarshalJSON(data []byte) error {
+	var j optionResultJSON
+	err := json.Unmarshal(data, &j)
+	if err != nil {
+		return err
+	}
+	*o = OptionResult{
+		Title:       j.Title,
+		votes:       j.Votes,
+		percent:     j.Percent,
+		color:       j.Color,
+		averageRank: j.AverageRank,
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package survey
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirror(t *testing.T) {
+	primary := New("localhost", 30, false, true)
+	sid, err := primary.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	assert.NoError(t, primary.Vote(sid, "a", []int{1}, 1))
+
+	state, err := primary.Replicate(context.Background(), sid, 0, time.Second)
+	assert.NoError(t, err)
+	// the state is sent as JSON
+	data, err := json.Marshal(state)
+	assert.NoError(t, err)
+	var received Replica
+	assert.NoError(t, json.Unmarshal(data, &received))
+
+	replica := New("localhost", 30, false, true)
+	replica.Mirror(sid, received)
+	assert.True(t, replica.IsMirror(sid))
+	assert.Equal(t, received.Result, replica.GetResult("creator", sid))
+	assert.Equal(t, -1, replica.GetResult("creator", sid).Result[1].VoteCount())
+	assert.Equal(t, "Test", replica.GetQuestion(sid).Question.Title)
+
+	// the copies are not deleted by the timeout
+	deleted, _ := replica.cleanup(0)
+	assert.Equal(t, 0, deleted)
+
+	assert.NoError(t, primary.Uncover("creator", sid))
+	state, err = primary.Replicate(context.Background(), sid, state.Result.Version, time.Second)
+	assert.NoError(t, err)
+	replica.Mirror(sid, state)
+	assert.Equal(t, 1, replica.GetResult("creator", sid).Result[1].VoteCount())
+
+	primary.Clear(sid, "creator")
+	state, err = primary.Replicate(context.Background(), sid, state.Result.Version, time.Second)
+	assert.NoError(t, err)
+	replica.Mirror(sid, state)
+	assert.False(t, replica.IsMirror(sid))
+}
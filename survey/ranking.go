@@ -0,0 +1,70 @@
+package survey
+
+import ()
+
+// checkRanking returns an error if the options are not a permutation of
+// all options of a ranked question
+func checkRanking(option []int, n int) error {
+	if len(option) != n {
+		return newError("rankAllOptions")
+	}
+	seen := make([]bool, n)
+	for _, o := range option {
+		if o < 0 || o >= n || seen[o] {
+			return ErrInvalidOption
+		}
+		seen[o] = true
+	}
+	return nil
+}
+
+// addRanking adds the given ranking to the Borda counts. The option ranked
+// first gets n-1 points, the option ranked last gets no points. The votes
+// of an option are the number of first preferences.
+func (o Options) addRanking(ranking []int) {
+	n := len(ranking)
+	for rank, i := range ranking {
+		o[i].Points += n - 1 - rank
+		if rank == 0 {
+			o[i].Votes++
+		}
+	}
+}
+
+// rankedResult returns the result of a ranked question ordered by the
+// Borda count. The count is shown as the votes of the option and the
+// percentage is relative to the maximum possible count.
+func (o Options) rankedResult(voters int, hidden bool) ([]OptionResult, float64) {
+	if hidden || voters == 0 || len(o) < 2 {
+		return o.result(voters, hidden)
+	}
+	maxPoints := float64(voters * (len(o) - 1))
+	var maxPercent float64
+	res := make([]OptionResult, len(o))
+	for i, option := range o {
+		percent := float64(option.Points) / maxPoints * 100
+		res[i] = OptionResult{
+			Title:   option.Title,
+			votes:   option.Points,
+			percent: percent,
+			color:   option.Color,
+			// the sum of the ranks is n*voters minus the points
+			averageRank: float64(len(o)) - float64(option.Points)/float64(voters),
+		}
+		maxPercent = max(maxPercent, percent)
+	}
+	sortByVotes(res)
+	if maxPercent < 1 {
+		maxPercent = 1
+	}
+	return res, maxPercent
+}
+
+// AverageRank returns the average rank of the option of a ranked question
+// or an empty string if it is not known
+func (o OptionResult) AverageRank() string {
+	if o.averageRank == 0 {
+		return ""
+	}
+	return FormatNumber(o.averageRank, 1, o.locale)
+}
@@ -0,0 +1,45 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRanking(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B", "C"}, Ranked: true, Multiple: true})
+	assert.NoError(t, err)
+	assert.False(t, s.GetQuestion(sid).Question.Multiple)
+
+	assert.Error(t, s.Vote(sid, "x", []int{0}, 1))
+	assert.Error(t, s.Vote(sid, "x", []int{0, 0, 1}, 1))
+	assert.Error(t, s.Vote(sid, "x", []int{0, 1, 3}, 1))
+	assert.False(t, s.HasVoted(sid, "x"))
+
+	assert.NoError(t, s.Vote(sid, "a", []int{1, 0, 2}, 1))
+	assert.NoError(t, s.Vote(sid, "b", []int{1, 2, 0}, 1))
+	assert.NoError(t, s.Vote(sid, "c", []int{0, 1, 2}, 1))
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.True(t, r.Ranked)
+	assert.Equal(t, 3, r.Votes)
+	// B: 2+2+1=5, A: 1+0+2=3, C: 0+1+0=1
+	var titles []string
+	var points []int
+	for _, o := range r.Result {
+		titles = append(titles, o.Title)
+		points = append(points, o.VoteCount())
+	}
+	assert.Equal(t, []string{"B", "A", "C"}, titles)
+	assert.Equal(t, []int{5, 3, 1}, points)
+	assert.Equal(t, "1.3", r.Result[0].AverageRank())
+	assert.Equal(t, "2.7", r.Result[2].AverageRank())
+	assert.InDelta(t, 5.0/6*100, r.Result[0].PercentValue(), 1e-9)
+
+	assert.Error(t, s.SetRemoteVotes(sid, 1, "remote", []int{1, 0, 0}, 1))
+
+	_, err = s.New("creator", "", SurveyQuestion{Title: "Test", Ranked: true, Rating: 5})
+	assert.Error(t, err)
+}
@@ -0,0 +1,86 @@
+package survey
+
+import "strconv"
+
+// maxRating is the maximum number of points of a rating scale
+const maxRating = 10
+
+// Rating contains the statistics of a rating question
+type Rating struct {
+	// Mean is the mean of the scale values
+	Mean float64
+	// Median is the median of the scale values
+	Median float64
+	// locale is used to format the numbers, see Result.Localize
+	locale string
+}
+
+// MeanString returns the mean with one decimal place
+func (r Rating) MeanString() string {
+	return FormatNumber(r.Mean, 1, r.locale)
+}
+
+// MedianString returns the median with one decimal place if required
+func (r Rating) MedianString() string {
+	return FormatNumber(r.Median, -1, r.locale)
+}
+
+// ratingOptions sets the options of a rating question. If no options are
+// given, the options are the scale values 1 to Rating. Otherwise, there
+// must be an option for every scale value, e.g. the labels of a Likert scale.
+func (d SurveyQuestion) ratingOptions() (SurveyQuestion, error) {
+	if d.Rating < 2 || d.Rating > maxRating {
+		return d, newError("ratingLevels", maxRating)
+	}
+	if len(d.Options) == 0 {
+		d.Options = make([]string, d.Rating)
+		for i := range d.Options {
+			d.Options[i] = strconv.Itoa(i + 1)
+		}
+		d.Colors = nil
+		d.Groups = nil
+	} else if len(d.Options) != d.Rating {
+		return d, newError("ratingOptions", d.Rating, d.Rating)
+	}
+	return d, nil
+}
+
+// RatingOption returns the index of the option of the given scale value
+func (d SurveyQuestion) RatingOption(value int) (int, error) {
+	if d.Rating == 0 {
+		return 0, newError("notRating")
+	}
+	if value < 1 || value > d.Rating {
+		return 0, newError("ratingRange", d.Rating)
+	}
+	return value - 1, nil
+}
+
+// rating computes the statistics from the votes of the options. The
+// option with index i is the scale value i+1.
+func (o Options) rating() *Rating {
+	n := 0
+	sum := 0
+	for i, option := range o {
+		n += option.Votes
+		sum += (i + 1) * option.Votes
+	}
+	if n == 0 {
+		return nil
+	}
+	return &Rating{
+		Mean:   float64(sum) / float64(n),
+		Median: (o.valueAt(n/2) + o.valueAt((n-1)/2)) / 2,
+	}
+}
+
+// valueAt returns the scale value of the k-th vote if the votes are sorted
+func (o Options) valueAt(k int) float64 {
+	for i, option := range o {
+		if k < option.Votes {
+			return float64(i + 1)
+		}
+		k -= option.Votes
+	}
+	return float64(len(o))
+}
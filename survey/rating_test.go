@@ -0,0 +1,60 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRating(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Wie war der Vortrag?", Rating: 5, Multiple: true})
+	assert.NoError(t, err)
+
+	q := s.GetQuestion(sid).Question
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, q.Options)
+	assert.False(t, q.Multiple)
+
+	for i, value := range []int{5, 4, 4, 2} {
+		o, err := q.RatingOption(value)
+		assert.NoError(t, err)
+		assert.NoError(t, s.Vote(sid, UserId(rune('a'+i)), []int{o}, 1))
+	}
+	_, err = q.RatingOption(6)
+	assert.Error(t, err)
+
+	assert.Nil(t, s.GetResult("creator", sid).Rating)
+	assert.NoError(t, s.Uncover("creator", sid))
+	r := s.GetResult("creator", sid).Rating
+	assert.NotNil(t, r)
+	assert.Equal(t, 3.75, r.Mean)
+	assert.Equal(t, 4.0, r.Median)
+	assert.Equal(t, "3.8", r.MeanString())
+	assert.Equal(t, "4", r.MedianString())
+}
+
+func TestRatingMedian(t *testing.T) {
+	o := Options{{Votes: 1}, {Votes: 0}, {Votes: 1}}
+	assert.Equal(t, 2.0, o.rating().Median)
+	o = Options{{Votes: 1}, {Votes: 2}, {Votes: 0}, {Votes: 1}}
+	assert.Equal(t, 2.0, o.rating().Median)
+	o = Options{{Votes: 1}, {Votes: 0}, {Votes: 0}, {Votes: 1}}
+	assert.Equal(t, 2.5, o.rating().Median)
+	assert.Nil(t, Options{{}, {}}.rating())
+}
+
+func TestRatingLabels(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	labels := []string{"stimme nicht zu", "neutral", "stimme zu"}
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Likert", Rating: 3, Options: labels})
+	assert.NoError(t, err)
+	assert.Equal(t, labels, s.GetQuestion(sid).Question.Options)
+
+	_, err = s.New("creator", "", SurveyQuestion{Title: "Likert", Rating: 5, Options: labels})
+	var verr ValidationError
+	assert.ErrorAs(t, err, &verr)
+	assert.NotEmpty(t, verr.Field("rating"))
+
+	_, err = s.New("creator", "", SurveyQuestion{Title: "Likert", Rating: 11})
+	assert.Error(t, err)
+}
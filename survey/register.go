@@ -0,0 +1,76 @@
+package survey
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+)
+
+// RegisteredVoter is a voter which was pre-registered by the creator
+// of a survey. The url contains a personal signed token which is
+// used to identify the voter.
+type RegisteredVoter struct {
+	EMail string
+	URL   string
+}
+
+// RegisterVoters switches the survey to the registration mode.
+// In this mode only voters holding a personal voting link are
+// allowed to vote, and each link can be used once per question.
+// A second call replaces the previously registered voters.
+func (s *Surveys) RegisterVoters(userId UserId, surveyId SurveyId, emails []string) ([]RegisteredVoter, error) {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return nil, ErrSurveyNotFound
+	}
+
+	if len(emails) == 0 {
+		return nil, newError("noEmailAddresses")
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	tokens := make(map[UserId]struct{})
+	var voters []RegisteredVoter
+	for _, email := range emails {
+		token := s.voterToken(surveyId, email)
+		if _, exists := tokens[token]; exists {
+			continue
+		}
+		tokens[token] = struct{}{}
+		voters = append(voters, RegisteredVoter{
+			EMail: email,
+			URL:   voteUrl(survey.host, surveyId) + "&t=" + string(token),
+		})
+	}
+
+	survey.voterTokens = tokens
+	survey.changed()
+	return voters, nil
+}
+
+// RegisteredVoters returns the number of registered voters.
+// If the survey is not in registration mode, zero is returned.
+func (s *Surveys) RegisteredVoters(userId UserId, surveyId SurveyId) int {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return 0
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	return len(survey.voterTokens)
+}
+
+// voterToken creates the signed token of a registered voter. The token
+// is used as the UserId of the voter.
+func (s *Surveys) voterToken(surveyId SurveyId, email string) UserId {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(surveyId))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strings.ToLower(strings.TrimSpace(email))))
+	return UserId(base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:18]))
+}
@@ -0,0 +1,30 @@
+package survey
+
+import (
+	"flashSurvey/randid"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterVoters(t *testing.T) {
+	s := New("https://example.com", 30, false, false)
+	userId := UserId(randid.String())
+	sid, err := s.New(userId, "", description)
+	assert.NoError(t, err)
+
+	voters, err := s.RegisterVoters(userId, sid, []string{"a@example.com", "b@example.com", "A@example.com"})
+	assert.NoError(t, err)
+	assert.Len(t, voters, 2)
+	assert.EqualValues(t, 2, s.RegisteredVoters(userId, sid))
+
+	// unregistered voters are rejected
+	assert.Error(t, s.Vote(sid, UserId(randid.String()), []int{0}, 1))
+
+	u, err := url.Parse(voters[0].URL)
+	assert.NoError(t, err)
+	token := UserId(u.Query().Get("t"))
+	assert.NoError(t, s.Vote(sid, token, []int{0}, 1))
+	assert.Error(t, s.Vote(sid, token, []int{0}, 1))
+}
@@ -0,0 +1,72 @@
+package survey
+
+import "slices"
+
+// remoteTally contains the votes collected by an external source
+type remoteTally struct {
+	votes  []int
+	voters int
+}
+
+// SetRemoteVotes sets the votes collected by the given external source, e.g.
+// a Fediverse poll. The source is responsible for the deduplication of its
+// voters. The votes replace the votes previously set by the same source.
+func (s *Surveys) SetRemoteVotes(surveyId SurveyId, number int, source string, votes []int, voters int) error {
+	survey, exists := s.getSurveyToVote(surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if number != survey.number {
+		return ErrRoundClosed
+	}
+	if survey.question.Ranked {
+		// the external sources only count the selected options
+		return ErrInvalidOption
+	}
+	if len(votes) != len(survey.options)-survey.promoted || voters < 0 {
+		return ErrInvalidOption
+	}
+	for _, v := range votes {
+		if v < 0 {
+			return ErrInvalidOption
+		}
+	}
+
+	old, ok := survey.remoteVotes[source]
+	if ok && old.voters == voters && slices.Equal(old.votes, votes) {
+		return nil
+	}
+	if survey.remoteVotes == nil {
+		survey.remoteVotes = make(map[string]remoteTally)
+	}
+	survey.remoteVotes[source] = remoteTally{votes: slices.Clone(votes), voters: voters}
+	survey.changed()
+	return nil
+}
+
+// voteCount returns the number of local and remote voters
+func (s *Survey) voteCount() int {
+	n := s.counted
+	for _, r := range s.remoteVotes {
+		n += r.voters
+	}
+	return n
+}
+
+// tally returns the options including the remote votes
+func (s *Survey) tally() Options {
+	if len(s.remoteVotes) == 0 {
+		return s.options
+	}
+	o := slices.Clone(s.options)
+	for _, r := range s.remoteVotes {
+		for i, v := range r.votes {
+			o[i].Votes += v
+		}
+	}
+	return o
+}
@@ -0,0 +1,36 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemoteVotes(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", description)
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.Vote(sid, "v1", []int{0}, 1))
+	assert.NoError(t, s.SetRemoteVotes(sid, 1, "poll", []int{2, 3}, 5))
+	// the votes of a source are replaced, not added
+	assert.NoError(t, s.SetRemoteVotes(sid, 1, "poll", []int{3, 4}, 7))
+	assert.NoError(t, s.SetRemoteVotes(sid, 1, "other", []int{0, 1}, 1))
+
+	assert.Error(t, s.SetRemoteVotes(sid, 2, "poll", []int{3, 4}, 7))
+	assert.Error(t, s.SetRemoteVotes(sid, 1, "poll", []int{3}, 7))
+	assert.Error(t, s.SetRemoteVotes(sid, 1, "poll", []int{3, -1}, 7))
+	assert.Error(t, s.SetRemoteVotes("unknown", 1, "poll", []int{3, 4}, 7))
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.EqualValues(t, 9, r.Votes)
+	assert.EqualValues(t, 4, r.Result[0].VoteCount())
+	assert.EqualValues(t, 5, r.Result[1].VoteCount())
+
+	// a new question removes the remote votes
+	_, err = s.New("creator", sid, description)
+	assert.NoError(t, err)
+	r = s.GetResult("creator", sid)
+	assert.EqualValues(t, 0, r.Votes)
+}
@@ -0,0 +1,33 @@
+package survey
+
+// ResetVotes discards the votes of the running question and starts it
+// again with the same options, e.g. to repeat the vote after a discussion.
+// The discarded result is kept in the completed questions, and everybody
+// can vote again. A locked survey can not be reset.
+func (s *Surveys) ResetVotes(userId UserId, surveyId SurveyId) error {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	if !s.mayControl(survey, userId) {
+		survey.Unlock()
+		return ErrNotOwner
+	}
+	if survey.locked.Load() {
+		survey.Unlock()
+		return ErrLockedReset
+	}
+
+	survey.releaseCodes()
+	opt := make([]Option, len(survey.options))
+	for i, o := range survey.options {
+		opt[i] = Option{Title: o.Title, Color: o.Color, Group: o.Group}
+	}
+	survey.restart(survey.question, opt)
+	survey.Unlock()
+
+	s.startEvent(surveyId)
+	return nil
+}
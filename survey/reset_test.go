@@ -0,0 +1,38 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResetVotes(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	codes, err := s.GenerateCodes("creator", sid, 2)
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, CodeVoter(codes[0].Code), []int{0}, 1))
+	assert.NoError(t, s.Uncover("creator", sid))
+
+	assert.Error(t, s.ResetVotes("other", sid))
+	assert.NoError(t, s.SetLocked("creator", sid, true))
+	assert.Error(t, s.ResetVotes("creator", sid))
+	assert.NoError(t, s.SetLocked("creator", sid, false))
+
+	assert.NoError(t, s.ResetVotes("creator", sid))
+	q := s.GetQuestion(sid)
+	assert.Equal(t, 2, q.Number)
+	assert.Equal(t, "Test", q.Question.Title)
+	r := s.GetResult("creator", sid)
+	assert.Equal(t, 0, r.Votes)
+	assert.True(t, r.Hidden())
+
+	// the discarded result is kept and the code can be used again
+	rounds, err := s.History("creator", sid)
+	assert.NoError(t, err)
+	assert.Len(t, rounds, 1)
+	assert.Equal(t, 1, rounds[0].Result.Votes)
+	assert.NoError(t, s.Vote(sid, CodeVoter(codes[0].Code), []int{1}, 2))
+	assert.Error(t, s.Vote(sid, CodeVoter(codes[0].Code), []int{1}, 1))
+}
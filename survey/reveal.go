@@ -0,0 +1,97 @@
+package survey
+
+import (
+	"slices"
+	"sort"
+)
+
+// Hide covers the result of the running question again, e.g. if it was
+// uncovered by mistake. Votes are accepted as before, and the result can
+// be uncovered again, also one option at a time.
+func (s *Surveys) Hide(userId UserId, surveyId SurveyId) error {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	if !s.mayControl(survey, userId) {
+		survey.Unlock()
+		return ErrNotOwner
+	}
+
+	if !survey.resultHidden || survey.revealed > 0 {
+		survey.resultHidden = true
+		survey.revealed = 0
+		survey.changed()
+		e := survey.resultEvent()
+		survey.Unlock()
+		onVisibility(e)
+		return nil
+	}
+	survey.Unlock()
+	return nil
+}
+
+// RevealNext uncovers the votes of one more option of the hidden result.
+// The options are uncovered in the order of increasing votes, so the
+// option with the most votes comes last, like in a quiz show. After the
+// last option the whole result is uncovered.
+func (s *Surveys) RevealNext(userId UserId, surveyId SurveyId) error {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	if !s.mayControl(survey, userId) {
+		survey.Unlock()
+		return ErrNotOwner
+	}
+	if !survey.resultHidden {
+		survey.Unlock()
+		return nil
+	}
+	if survey.question.Encrypted() || survey.question.Ranked {
+		survey.Unlock()
+		return newError("revealNotPossible")
+	}
+	if err := s.enoughVotes(survey); err != nil {
+		survey.Unlock()
+		return err
+	}
+
+	if survey.revealed+1 < len(survey.options) {
+		survey.revealed++
+		survey.changed()
+		survey.Unlock()
+		return nil
+	}
+
+	e, first, err := s.reveal(survey)
+	survey.Unlock()
+	if err != nil {
+		return err
+	}
+	s.afterReveal(e, first)
+	return nil
+}
+
+// partialResult uncovers the options of the hidden result which are
+// already revealed, these are the options with the fewest votes. The
+// survey must be locked.
+func (s *Survey) partialResult(tally Options, base int, hidden []OptionResult) []OptionResult {
+	full, _ := tally.result(base, false)
+	order := make([]int, len(full))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return full[order[i]].votes < full[order[j]].votes
+	})
+	res := slices.Clone(hidden)
+	for _, i := range order[:min(s.revealed, len(order))] {
+		res[i] = full[i]
+	}
+	return res
+}
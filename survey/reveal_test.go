@@ -0,0 +1,97 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevealNext(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B", "C"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "v1", []int{0}, 1))
+	assert.NoError(t, s.Vote(sid, "v2", []int{0}, 1))
+	assert.NoError(t, s.Vote(sid, "v3", []int{2}, 1))
+
+	assert.Error(t, s.RevealNext("other", sid))
+
+	// the option with the fewest votes is uncovered first
+	assert.NoError(t, s.RevealNext("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.True(t, r.Hidden())
+	assert.False(t, r.Uncovered())
+	assert.Equal(t, []int{-1, 0, -1}, voteCounts(r))
+
+	assert.NoError(t, s.RevealNext("creator", sid))
+	assert.Equal(t, []int{-1, 0, 1}, voteCounts(s.GetResult("creator", sid)))
+
+	// the last option uncovers the whole result
+	assert.NoError(t, s.RevealNext("creator", sid))
+	r = s.GetResult("creator", sid)
+	assert.True(t, r.Uncovered())
+	assert.Equal(t, []int{2, 0, 1}, voteCounts(r))
+}
+
+func TestHide(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "v1", []int{0}, 1))
+	assert.NoError(t, s.Uncover("creator", sid))
+	assert.True(t, s.GetResult("creator", sid).Uncovered())
+
+	assert.Error(t, s.Hide("other", sid))
+	assert.NoError(t, s.Hide("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.True(t, r.Hidden())
+	assert.Equal(t, []int{-1, -1}, voteCounts(r))
+
+	// votes are still accepted and the result can be uncovered again
+	assert.NoError(t, s.Vote(sid, "v2", []int{1}, 1))
+	assert.NoError(t, s.Uncover("creator", sid))
+	assert.Equal(t, []int{1, 1}, voteCounts(s.GetResult("creator", sid)))
+}
+
+func TestHideHooks(t *testing.T) {
+	t.Cleanup(func() { registered.hooks = nil })
+	var uncovered int
+	var visible []bool
+	RegisterHooks(Hooks{
+		AfterUncover: func(e ResultEvent) { uncovered++ },
+		OnVisibility: func(e ResultEvent) { visible = append(visible, e.Result.Uncovered()) },
+	})
+
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Uncover("creator", sid))
+	assert.NoError(t, s.Hide("creator", sid))
+	assert.NoError(t, s.Hide("creator", sid))
+	assert.NoError(t, s.Uncover("creator", sid))
+
+	// the result is announced only once, the displays follow every change
+	assert.Equal(t, 1, uncovered)
+	assert.Equal(t, []bool{true, false, true}, visible)
+
+	// the next question is announced again
+	_, err = s.New("creator", sid, SurveyQuestion{Title: "Next", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Uncover("creator", sid))
+	assert.Equal(t, 2, uncovered)
+}
+
+func TestRevealNextRanked(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}, Ranked: true})
+	assert.NoError(t, err)
+	assert.Error(t, s.RevealNext("creator", sid))
+}
+
+func voteCounts(r Result) []int {
+	var n []int
+	for _, o := range r.Result {
+		n = append(n, o.VoteCount())
+	}
+	return n
+}
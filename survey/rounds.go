@@ -0,0 +1,77 @@
+package survey
+
+import "time"
+
+// maxRounds is the number of completed questions kept per survey
+const maxRounds = 100
+
+// Round is a completed question of a survey
+type Round struct {
+	// Result is the final result, it is also known if it was never uncovered
+	Result Result
+	Ended  time.Time
+	// Uncovered is set if the result was shown before the question was replaced
+	Uncovered bool `json:",omitempty"`
+}
+
+// finalRound returns the final result of the current question, it
+// includes the votes even if the result was never uncovered. The survey
+// must be locked.
+func (s *Survey) finalRound() Round {
+	s.applyPending()
+	hidden := s.resultHidden
+	s.resultHidden = false
+	r := s.computeResult()
+	s.resultHidden = hidden
+	// the QR code is the same for all rounds
+	r.QRCode = ""
+	r.Expires = time.Time{}
+	return Round{Result: r, Ended: time.Now(), Uncovered: !hidden}
+}
+
+// addRound keeps the final result of the current question before it is
+// replaced. The survey must be locked.
+func (s *Survey) addRound() {
+	s.rounds = append(s.rounds, s.finalRound())
+	if len(s.rounds) > maxRounds {
+		s.rounds = s.rounds[1:]
+	}
+}
+
+// finalEvent returns the event of a survey which is deleted, it contains
+// all questions including the current one. The survey must be locked.
+func (s *Survey) finalEvent() ResultEvent {
+	e := s.resultEvent()
+	e.Rounds = append(append([]Round(nil), s.rounds...), s.finalRound())
+	return e
+}
+
+// History returns the completed questions of the survey, the oldest first
+func (s *Surveys) History(userId UserId, surveyId SurveyId) ([]Round, error) {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return nil, ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	return append([]Round(nil), survey.rounds...), nil
+}
+
+// Previous returns the last completed question of the survey, false is
+// returned if there is none
+func (s *Surveys) Previous(userId UserId, surveyId SurveyId) (Round, bool) {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return Round{}, false
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if len(survey.rounds) == 0 {
+		return Round{}, false
+	}
+	return survey.rounds[len(survey.rounds)-1], true
+}
@@ -0,0 +1,42 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "First", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{1}, 1))
+
+	rounds, err := s.History("creator", sid)
+	assert.NoError(t, err)
+	assert.Empty(t, rounds)
+
+	_, err = s.New("creator", sid, SurveyQuestion{Title: "Second", Options: []string{"C", "D"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 2))
+	assert.NoError(t, s.Uncover("creator", sid))
+	_, err = s.New("creator", sid, SurveyQuestion{Title: "Third", Options: []string{"E", "F"}})
+	assert.NoError(t, err)
+
+	rounds, err = s.History("creator", sid)
+	assert.NoError(t, err)
+	assert.Len(t, rounds, 2)
+	first := rounds[0].Result
+	assert.Equal(t, "First", first.Title)
+	assert.Equal(t, 1, first.Number)
+	assert.Equal(t, 1, first.Votes)
+	// the tallies are kept even if the result was never uncovered
+	assert.Equal(t, 1, first.Result[1].VoteCount())
+	assert.Empty(t, first.QRCode)
+	assert.False(t, rounds[0].Ended.Before(first.Started))
+	assert.Equal(t, "Second", rounds[1].Result.Title)
+	assert.Equal(t, 1, rounds[1].Result.Result[0].VoteCount())
+
+	_, err = s.History("other", sid)
+	assert.Error(t, err)
+}
@@ -0,0 +1,89 @@
+package survey
+
+// maxSeries is the maximum number of questions of a series
+const maxSeries = maxRounds
+
+// Series is the position of the running question in a series of questions
+// the creator has announced, so the voters can see their progress. It is
+// empty if there is no series or the series is completed.
+type Series struct {
+	// Position is the number of the running question, starting at one
+	Position int `json:",omitempty"`
+	// Total is the number of questions of the series
+	Total int `json:",omitempty"`
+}
+
+// IsEmpty returns true if no progress is shown
+func (s Series) IsEmpty() bool {
+	return s.Total == 0
+}
+
+// Percent returns the progress of the series in percent
+func (s Series) Percent() int {
+	if s.Total == 0 {
+		return 0
+	}
+	return s.Position * 100 / s.Total
+}
+
+// seriesPosition returns the position of the running question in the
+// series. The survey must be locked.
+func (s *Survey) seriesPosition() Series {
+	if s.seriesTotal == 0 {
+		return Series{}
+	}
+	position := s.number - s.seriesStart + 1
+	if position < 1 || position > s.seriesTotal {
+		return Series{}
+	}
+	return Series{Position: position, Total: s.seriesTotal}
+}
+
+// SetSeries announces a series of questions, the running question is the
+// question at the given position. The following questions advance the
+// position, after the last question no progress is shown anymore. A total
+// of zero removes the series.
+func (s *Surveys) SetSeries(userId UserId, surveyId SurveyId, series Series) error {
+	if series.Total < 0 || series.Total > maxSeries {
+		return newError("seriesRange", maxSeries)
+	}
+	if series.Total > 0 && (series.Position < 1 || series.Position > series.Total) {
+		return newError("seriesPosition", series.Total)
+	}
+
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if !s.mayControl(survey, userId) {
+		return ErrNotOwner
+	}
+
+	survey.setSeries(series)
+	survey.changed()
+	return nil
+}
+
+// setSeries stores the series so that the running question is at the
+// position of the series. The survey must be locked.
+func (s *Survey) setSeries(series Series) {
+	s.seriesTotal = series.Total
+	s.seriesStart = s.number - series.Position + 1
+}
+
+// GetSeries returns the position of the running question in the series
+func (s *Surveys) GetSeries(userId UserId, surveyId SurveyId) Series {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return Series{}
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	return survey.seriesPosition()
+}
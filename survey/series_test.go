@@ -0,0 +1,39 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeries(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B"}}
+	sid, err := s.New("creator", "", q)
+	require.NoError(t, err)
+	assert.True(t, s.GetQuestion(sid).Series.IsEmpty())
+
+	assert.ErrorIs(t, s.SetSeries("other", sid, Series{Position: 1, Total: 3}), ErrSurveyNotFound)
+	assert.Error(t, s.SetSeries("creator", sid, Series{Position: 1, Total: maxSeries + 1}))
+	assert.Error(t, s.SetSeries("creator", sid, Series{Position: 4, Total: 3}))
+	assert.Error(t, s.SetSeries("creator", sid, Series{Position: 0, Total: 3}))
+
+	require.NoError(t, s.SetSeries("creator", sid, Series{Position: 2, Total: 3}))
+	assert.Equal(t, Series{Position: 2, Total: 3}, s.GetQuestion(sid).Series)
+	assert.Equal(t, 66, s.GetQuestion(sid).Series.Percent())
+
+	// the next question advances the position
+	_, err = s.New("creator", sid, q)
+	require.NoError(t, err)
+	assert.Equal(t, Series{Position: 3, Total: 3}, s.GetSeries("creator", sid))
+
+	// after the last question the series is completed
+	_, err = s.New("creator", sid, q)
+	require.NoError(t, err)
+	assert.True(t, s.GetQuestion(sid).Series.IsEmpty())
+
+	require.NoError(t, s.SetSeries("creator", sid, Series{Position: 1, Total: 2}))
+	require.NoError(t, s.SetSeries("creator", sid, Series{}))
+	assert.True(t, s.GetSeries("creator", sid).IsEmpty())
+}
@@ -0,0 +1,79 @@
+package survey
+
+import (
+	"math"
+)
+
+// significanceLevel is the p-value below which a lead is significant
+const significanceLevel = 0.05
+
+// Lead describes the lead of the top option over the runner-up
+type Lead struct {
+	Leader   string
+	RunnerUp string
+	// P is the two-sided p-value of the sign test of the votes of the two
+	// options, it is the probability of a lead at least this large if both
+	// options were equally popular
+	P float64
+	// locale is used to format the numbers, see Result.Localize
+	locale string
+}
+
+// Significant returns true if the lead is statistically significant
+func (l Lead) Significant() bool {
+	return l.P < significanceLevel
+}
+
+// PString returns the p-value with three decimal places
+func (l Lead) PString() string {
+	if l.P < 0.001 {
+		return "< " + FormatNumber(0.001, 3, l.locale)
+	}
+	return "= " + FormatNumber(l.P, 3, l.locale)
+}
+
+// lead returns the lead of the option with the most votes over the option
+// with the second most votes, or nil if no option has votes
+func (o Options) lead() *Lead {
+	if len(o) < 2 {
+		return nil
+	}
+	first, second := 0, 1
+	if o[second].Votes > o[first].Votes {
+		first, second = second, first
+	}
+	for i := 2; i < len(o); i++ {
+		if o[i].Votes > o[first].Votes {
+			first, second = i, first
+		} else if o[i].Votes > o[second].Votes {
+			second = i
+		}
+	}
+	if o[first].Votes == 0 {
+		return nil
+	}
+	return &Lead{
+		Leader:   o[first].Title,
+		RunnerUp: o[second].Title,
+		P:        signTest(o[first].Votes, o[first].Votes+o[second].Votes),
+	}
+}
+
+// signTest returns the two-sided p-value of k successes in n trials if
+// the probability of a success is one half, k must not be less than n/2.
+// This tests if the voters choosing one of the two options prefer one of
+// them. The binomial coefficients are computed in log space to cope with
+// large audiences.
+func signTest(k, n int) float64 {
+	if 2*k == n {
+		return 1
+	}
+	lgN, _ := math.Lgamma(float64(n + 1))
+	var tail float64
+	for i := k; i <= n; i++ {
+		lgI, _ := math.Lgamma(float64(i + 1))
+		lgNI, _ := math.Lgamma(float64(n - i + 1))
+		tail += math.Exp(lgN - lgI - lgNI - float64(n)*math.Ln2)
+	}
+	return min(1, 2*tail)
+}
@@ -0,0 +1,48 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSignTest(t *testing.T) {
+	assert.Equal(t, 1.0, signTest(5, 10))
+	// 2 * (1+10)/1024
+	assert.InDelta(t, 0.021484375, signTest(9, 10), 1e-12)
+	assert.InDelta(t, 0.5, signTest(2, 2), 1e-12)
+	assert.InDelta(t, 0.75390625, signTest(6, 10), 1e-12)
+	// large audiences do not overflow
+	assert.Less(t, signTest(5500, 10000), 1e-20)
+	assert.InDelta(t, 1, signTest(5001, 10001), 1e-12)
+}
+
+func TestLead(t *testing.T) {
+	assert.Nil(t, Options{{Title: "A"}, {Title: "B"}}.lead())
+
+	l := Options{{Title: "A", Votes: 1}, {Title: "B", Votes: 9}, {Title: "C", Votes: 3}}.lead()
+	assert.Equal(t, "B", l.Leader)
+	assert.Equal(t, "C", l.RunnerUp)
+	assert.InDelta(t, signTest(9, 12), l.P, 1e-12)
+	assert.False(t, l.Significant())
+
+	l = Options{{Title: "A", Votes: 40}, {Title: "B", Votes: 10}}.lead()
+	assert.True(t, l.Significant())
+	assert.Equal(t, "< 0.001", l.PString())
+}
+
+func TestSignificance(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B"}, Display: Display{Significance: true}}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	for _, v := range []string{"a", "b", "c"} {
+		assert.NoError(t, s.Vote(sid, UserId(v), []int{0}, 1))
+	}
+	assert.Nil(t, s.GetResult("creator", sid).Lead)
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	l := s.GetResult("creator", sid).Localize("de").Lead
+	assert.Equal(t, "A", l.Leader)
+	assert.Equal(t, "= 0,250", l.PString())
+}
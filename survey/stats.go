@@ -0,0 +1,86 @@
+package survey
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats contains the metrics of the surveys
+type Stats struct {
+	// Surveys is the number of active surveys
+	Surveys int
+	// Votes is the number of votes accepted since the start
+	Votes int64
+	// VotesPerSecond is the average number of votes per second during the last minute
+	VotesPerSecond float64
+	// Expired is the number of surveys deleted because of the timeout
+	Expired int64
+}
+
+type stats struct {
+	votes   atomic.Int64
+	expired atomic.Int64
+	rate    rate
+}
+
+// Stats returns the metrics of the surveys
+func (s *Surveys) Stats() Stats {
+	return Stats{
+		Surveys:        s.Count(),
+		Votes:          s.stats.votes.Load(),
+		VotesPerSecond: s.stats.rate.perSecond(time.Now()),
+		Expired:        s.stats.expired.Load(),
+	}
+}
+
+func (s *Surveys) countVote() {
+	s.stats.votes.Add(1)
+	s.stats.rate.add(time.Now())
+}
+
+// rateWindow is the number of seconds the rate is averaged over
+const rateWindow = 60
+
+// rate counts events in buckets of one second
+type rate struct {
+	mutex   sync.Mutex
+	buckets [rateWindow + 1]int64
+	// second is the unix time of the newest bucket
+	second int64
+}
+
+// advance clears the buckets of the seconds passed since the last call
+func (r *rate) advance(now time.Time) int64 {
+	sec := now.Unix()
+	if sec-r.second > rateWindow {
+		r.buckets = [rateWindow + 1]int64{}
+		r.second = sec
+	}
+	for r.second < sec {
+		r.second++
+		r.buckets[r.second%int64(len(r.buckets))] = 0
+	}
+	return sec
+}
+
+func (r *rate) add(now time.Time) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	sec := r.advance(now)
+	r.buckets[sec%int64(len(r.buckets))]++
+}
+
+// perSecond returns the average over the last complete seconds
+func (r *rate) perSecond(now time.Time) float64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	sec := r.advance(now)
+	var sum int64
+	for i, n := range r.buckets {
+		if int64(i) != sec%int64(len(r.buckets)) {
+			sum += n
+		}
+	}
+	return float64(sum) / rateWindow
+}
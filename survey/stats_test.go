@@ -0,0 +1,34 @@
+package survey
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRate(t *testing.T) {
+	var r rate
+	now := time.Unix(1000, 0)
+	for i := 0; i < 120; i++ {
+		r.add(now)
+	}
+	// the current second is not complete
+	assert.Equal(t, 0.0, r.perSecond(now))
+	assert.Equal(t, 2.0, r.perSecond(now.Add(time.Second)))
+	assert.Equal(t, 2.0, r.perSecond(now.Add(rateWindow*time.Second)))
+	assert.Equal(t, 0.0, r.perSecond(now.Add((rateWindow+1)*time.Second)))
+	assert.Equal(t, 0.0, r.perSecond(now.Add(time.Hour)))
+}
+
+func TestStats(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+	assert.Error(t, s.Vote(sid, "a", []int{0}, 1))
+
+	st := s.Stats()
+	assert.Equal(t, 1, st.Surveys)
+	assert.Equal(t, int64(1), st.Votes)
+}
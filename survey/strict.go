@@ -0,0 +1,135 @@
+package survey
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+// EnableStrictVoting makes ballot stuffing harder than just deleting the
+// user id cookie. The vote links contain a key, so the survey id alone is
+// not sufficient to vote, the vote page issues a signed voter cookie, and
+// only a single vote per device fingerprint is accepted for each question.
+// Must be called before the surveys are used.
+func (s *Surveys) EnableStrictVoting() {
+	s.strictVoting = true
+}
+
+// StrictVoting returns true if the strict mode is enabled
+func (s *Surveys) StrictVoting() bool {
+	return s.strictVoting
+}
+
+// newVoteKey returns the key of the vote links of a new survey, it is
+// empty if the strict mode is disabled
+func (s *Surveys) newVoteKey(surveyId SurveyId) string {
+	if !s.strictVoting {
+		return ""
+	}
+	return s.sign("key", string(surveyId))
+}
+
+// VoteKey returns the key the creator has to add to the vote links, it is
+// empty if the strict mode is disabled
+func (s *Surveys) VoteKey(userId UserId, surveyId SurveyId) string {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ""
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	return survey.voteKey
+}
+
+// CheckVoteKey returns true if the given key is the key of the vote links
+// of the survey or if the survey does not require a key
+func (s *Surveys) CheckVoteKey(surveyId SurveyId, key string) bool {
+	survey, exists := s.getSurveyToVote(surveyId)
+	if !exists {
+		return false
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	return survey.voteKey == "" || subtle.ConstantTimeCompare([]byte(survey.voteKey), []byte(key)) == 1
+}
+
+// SignVoter returns the signature of the cookie of a voter
+func (s *Surveys) SignVoter(voterId UserId) string {
+	return s.sign("voter", string(voterId))
+}
+
+// VerifyVoter returns true if the signature of the cookie of the voter is valid
+func (s *Surveys) VerifyVoter(voterId UserId, signature string) bool {
+	return hmac.Equal([]byte(s.SignVoter(voterId)), []byte(signature))
+}
+
+// Fingerprint returns the fingerprint of a device which votes in the given
+// survey. Only a keyed hash of the address and the user agent is kept, so
+// they can not be recovered from the fingerprint.
+func (s *Surveys) Fingerprint(surveyId SurveyId, address, userAgent string) string {
+	return s.sign("fingerprint", string(surveyId), address, userAgent)
+}
+
+// ClaimFingerprint binds the fingerprint of the device to the voter for the
+// question with the given number. If another voter has already claimed the
+// fingerprint, an error is returned. The same voter can claim it again, so
+// a rejected vote can be repeated.
+func (s *Surveys) ClaimFingerprint(surveyId SurveyId, number int, fingerprint string, voterId UserId) error {
+	survey, exists := s.getSurveyToVote(surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if number != survey.number {
+		return ErrRoundClosed
+	}
+	if claimed, ok := survey.fingerprints[fingerprint]; ok && claimed != voterId {
+		return ErrDeviceVoted
+	}
+	if survey.fingerprints == nil {
+		survey.fingerprints = make(map[string]UserId)
+	}
+	survey.fingerprints[fingerprint] = voterId
+	return nil
+}
+
+// VerifiedVoter returns true if the voter is identified by a registered
+// token or an unused access code of the survey. Such a voter can only vote
+// once anyway, so the checks of the strict mode are not needed.
+func (s *Surveys) VerifiedVoter(surveyId SurveyId, voterId UserId) bool {
+	survey, exists := s.getSurveyToVote(surveyId)
+	if !exists {
+		return false
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if survey.voterTokens != nil {
+		_, registered := survey.voterTokens[voterId]
+		return registered
+	}
+	if survey.accessCodes != nil {
+		_, err := survey.useCode(voterId)
+		return err == nil
+	}
+	return false
+}
+
+func (s *Surveys) sign(purpose string, values ...string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(purpose))
+	for _, v := range values {
+		mac.Write([]byte{0})
+		mac.Write([]byte(v))
+	}
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:18])
+}
@@ -0,0 +1,42 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictVoting(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "", s.VoteKey("creator", sid))
+	assert.True(t, s.CheckVoteKey(sid, ""))
+
+	s.EnableStrictVoting()
+	sid, err = s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	key := s.VoteKey("creator", sid)
+	assert.NotEqual(t, "", key)
+	assert.Equal(t, "", s.VoteKey("other", sid))
+	assert.Equal(t, key, s.GetQuestion(sid).VoteKey)
+	assert.True(t, s.CheckVoteKey(sid, key))
+	assert.False(t, s.CheckVoteKey(sid, ""))
+	assert.False(t, s.CheckVoteKey(sid, key+"x"))
+
+	assert.True(t, s.VerifyVoter("a", s.SignVoter("a")))
+	assert.False(t, s.VerifyVoter("b", s.SignVoter("a")))
+
+	fp := s.Fingerprint(sid, "10.0.0.1", "Firefox")
+	assert.NotEqual(t, fp, s.Fingerprint(sid, "10.0.0.2", "Firefox"))
+	assert.NoError(t, s.ClaimFingerprint(sid, 1, fp, "a"))
+	assert.NoError(t, s.ClaimFingerprint(sid, 1, fp, "a"))
+	assert.EqualError(t, s.ClaimFingerprint(sid, 1, fp, "b"), "Von diesem Gerät wurde bereits abgestimmt!")
+	assert.Error(t, s.ClaimFingerprint(sid, 2, fp, "b"))
+
+	// the fingerprints are claimed again for the next question
+	_, err = s.New("creator", sid, SurveyQuestion{Title: "Next", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+	assert.NoError(t, s.ClaimFingerprint(sid, 2, fp, "b"))
+	assert.Equal(t, key, s.VoteKey("creator", sid))
+}
@@ -0,0 +1,90 @@
+package survey
+
+// QuestionChange is sent to the subscribers of a survey if a new question
+// is started or the texts of the running question are corrected.
+type QuestionChange struct {
+	Number   int
+	Revision int
+}
+
+// subscribers is the registry of the voters notified about the changes of
+// the question. Unlike the waiters, which are released on every vote, the
+// subscribers are only notified if the question has changed. It is
+// protected by the lock of the survey.
+type subscribers struct {
+	channels map[chan QuestionChange]struct{}
+	// last is the change sent most recently
+	last   QuestionChange
+	closed bool
+}
+
+// notify sends the change to all subscribers if the question has changed
+// since the last notification. A subscriber which has not yet received the
+// former change only gets the latest one.
+func (sub *subscribers) notify(change QuestionChange) {
+	if sub.closed || change == sub.last {
+		return
+	}
+	sub.last = change
+	for ch := range sub.channels {
+		select {
+		case ch <- change:
+		default:
+			select {
+			case <-ch:
+			default:
+			}
+			select {
+			case ch <- change:
+			default:
+			}
+		}
+	}
+}
+
+// close closes the channels of all subscribers because the survey is deleted
+func (sub *subscribers) close() {
+	if sub.closed {
+		return
+	}
+	for ch := range sub.channels {
+		close(ch)
+	}
+	sub.channels = nil
+	sub.closed = true
+}
+
+// Subscribe returns a channel receiving the changes of the question of the
+// given survey. The channel is closed if the survey is deleted. The returned
+// function has to be called to unsubscribe.
+func (s *Surveys) Subscribe(surveyId SurveyId) (<-chan QuestionChange, func(), error) {
+	select {
+	case <-s.shutdown:
+		return nil, nil, ErrShutdown
+	default:
+	}
+	survey, exists := s.getSurveyToVote(surveyId)
+	if !exists {
+		return nil, nil, ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if survey.subscribers.closed {
+		return nil, nil, ErrSurveyNotFound
+	}
+	if s.maxWaiters > 0 && len(survey.subscribers.channels) >= s.maxWaiters {
+		return nil, nil, ErrTooManyWaiters
+	}
+	ch := make(chan QuestionChange, 1)
+	if survey.subscribers.channels == nil {
+		survey.subscribers.channels = make(map[chan QuestionChange]struct{})
+	}
+	survey.subscribers.channels[ch] = struct{}{}
+	return ch, func() {
+		survey.Lock()
+		defer survey.Unlock()
+		delete(survey.subscribers.channels, ch)
+	}, nil
+}
@@ -0,0 +1,41 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubscribe(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B"}}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+
+	_, _, err = s.Subscribe("unknown")
+	assert.Error(t, err)
+
+	changes, unsubscribe, err := s.Subscribe(sid)
+	assert.NoError(t, err)
+
+	// votes are not sent to the subscribers
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+	assert.Len(t, changes, 0)
+
+	_, err = s.New("creator", sid, q)
+	assert.NoError(t, err)
+	assert.Equal(t, QuestionChange{Number: 2}, <-changes)
+
+	// only the latest change is kept
+	assert.NoError(t, s.Correct("creator", sid, "Test!", []string{"A", "B"}))
+	assert.NoError(t, s.Correct("creator", sid, "Test?", []string{"A", "B"}))
+	assert.Equal(t, QuestionChange{Number: 2, Revision: 2}, <-changes)
+	assert.Len(t, changes, 0)
+
+	other, _, err := s.Subscribe(sid)
+	assert.NoError(t, err)
+	unsubscribe()
+	s.Clear(sid, "creator")
+	_, ok := <-other
+	assert.False(t, ok)
+}
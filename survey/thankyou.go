@@ -0,0 +1,120 @@
+package survey
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxThankYouLen is the maximum number of characters of the thank-you message
+	maxThankYouLen = 500
+	// maxRedirectLen is the maximum length of the redirect url
+	maxRedirectLen = 2048
+)
+
+// ThankYou is shown to the voters after they have voted instead of the
+// default confirmation. It is kept for all questions of the survey.
+type ThankYou struct {
+	// Message is shown to the voter
+	Message string `json:",omitempty"`
+	// Redirect is the url the voter is sent to, e.g. the slides or a
+	// follow-up form
+	Redirect string `json:",omitempty"`
+	// Context adds the survey id and the round to the redirect, so that a
+	// follow-up form can relate its answers to the poll round
+	Context bool `json:",omitempty"`
+}
+
+// IsEmpty returns true if the default confirmation is shown
+func (t ThankYou) IsEmpty() bool {
+	return t.Message == "" && t.Redirect == ""
+}
+
+// Link returns the redirect of the voters of the given round. If Context
+// is set, the survey id and the round are added as query parameters
+// together with the utm parameters, which are kept if the redirect
+// already contains them.
+func (t ThankYou) Link(surveyId SurveyId, round int) string {
+	if !t.Context || t.Redirect == "" {
+		return t.Redirect
+	}
+	u, err := url.Parse(t.Redirect)
+	if err != nil {
+		return t.Redirect
+	}
+	q := u.Query()
+	q.Set("survey", string(surveyId))
+	q.Set("round", strconv.Itoa(round))
+	for key, value := range map[string]string{
+		"utm_source":   "flashSurvey",
+		"utm_medium":   "poll",
+		"utm_campaign": string(surveyId),
+		"utm_content":  "round" + strconv.Itoa(round),
+	} {
+		if !q.Has(key) {
+			q.Set(key, value)
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String()
+}
+
+func (t ThankYou) validate() (ThankYou, error) {
+	t.Message = strings.TrimSpace(t.Message)
+	t.Redirect = strings.TrimSpace(t.Redirect)
+	if utf8.RuneCountInString(t.Message) > maxThankYouLen {
+		return t, newError("thankYouTooLong", maxThankYouLen)
+	}
+	if t.Redirect != "" {
+		if len(t.Redirect) > maxRedirectLen {
+			return t, newError("invalidRedirect")
+		}
+		u, err := url.Parse(t.Redirect)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return t, newError("invalidRedirect")
+		}
+	} else {
+		t.Context = false
+	}
+	return t, nil
+}
+
+// SetThankYou sets the message and the redirect shown to the voters after
+// they have voted. An empty ThankYou restores the default confirmation.
+func (s *Surveys) SetThankYou(userId UserId, surveyId SurveyId, thankYou ThankYou) error {
+	thankYou, err := thankYou.validate()
+	if err != nil {
+		return err
+	}
+
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if !s.mayControl(survey, userId) {
+		return ErrNotOwner
+	}
+
+	survey.thankYou = thankYou
+	survey.changed()
+	return nil
+}
+
+// GetThankYou returns the thank-you page of the survey
+func (s *Surveys) GetThankYou(userId UserId, surveyId SurveyId) ThankYou {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ThankYou{}
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	return survey.thankYou
+}
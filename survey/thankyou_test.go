@@ -0,0 +1,62 @@
+package survey
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetThankYou(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+	assert.True(t, s.GetQuestion(sid).ThankYou.IsEmpty())
+
+	assert.Error(t, s.SetThankYou("other", sid, ThankYou{Message: "Danke"}))
+	assert.Error(t, s.SetThankYou("creator", sid, ThankYou{Message: strings.Repeat("a", maxThankYouLen+1)}))
+	for _, redirect := range []string{"javascript:alert(1)", "/slides", "ftp://host/file", "https://"} {
+		assert.Error(t, s.SetThankYou("creator", sid, ThankYou{Redirect: redirect}), redirect)
+	}
+
+	require.NoError(t, s.SetThankYou("creator", sid, ThankYou{Message: " Danke! ", Redirect: "https://example.com/slides"}))
+	expected := ThankYou{Message: "Danke!", Redirect: "https://example.com/slides"}
+	assert.Equal(t, expected, s.GetQuestion(sid).ThankYou)
+	assert.Equal(t, expected, s.GetThankYou("creator", sid))
+
+	// the thank-you page is kept for the next question
+	_, err = s.New("creator", sid, SurveyQuestion{Title: "Next", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+	assert.Equal(t, expected, s.GetQuestion(sid).ThankYou)
+
+	require.NoError(t, s.SetThankYou("creator", sid, ThankYou{}))
+	assert.True(t, s.GetQuestion(sid).ThankYou.IsEmpty())
+}
+
+func TestThankYouLink(t *testing.T) {
+	plain := ThankYou{Redirect: "https://example.com/form?x=1"}
+	assert.Equal(t, "https://example.com/form?x=1", plain.Link("abc", 3))
+
+	withContext := ThankYou{Redirect: "https://example.com/form?x=1&utm_source=lecture#top", Context: true}
+	assert.Equal(t, "https://example.com/form?round=3&survey=abc&utm_campaign=abc&utm_content=round3&utm_medium=poll&utm_source=lecture&x=1#top",
+		withContext.Link("abc", 3))
+
+	assert.Equal(t, "", ThankYou{Context: true}.Link("abc", 3))
+}
+
+func TestThankYouContextRound(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+	require.NoError(t, s.SetThankYou("creator", sid, ThankYou{Redirect: "https://example.com/form", Context: true}))
+	assert.Contains(t, s.GetQuestion(sid).FollowUp(), "round=1")
+
+	_, err = s.New("creator", sid, SurveyQuestion{Title: "Next", Options: []string{"A", "B"}})
+	require.NoError(t, err)
+	assert.Contains(t, s.GetQuestion(sid).FollowUp(), "round=2")
+
+	// the context is dropped without a redirect
+	require.NoError(t, s.SetThankYou("creator", sid, ThankYou{Message: "Danke", Context: true}))
+	assert.False(t, s.GetThankYou("creator", sid).Context)
+}
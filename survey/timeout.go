@@ -0,0 +1,64 @@
+package survey
+
+import "time"
+
+// SetTimeoutBounds sets the range of the timeout in minutes the creators
+// can choose for a survey. If max is not larger than min, the creators can
+// not choose a timeout. Must be called before the surveys are used.
+func (s *Surveys) SetTimeoutBounds(min, max int) {
+	s.minTimeout = min
+	s.maxTimeout = max
+}
+
+// TimeoutBounds returns the range of the timeout in minutes the creators
+// can choose, both are zero if the timeout can not be chosen
+func (s *Surveys) TimeoutBounds() (int, int) {
+	if s.maxTimeout <= s.minTimeout || s.maxTimeout <= 0 {
+		return 0, 0
+	}
+	return max(s.minTimeout, 1), s.maxTimeout
+}
+
+// DefaultTimeout returns the timeout in minutes used if the creator has not chosen one
+func (s *Surveys) DefaultTimeout() int {
+	return int(s.defaultTimeout() / time.Minute)
+}
+
+func (s *Surveys) defaultTimeout() time.Duration {
+	return time.Duration(s.timeout.Load())
+}
+
+// SetTimeout changes the timeout in minutes used if the creator has not
+// chosen one. It can be called while the surveys are used, the running
+// surveys are deleted by the new timeout.
+func (s *Surveys) SetTimeout(minutes int) error {
+	if minutes < 1 {
+		return newError("timeoutMin")
+	}
+	s.timeout.Store(int64(time.Duration(minutes) * time.Minute))
+	return nil
+}
+
+// validateTimeout checks the timeout chosen by the creator
+func (s *Surveys) validateTimeout(def SurveyQuestion) error {
+	if def.Timeout == 0 {
+		return nil
+	}
+	min, max := s.TimeoutBounds()
+	if def.Timeout < min || def.Timeout > max {
+		if max == 0 {
+			return newError("timeoutFixed")
+		}
+		return newError("timeoutRange", min, max)
+	}
+	return nil
+}
+
+// timeout returns the time of inactivity after which the survey is
+// deleted. The survey must be locked.
+func (s *Survey) timeout(def time.Duration) time.Duration {
+	if s.question.Timeout > 0 {
+		return time.Duration(s.question.Timeout) * time.Minute
+	}
+	return def
+}
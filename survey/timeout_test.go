@@ -0,0 +1,44 @@
+package survey
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSurveyTimeout(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B"}, Timeout: 120}
+
+	// without bounds the timeout can not be chosen
+	min, max := s.TimeoutBounds()
+	assert.Equal(t, 0, min+max)
+	_, err := s.New("creator", "", q)
+	assert.Error(t, err)
+
+	s.SetTimeoutBounds(5, 240)
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	other, err := s.New("other", "", SurveyQuestion{Title: "Test", Options: []string{"A", "B"}})
+	assert.NoError(t, err)
+
+	for _, id := range []SurveyId{sid, other} {
+		survey, _ := s.getSurveyToVote(id)
+		survey.Lock()
+		survey.creationTime = time.Now().Add(-time.Hour)
+		survey.Unlock()
+	}
+	// only the survey using the default timeout is deleted
+	deleted, remaining := s.cleanup(30 * time.Minute)
+	assert.Equal(t, 1, deleted)
+	assert.Equal(t, 1, remaining)
+	assert.Equal(t, "Test", s.GetResult("creator", sid).Title)
+
+	q.Timeout = 300
+	_, err = s.New("creator", sid, q)
+	assert.Error(t, err)
+	q.Timeout = 1
+	_, err = s.New("creator", sid, q)
+	assert.Error(t, err)
+}
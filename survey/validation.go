@@ -0,0 +1,144 @@
+package survey
+
+import (
+	"errors"
+	"flashSurvey/i18n"
+	"strconv"
+	"strings"
+)
+
+// FieldError is the validation error of a single input field
+type FieldError struct {
+	// Field is the name of the input field, e.g. "title" or "option2"
+	Field   string
+	Message string
+	// Code and Args identify the message in the catalog
+	Code string
+	Args []any
+}
+
+// Localize returns the message in the given language
+func (e FieldError) Localize(lang string) string {
+	if e.Code == "" {
+		return e.Message
+	}
+	return i18n.Message(lang, e.Code, e.Args...)
+}
+
+// ValidationError contains the errors of all invalid fields of a survey
+// definition, so they can be shown next to the inputs
+type ValidationError []FieldError
+
+func (v ValidationError) Error() string {
+	return v.Localize(i18n.Default)
+}
+
+// Localize returns the messages of all fields in the given language
+func (v ValidationError) Localize(lang string) string {
+	messages := make([]string, len(v))
+	for i, e := range v {
+		messages[i] = e.Localize(lang)
+	}
+	return strings.Join(messages, " ")
+}
+
+// Field returns the error message of the given field or an empty string
+func (v ValidationError) Field(name string) string {
+	for _, e := range v {
+		if e.Field == name {
+			return e.Message
+		}
+	}
+	return ""
+}
+
+func (v *ValidationError) add(field string, err error) {
+	e := FieldError{Field: field, Message: err.Error()}
+	var se *Error
+	if errors.As(err, &se) {
+		e.Code = se.Code
+		e.Args = se.Args
+	}
+	*v = append(*v, e)
+}
+
+// optionField returns the name of the input field of the option with the given index
+func optionField(i int) string {
+	return "option" + strconv.Itoa(i)
+}
+
+// normalizeOption returns the key used to detect duplicate options. Case and
+// whitespace are ignored.
+func normalizeOption(option string) string {
+	return strings.Join(strings.Fields(strings.ToLower(option)), " ")
+}
+
+// mergeDuplicates removes the options which are equal to a previous option
+// together with their colors and groups
+func (d SurveyQuestion) mergeDuplicates() SurveyQuestion {
+	var options, colors, groups []string
+	seen := map[string]bool{}
+	for i, option := range d.Options {
+		key := normalizeOption(option)
+		if key != "" && seen[key] {
+			continue
+		}
+		seen[key] = true
+		options = append(options, option)
+		if i < len(d.Colors) {
+			colors = append(colors, d.Colors[i])
+		}
+		if i < len(d.Groups) {
+			groups = append(groups, d.Groups[i])
+		}
+	}
+	d.Options = options
+	d.Colors = colors
+	d.Groups = groups
+	return d
+}
+
+// ParseOptionList returns the options given as a list with one option per
+// line. Empty lines and list markers like "-" or "*" are removed.
+func ParseOptionList(list string) []string {
+	var options []string
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		for _, marker := range []string{"- ", "* ", "• "} {
+			line = strings.TrimSpace(strings.TrimPrefix(line, marker))
+		}
+		if line != "" {
+			options = append(options, line)
+		}
+	}
+	return options
+}
+
+// validateTexts checks the title and the options and returns them without
+// surrounding whitespace
+func validateTexts(title string, options []string, maxLen int) (string, []string, ValidationError) {
+	var v ValidationError
+	title = strings.TrimSpace(title)
+	if title == "" {
+		v.add("title", newError("titleMissing"))
+	} else if len(title) > maxLen {
+		v.add("title", newError("titleTooLong", maxStringLen))
+	}
+
+	trimmed := make([]string, len(options))
+	seen := map[string]int{}
+	for i, option := range options {
+		option = strings.TrimSpace(option)
+		trimmed[i] = option
+		if option == "" {
+			v.add(optionField(i), newError("optionEmpty", i+1))
+		} else if len(option) > maxLen {
+			v.add(optionField(i), newError("optionTooLong", i+1, maxStringLen))
+		} else if j, ok := seen[normalizeOption(option)]; ok {
+			v.add(optionField(i), newError("optionDuplicate", i+1, j+1))
+		} else {
+			seen[normalizeOption(option)] = i
+		}
+	}
+	return title, trimmed, v
+}
@@ -0,0 +1,74 @@
+package survey
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidationError(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	_, err := s.New("creator", "", SurveyQuestion{
+		Title:   strings.Repeat("x", maxStringLen+1),
+		Options: []string{"Yes", " ", "yes ", "No"},
+		Display: Display{Chart: "bubble"},
+	})
+	var v ValidationError
+	assert.True(t, errors.As(err, &v))
+	assert.Equal(t, "Der Titel ist zu lang! Maximal 100 Zeichen erlaubt.", v.Field("title"))
+	assert.Equal(t, "", v.Field("option0"))
+	assert.Equal(t, "Option 2 ist leer!", v.Field("option1"))
+	assert.Equal(t, "Option 3 ist identisch mit Option 1!", v.Field("option2"))
+	assert.Equal(t, "", v.Field("option3"))
+	assert.Equal(t, "Ungültige Darstellung!", v.Field("chart"))
+	assert.Len(t, v, 4)
+
+	_, err = s.New("creator", "", SurveyQuestion{Title: "Test", Options: []string{"A"}})
+	assert.True(t, errors.As(err, &v))
+	assert.Equal(t, "Es müssen mindestens zwei Optionen angegeben werden!", v.Field("option1"))
+}
+
+func TestMergeDuplicates(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{
+		Title:   "Test",
+		Options: []string{"Very good", "very  GOOD ", "Bad"},
+		Colors:  []string{"#000001", "#000002", "#000003"},
+		Groups:  []string{"a", "b", "c"},
+	}
+	_, err := s.New("creator", "", q)
+	assert.Error(t, err)
+
+	q.MergeDuplicates = true
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Very good", "Bad"}, s.GetQuestion(sid).Question.Options)
+	r := s.GetResult("creator", sid)
+	assert.Equal(t, "#000003", r.Result[1].Color())
+}
+
+func TestMaxOptions(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	s.SetMaxOptions(3)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B", "C", "D"}}
+	_, err := s.New("creator", "", q)
+	var v ValidationError
+	assert.True(t, errors.As(err, &v))
+	assert.Equal(t, "Es sind maximal 3 Optionen erlaubt!", v.Field("option2"))
+
+	q.Options = []string{"A", "B"}
+	q.WriteIn = true
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	assert.NoError(t, s.VoteWriteIn(sid, "a", nil, "C", 1))
+	assert.NoError(t, s.VoteWriteIn(sid, "b", nil, "D", 1))
+	assert.NoError(t, s.PromoteWriteIn("creator", sid, "C"))
+	assert.Error(t, s.PromoteWriteIn("creator", sid, "D"))
+}
+
+func TestParseOptionList(t *testing.T) {
+	assert.Equal(t, []string{"A", "B b", "C", "D"}, ParseOptionList("A\r\n  B b \n\n- C\n* D\n"))
+	assert.Nil(t, ParseOptionList(" \n "))
+}
@@ -0,0 +1,60 @@
+package survey
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// ViewerToken returns a token which allows to show the result of the
+// survey without the identity of the creator. It can be used on an
+// unattended projector, because the result can neither be uncovered nor
+// can the survey be changed with it.
+func (s *Surveys) ViewerToken(userId UserId, surveyId SurveyId) (string, error) {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return "", ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if !s.mayControl(survey, userId) {
+		return "", ErrNotOwner
+	}
+	if survey.question.Encrypted() {
+		// the keys are only available in the browser of the creator
+		return "", newError("viewerEncrypted")
+	}
+	return s.viewerToken(surveyId), nil
+}
+
+// Viewer returns the creator of the survey if the given token is a
+// viewer token of the survey.
+func (s *Surveys) Viewer(surveyId SurveyId, token string) (UserId, bool) {
+	survey, exists := s.getSurveyToVote(surveyId)
+	if !exists {
+		return "", false
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	expected := s.viewerToken(surveyId)
+	if survey.mirror {
+		// the token was issued by the primary instance
+		expected = survey.mirroredViewerToken
+	}
+	if expected == "" || !hmac.Equal([]byte(token), []byte(expected)) {
+		return "", false
+	}
+	return survey.userId, true
+}
+
+func (s *Surveys) viewerToken(surveyId SurveyId) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte("viewer"))
+	mac.Write([]byte{0})
+	mac.Write([]byte(surveyId))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:18])
+}
@@ -0,0 +1,151 @@
+package survey
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+// ErrTooManyWaiters is returned by Wait if too many clients are already
+// waiting for a modification of the survey.
+var ErrTooManyWaiters = errors.New("too many clients waiting for this survey")
+
+// ErrShutdown is returned by Wait and Subscribe if the server shuts down
+var ErrShutdown = errors.New("the server is restarting")
+
+// waiters is the registry of the clients waiting for a modification of a
+// survey. It is protected by the lock of the survey.
+type waiters struct {
+	count  int
+	notify chan struct{}
+	// closed is set if the survey is deleted, all waiters are released
+	// and no new waiters are accepted.
+	closed bool
+}
+
+func newWaiters() waiters {
+	return waiters{notify: make(chan struct{})}
+}
+
+// release wakes up all waiting clients
+func (w *waiters) release() {
+	if w.closed {
+		return
+	}
+	close(w.notify)
+	w.notify = make(chan struct{})
+}
+
+// close wakes up all waiting clients for the last time
+func (w *waiters) close() {
+	if w.closed {
+		return
+	}
+	close(w.notify)
+	w.closed = true
+}
+
+// WaiterStats contains the metrics of the waiting clients
+type WaiterStats struct {
+	// Waiting is the number of clients currently waiting
+	Waiting int64
+	// Notified is the number of waits ended by a modification
+	Notified int64
+	// TimedOut is the number of waits ended by the timeout
+	TimedOut int64
+	// Canceled is the number of waits ended by the client
+	Canceled int64
+	// Rejected is the number of waits rejected because of too many waiters
+	Rejected int64
+}
+
+type waiterStats struct {
+	waiting, notified, timedOut, canceled, rejected atomic.Int64
+}
+
+// SetMaxWaiters limits the number of clients waiting for a modification of
+// a single survey. If max is zero, the number is not limited.
+// Must be called before the surveys are used.
+func (s *Surveys) SetMaxWaiters(max int) {
+	s.maxWaiters = max
+}
+
+// WaiterStats returns the metrics of the waiting clients
+func (s *Surveys) WaiterStats() WaiterStats {
+	return WaiterStats{
+		Waiting:  s.waiterStats.waiting.Load(),
+		Notified: s.waiterStats.notified.Load(),
+		TimedOut: s.waiterStats.timedOut.Load(),
+		Canceled: s.waiterStats.canceled.Load(),
+		Rejected: s.waiterStats.rejected.Load(),
+	}
+}
+
+// Close releases all clients waiting for a modification and rejects new
+// waits and subscriptions. So the server can shut down without waiting for
+// the timeouts of the long-polling requests.
+func (s *Surveys) Close() {
+	s.closeOnce.Do(func() {
+		close(s.shutdown)
+	})
+}
+
+// Done returns a channel which is closed by Close
+func (s *Surveys) Done() <-chan struct{} {
+	return s.shutdown
+}
+
+// Wait blocks until the survey has a version higher than the client's
+// version, the timeout has elapsed, the context is canceled or the survey
+// is deleted. If the survey does not exist, Wait returns immediately.
+// If the surveys are closed, ErrShutdown is returned.
+func (s *Surveys) Wait(ctx context.Context, userId UserId, surveyId SurveyId, clientVersion int, timeout time.Duration) error {
+	select {
+	case <-s.shutdown:
+		return ErrShutdown
+	default:
+	}
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return nil
+	}
+
+	survey.Lock()
+	if survey.version > clientVersion || survey.waiters.closed {
+		survey.Unlock()
+		return nil
+	}
+	if s.maxWaiters > 0 && survey.waiters.count >= s.maxWaiters {
+		survey.Unlock()
+		s.waiterStats.rejected.Add(1)
+		return ErrTooManyWaiters
+	}
+	survey.waiters.count++
+	notify := survey.waiters.notify
+	survey.Unlock()
+
+	s.waiterStats.waiting.Add(1)
+	defer func() {
+		s.waiterStats.waiting.Add(-1)
+		survey.Lock()
+		survey.waiters.count--
+		survey.Unlock()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-notify:
+		s.waiterStats.notified.Add(1)
+	case <-timer.C:
+		s.waiterStats.timedOut.Add(1)
+	case <-ctx.Done():
+		s.waiterStats.canceled.Add(1)
+	case <-s.shutdown:
+		s.waiterStats.canceled.Add(1)
+		return ErrShutdown
+	}
+	return nil
+}
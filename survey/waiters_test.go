@@ -0,0 +1,79 @@
+package survey
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWait(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	s.SetMaxWaiters(1)
+	sid, err := s.New("creator", "", description)
+	assert.NoError(t, err)
+	version := s.GetResult("creator", sid).Version
+
+	// an outdated client returns immediately
+	assert.NoError(t, s.Wait(context.Background(), "creator", sid, version-1, time.Hour))
+
+	// a vote releases the waiting client
+	done := make(chan error)
+	go func() {
+		done <- s.Wait(context.Background(), "creator", sid, version, time.Hour)
+	}()
+	assert.Eventually(t, func() bool { return s.WaiterStats().Waiting == 1 }, time.Second, time.Millisecond)
+	assert.ErrorIs(t, s.Wait(context.Background(), "creator", sid, version, time.Hour), ErrTooManyWaiters)
+	assert.NoError(t, s.Vote(sid, "a", []int{0}, 1))
+	assert.NoError(t, <-done)
+
+	// the timeout and the client end the wait
+	version = s.GetResult("creator", sid).Version
+	assert.NoError(t, s.Wait(context.Background(), "creator", sid, version, time.Millisecond))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.NoError(t, s.Wait(ctx, "creator", sid, version, time.Hour))
+
+	// clearing the survey releases the waiting clients and later changes do not panic
+	go func() {
+		done <- s.Wait(context.Background(), "creator", sid, version, time.Hour)
+	}()
+	assert.Eventually(t, func() bool { return s.WaiterStats().Waiting == 1 }, time.Second, time.Millisecond)
+	survey, _ := s.getSurveyToVote(sid)
+	s.Clear(sid, "creator")
+	assert.NoError(t, <-done)
+	survey.Lock()
+	survey.changed()
+	survey.Unlock()
+
+	st := s.WaiterStats()
+	assert.EqualValues(t, 0, st.Waiting)
+	assert.EqualValues(t, 2, st.Notified)
+	assert.EqualValues(t, 1, st.TimedOut)
+	assert.EqualValues(t, 1, st.Canceled)
+	assert.EqualValues(t, 1, st.Rejected)
+}
+
+func TestClose(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	sid, err := s.New("creator", "", description)
+	assert.NoError(t, err)
+	version := s.GetResult("creator", sid).Version
+
+	done := make(chan error)
+	go func() {
+		done <- s.Wait(context.Background(), "creator", sid, version, time.Hour)
+	}()
+	assert.Eventually(t, func() bool { return s.WaiterStats().Waiting == 1 }, time.Second, time.Millisecond)
+
+	// closing releases the waiting client and rejects new ones
+	s.Close()
+	assert.ErrorIs(t, <-done, ErrShutdown)
+	assert.ErrorIs(t, s.Wait(context.Background(), "creator", sid, version, time.Hour), ErrShutdown)
+	_, _, err = s.Subscribe(sid)
+	assert.ErrorIs(t, err, ErrShutdown)
+
+	// closing twice does not panic
+	s.Close()
+}
@@ -0,0 +1,128 @@
+package survey
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxWriteIns is the maximum number of different write-in answers per question
+const maxWriteIns = 200
+
+// WriteIn is a free text answer given by the voters in addition to the
+// options. Similar answers are counted together.
+type WriteIn struct {
+	// Text is the answer as it was given first
+	Text  string
+	Count int
+}
+
+// normalizeWriteIn returns the key used to cluster similar answers. Case,
+// punctuation and whitespace are ignored.
+func normalizeWriteIn(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(words, " ")
+}
+
+// addWriteIn counts the answer. If the answer has been promoted to an
+// option, the option is counted instead. The survey must be locked.
+func (s *Survey) addWriteIn(text string) {
+	key := normalizeWriteIn(text)
+	for i := range s.options {
+		if normalizeWriteIn(s.options[i].Title) == key {
+			s.options[i].Votes++
+			return
+		}
+	}
+	if w, ok := s.writeIns[key]; ok {
+		w.Count++
+		return
+	}
+	if len(s.writeIns) >= maxWriteIns {
+		return
+	}
+	if s.writeIns == nil {
+		s.writeIns = make(map[string]*WriteIn)
+	}
+	s.writeIns[key] = &WriteIn{Text: text, Count: 1}
+}
+
+// writeInResult returns the answers, the most frequent first
+func (s *Survey) writeInResult() []WriteIn {
+	if len(s.writeIns) == 0 {
+		return nil
+	}
+	list := make([]WriteIn, 0, len(s.writeIns))
+	for _, w := range s.writeIns {
+		list = append(list, *w)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Text < list[j].Text
+	})
+	return list
+}
+
+// VoteWriteIn counts the vote of a voter who has given a free text answer.
+// If the question allows multiple options, the voter can select options in
+// addition to the answer. The answers are not counted as options.
+func (s *Surveys) VoteWriteIn(surveyId SurveyId, voterId UserId, option []int, writeIn string, number int) error {
+	writeIn = strings.TrimSpace(writeIn)
+	if writeIn == "" || normalizeWriteIn(writeIn) == "" {
+		return newError("answerEmpty")
+	}
+	if len(writeIn) > maxStringLen {
+		return newError("answerTooLong")
+	}
+	e, err := s.vote(surveyId, voterId, option, "", writeIn, number)
+	if err != nil {
+		return err
+	}
+	afterVote(e)
+	return nil
+}
+
+// PromoteWriteIn adds the given free text answer as a new option to the
+// running question. The votes of the voters who have given this answer are
+// counted for the new option, and later answers with the same text are
+// counted for the option as well.
+func (s *Surveys) PromoteWriteIn(userId UserId, surveyId SurveyId, text string) error {
+	survey, exists := s.getSurveyCheckUser(userId, surveyId)
+	if !exists {
+		return ErrSurveyNotFound
+	}
+
+	survey.Lock()
+	defer survey.Unlock()
+
+	if !s.mayControl(survey, userId) {
+		return ErrNotOwner
+	}
+
+	survey.applyPending()
+	key := normalizeWriteIn(text)
+	w, ok := survey.writeIns[key]
+	if !ok {
+		return ErrAnswerNotFound
+	}
+
+	i := len(survey.options)
+	if s.tooManyOptions(i + 1) {
+		return newError("maxOptions", s.maxOptions)
+	}
+	color, _ := survey.question.color(i)
+	survey.options = append(slices.Clone(survey.options), Option{Title: w.Text, Votes: w.Count, Color: color})
+	survey.question.Options = append(slices.Clone(survey.question.Options), w.Text)
+	survey.promoted++
+	survey.revision++
+	delete(survey.writeIns, key)
+	// the structure has changed, so the snapshots can not be used for deltas anymore
+	survey.history = nil
+	survey.changed()
+	return nil
+}
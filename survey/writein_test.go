@@ -0,0 +1,65 @@
+package survey
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteIn(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B"}, WriteIn: true}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+
+	assert.NoError(t, s.VoteWriteIn(sid, "a", nil, "Blue Sky", 1))
+	assert.NoError(t, s.VoteWriteIn(sid, "b", nil, " blue  sky! ", 1))
+	assert.NoError(t, s.VoteWriteIn(sid, "c", nil, "Green", 1))
+	assert.NoError(t, s.Vote(sid, "d", []int{0}, 1))
+
+	assert.Error(t, s.VoteWriteIn(sid, "e", nil, " ?! ", 1))
+	assert.Error(t, s.VoteWriteIn(sid, "e", []int{0}, "Red", 1))
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.Equal(t, []WriteIn{{Text: "Blue Sky", Count: 2}, {Text: "Green", Count: 1}}, r.WriteIns)
+	assert.Equal(t, "1", r.Result[0].Votes())
+	assert.Equal(t, "0", r.Result[1].Votes())
+	assert.Equal(t, 4, r.Votes)
+
+	q.WriteIn = false
+	sid, err = s.New("creator", "", q)
+	assert.NoError(t, err)
+	assert.Error(t, s.VoteWriteIn(sid, "a", nil, "Blue", 1))
+}
+
+func TestPromoteWriteIn(t *testing.T) {
+	s := New("localhost", 30, false, true)
+	q := SurveyQuestion{Title: "Test", Options: []string{"A", "B"}, WriteIn: true}
+	sid, err := s.New("creator", "", q)
+	assert.NoError(t, err)
+	assert.NoError(t, s.SetRemoteVotes(sid, 1, "poll", []int{1, 2}, 3))
+
+	assert.NoError(t, s.VoteWriteIn(sid, "a", nil, "Blue", 1))
+	assert.NoError(t, s.VoteWriteIn(sid, "b", nil, "blue", 1))
+	assert.NoError(t, s.VoteWriteIn(sid, "c", nil, "Green", 1))
+
+	assert.Error(t, s.PromoteWriteIn("other", sid, "Blue"))
+	assert.Error(t, s.PromoteWriteIn("creator", sid, "Red"))
+	assert.NoError(t, s.PromoteWriteIn("creator", sid, "BLUE"))
+
+	// the option can be selected and later answers are counted for it
+	assert.NoError(t, s.Vote(sid, "d", []int{2}, 1))
+	assert.NoError(t, s.VoteWriteIn(sid, "e", nil, "blue!", 1))
+	// the external source still knows only the original options
+	assert.NoError(t, s.SetRemoteVotes(sid, 1, "poll", []int{2, 2}, 4))
+
+	assert.NoError(t, s.Uncover("creator", sid))
+	r := s.GetResult("creator", sid)
+	assert.Equal(t, 3, len(r.Result))
+	assert.Equal(t, "Blue", r.Result[2].Title)
+	assert.Equal(t, "4", r.Result[2].Votes())
+	assert.Equal(t, []WriteIn{{Text: "Green", Count: 1}}, r.WriteIns)
+	assert.Equal(t, 9, r.Votes)
+	assert.Equal(t, "Blue", s.GetQuestion(sid).Question.Options[2])
+}